@@ -5,11 +5,11 @@ import (
 	"os"
 	"strings"
 
+	"cosign/pkg/cosignclient"
 	"git.sr.ht/~jakintosh/command-go/pkg/args"
 	cors "git.sr.ht/~jakintosh/command-go/pkg/cors/cmd"
 	"git.sr.ht/~jakintosh/command-go/pkg/envs"
 	keys "git.sr.ht/~jakintosh/command-go/pkg/keys/cmd"
-	"git.sr.ht/~jakintosh/command-go/pkg/wire"
 )
 
 var settingsCmd = &args.Command{
@@ -27,6 +27,7 @@ var apiCmd = &args.Command{
 	Subcommands: []*args.Command{
 		campaignCmd,
 		signaturesCmd,
+		webhooksCmd,
 		settingsCmd,
 	},
 }
@@ -35,19 +36,22 @@ func resolveClient(
 	i *args.Input,
 	pathPrefix string,
 ) (
-	wire.Client,
+	*cosignclient.Client,
 	error,
 ) {
 	client, err := envs.ResolveClient(i, DEFAULT_CFG, pathPrefix)
 	if err != nil {
-		return wire.Client{}, err
+		return nil, err
 	}
 
 	if strings.HasPrefix(client.BaseURL, "/") {
 		client.BaseURL = DEFAULT_BASE_URL + client.BaseURL
 	}
 
-	return client, nil
+	return cosignclient.New(cosignclient.Options{
+		BaseURL: client.BaseURL,
+		APIKey:  client.APIKey,
+	}), nil
 }
 
 func writeJSON(v any) error {
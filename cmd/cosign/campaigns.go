@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"cosign/internal/service"
+	"cosign/pkg/cosignclient"
 	"git.sr.ht/~jakintosh/command-go/pkg/args"
 )
 
@@ -18,7 +21,10 @@ var campaignCmd = &args.Command{
 		campaignCreateCmd,
 		campaignGetCmd,
 		campaignUpdateCmd,
+		campaignTranslateCmd,
 		campaignDeleteCmd,
+		campaignTrashCmd,
+		campaignRestoreCmd,
 		campaignLocationsCmd,
 	},
 }
@@ -32,8 +38,8 @@ var campaignListCmd = &args.Command{
 			return err
 		}
 
-		var response service.Campaigns
-		if err := client.Get("/admin/campaigns", &response); err != nil {
+		response, err := client.ListCampaigns(context.Background(), 0, 0)
+		if err != nil {
 			return err
 		}
 
@@ -66,18 +72,9 @@ var campaignCreateCmd = &args.Command{
 			return err
 		}
 
-		// build request
-		req := service.CreateCampaignRequest{
-			Name: name,
-		}
-		body, err := json.Marshal(req)
-		if err != nil {
-			return err
-		}
-
 		// post campaign
-		var response service.Campaign
-		if err := client.Post("/admin/campaigns", body, &response); err != nil {
+		response, err := client.CreateCampaign(context.Background(), name)
+		if err != nil {
 			return err
 		}
 
@@ -99,8 +96,8 @@ var campaignGetCmd = &args.Command{
 			return err
 		}
 
-		var response service.Campaign
-		if err := client.Get("/admin/campaigns/"+id, &response); err != nil {
+		response, err := client.GetCampaign(context.Background(), id)
+		if err != nil {
 			return err
 		}
 
@@ -128,6 +125,51 @@ var campaignUpdateCmd = &args.Command{
 			Type: args.OptionTypeFlag,
 			Help: "allow custom location text",
 		},
+		{
+			Long: "letter",
+			Type: args.OptionTypeParameter,
+			Help: "letter text shown on the public page",
+		},
+		{
+			Long: "location-level",
+			Type: args.OptionTypeParameter,
+			Help: "depth signers pick locations at (0 = most specific)",
+		},
+		{
+			Long: "language",
+			Type: args.OptionTypeParameter,
+			Help: "language tag of the base name and letter (default en)",
+		},
+		{
+			Long: "goal",
+			Type: args.OptionTypeParameter,
+			Help: "signature goal shown on badges and share cards (0 for none)",
+		},
+		{
+			Long: "primary-color",
+			Type: args.OptionTypeParameter,
+			Help: "public page primary color (#rrggbb)",
+		},
+		{
+			Long: "background-color",
+			Type: args.OptionTypeParameter,
+			Help: "public page background color (#rrggbb)",
+		},
+		{
+			Long: "logo-url",
+			Type: args.OptionTypeParameter,
+			Help: "public page logo URL",
+		},
+		{
+			Long: "success-url",
+			Type: args.OptionTypeParameter,
+			Help: "redirect after a form-encoded signature succeeds (must match a CORS origin)",
+		},
+		{
+			Long: "error-url",
+			Type: args.OptionTypeParameter,
+			Help: "redirect after a form-encoded signature fails, with ?error={code} (must match a CORS origin)",
+		},
 	},
 	Handler: func(i *args.Input) error {
 		// get input
@@ -167,14 +209,149 @@ var campaignUpdateCmd = &args.Command{
 			allowCustomText := true
 			payload.AllowCustomText = &allowCustomText
 		}
-		body, err := json.Marshal(payload)
+		if letter := i.GetParameter("letter"); letter != nil {
+			payload.Letter = letter
+		}
+		if rawLevel := i.GetParameter("location-level"); rawLevel != nil {
+			level, err := strconv.Atoi(strings.TrimSpace(*rawLevel))
+			if err != nil || level < 0 {
+				return fmt.Errorf("invalid location level %q", *rawLevel)
+			}
+			payload.LocationLevel = &level
+		}
+		if language := i.GetParameter("language"); language != nil {
+			payload.Language = language
+		}
+		if rawGoal := i.GetParameter("goal"); rawGoal != nil {
+			goal, err := strconv.Atoi(strings.TrimSpace(*rawGoal))
+			if err != nil || goal < 0 {
+				return fmt.Errorf("invalid goal %q", *rawGoal)
+			}
+			payload.Goal = &goal
+		}
+		if successURL := i.GetParameter("success-url"); successURL != nil {
+			payload.SuccessURL = successURL
+		}
+		if errorURL := i.GetParameter("error-url"); errorURL != nil {
+			payload.ErrorURL = errorURL
+		}
+
+		primaryColor := i.GetParameter("primary-color")
+		backgroundColor := i.GetParameter("background-color")
+		logoURL := i.GetParameter("logo-url")
+		if primaryColor != nil || backgroundColor != nil || logoURL != nil {
+			// theme is replaced as a whole, so start from the current one
+			existing, err := client.GetCampaign(context.Background(), id)
+			if err != nil {
+				return err
+			}
+
+			theme := existing.Theme
+			if primaryColor != nil {
+				theme.PrimaryColor = *primaryColor
+			}
+			if backgroundColor != nil {
+				theme.BackgroundColor = *backgroundColor
+			}
+			if logoURL != nil {
+				theme.LogoURL = *logoURL
+			}
+			payload.Theme = &theme
+		}
+
+		// send request
+		response, err := client.UpdateCampaign(context.Background(), id, payload)
 		if err != nil {
 			return err
 		}
 
-		// send request
-		var response service.Campaign
-		if err := client.Put("/admin/campaigns/"+id, body, &response); err != nil {
+		return writeJSON(response)
+	},
+}
+
+var campaignTranslateCmd = &args.Command{
+	Name: "translate",
+	Help: "set or remove a campaign translation",
+	Operands: []args.Operand{
+		{
+			Name: "lang",
+			Help: "language tag, e.g. fr or es",
+		},
+	},
+	Options: []args.Option{
+		{
+			Long: "name",
+			Type: args.OptionTypeParameter,
+			Help: "translated campaign name",
+		},
+		{
+			Long: "letter",
+			Type: args.OptionTypeParameter,
+			Help: "translated letter text",
+		},
+		{
+			Long: "remove",
+			Type: args.OptionTypeFlag,
+			Help: "remove the translation",
+		},
+	},
+	Handler: func(i *args.Input) error {
+		// get input
+		lang := strings.ToLower(strings.TrimSpace(i.GetOperand("lang")))
+		name := i.GetParameter("name")
+		letter := i.GetParameter("letter")
+		remove := i.GetFlag("remove")
+		id, err := resolveCampaignId(i)
+		if err != nil {
+			return err
+		}
+
+		// validate input
+		if lang == "" {
+			return fmt.Errorf("language required")
+		}
+		if remove && (name != nil || letter != nil) {
+			return fmt.Errorf("use either --remove or --name/--letter")
+		}
+		if !remove && name == nil && letter == nil {
+			return fmt.Errorf("--name or --letter required")
+		}
+
+		// setup client
+		client, err := resolveClient(i, API_PREFIX)
+		if err != nil {
+			return err
+		}
+
+		// translations are replaced as a whole, so start from the current set
+		existing, err := client.GetCampaign(context.Background(), id)
+		if err != nil {
+			return err
+		}
+
+		translations := existing.Translations
+		if translations == nil {
+			translations = make(map[string]service.CampaignTranslation)
+		}
+		if remove {
+			delete(translations, lang)
+		} else {
+			translation := translations[lang]
+			if name != nil {
+				translation.Name = *name
+			}
+			if letter != nil {
+				translation.Letter = *letter
+			}
+			translations[lang] = translation
+		}
+
+		// fail rather than drop a translation written since the read
+		response, err := client.UpdateCampaign(context.Background(), id, service.UpdateCampaignRequest{
+			Translations: translations,
+			Revision:     &existing.Revision,
+		})
+		if err != nil {
 			return err
 		}
 
@@ -184,7 +361,7 @@ var campaignUpdateCmd = &args.Command{
 
 var campaignDeleteCmd = &args.Command{
 	Name: "delete",
-	Help: "delete campaign",
+	Help: "move campaign to the trash",
 	Handler: func(i *args.Input) error {
 		id, err := resolveCampaignId(i)
 		if err != nil {
@@ -196,21 +373,70 @@ var campaignDeleteCmd = &args.Command{
 			return err
 		}
 
-		if err := client.Delete("/admin/campaigns/"+id, nil); err != nil {
+		if err := client.DeleteCampaign(context.Background(), id); err != nil {
 			return err
 		}
 
-		fmt.Println("campaign deleted")
+		fmt.Println("campaign moved to trash")
 		return nil
 	},
 }
 
+var campaignTrashCmd = &args.Command{
+	Name: "trash",
+	Help: "list campaigns in the trash",
+	Handler: func(i *args.Input) error {
+		client, err := resolveClient(i, API_PREFIX)
+		if err != nil {
+			return err
+		}
+
+		response, err := client.ListDeletedCampaigns(context.Background(), 0, 0)
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(response)
+	},
+}
+
+var campaignRestoreCmd = &args.Command{
+	Name: "restore",
+	Help: "restore campaign from the trash",
+	Handler: func(i *args.Input) error {
+		id, err := resolveCampaignId(i)
+		if err != nil {
+			return err
+		}
+
+		client, err := resolveClient(i, API_PREFIX)
+		if err != nil {
+			return err
+		}
+
+		response, err := client.RestoreCampaign(context.Background(), id)
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(response)
+	},
+}
+
 var campaignLocationsCmd = &args.Command{
 	Name: "locations",
 	Help: "manage campaign locations",
 	Subcommands: []*args.Command{
 		campaignLocationsGetCmd,
 		campaignLocationsSetCmd,
+		campaignLocationsTranslateCmd,
+		campaignLocationsStatsCmd,
+		campaignLocationsRenameCmd,
+		campaignLocationsAliasesCmd,
+		campaignLocationsCoordinatesCmd,
+		campaignLocationsMergeCmd,
+		campaignLocationsSuggestCmd,
+		campaignLocationsNormalizeCmd,
 	},
 }
 
@@ -228,8 +454,8 @@ var campaignLocationsGetCmd = &args.Command{
 			return err
 		}
 
-		var response service.CampaignLocationsResponse
-		if err := client.Get("/admin/campaigns/"+id+"/locations", &response); err != nil {
+		response, err := client.GetLocations(context.Background(), id)
+		if err != nil {
 			return err
 		}
 
@@ -240,29 +466,135 @@ var campaignLocationsGetCmd = &args.Command{
 var campaignLocationsSetCmd = &args.Command{
 	Name: "set",
 	Help: "replace campaign locations",
+	Options: []args.Option{
+		{
+			Long: "location",
+			Type: args.OptionTypeArray,
+			Help: "location values in desired display order",
+		},
+		{
+			Long: "file",
+			Type: args.OptionTypeParameter,
+			Help: "JSON file with a (possibly nested) location list",
+		},
+	},
+	Handler: func(i *args.Input) error {
+		values := i.GetArray("location")
+		file := i.GetParameter("file")
+		if file != nil && len(values) > 0 {
+			return fmt.Errorf("use either --location or --file")
+		}
+
+		id, err := resolveCampaignId(i)
+		if err != nil {
+			return err
+		}
+
+		client, err := resolveClient(i, API_PREFIX)
+		if err != nil {
+			return err
+		}
+
+		var locations []service.LocationOption
+		var revision *int64
+		if file != nil {
+			data, err := os.ReadFile(*file)
+			if err != nil {
+				return fmt.Errorf("read locations file: %w", err)
+			}
+			if err := json.Unmarshal(data, &locations); err != nil {
+				return fmt.Errorf("parse locations file: %w", err)
+			}
+		} else {
+			// keep labels, codes, coordinates, and parents for values that stay in the list
+			existing, err := client.GetLocations(context.Background(), id)
+			if err != nil {
+				return err
+			}
+			revision = &existing.Revision
+			previous := make(map[string]service.LocationOption, len(existing.Locations))
+			for _, loc := range existing.Locations {
+				previous[loc.Value] = loc
+			}
+
+			kept := make(map[string]bool, len(values))
+			for _, value := range values {
+				kept[strings.TrimSpace(value)] = true
+			}
+
+			locations = make([]service.LocationOption, 0, len(values))
+			for idx, value := range values {
+				trimmed := strings.TrimSpace(value)
+				if trimmed == "" {
+					continue
+				}
+				prev := previous[trimmed]
+				loc := service.LocationOption{
+					Value:           trimmed,
+					DisplayOrder:    idx + 1,
+					CountryCode:     prev.CountryCode,
+					SubdivisionCode: prev.SubdivisionCode,
+					Labels:          prev.Labels,
+					Aliases:         prev.Aliases,
+					Latitude:        prev.Latitude,
+					Longitude:       prev.Longitude,
+				}
+				if kept[prev.Parent] {
+					loc.Parent = prev.Parent
+				}
+				locations = append(locations, loc)
+			}
+		}
+
+		response, err := client.SetLocations(context.Background(), id, service.CampaignLocationsRequest{
+			Locations: locations,
+			Revision:  revision,
+		})
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(response)
+	},
+}
+
+var campaignLocationsTranslateCmd = &args.Command{
+	Name: "translate",
+	Help: "set translated location labels",
+	Operands: []args.Operand{
+		{
+			Name: "lang",
+			Help: "language tag, e.g. fr or es",
+		},
+	},
 	Options: []args.Option{{
-		Long: "location",
+		Long: "label",
 		Type: args.OptionTypeArray,
-		Help: "location values in desired display order",
+		Help: "translated label as VALUE=LABEL; an empty LABEL removes it",
 	}},
 	Handler: func(i *args.Input) error {
-		values := i.GetArray("location")
+		lang := strings.ToLower(strings.TrimSpace(i.GetOperand("lang")))
+		pairs := i.GetArray("label")
 
 		id, err := resolveCampaignId(i)
 		if err != nil {
 			return err
 		}
 
-		locations := make([]service.LocationOption, 0, len(values))
-		for idx, value := range values {
-			trimmed := strings.TrimSpace(value)
-			if trimmed == "" {
-				continue
+		if lang == "" {
+			return fmt.Errorf("language required")
+		}
+		if len(pairs) == 0 {
+			return fmt.Errorf("at least one --label required")
+		}
+
+		updates := make(map[string]string, len(pairs))
+		for _, pair := range pairs {
+			value, label, ok := strings.Cut(pair, "=")
+			if !ok || strings.TrimSpace(value) == "" {
+				return fmt.Errorf("invalid label %q; use VALUE=LABEL", pair)
 			}
-			locations = append(locations, service.LocationOption{
-				Value:        trimmed,
-				DisplayOrder: idx + 1,
-			})
+			updates[strings.TrimSpace(value)] = strings.TrimSpace(label)
 		}
 
 		client, err := resolveClient(i, API_PREFIX)
@@ -270,16 +602,196 @@ var campaignLocationsSetCmd = &args.Command{
 			return err
 		}
 
-		req := service.CampaignLocationsRequest{
+		existing, err := client.GetLocations(context.Background(), id)
+		if err != nil {
+			return err
+		}
+
+		locations := existing.Locations
+		for idx := range locations {
+			label, ok := updates[locations[idx].Value]
+			if !ok {
+				continue
+			}
+			delete(updates, locations[idx].Value)
+
+			if locations[idx].Labels == nil {
+				locations[idx].Labels = make(map[string]string)
+			}
+			if label == "" {
+				delete(locations[idx].Labels, lang)
+			} else {
+				locations[idx].Labels[lang] = label
+			}
+		}
+		for value := range updates {
+			return fmt.Errorf("location %q not found", value)
+		}
+
+		response, err := client.SetLocations(context.Background(), id, service.CampaignLocationsRequest{
 			Locations: locations,
+			Revision:  &existing.Revision,
+		})
+		if err != nil {
+			return err
 		}
-		body, err := json.Marshal(req)
+
+		return writeJSON(response)
+	},
+}
+
+var campaignLocationsStatsCmd = &args.Command{
+	Name: "stats",
+	Help: "show signature counts rolled up by location",
+	Handler: func(i *args.Input) error {
+		id, err := resolveCampaignId(i)
 		if err != nil {
 			return err
 		}
 
-		var response service.CampaignLocationsResponse
-		if err := client.Put("/admin/campaigns/"+id+"/locations", body, &response); err != nil {
+		client, err := resolveClient(i, API_PREFIX)
+		if err != nil {
+			return err
+		}
+
+		response, err := client.LocationStats(context.Background(), id)
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(response)
+	},
+}
+
+var campaignLocationsRenameCmd = &args.Command{
+	Name: "rename",
+	Help: "rename a location and the signatures that chose it",
+	Operands: []args.Operand{
+		{
+			Name: "value",
+			Help: "current location value",
+		},
+		{
+			Name: "new-value",
+			Help: "new location value",
+		},
+	},
+	Handler: func(i *args.Input) error {
+		value := strings.TrimSpace(i.GetOperand("value"))
+		newValue := strings.TrimSpace(i.GetOperand("new-value"))
+
+		id, err := resolveCampaignId(i)
+		if err != nil {
+			return err
+		}
+
+		client, err := resolveClient(i, API_PREFIX)
+		if err != nil {
+			return err
+		}
+
+		locations, err := fetchLocationIDs(client, id)
+		if err != nil {
+			return err
+		}
+		locationID, ok := locations[value]
+		if !ok {
+			return fmt.Errorf("location %q not found", value)
+		}
+
+		response, err := client.UpdateLocation(context.Background(), id, locationID, service.UpdateLocationRequest{Value: &newValue})
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(response)
+	},
+}
+
+var campaignLocationsAliasesCmd = &args.Command{
+	Name: "aliases",
+	Help: "replace the aliases signers may type for a location",
+	Operands: []args.Operand{
+		{
+			Name: "value",
+			Help: "location value",
+		},
+	},
+	Options: []args.Option{{
+		Long: "alias",
+		Type: args.OptionTypeArray,
+		Help: "alternate spelling; omit to clear all aliases",
+	}},
+	Handler: func(i *args.Input) error {
+		value := strings.TrimSpace(i.GetOperand("value"))
+		aliases := i.GetArray("alias")
+		if aliases == nil {
+			aliases = []string{}
+		}
+
+		id, err := resolveCampaignId(i)
+		if err != nil {
+			return err
+		}
+
+		client, err := resolveClient(i, API_PREFIX)
+		if err != nil {
+			return err
+		}
+
+		locations, err := fetchLocationIDs(client, id)
+		if err != nil {
+			return err
+		}
+		locationID, ok := locations[value]
+		if !ok {
+			return fmt.Errorf("location %q not found", value)
+		}
+
+		response, err := client.UpdateLocation(context.Background(), id, locationID, service.UpdateLocationRequest{Aliases: aliases})
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(response)
+	},
+}
+
+var campaignLocationsCoordinatesCmd = &args.Command{
+	Name: "coordinates",
+	Help: "load location coordinates from a gazetteer csv",
+	Operands: []args.Operand{
+		{
+			Name: "file",
+			Help: "csv with name, lat and lng columns",
+		},
+	},
+	Handler: func(i *args.Input) error {
+		path := i.GetOperand("file")
+
+		id, err := resolveCampaignId(i)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("open gazetteer: %w", err)
+		}
+		defer file.Close()
+
+		coordinates, err := service.ParseGazetteerCSV(file)
+		if err != nil {
+			return fmt.Errorf("parse gazetteer: %w", err)
+		}
+
+		client, err := resolveClient(i, API_PREFIX)
+		if err != nil {
+			return err
+		}
+
+		response, err := client.ImportCoordinates(context.Background(), id, coordinates)
+		if err != nil {
 			return err
 		}
 
@@ -287,6 +799,177 @@ var campaignLocationsSetCmd = &args.Command{
 	},
 }
 
+var campaignLocationsMergeCmd = &args.Command{
+	Name: "merge",
+	Help: "merge a location, its children and signatures into another",
+	Operands: []args.Operand{
+		{
+			Name: "value",
+			Help: "location value to merge away",
+		},
+		{
+			Name: "into",
+			Help: "location value to keep",
+		},
+	},
+	Handler: func(i *args.Input) error {
+		value := strings.TrimSpace(i.GetOperand("value"))
+		into := strings.TrimSpace(i.GetOperand("into"))
+
+		id, err := resolveCampaignId(i)
+		if err != nil {
+			return err
+		}
+
+		client, err := resolveClient(i, API_PREFIX)
+		if err != nil {
+			return err
+		}
+
+		locations, err := fetchLocationIDs(client, id)
+		if err != nil {
+			return err
+		}
+		sourceID, ok := locations[value]
+		if !ok {
+			return fmt.Errorf("location %q not found", value)
+		}
+		targetID, ok := locations[into]
+		if !ok {
+			return fmt.Errorf("location %q not found", into)
+		}
+
+		response, err := client.MergeLocation(context.Background(), id, sourceID, targetID)
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(response)
+	},
+}
+
+var campaignLocationsSuggestCmd = &args.Command{
+	Name: "suggest",
+	Help: "suggest presets for custom signature locations",
+	Handler: func(i *args.Input) error {
+		id, err := resolveCampaignId(i)
+		if err != nil {
+			return err
+		}
+
+		client, err := resolveClient(i, API_PREFIX)
+		if err != nil {
+			return err
+		}
+
+		response, err := client.LocationSuggestions(context.Background(), id)
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(response)
+	},
+}
+
+var campaignLocationsNormalizeCmd = &args.Command{
+	Name: "normalize",
+	Help: "rewrite custom signature locations onto presets",
+	Options: []args.Option{
+		{
+			Long: "map",
+			Type: args.OptionTypeArray,
+			Help: "mapping as SIGNED=PRESET",
+		},
+		{
+			Long: "suggested",
+			Type: args.OptionTypeFlag,
+			Help: "apply every suggestion",
+		},
+	},
+	Handler: func(i *args.Input) error {
+		pairs := i.GetArray("map")
+		suggested := i.GetFlag("suggested")
+
+		id, err := resolveCampaignId(i)
+		if err != nil {
+			return err
+		}
+
+		if len(pairs) == 0 && !suggested {
+			return fmt.Errorf("at least one --map or --suggested required")
+		}
+
+		client, err := resolveClient(i, API_PREFIX)
+		if err != nil {
+			return err
+		}
+
+		var mappings []service.LocationMapping
+		if suggested {
+			suggestions, err := client.LocationSuggestions(context.Background(), id)
+			if err != nil {
+				return err
+			}
+			for _, suggestion := range suggestions.Suggestions {
+				if suggestion.LocationID == 0 {
+					continue
+				}
+				mappings = append(mappings, service.LocationMapping{
+					Value:      suggestion.Value,
+					LocationID: suggestion.LocationID,
+				})
+			}
+		}
+
+		if len(pairs) > 0 {
+			locations, err := fetchLocationIDs(client, id)
+			if err != nil {
+				return err
+			}
+			for _, pair := range pairs {
+				value, preset, ok := strings.Cut(pair, "=")
+				if !ok || value == "" {
+					return fmt.Errorf("invalid mapping %q; use SIGNED=PRESET", pair)
+				}
+				locationID, ok := locations[strings.TrimSpace(preset)]
+				if !ok {
+					return fmt.Errorf("location %q not found", preset)
+				}
+				mappings = append(mappings, service.LocationMapping{
+					Value:      value,
+					LocationID: locationID,
+				})
+			}
+		}
+
+		rewritten, err := client.NormalizeLocations(context.Background(), id, mappings)
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(service.NormalizeLocationsResponse{Rewritten: rewritten})
+	},
+}
+
+func fetchLocationIDs(
+	client *cosignclient.Client,
+	campaignID string,
+) (
+	map[string]int64,
+	error,
+) {
+	response, err := client.GetLocations(context.Background(), campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]int64, len(response.Locations))
+	for _, loc := range response.Locations {
+		ids[loc.Value] = loc.ID
+	}
+	return ids, nil
+}
+
 func resolveCampaignId(
 	i *args.Input,
 ) (
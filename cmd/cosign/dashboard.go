@@ -2,24 +2,86 @@ package main
 
 import (
 	"cosign/internal/app"
+	"cosign/internal/database"
+	"cosign/pkg/cosignclient"
 	"fmt"
 	"log"
+	"net"
+	"os"
 	"strings"
+	"time"
 
 	"git.sr.ht/~jakintosh/command-go/pkg/args"
-	"git.sr.ht/~jakintosh/command-go/pkg/wire"
 )
 
 const (
-	DEFAULT_DASHBOARD_PORT      = "3000"
-	DEFAULT_DASHBOARD_CREDS_DIR = "./secrets"
-	DEFAULT_DASHBOARD_KEY_FILE  = "api_key"
+	DEFAULT_DASHBOARD_PORT        = "3000"
+	DEFAULT_DASHBOARD_CREDS_DIR   = "./secrets"
+	DEFAULT_DASHBOARD_KEY_FILE    = "api_key"
+	DEFAULT_DASHBOARD_DB_PATH     = "./data/dashboard.db"
+	DEFAULT_DASHBOARD_SESSION_TTL = "12h"
 )
 
+// dashboardAuthOptions are the sign-in options shared by dashboard and
+// serve --with-dashboard.
+var dashboardAuthOptions = []args.Option{
+	{
+		Long: "session-ttl",
+		Type: args.OptionTypeParameter,
+		Help: "how long a dashboard sign-in lasts, e.g. 12h",
+	},
+	{
+		Long: "secure-cookies",
+		Type: args.OptionTypeFlag,
+		Help: "always mark dashboard cookies Secure, e.g. behind a TLS-terminating proxy",
+	},
+}
+
+func resolveDashboardAuthOptions(
+	i *args.Input,
+	auth app.AuthStore,
+) (
+	app.Options,
+	error,
+) {
+	rawSessionTTL := resolveOption(i, "session-ttl", "COSIGN_DASHBOARD_SESSION_TTL", DEFAULT_DASHBOARD_SESSION_TTL)
+	secureCookies := i.GetFlag("secure-cookies") || isTruthy(os.Getenv("COSIGN_DASHBOARD_SECURE_COOKIES"))
+
+	sessionTTL, err := time.ParseDuration(strings.TrimSpace(rawSessionTTL))
+	if err != nil || sessionTTL <= 0 {
+		return app.Options{}, fmt.Errorf("invalid session ttl %q", rawSessionTTL)
+	}
+
+	return app.Options{
+		PageSize:      10,
+		Auth:          auth,
+		SessionTTL:    sessionTTL,
+		SecureCookies: secureCookies,
+	}, nil
+}
+
+// openDashboardDB opens the database holding dashboard users and sessions.
+func openDashboardDB(i *args.Input) (*database.DB, error) {
+	rawDBPath := resolveOption(i, "db-path", "COSIGN_DASHBOARD_DB_PATH", DEFAULT_DASHBOARD_DB_PATH)
+	dbPath := strings.TrimSpace(rawDBPath)
+	if dbPath == "" {
+		return nil, fmt.Errorf("database path required")
+	}
+
+	db, err := database.Open(database.Options{Path: dbPath, WAL: true})
+	if err != nil {
+		return nil, fmt.Errorf("initialize database: %w", err)
+	}
+	return db, nil
+}
+
 var dashboardCmd = &args.Command{
 	Name: "dashboard",
 	Help: "run the admin dashboard web UI",
-	Options: []args.Option{
+	Subcommands: []*args.Command{
+		dashboardUsersCmd,
+	},
+	Options: append(append([]args.Option{
 		{
 			Long: "port",
 			Type: args.OptionTypeParameter,
@@ -45,7 +107,12 @@ var dashboardCmd = &args.Command{
 			Type: args.OptionTypeParameter,
 			Help: "api key filename",
 		},
-	},
+		{
+			Long: "db-path",
+			Type: args.OptionTypeParameter,
+			Help: "database file for dashboard users and sessions",
+		},
+	}, httpServerOptions...), dashboardAuthOptions...),
 	Handler: func(i *args.Input) error {
 		rawPort := resolveOption(i, "port", "COSIGN_DASHBOARD_PORT", DEFAULT_DASHBOARD_PORT)
 		rawAPIBaseURL := resolveOption(i, "api-base-url", "COSIGN_DASHBOARD_API_BASE_URL", DEFAULT_BASE_URL)
@@ -81,22 +148,53 @@ var dashboardCmd = &args.Command{
 			return fmt.Errorf("api base URL required")
 		}
 
+		serverOpts, err := resolveHTTPServerOptions(i, "COSIGN_DASHBOARD_")
+		if err != nil {
+			return err
+		}
+
 		apiKey, err := loadCredential(apiKeyFile, credentialsDir)
 		if err != nil {
 			return err
 		}
 
-		client := wire.Client{
+		db, err := openDashboardDB(i)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := db.Close(); err != nil {
+				log.Printf("close database: %v", err)
+			}
+		}()
+
+		dashboardOpts, err := resolveDashboardAuthOptions(i, db)
+		if err != nil {
+			return err
+		}
+		dashboardOpts.Client = cosignclient.New(cosignclient.Options{
 			BaseURL: strings.TrimRight(apiBaseURL, "/") + apiPrefix,
 			APIKey:  apiKey,
-		}
+		})
 
-		dashboard, err := app.New(app.Options{Client: client, PageSize: 10})
+		dashboard, err := app.New(dashboardOpts)
 		if err != nil {
 			return fmt.Errorf("initialize dashboard: %w", err)
 		}
 
+		ctx, stop := shutdownContext()
+		defer stop()
+
+		ln, err := net.Listen("tcp", port)
+		if err != nil {
+			return err
+		}
+
 		log.Printf("Starting dashboard on %s...", port)
-		return dashboard.Serve(port)
+		if err := dashboard.Serve(ctx, ln, serverOpts); err != nil {
+			return err
+		}
+		log.Printf("Dashboard stopped")
+		return nil
 	},
 }
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"cosign/internal/app"
+	"fmt"
+	"os"
+	"strings"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/args"
+	"golang.org/x/term"
+)
+
+var dashboardUsersCmd = &args.Command{
+	Name: "users",
+	Help: "manage dashboard user accounts in --db-path",
+	Subcommands: []*args.Command{
+		dashboardUsersListCmd,
+		dashboardUsersAddCmd,
+		dashboardUsersPasswordCmd,
+		dashboardUsersDeleteCmd,
+		dashboardUsersGrantCmd,
+		dashboardUsersRevokeCmd,
+	},
+}
+
+var dashboardUsersListCmd = &args.Command{
+	Name: "list",
+	Help: "list dashboard users and their roles",
+	Handler: func(i *args.Input) error {
+		db, err := openDashboardDB(i)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		users, err := app.ListUsers(db)
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(users)
+	},
+}
+
+var dashboardUsersAddCmd = &args.Command{
+	Name: "add",
+	Help: "add a dashboard user; the password is prompted for, or read from stdin",
+	Operands: []args.Operand{
+		{
+			Name: "username",
+			Help: "username",
+		},
+	},
+	Options: []args.Option{
+		{
+			Long: "role",
+			Type: args.OptionTypeParameter,
+			Help: "role to grant (viewer, editor, owner); scoped to --campaign-id when set, otherwise global",
+		},
+	},
+	Handler: func(i *args.Input) error {
+		username := i.GetOperand("username")
+		role, err := app.ParseRole(i.GetParameterOr("role", string(app.RoleViewer)))
+		if err != nil {
+			return err
+		}
+		campaignID := strings.TrimSpace(i.GetParameterOr("campaign-id", ""))
+
+		password, err := readPassword()
+		if err != nil {
+			return err
+		}
+
+		db, err := openDashboardDB(i)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		user, err := app.CreateUser(db, username, password)
+		if err != nil {
+			return err
+		}
+
+		assignment := app.RoleAssignment{CampaignID: campaignID, Role: role}
+		if err := app.GrantRole(db, user.Username, assignment); err != nil {
+			return err
+		}
+		user.Roles = []app.RoleAssignment{assignment}
+
+		return writeJSON(user)
+	},
+}
+
+var dashboardUsersPasswordCmd = &args.Command{
+	Name: "set-password",
+	Help: "change a dashboard user's password and sign out their sessions",
+	Operands: []args.Operand{
+		{
+			Name: "username",
+			Help: "username",
+		},
+	},
+	Handler: func(i *args.Input) error {
+		username := i.GetOperand("username")
+
+		password, err := readPassword()
+		if err != nil {
+			return err
+		}
+
+		db, err := openDashboardDB(i)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		return app.SetUserPassword(db, username, password)
+	},
+}
+
+var dashboardUsersDeleteCmd = &args.Command{
+	Name: "delete",
+	Help: "delete a dashboard user and their sessions",
+	Operands: []args.Operand{
+		{
+			Name: "username",
+			Help: "username",
+		},
+	},
+	Handler: func(i *args.Input) error {
+		db, err := openDashboardDB(i)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		return app.DeleteUser(db, i.GetOperand("username"))
+	},
+}
+
+var dashboardUsersGrantCmd = &args.Command{
+	Name: "grant",
+	Help: "set a dashboard user's role; scoped to --campaign-id when set, otherwise global",
+	Operands: []args.Operand{
+		{
+			Name: "username",
+			Help: "username",
+		},
+		{
+			Name: "role",
+			Help: "viewer, editor or owner",
+		},
+	},
+	Handler: func(i *args.Input) error {
+		role, err := app.ParseRole(i.GetOperand("role"))
+		if err != nil {
+			return err
+		}
+		campaignID := strings.TrimSpace(i.GetParameterOr("campaign-id", ""))
+
+		db, err := openDashboardDB(i)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		return app.GrantRole(db, i.GetOperand("username"), app.RoleAssignment{
+			CampaignID: campaignID,
+			Role:       role,
+		})
+	},
+}
+
+var dashboardUsersRevokeCmd = &args.Command{
+	Name: "revoke",
+	Help: "remove a dashboard user's role; scoped to --campaign-id when set, otherwise global",
+	Operands: []args.Operand{
+		{
+			Name: "username",
+			Help: "username",
+		},
+	},
+	Handler: func(i *args.Input) error {
+		campaignID := strings.TrimSpace(i.GetParameterOr("campaign-id", ""))
+
+		db, err := openDashboardDB(i)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		return app.RevokeRole(db, i.GetOperand("username"), campaignID)
+	},
+}
+
+// readPassword prompts twice without echo on a terminal, and otherwise
+// reads the first line of stdin.
+func readPassword() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("read password from stdin: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("read password: %w", err)
+	}
+
+	fmt.Fprint(os.Stderr, "Confirm password: ")
+	confirm, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("read password: %w", err)
+	}
+
+	if string(password) != string(confirm) {
+		return "", fmt.Errorf("passwords do not match")
+	}
+	return string(password), nil
+}
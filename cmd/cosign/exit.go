@@ -0,0 +1,73 @@
+package main
+
+import (
+	"cosign/pkg/cosignclient"
+	"fmt"
+	"net/http"
+	"os"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/args"
+)
+
+// Exit statuses for API failures, so scripts can branch on the kind of
+// error without parsing messages. Other errors exit with 1.
+const (
+	EXIT_ERROR        = 1
+	EXIT_INVALID      = 2
+	EXIT_NOT_FOUND    = 3
+	EXIT_CONFLICT     = 4
+	EXIT_AUTH         = 5
+	EXIT_RATE_LIMITED = 6
+	EXIT_SERVER       = 7
+)
+
+// withExitCodes wraps every handler in the command tree so API errors print
+// their code and field details and exit with a status for their kind.
+func withExitCodes(cmd *args.Command) {
+	if handler := cmd.Handler; handler != nil {
+		cmd.Handler = func(i *args.Input) error {
+			err := handler(i)
+			apiErr, ok := cosignclient.AsError(err)
+			if !ok {
+				return err
+			}
+
+			printAPIError(apiErr)
+			os.Exit(exitCodeForStatus(apiErr.Status))
+			return nil
+		}
+	}
+	for _, sub := range cmd.Subcommands {
+		withExitCodes(sub)
+	}
+}
+
+func printAPIError(apiErr *cosignclient.Error) {
+	if apiErr.Code == "" {
+		fmt.Fprintf(os.Stderr, "error: %s\n", apiErr.Message)
+	} else {
+		fmt.Fprintf(os.Stderr, "error [%s]: %s\n", apiErr.Code, apiErr.Message)
+	}
+	for _, field := range apiErr.Fields {
+		fmt.Fprintf(os.Stderr, "  %s: %s [%s]\n", field.Field, field.Message, field.Code)
+	}
+}
+
+func exitCodeForStatus(status int) int {
+	switch {
+	case status == http.StatusNotFound:
+		return EXIT_NOT_FOUND
+	case status == http.StatusConflict || status == http.StatusPreconditionFailed:
+		return EXIT_CONFLICT
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return EXIT_AUTH
+	case status == http.StatusTooManyRequests:
+		return EXIT_RATE_LIMITED
+	case status >= http.StatusInternalServerError:
+		return EXIT_SERVER
+	case status >= http.StatusBadRequest:
+		return EXIT_INVALID
+	default:
+		return EXIT_ERROR
+	}
+}
@@ -17,6 +17,7 @@ const (
 )
 
 func main() {
+	withExitCodes(root)
 	root.Parse()
 }
 
@@ -1,14 +1,26 @@
 package main
 
 import (
+	"context"
+	"cosign/internal/app"
 	"cosign/internal/database"
+	"cosign/internal/httpserver"
+	"cosign/internal/public"
 	"cosign/internal/service"
+	"cosign/pkg/cosignclient"
+	"errors"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"git.sr.ht/~jakintosh/command-go/pkg/args"
 	"git.sr.ht/~jakintosh/command-go/pkg/cors"
@@ -19,6 +31,9 @@ const (
 	DEFAULT_CREDS_DIR       = "/etc/cosign"
 	DEFAULT_DB_PATH         = "/var/lib/cosign/cosign.db"
 	DEFAULT_ALLOWED_ORIGINS = "http://localhost:3000"
+	DEFAULT_IDEMPOTENCY_TTL = "24h"
+	DEFAULT_BULK_UNDO       = "10m"
+	DEFAULT_TRASH_RETENTION = "720h"
 )
 
 func resolveOption(
@@ -80,6 +95,13 @@ func parseCSVValues(
 	return values
 }
 
+func isTruthy(
+	raw string,
+) bool {
+	value, err := strconv.ParseBool(strings.TrimSpace(raw))
+	return err == nil && value
+}
+
 func loadCredential(
 	name string,
 	credsDir string,
@@ -101,10 +123,71 @@ func loadCredential(
 	return value, nil
 }
 
+// httpServerOptions are the timeout options shared by serve and dashboard.
+var httpServerOptions = []args.Option{
+	{
+		Long: "read-timeout",
+		Type: args.OptionTypeParameter,
+		Help: "max time to read a request, e.g. 15s",
+	},
+	{
+		Long: "write-timeout",
+		Type: args.OptionTypeParameter,
+		Help: "max time to write a response, e.g. 30s (event streams are exempt)",
+	},
+	{
+		Long: "idle-timeout",
+		Type: args.OptionTypeParameter,
+		Help: "how long idle keep-alive connections stay open, e.g. 60s",
+	},
+	{
+		Long: "shutdown-timeout",
+		Type: args.OptionTypeParameter,
+		Help: "how long in-flight requests may finish on SIGINT/SIGTERM, e.g. 20s",
+	},
+}
+
+func resolveHTTPServerOptions(
+	i *args.Input,
+	envPrefix string,
+) (
+	httpserver.Options,
+	error,
+) {
+	opts := httpserver.Options{}
+	durations := []struct {
+		option string
+		env    string
+		def    time.Duration
+		target *time.Duration
+	}{
+		{"read-timeout", "READ_TIMEOUT", httpserver.DefaultReadTimeout, &opts.ReadTimeout},
+		{"write-timeout", "WRITE_TIMEOUT", httpserver.DefaultWriteTimeout, &opts.WriteTimeout},
+		{"idle-timeout", "IDLE_TIMEOUT", httpserver.DefaultIdleTimeout, &opts.IdleTimeout},
+		{"shutdown-timeout", "SHUTDOWN_TIMEOUT", httpserver.DefaultShutdownTimeout, &opts.ShutdownTimeout},
+	}
+
+	for _, d := range durations {
+		raw := resolveOption(i, d.option, envPrefix+d.env, d.def.String())
+		value, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil || value <= 0 {
+			return httpserver.Options{}, fmt.Errorf("invalid %s %q", d.option, raw)
+		}
+		*d.target = value
+	}
+
+	return opts, nil
+}
+
+// shutdownContext ends on SIGINT or SIGTERM.
+func shutdownContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
 var serveCmd = &args.Command{
 	Name: "serve",
 	Help: "run the cosign HTTP API server",
-	Options: []args.Option{
+	Options: append([]args.Option{
 		{
 			Long: "db-path",
 			Type: args.OptionTypeParameter,
@@ -125,13 +208,49 @@ var serveCmd = &args.Command{
 			Type: args.OptionTypeParameter,
 			Help: "credentials directory",
 		},
-	},
+		{
+			Long: "public-pages",
+			Type: args.OptionTypeFlag,
+			Help: "serve hosted campaign pages at /c/{campaign_id} and the embed widget at /widget.js",
+		},
+		{
+			Long: "idempotency-ttl",
+			Type: args.OptionTypeParameter,
+			Help: "how long Idempotency-Key responses are replayed, e.g. 24h",
+		},
+		{
+			Long: "bulk-undo-window",
+			Type: args.OptionTypeParameter,
+			Help: "how long bulk signature actions can be undone, e.g. 10m",
+		},
+		{
+			Long: "trash-retention",
+			Type: args.OptionTypeParameter,
+			Help: "how long deleted campaigns and signatures stay in the trash before they are purged, e.g. 720h",
+		},
+		{
+			Long: "with-dashboard",
+			Type: args.OptionTypeFlag,
+			Help: "also run the admin dashboard in this process, calling the API without a network hop",
+		},
+		{
+			Long: "dashboard-port",
+			Type: args.OptionTypeParameter,
+			Help: "dashboard port when running with --with-dashboard",
+		},
+	}, append(httpServerOptions, dashboardAuthOptions...)...),
 	Handler: func(i *args.Input) error {
 		// read inputs
 		rawDBPath := resolveOption(i, "db-path", "COSIGN_DB_PATH", DEFAULT_DB_PATH)
 		rawPort := resolveOption(i, "port", "COSIGN_PORT", DEFAULT_PORT)
 		rawOrigins := resolveOption(i, "cors-allowed-origins", "COSIGN_CORS_ALLOWED_ORIGINS", DEFAULT_ALLOWED_ORIGINS)
 		rawCredentialsDirectory := resolveOption(i, "credentials-directory", "COSIGN_CREDENTIALS_DIRECTORY", DEFAULT_CREDS_DIR)
+		publicPages := i.GetFlag("public-pages") || isTruthy(os.Getenv("COSIGN_PUBLIC_PAGES"))
+		rawIdempotencyTTL := resolveOption(i, "idempotency-ttl", "COSIGN_IDEMPOTENCY_TTL", DEFAULT_IDEMPOTENCY_TTL)
+		rawBulkUndoWindow := resolveOption(i, "bulk-undo-window", "COSIGN_BULK_UNDO_WINDOW", DEFAULT_BULK_UNDO)
+		rawTrashRetention := resolveOption(i, "trash-retention", "COSIGN_TRASH_RETENTION", DEFAULT_TRASH_RETENTION)
+		withDashboard := i.GetFlag("with-dashboard") || isTruthy(os.Getenv("COSIGN_WITH_DASHBOARD"))
+		rawDashboardPort := resolveOption(i, "dashboard-port", "COSIGN_DASHBOARD_PORT", DEFAULT_DASHBOARD_PORT)
 
 		// validate inputs
 		dbPath := strings.TrimSpace(rawDBPath)
@@ -146,16 +265,52 @@ var serveCmd = &args.Command{
 
 		origins := parseCSVValues(rawOrigins)
 
+		idempotencyTTL, err := time.ParseDuration(strings.TrimSpace(rawIdempotencyTTL))
+		if err != nil || idempotencyTTL <= 0 {
+			return fmt.Errorf("invalid idempotency ttl %q", rawIdempotencyTTL)
+		}
+
+		bulkUndoWindow, err := time.ParseDuration(strings.TrimSpace(rawBulkUndoWindow))
+		if err != nil || bulkUndoWindow <= 0 {
+			return fmt.Errorf("invalid bulk undo window %q", rawBulkUndoWindow)
+		}
+
+		trashRetention, err := time.ParseDuration(strings.TrimSpace(rawTrashRetention))
+		if err != nil || trashRetention <= 0 {
+			return fmt.Errorf("invalid trash retention %q", rawTrashRetention)
+		}
+
 		port, err := normalizePort(rawPort)
 		if err != nil {
 			return err
 		}
 
-		bootstrapToken, err := loadCredential("api_key", credentialsDirectory)
+		serverOpts, err := resolveHTTPServerOptions(i, "COSIGN_")
 		if err != nil {
 			return err
 		}
 
+		var dashboardPort string
+		if withDashboard {
+			dashboardPort, err = normalizePort(rawDashboardPort)
+			if err != nil {
+				return err
+			}
+			if dashboardPort == port {
+				return fmt.Errorf("dashboard port must differ from the API port %s", port)
+			}
+		}
+
+		// the embedded dashboard needs no key, so the bootstrap key is
+		// optional in that mode
+		bootstrapToken, err := loadCredential("api_key", credentialsDirectory)
+		if err != nil {
+			if !withDashboard || !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			bootstrapToken = ""
+		}
+
 		// init db
 		log.Printf("Initializing database at %s...", dbPath)
 		dbOpts := database.Options{
@@ -166,7 +321,11 @@ var serveCmd = &args.Command{
 		if err != nil {
 			return fmt.Errorf("initialize database: %w", err)
 		}
-		defer db.Close()
+		defer func() {
+			if err := db.Close(); err != nil {
+				log.Printf("close database: %v", err)
+			}
+		}()
 
 		// init service
 		svcOpts := service.Options{
@@ -179,14 +338,101 @@ var serveCmd = &args.Command{
 				Store:          db.CORSStore,
 				InitialOrigins: origins,
 			},
-			HealthCheck: db.HealthCheck,
+			HealthCheck:    db.HealthCheck,
+			IdempotencyTTL: idempotencyTTL,
+			BulkUndoWindow: bulkUndoWindow,
+			TrashRetention: trashRetention,
 		}
 		svc, err := service.New(svcOpts)
 		if err != nil {
 			return fmt.Errorf("initialize service: %w", err)
 		}
 
+		var mounts []service.Mount
+		if publicPages {
+			pages, err := public.New(public.Options{
+				Service:   svc,
+				APIPrefix: API_PREFIX,
+			})
+			if err != nil {
+				return fmt.Errorf("initialize public pages: %w", err)
+			}
+			mounts = pages.Mounts()
+			log.Printf("Serving public campaign pages at /c/{campaign_id}")
+		}
+
+		ctx, stop := shutdownContext()
+		defer stop()
+
+		ln, err := net.Listen("tcp", port)
+		if err != nil {
+			return err
+		}
+
+		if withDashboard {
+			dashboardOpts, err := resolveDashboardAuthOptions(i, db)
+			if err != nil {
+				ln.Close()
+				return err
+			}
+			dashboardLn, err := net.Listen("tcp", dashboardPort)
+			if err != nil {
+				ln.Close()
+				return err
+			}
+			return serveWithDashboard(ctx, svc, ln, dashboardLn, serverOpts, dashboardOpts, mounts)
+		}
+
 		log.Printf("Starting server on %s...", port)
-		return svc.Serve(port, API_PREFIX)
+		if err := svc.Serve(ctx, ln, API_PREFIX, serverOpts, mounts...); err != nil {
+			return err
+		}
+		log.Printf("Server stopped")
+		return nil
 	},
 }
+
+// serveWithDashboard runs the API on ln and the dashboard on dashboardLn
+// until ctx ends or either server fails. The dashboard calls the service
+// through its in-process transport.
+func serveWithDashboard(
+	ctx context.Context,
+	svc *service.Service,
+	ln net.Listener,
+	dashboardLn net.Listener,
+	opts httpserver.Options,
+	dashboardOpts app.Options,
+	mounts []service.Mount,
+) error {
+	dashboardOpts.Client = cosignclient.New(cosignclient.Options{
+		BaseURL:    "http://cosign",
+		HTTPClient: &http.Client{Transport: svc.Transport()},
+	})
+	dashboard, err := app.New(dashboardOpts)
+	if err != nil {
+		ln.Close()
+		dashboardLn.Close()
+		return fmt.Errorf("initialize dashboard: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var dashboardErr error
+	wg.Go(func() {
+		defer cancel()
+		log.Printf("Starting dashboard on %s...", dashboardLn.Addr())
+		dashboardErr = dashboard.Serve(ctx, dashboardLn, opts)
+	})
+
+	log.Printf("Starting server on %s...", ln.Addr())
+	serveErr := svc.Serve(ctx, ln, API_PREFIX, opts, mounts...)
+	cancel()
+	wg.Wait()
+	if err := errors.Join(serveErr, dashboardErr); err != nil {
+		return err
+	}
+	log.Printf("Server stopped")
+	return nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/csv"
 	"fmt"
 	"os"
@@ -8,7 +9,6 @@ import (
 	"strings"
 	"time"
 
-	"cosign/internal/service"
 	"git.sr.ht/~jakintosh/command-go/pkg/args"
 )
 
@@ -18,6 +18,8 @@ var signaturesCmd = &args.Command{
 	Subcommands: []*args.Command{
 		signaturesListCmd,
 		signaturesExportCmd,
+		signaturesTrashCmd,
+		signaturesRestoreCmd,
 	},
 }
 
@@ -57,9 +59,87 @@ var signaturesListCmd = &args.Command{
 			return err
 		}
 
-		var response service.Signatures
-		path := fmt.Sprintf("/admin/campaigns/%s/signatures?limit=%d&offset=%d", id, limit, offset)
-		if err := client.Get(path, &response); err != nil {
+		response, err := client.ListSignatures(context.Background(), id, limit, offset)
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(response)
+	},
+}
+
+var signaturesTrashCmd = &args.Command{
+	Name: "trash",
+	Help: "list campaign signatures in the trash",
+	Options: []args.Option{
+		{
+			Long: "limit",
+			Type: args.OptionTypeParameter,
+			Help: "page size",
+		},
+		{
+			Long: "offset",
+			Type: args.OptionTypeParameter,
+			Help: "page offset",
+		},
+	},
+	Handler: func(i *args.Input) error {
+		limit := i.GetIntParameterOr("limit", 100)
+		offset := i.GetIntParameterOr("offset", 0)
+
+		if limit < 1 {
+			return fmt.Errorf("limit must be at least 1")
+		}
+		if offset < 0 {
+			return fmt.Errorf("offset must not be negative")
+		}
+
+		id, err := resolveCampaignId(i)
+		if err != nil {
+			return err
+		}
+
+		client, err := resolveClient(i, API_PREFIX)
+		if err != nil {
+			return err
+		}
+
+		response, err := client.ListDeletedSignatures(context.Background(), id, limit, offset)
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(response)
+	},
+}
+
+var signaturesRestoreCmd = &args.Command{
+	Name: "restore",
+	Help: "restore signature from the trash",
+	Operands: []args.Operand{
+		{
+			Name: "signature-id",
+			Help: "id of the signature to restore",
+		},
+	},
+	Handler: func(i *args.Input) error {
+		signatureID, err := strconv.ParseInt(strings.TrimSpace(i.GetOperand("signature-id")), 10, 64)
+		if err != nil || signatureID <= 0 {
+			return fmt.Errorf("signature id must be a positive integer")
+		}
+
+		id, err := resolveCampaignId(i)
+		if err != nil {
+			return err
+		}
+
+		client, err := resolveClient(i, API_PREFIX)
+		if err != nil {
+			return err
+		}
+
+		response, err := client.RestoreSignature(context.Background(), id, signatureID)
+		if err != nil {
 			return err
 		}
 
@@ -96,11 +176,6 @@ var signaturesExportCmd = &args.Command{
 			return err
 		}
 
-		var response service.Signatures
-		if err := client.Get("/admin/campaigns/"+id+"/signatures", &response); err != nil {
-			return err
-		}
-
 		file, err := os.Create(output)
 		if err != nil {
 			return fmt.Errorf("create output file: %w", err)
@@ -113,7 +188,11 @@ var signaturesExportCmd = &args.Command{
 			return err
 		}
 
-		for _, signature := range response.Signatures {
+		exported := 0
+		for signature, err := range client.AllSignatures(context.Background(), id, 0) {
+			if err != nil {
+				return err
+			}
 			createdAt := time.Unix(signature.CreatedAt, 0).UTC().Format(time.RFC3339)
 			row := []string{
 				strconv.FormatInt(signature.ID, 10),
@@ -125,6 +204,7 @@ var signaturesExportCmd = &args.Command{
 			if err := writer.Write(row); err != nil {
 				return err
 			}
+			exported++
 		}
 
 		writer.Flush()
@@ -132,7 +212,7 @@ var signaturesExportCmd = &args.Command{
 			return err
 		}
 
-		fmt.Printf("exported %d signatures to %s\n", len(response.Signatures), output)
+		fmt.Printf("exported %d signatures to %s\n", exported, output)
 		return nil
 	},
 }
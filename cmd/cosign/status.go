@@ -1,7 +1,8 @@
 package main
 
 import (
-	"cosign/internal/service"
+	"context"
+	"cosign/pkg/cosignclient"
 	"fmt"
 	"strings"
 
@@ -34,8 +35,9 @@ var statusCmd = &args.Command{
 			fmt.Println("API Key: present")
 		}
 
-		response := service.HealthResponse{}
-		if err := client.Get("/health", &response); err != nil {
+		api := cosignclient.New(cosignclient.Options{BaseURL: client.BaseURL, APIKey: client.APIKey})
+		response, err := api.Health(context.Background())
+		if err != nil {
 			fmt.Println("Health: down")
 			if verbose {
 				fmt.Printf("  error: %v\n", err)
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cosign/internal/service"
+	"git.sr.ht/~jakintosh/command-go/pkg/args"
+)
+
+var webhooksCmd = &args.Command{
+	Name: "webhooks",
+	Help: "manage outbound webhooks",
+	Subcommands: []*args.Command{
+		webhooksListCmd,
+		webhooksCreateCmd,
+		webhooksDeleteCmd,
+		webhooksDeliveriesCmd,
+		webhooksReplayCmd,
+	},
+}
+
+var webhooksListCmd = &args.Command{
+	Name: "list",
+	Help: "list webhooks",
+	Handler: func(i *args.Input) error {
+		client, err := resolveClient(i, API_PREFIX)
+		if err != nil {
+			return err
+		}
+
+		campaignID := strings.TrimSpace(i.GetParameterOr("campaign-id", ""))
+		response, err := client.ListWebhooks(context.Background(), campaignID)
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(response)
+	},
+}
+
+var webhooksCreateCmd = &args.Command{
+	Name: "create",
+	Help: "create webhook; scoped to --campaign-id when set, otherwise global",
+	Operands: []args.Operand{
+		{
+			Name: "url",
+			Help: "receiver URL",
+		},
+	},
+	Options: []args.Option{
+		{
+			Long: "event",
+			Type: args.OptionTypeArray,
+			Help: "event to subscribe to (signature.created, signature.deleted, campaign.updated, milestone.reached)",
+		},
+		{
+			Long: "secret",
+			Type: args.OptionTypeParameter,
+			Help: "signing secret; generated when omitted",
+		},
+	},
+	Handler: func(i *args.Input) error {
+		// get input
+		target := strings.TrimSpace(i.GetOperand("url"))
+		events := i.GetArray("event")
+		secret := i.GetParameterOr("secret", "")
+		campaignID := strings.TrimSpace(i.GetParameterOr("campaign-id", ""))
+
+		// validate input
+		if target == "" {
+			return fmt.Errorf("webhook url required")
+		}
+		if len(events) == 0 {
+			return fmt.Errorf("at least one --event required")
+		}
+
+		// setup client
+		client, err := resolveClient(i, API_PREFIX)
+		if err != nil {
+			return err
+		}
+
+		// post webhook
+		response, err := client.CreateWebhook(context.Background(), service.CreateWebhookRequest{
+			CampaignID: campaignID,
+			URL:        target,
+			Secret:     secret,
+			Events:     events,
+		})
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(response)
+	},
+}
+
+var webhooksDeleteCmd = &args.Command{
+	Name: "delete",
+	Help: "delete webhook",
+	Operands: []args.Operand{
+		{
+			Name: "id",
+			Help: "webhook ID",
+		},
+	},
+	Handler: func(i *args.Input) error {
+		id := strings.TrimSpace(i.GetOperand("id"))
+		if id == "" {
+			return fmt.Errorf("webhook id required")
+		}
+
+		client, err := resolveClient(i, API_PREFIX)
+		if err != nil {
+			return err
+		}
+
+		if err := client.DeleteWebhook(context.Background(), id); err != nil {
+			return err
+		}
+
+		fmt.Println("webhook deleted")
+		return nil
+	},
+}
+
+var webhooksDeliveriesCmd = &args.Command{
+	Name: "deliveries",
+	Help: "list webhook deliveries",
+	Operands: []args.Operand{
+		{
+			Name: "id",
+			Help: "webhook ID",
+		},
+	},
+	Options: []args.Option{
+		{
+			Long: "status",
+			Type: args.OptionTypeParameter,
+			Help: "filter by status (pending, succeeded, dead)",
+		},
+		{
+			Long: "limit",
+			Type: args.OptionTypeParameter,
+			Help: "page size",
+		},
+		{
+			Long: "offset",
+			Type: args.OptionTypeParameter,
+			Help: "page offset",
+		},
+	},
+	Handler: func(i *args.Input) error {
+		id := strings.TrimSpace(i.GetOperand("id"))
+		status := strings.TrimSpace(i.GetParameterOr("status", ""))
+		limit := i.GetIntParameterOr("limit", 100)
+		offset := i.GetIntParameterOr("offset", 0)
+
+		if id == "" {
+			return fmt.Errorf("webhook id required")
+		}
+		if limit < 1 {
+			return fmt.Errorf("limit must be at least 1")
+		}
+		if offset < 0 {
+			return fmt.Errorf("offset must not be negative")
+		}
+
+		client, err := resolveClient(i, API_PREFIX)
+		if err != nil {
+			return err
+		}
+
+		response, err := client.ListWebhookDeliveries(context.Background(), id, status, limit, offset)
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(response)
+	},
+}
+
+var webhooksReplayCmd = &args.Command{
+	Name: "replay",
+	Help: "queue a webhook delivery to be sent again",
+	Operands: []args.Operand{
+		{
+			Name: "id",
+			Help: "webhook ID",
+		},
+		{
+			Name: "delivery-id",
+			Help: "delivery ID",
+		},
+	},
+	Handler: func(i *args.Input) error {
+		id := strings.TrimSpace(i.GetOperand("id"))
+		deliveryID := strings.TrimSpace(i.GetOperand("delivery-id"))
+
+		if id == "" {
+			return fmt.Errorf("webhook id required")
+		}
+		parsedDeliveryID, err := strconv.ParseInt(deliveryID, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid delivery id %q", deliveryID)
+		}
+
+		client, err := resolveClient(i, API_PREFIX)
+		if err != nil {
+			return err
+		}
+
+		response, err := client.ReplayWebhookDelivery(context.Background(), id, parsedDeliveryID)
+		if err != nil {
+			return err
+		}
+
+		return writeJSON(response)
+	},
+}
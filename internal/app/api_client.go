@@ -1,134 +1,254 @@
 package app
 
 import (
+	"context"
 	"cosign/internal/service"
-	"encoding/json"
+	"cosign/pkg/cosignclient"
 	"errors"
-	"fmt"
-	"net/url"
+	"net/http"
 	"strings"
 )
 
-func (s *Server) createCampaign(name string) error {
-	body, err := json.Marshal(service.CreateCampaignRequest{Name: name})
-	if err != nil {
-		return err
+func (s *Server) createCampaign(ctx context.Context, name string) error {
+	_, err := s.client.CreateCampaign(ctx, name)
+	return err
+}
+
+// listCampaigns lists every campaign for users with a global role, and
+// otherwise only the campaigns they hold a role on.
+func (s *Server) listCampaigns(ctx context.Context, limit int, offset int) (*service.Campaigns, error) {
+	access := accessFromContext(ctx)
+	if access.AllCampaigns() {
+		return s.client.ListCampaigns(ctx, limit, offset)
 	}
 
-	var response service.Campaign
-	return s.client.Post("/admin/campaigns", body, &response)
+	ids := access.CampaignIDs()
+	response := &service.Campaigns{Campaigns: []*service.Campaign{}, Total: len(ids), Limit: limit, Offset: offset}
+	for _, id := range ids[min(offset, len(ids)):min(offset+limit, len(ids))] {
+		campaign, err := s.client.GetCampaign(ctx, id)
+		if isNotFoundError(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		response.Campaigns = append(response.Campaigns, campaign)
+	}
+	return response, nil
 }
 
-func (s *Server) listCampaigns(limit int, offset int) (*service.Campaigns, error) {
-	var response service.Campaigns
-	path := fmt.Sprintf("/admin/campaigns?limit=%d&offset=%d", limit, offset)
-	if err := s.client.Get(path, &response); err != nil {
-		return nil, err
+// listDeletedCampaigns lists the whole trash for global owners, and
+// otherwise only the trashed campaigns the user owns.
+func (s *Server) listDeletedCampaigns(ctx context.Context, limit int, offset int) (*service.Campaigns, error) {
+	access := accessFromContext(ctx)
+	if access.Can("", RoleOwner) {
+		return s.client.ListDeletedCampaigns(ctx, limit, offset)
 	}
-	return &response, nil
-}
 
-func (s *Server) getCampaign(campaignID string) (*service.Campaign, error) {
-	var response service.Campaign
-	path := "/admin/campaigns/" + url.PathEscape(campaignID)
-	if err := s.client.Get(path, &response); err != nil {
-		return nil, err
+	owned := []*service.Campaign{}
+	for next := 0; ; {
+		page, err := s.client.ListDeletedCampaigns(ctx, 100, next)
+		if err != nil {
+			return nil, err
+		}
+		for _, campaign := range page.Campaigns {
+			if access.Can(campaign.ID, RoleOwner) {
+				owned = append(owned, campaign)
+			}
+		}
+		next += len(page.Campaigns)
+		if len(page.Campaigns) == 0 || next >= page.Total {
+			break
+		}
 	}
-	return &response, nil
+
+	return &service.Campaigns{
+		Campaigns: owned[min(offset, len(owned)):min(offset+limit, len(owned))],
+		Total:     len(owned),
+		Limit:     limit,
+		Offset:    offset,
+	}, nil
 }
 
-func (s *Server) updateCampaign(campaignID string, name string, allowCustomText bool) error {
-	body, err := json.Marshal(service.UpdateCampaignRequest{
-		Name:            name,
-		AllowCustomText: &allowCustomText,
-	})
-	if err != nil {
-		return err
-	}
+func (s *Server) restoreCampaign(ctx context.Context, campaignID string) (*service.Campaign, error) {
+	return s.client.RestoreCampaign(ctx, campaignID)
+}
+
+func (s *Server) getSignatureStats(ctx context.Context, campaignID string, days int) (*service.SignatureStats, error) {
+	return s.client.SignatureStats(ctx, campaignID, days)
+}
 
-	var response service.Campaign
-	path := "/admin/campaigns/" + url.PathEscape(campaignID)
-	return s.client.Put(path, body, &response)
+func (s *Server) getCampaign(ctx context.Context, campaignID string) (*service.Campaign, error) {
+	return s.client.GetCampaign(ctx, campaignID)
 }
 
-func (s *Server) deleteCampaign(campaignID string) error {
-	path := "/admin/campaigns/" + url.PathEscape(campaignID)
-	return s.client.Delete(path, nil)
+func (s *Server) updateCampaign(ctx context.Context, campaignID string, req service.UpdateCampaignRequest) (*service.Campaign, error) {
+	return s.client.UpdateCampaign(ctx, campaignID, req)
 }
 
-func (s *Server) getCampaignLocations(campaignID string) ([]service.LocationOption, error) {
-	var response service.CampaignLocationsResponse
-	path := "/admin/campaigns/" + url.PathEscape(campaignID) + "/locations"
-	if err := s.client.Get(path, &response); err != nil {
+func (s *Server) deleteCampaign(ctx context.Context, campaignID string) error {
+	return s.client.DeleteCampaign(ctx, campaignID)
+}
+
+func (s *Server) getCampaignLocations(ctx context.Context, campaignID string) ([]service.LocationOption, error) {
+	response, err := s.client.GetLocations(ctx, campaignID)
+	if err != nil {
 		return nil, err
 	}
 
 	return response.Locations, nil
 }
 
-func (s *Server) setCampaignLocations(campaignID string, locations []service.LocationOption) error {
-	normalized := make([]service.LocationOption, 0, len(locations))
-	for idx, loc := range locations {
-		value := strings.TrimSpace(loc.Value)
-		if value == "" {
-			continue
-		}
+func (s *Server) createLocation(ctx context.Context, campaignID string, location service.LocationOption) error {
+	_, err := s.client.CreateLocation(ctx, campaignID, location)
+	return err
+}
 
-		normalized = append(normalized, service.LocationOption{
-			Value:        value,
-			DisplayOrder: idx + 1,
-		})
-	}
+func (s *Server) updateLocation(ctx context.Context, campaignID string, locationID int64, req service.UpdateLocationRequest) error {
+	_, err := s.client.UpdateLocation(ctx, campaignID, locationID, req)
+	return err
+}
 
-	body, err := json.Marshal(service.CampaignLocationsRequest{Locations: normalized})
-	if err != nil {
-		return err
-	}
+func (s *Server) deleteLocation(ctx context.Context, campaignID string, locationID int64) error {
+	return s.client.DeleteLocation(ctx, campaignID, locationID)
+}
+
+func (s *Server) moveLocation(ctx context.Context, campaignID string, locationID int64, req service.MoveLocationRequest) error {
+	_, err := s.client.MoveLocation(ctx, campaignID, locationID, req)
+	return err
+}
 
-	var response service.CampaignLocationsResponse
-	path := "/admin/campaigns/" + url.PathEscape(campaignID) + "/locations"
-	return s.client.Put(path, body, &response)
+func (s *Server) mergeLocation(ctx context.Context, campaignID string, locationID int64, req service.MergeLocationRequest) error {
+	_, err := s.client.MergeLocation(ctx, campaignID, locationID, req.IntoID)
+	return err
 }
 
-func (s *Server) listSignatures(campaignID string, limit int, offset int) (*service.Signatures, error) {
-	var response service.Signatures
-	path := fmt.Sprintf(
-		"/admin/campaigns/%s/signatures?limit=%d&offset=%d",
-		url.PathEscape(campaignID),
-		limit,
-		offset,
-	)
-	if err := s.client.Get(path, &response); err != nil {
+func (s *Server) getLocationSuggestions(ctx context.Context, campaignID string) ([]service.LocationSuggestion, error) {
+	response, err := s.client.LocationSuggestions(ctx, campaignID)
+	if err != nil {
 		return nil, err
 	}
-	return &response, nil
+
+	return response.Suggestions, nil
+}
+
+func (s *Server) normalizeLocations(ctx context.Context, campaignID string, req service.NormalizeLocationsRequest) error {
+	_, err := s.client.NormalizeLocations(ctx, campaignID, req.Mappings)
+	return err
+}
+
+func (s *Server) importLocationCoordinates(ctx context.Context, campaignID string, req service.ImportCoordinatesRequest) (*service.ImportCoordinatesResponse, error) {
+	return s.client.ImportCoordinates(ctx, campaignID, req.Coordinates)
 }
 
-func (s *Server) createSignature(campaignID, name, email, location string) error {
-	body, err := json.Marshal(service.CreateSignatureRequest{
+func (s *Server) listSignatures(ctx context.Context, campaignID string, filter service.SignatureFilter, limit int, offset int) (*service.Signatures, error) {
+	return s.client.ListSignaturesMatching(ctx, campaignID, filter, limit, offset)
+}
+
+func (s *Server) createSignature(ctx context.Context, campaignID, name, email, location string) error {
+	_, err := s.client.CreateSignature(ctx, campaignID, service.CreateSignatureRequest{
 		Name:     name,
 		Email:    email,
 		Location: location,
 	})
+	return err
+}
+
+func (s *Server) deleteSignature(ctx context.Context, campaignID string, signatureID int64) error {
+	return s.client.DeleteSignature(ctx, campaignID, signatureID)
+}
+
+func (s *Server) listDeletedSignatures(ctx context.Context, campaignID string, limit int, offset int) (*service.Signatures, error) {
+	return s.client.ListDeletedSignatures(ctx, campaignID, limit, offset)
+}
+
+func (s *Server) restoreSignature(ctx context.Context, campaignID string, signatureID int64) (*service.Signature, error) {
+	return s.client.RestoreSignature(ctx, campaignID, signatureID)
+}
+
+func (s *Server) bulkSignatures(ctx context.Context, campaignID string, req service.BulkSignaturesRequest) (*service.BulkSignaturesResponse, error) {
+	return s.client.BulkSignatures(ctx, campaignID, req)
+}
+
+func (s *Server) getSignatureBulkOperation(ctx context.Context, campaignID, operationID string) (*service.SignatureBulkOperation, error) {
+	return s.client.GetSignatureBulkOperation(ctx, campaignID, operationID)
+}
+
+func (s *Server) undoBulkSignatures(ctx context.Context, campaignID, operationID string) (*service.BulkUndoResponse, error) {
+	return s.client.UndoBulkSignatures(ctx, campaignID, operationID)
+}
+
+func (s *Server) listAPIKeys(ctx context.Context) ([]*service.APIKey, error) {
+	response, err := s.client.ListAPIKeys(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	var response service.Signature
-	path := "/campaigns/" + url.PathEscape(campaignID) + "/signatures"
-	return s.client.Post(path, body, &response)
+	return response.Keys, nil
 }
 
-func (s *Server) deleteSignature(campaignID string, signatureID int64) error {
-	path := fmt.Sprintf("/admin/campaigns/%s/signatures/%d", url.PathEscape(campaignID), signatureID)
-	return s.client.Delete(path, nil)
+func (s *Server) createAPIKey(ctx context.Context) (string, error) {
+	return s.client.CreateAPIKey(ctx)
+}
+
+func (s *Server) revokeAPIKey(ctx context.Context, keyID string) error {
+	return s.client.DeleteAPIKey(ctx, keyID)
+}
+
+func (s *Server) listCORSOrigins(ctx context.Context) ([]string, error) {
+	origins, err := s.client.CORSOrigins(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, len(origins))
+	for _, origin := range origins {
+		urls = append(urls, origin.URL)
+	}
+	return urls, nil
+}
+
+func (s *Server) setCORSOrigins(ctx context.Context, urls []string) error {
+	origins := make([]cosignclient.AllowedOrigin, 0, len(urls))
+	for _, url := range urls {
+		origins = append(origins, cosignclient.AllowedOrigin{URL: url})
+	}
+	return s.client.SetCORSOrigins(ctx, origins)
+}
+
+// conflictMessage replaces the API's revision conflict error so the user
+// knows to reload rather than resubmit over someone else's edit.
+const conflictMessage = "This campaign was changed elsewhere since you loaded it. Reload and retry your edit."
+
+// formFieldErrors keys the API's field errors by form input name; theme
+// fields are flattened into the campaign form.
+func formFieldErrors(err error) map[string]string {
+	apiErr, ok := cosignclient.AsError(err)
+	if !ok || len(apiErr.Fields) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]string, len(apiErr.Fields))
+	for _, field := range apiErr.Fields {
+		fields[strings.TrimPrefix(field.Field, "theme.")] = field.Message
+	}
+	return fields
+}
+
+func isConflictError(err error) bool {
+	if errors.Is(err, service.ErrRevisionConflict) {
+		return true
+	}
+
+	apiErr, ok := cosignclient.AsError(err)
+	return ok && apiErr.Status == http.StatusPreconditionFailed
 }
 
 func isNotFoundError(err error) bool {
-	if err == nil {
-		return false
+	if errors.Is(err, service.ErrCampaignNotFound) {
+		return true
 	}
 
-	msg := strings.ToLower(err.Error())
-	return errors.Is(err, service.ErrCampaignNotFound) || strings.Contains(msg, "not found")
+	apiErr, ok := cosignclient.AsError(err)
+	return ok && apiErr.Status == http.StatusNotFound
 }
@@ -0,0 +1,169 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrUserExists         = errors.New("user already exists")
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrInvalidUsername    = errors.New("username must be 1-64 letters, digits, dots, dashes or underscores")
+	ErrPasswordTooShort   = fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	ErrPasswordTooLong    = fmt.Errorf("password must be at most %d bytes", maxPasswordBytes)
+	ErrInvalidCredentials = errors.New("invalid username or password")
+)
+
+const (
+	minPasswordLength = 10
+	// bcrypt ignores everything past 72 bytes.
+	maxPasswordBytes = 72
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,64}$`)
+
+type User struct {
+	ID           int64  `json:"id"`
+	Username     string `json:"username"`
+	PasswordHash string `json:"-"`
+	CreatedAt    int64  `json:"created_at"`
+
+	Roles []RoleAssignment `json:"roles,omitempty"`
+}
+
+// Session is a signed-in dashboard user. ID is the SHA-256 of the cookie
+// token, so a leaked database does not leak usable sessions.
+type Session struct {
+	ID        string
+	UserID    int64
+	Username  string
+	CSRFToken string
+	CreatedAt int64
+	ExpiresAt int64
+
+	// Access is loaded from the user's roles on every request.
+	Access Access
+}
+
+// AuthStore persists dashboard users, their roles and their sessions.
+// Deleting a user deletes their roles and sessions. A role with an empty
+// campaign ID is global.
+type AuthStore interface {
+	InsertDashboardUser(username, passwordHash string, createdAt int64) (int64, error)
+	GetDashboardUser(username string) (*User, error)
+	ListDashboardUsers() ([]*User, error)
+	UpdateDashboardUserPassword(username, passwordHash string) error
+	DeleteDashboardUser(username string) error
+
+	SetDashboardUserRole(userID int64, campaignID string, role Role) error
+	DeleteDashboardUserRole(userID int64, campaignID string) error
+	ListDashboardUserRoles(userID int64) ([]RoleAssignment, error)
+
+	InsertDashboardSession(session Session) error
+	GetDashboardSession(id string) (*Session, error)
+	DeleteDashboardSession(id string) error
+	DeleteDashboardUserSessions(userID int64) error
+	DeleteExpiredDashboardSessions(now int64) error
+}
+
+// dummyPasswordHash is compared against when a login names an unknown user,
+// so both failures take about as long.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("cosign-dummy-password"), bcrypt.DefaultCost)
+
+func NormalizeUsername(raw string) (string, error) {
+	username := strings.ToLower(strings.TrimSpace(raw))
+	if !usernamePattern.MatchString(username) {
+		return "", ErrInvalidUsername
+	}
+	return username, nil
+}
+
+func HashPassword(password string) (string, error) {
+	if len([]rune(password)) < minPasswordLength {
+		return "", ErrPasswordTooShort
+	}
+	if len(password) > maxPasswordBytes {
+		return "", ErrPasswordTooLong
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CreateUser adds a dashboard user with a bcrypt hash of password.
+func CreateUser(store AuthStore, username, password string) (*User, error) {
+	username, err := NormalizeUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	createdAt := time.Now().Unix()
+	id, err := store.InsertDashboardUser(username, hash, createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &User{ID: id, Username: username, CreatedAt: createdAt}, nil
+}
+
+// SetUserPassword replaces a user's password and signs out their sessions.
+func SetUserPassword(store AuthStore, username, password string) error {
+	user, err := getUser(store, username)
+	if err != nil {
+		return err
+	}
+
+	hash, err := HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := store.UpdateDashboardUserPassword(user.Username, hash); err != nil {
+		return err
+	}
+	return store.DeleteDashboardUserSessions(user.ID)
+}
+
+func DeleteUser(store AuthStore, username string) error {
+	username, err := NormalizeUsername(username)
+	if err != nil {
+		return err
+	}
+	return store.DeleteDashboardUser(username)
+}
+
+func authenticate(store AuthStore, username, password string) (*User, error) {
+	username, err := NormalizeUsername(username)
+	if err != nil {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+
+	user, err := store.GetDashboardUser(username)
+	if errors.Is(err, ErrUserNotFound) {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
@@ -0,0 +1,477 @@
+package app
+
+import (
+	"bytes"
+	"cosign/internal/service"
+	"cosign/pkg/cosignclient"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+const (
+	testSessionToken = "test-session"
+	testCSRFToken    = "test-csrf"
+	testPassword     = "correct horse battery"
+)
+
+type memAuthStore struct {
+	mu       sync.Mutex
+	nextID   int64
+	users    map[string]*User
+	roles    map[int64]map[string]Role
+	sessions map[string]Session
+}
+
+func newMemAuthStore() *memAuthStore {
+	return &memAuthStore{users: map[string]*User{}, roles: map[int64]map[string]Role{}, sessions: map[string]Session{}}
+}
+
+func (m *memAuthStore) InsertDashboardUser(username, passwordHash string, createdAt int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.users[username]; ok {
+		return 0, ErrUserExists
+	}
+	m.nextID++
+	m.users[username] = &User{ID: m.nextID, Username: username, PasswordHash: passwordHash, CreatedAt: createdAt}
+	return m.nextID, nil
+}
+
+func (m *memAuthStore) GetDashboardUser(username string) (*User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, ok := m.users[username]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	copy := *user
+	return &copy, nil
+}
+
+func (m *memAuthStore) ListDashboardUsers() ([]*User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	users := []*User{}
+	for _, user := range m.users {
+		copy := *user
+		users = append(users, &copy)
+	}
+	return users, nil
+}
+
+func (m *memAuthStore) UpdateDashboardUserPassword(username, passwordHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	user, ok := m.users[username]
+	if !ok {
+		return ErrUserNotFound
+	}
+	user.PasswordHash = passwordHash
+	return nil
+}
+
+func (m *memAuthStore) DeleteDashboardUser(username string) error {
+	m.mu.Lock()
+	user, ok := m.users[username]
+	if !ok {
+		m.mu.Unlock()
+		return ErrUserNotFound
+	}
+	delete(m.users, username)
+	delete(m.roles, user.ID)
+	m.mu.Unlock()
+	return m.DeleteDashboardUserSessions(user.ID)
+}
+
+func (m *memAuthStore) SetDashboardUserRole(userID int64, campaignID string, role Role) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.roles[userID] == nil {
+		m.roles[userID] = map[string]Role{}
+	}
+	m.roles[userID][campaignID] = role
+	return nil
+}
+
+func (m *memAuthStore) DeleteDashboardUserRole(userID int64, campaignID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.roles[userID], campaignID)
+	return nil
+}
+
+func (m *memAuthStore) ListDashboardUserRoles(userID int64) ([]RoleAssignment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	roles := []RoleAssignment{}
+	for campaignID, role := range m.roles[userID] {
+		roles = append(roles, RoleAssignment{CampaignID: campaignID, Role: role})
+	}
+	return roles, nil
+}
+
+func (m *memAuthStore) InsertDashboardSession(session Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID] = session
+	return nil
+}
+
+func (m *memAuthStore) GetDashboardSession(id string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[id]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (m *memAuthStore) DeleteDashboardSession(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *memAuthStore) DeleteDashboardUserSessions(userID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, session := range m.sessions {
+		if session.UserID == userID {
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (m *memAuthStore) DeleteExpiredDashboardSessions(now int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, session := range m.sessions {
+		if session.ExpiresAt <= now {
+			delete(m.sessions, id)
+		}
+	}
+	return nil
+}
+
+// newTestServer returns a dashboard with an "admin" user who is a global
+// owner and signed in with testSessionToken; see signedIn.
+func newTestServer(t *testing.T, client *cosignclient.Client) *Server {
+	t.Helper()
+	server, _ := newTestServerWithStore(t, client, time.Now)
+	return server
+}
+
+func newTestServerWithStore(t *testing.T, client *cosignclient.Client, clock func() time.Time) (*Server, *memAuthStore) {
+	t.Helper()
+	return newTestServerWithRoles(t, client, clock, RoleAssignment{Role: RoleOwner})
+}
+
+// newTestServerWithRoles is newTestServer with the test user holding only
+// roles.
+func newTestServerWithRoles(t *testing.T, client *cosignclient.Client, clock func() time.Time, roles ...RoleAssignment) (*Server, *memAuthStore) {
+	t.Helper()
+
+	store := newMemAuthStore()
+	user, err := CreateUser(store, "admin", testPassword)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	for _, role := range roles {
+		if err := GrantRole(store, user.Username, role); err != nil {
+			t.Fatalf("grant role: %v", err)
+		}
+	}
+	now := clock()
+	if err := store.InsertDashboardSession(Session{
+		ID:        hashToken(testSessionToken),
+		UserID:    user.ID,
+		Username:  user.Username,
+		CSRFToken: testCSRFToken,
+		CreatedAt: now.Unix(),
+		ExpiresAt: now.Add(time.Hour).Unix(),
+	}); err != nil {
+		t.Fatalf("insert session: %v", err)
+	}
+
+	server, err := New(Options{Client: client, Auth: store, Clock: clock})
+	if err != nil {
+		t.Fatalf("new dashboard server: %v", err)
+	}
+	return server, store
+}
+
+// signedIn sends req as the test user, with the CSRF header htmx adds.
+func signedIn(req *http.Request) *http.Request {
+	req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: testSessionToken})
+	req.Header.Set(csrfHeader, testCSRFToken)
+	return req
+}
+
+func newCampaignsBackend(t *testing.T, calls *int) *httptest.Server {
+	t.Helper()
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		wire.WriteData(w, http.StatusOK, service.Campaigns{Campaigns: []*service.Campaign{}, Limit: 10})
+	}))
+	t.Cleanup(backend.Close)
+	return backend
+}
+
+var csrfInputPattern = regexp.MustCompile(`name="_csrf" value="([^"]+)"`)
+
+// openLoginForm loads the sign-in page and returns its CSRF token and cookie.
+func openLoginForm(t *testing.T, handler http.Handler) (string, *http.Cookie) {
+	t.Helper()
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/login", nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected login page, got %d", res.Code)
+	}
+
+	match := csrfInputPattern.FindStringSubmatch(res.Body.String())
+	if match == nil {
+		t.Fatalf("expected a CSRF field in the login form, got %s", res.Body.String())
+	}
+	for _, cookie := range res.Result().Cookies() {
+		if cookie.Name == loginCookieName {
+			return match[1], cookie
+		}
+	}
+	t.Fatalf("expected a login cookie")
+	return "", nil
+}
+
+func postLogin(handler http.Handler, token string, cookie *http.Cookie, username, password, next string) *httptest.ResponseRecorder {
+	form := url.Values{
+		"_csrf":    {token},
+		"username": {username},
+		"password": {password},
+		"next":     {next},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	return res
+}
+
+func TestUnauthenticatedRequestsRedirectToLogin(t *testing.T) {
+	calls := 0
+	backend := newCampaignsBackend(t, &calls)
+	handler := newTestServer(t, cosignclient.New(cosignclient.Options{BaseURL: backend.URL})).BuildRouter()
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/campaigns?page=2", nil))
+	if res.Code != http.StatusSeeOther {
+		t.Fatalf("expected redirect, got %d", res.Code)
+	}
+	if location := res.Header().Get("Location"); location != "/login?next=%2Fcampaigns%3Fpage%3D2" {
+		t.Fatalf("unexpected redirect %q", location)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/campaigns", nil)
+	req.Header.Set("HX-Request", "true")
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	if res.Code != http.StatusUnauthorized || res.Header().Get("HX-Redirect") == "" {
+		t.Fatalf("expected htmx sign-in redirect, got %d %v", res.Code, res.Header())
+	}
+
+	if calls != 0 {
+		t.Fatalf("expected no API calls, got %d", calls)
+	}
+}
+
+func TestLoginStartsSessionAndLogoutEndsIt(t *testing.T) {
+	calls := 0
+	backend := newCampaignsBackend(t, &calls)
+	server, store := newTestServerWithStore(t, cosignclient.New(cosignclient.Options{BaseURL: backend.URL}), time.Now)
+	handler := server.BuildRouter()
+
+	token, loginCookie := openLoginForm(t, handler)
+	res := postLogin(handler, token, loginCookie, "Admin", testPassword, "/campaigns?page=1")
+	if res.Code != http.StatusSeeOther || res.Header().Get("Location") != "/campaigns?page=1" {
+		t.Fatalf("expected redirect to next, got %d %q: %s", res.Code, res.Header().Get("Location"), res.Body.String())
+	}
+
+	var sessionCookie *http.Cookie
+	for _, cookie := range res.Result().Cookies() {
+		if cookie.Name == sessionCookieName {
+			sessionCookie = cookie
+		}
+	}
+	if sessionCookie == nil || !sessionCookie.HttpOnly || sessionCookie.SameSite != http.SameSiteLaxMode || sessionCookie.MaxAge <= 0 {
+		t.Fatalf("expected an HttpOnly, SameSite session cookie with expiry, got %+v", sessionCookie)
+	}
+	if _, err := store.GetDashboardSession(sessionCookie.Value); err == nil {
+		t.Fatalf("expected the store to hold a hash of the token, not the token")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/campaigns", nil)
+	req.AddCookie(sessionCookie)
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected campaigns page, got %d", res.Code)
+	}
+	body := res.Body.String()
+	match := csrfInputPattern.FindStringSubmatch(body)
+	if match == nil || !strings.Contains(body, `"X-CSRF-Token": "`+match[1]+`"`) {
+		t.Fatalf("expected the page's forms and htmx headers to carry the session CSRF token, got %s", body)
+	}
+
+	logout := httptest.NewRequest(http.MethodPost, "/logout", strings.NewReader("_csrf="+match[1]))
+	logout.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	logout.AddCookie(sessionCookie)
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, logout)
+	if res.Code != http.StatusSeeOther || res.Header().Get("Location") != "/login" {
+		t.Fatalf("expected logout redirect, got %d", res.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/campaigns", nil)
+	req.AddCookie(sessionCookie)
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	if res.Code != http.StatusSeeOther {
+		t.Fatalf("expected the old session to be signed out, got %d", res.Code)
+	}
+}
+
+func TestLoginRejectsBadCredentialsAndRateLimits(t *testing.T) {
+	handler := newTestServer(t, cosignclient.New(cosignclient.Options{BaseURL: "http://unused"})).BuildRouter()
+	token, cookie := openLoginForm(t, handler)
+
+	if res := postLogin(handler, token, nil, "admin", testPassword, ""); res.Code != http.StatusForbidden {
+		t.Fatalf("expected a login without its CSRF cookie to be rejected, got %d", res.Code)
+	}
+
+	for attempt := 1; attempt <= loginAttemptsBurst; attempt++ {
+		res := postLogin(handler, token, cookie, "admin", "wrong password", "")
+		if res.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: expected 401, got %d", attempt, res.Code)
+		}
+		if !strings.Contains(res.Body.String(), "Invalid username or password.") {
+			t.Fatalf("expected a generic credentials error, got %s", res.Body.String())
+		}
+	}
+
+	res := postLogin(handler, token, cookie, "admin", testPassword, "")
+	if res.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected rate limit after %d attempts, got %d", loginAttemptsBurst, res.Code)
+	}
+}
+
+func TestLoginLimitersAreKeyedPerHostAndEvictedWhenIdle(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+	server, _ := newTestServerWithStore(t, cosignclient.New(cosignclient.Options{BaseURL: "http://unused"}), clock)
+	handler := server.BuildRouter()
+	token, cookie := openLoginForm(t, handler)
+
+	for attempt := 1; attempt <= loginAttemptsBurst; attempt++ {
+		postLogin(handler, token, cookie, "admin", "wrong password", "")
+	}
+	if res := postLogin(handler, token, cookie, "admin", testPassword, ""); res.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected rate limit, got %d", res.Code)
+	}
+	if _, ok := server.loginLimiters["user:192.0.2.1|admin"]; !ok || len(server.loginLimiters) != 2 {
+		t.Fatalf("expected one host and one per-host username limiter, got %v", server.loginLimiters)
+	}
+
+	now = now.Add(loginLimiterIdle)
+	if res := postLogin(handler, token, cookie, "admin", testPassword, ""); res.Code != http.StatusSeeOther {
+		t.Fatalf("expected idle limiters evicted and the login allowed, got %d", res.Code)
+	}
+	if len(server.loginLimiters) != 2 {
+		t.Fatalf("expected only the fresh limiters kept, got %v", server.loginLimiters)
+	}
+}
+
+func TestMutationsRequireCSRFToken(t *testing.T) {
+	calls := 0
+	backend := newCampaignsBackend(t, &calls)
+	handler := newTestServer(t, cosignclient.New(cosignclient.Options{BaseURL: backend.URL})).BuildRouter()
+
+	for _, token := range []string{"", "wrong"} {
+		req := httptest.NewRequest(http.MethodPost, "/campaigns/cmp-1", strings.NewReader("_method=DELETE&_csrf="+token))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: testSessionToken})
+
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+		if res.Code != http.StatusForbidden {
+			t.Fatalf("token %q: expected 403, got %d", token, res.Code)
+		}
+	}
+	if calls != 0 {
+		t.Fatalf("expected no API calls, got %d", calls)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/campaigns/cmp-1", strings.NewReader("_method=DELETE&_csrf="+testCSRFToken))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: testSessionToken})
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	if res.Code == http.StatusForbidden || calls == 0 {
+		t.Fatalf("expected the form token to be accepted, got %d with %d calls", res.Code, calls)
+	}
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	_ = writer.WriteField("_csrf", testCSRFToken)
+	_ = writer.Close()
+	req = httptest.NewRequest(http.MethodPost, "/campaigns/cmp-1/import", &form)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.AddCookie(&http.Cookie{Name: sessionCookieName, Value: testSessionToken})
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, req)
+	if res.Code != http.StatusForbidden {
+		t.Fatalf("expected a multipart post to need the header token, got %d", res.Code)
+	}
+}
+
+func TestExpiredSessionRedirectsToLogin(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+	server, _ := newTestServerWithStore(t, cosignclient.New(cosignclient.Options{BaseURL: "http://unused"}), clock)
+	handler := server.BuildRouter()
+
+	now = now.Add(2 * time.Hour)
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, signedIn(httptest.NewRequest(http.MethodGet, "/campaigns", nil)))
+	if res.Code != http.StatusSeeOther {
+		t.Fatalf("expected expired session to redirect, got %d", res.Code)
+	}
+}
+
+func TestSafeRedirectStaysOnSite(t *testing.T) {
+	for next, want := range map[string]string{
+		"/campaigns/cmp-1":   "/campaigns/cmp-1",
+		"":                   "/campaigns",
+		"https://evil.test/": "/campaigns",
+		"//evil.test/":       "/campaigns",
+		"/\\evil.test/":      "/campaigns",
+	} {
+		if got := safeRedirect(next); got != want {
+			t.Fatalf("safeRedirect(%q) = %q, want %q", next, got, want)
+		}
+	}
+}
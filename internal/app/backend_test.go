@@ -0,0 +1,53 @@
+package app
+
+import (
+	"cosign/pkg/cosignclient"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+// fakeBackend stands in for the API. Tests register the routes they need
+// with ServeMux patterns, e.g. "GET /admin/campaigns/{id}"; any other
+// request gets the API's not found error.
+type fakeBackend struct {
+	server *httptest.Server
+	mux    *http.ServeMux
+}
+
+func newFakeBackend(t *testing.T) *fakeBackend {
+	t.Helper()
+	backend := &fakeBackend{mux: http.NewServeMux()}
+	backend.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := backend.mux.Handler(r); pattern == "" {
+			wire.WriteError(w, http.StatusNotFound, "not found")
+			return
+		}
+		backend.mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(backend.server.Close)
+	return backend
+}
+
+func (b *fakeBackend) handle(pattern string, handler http.HandlerFunc) {
+	b.mux.HandleFunc(pattern, handler)
+}
+
+// respond answers every request matching pattern with data.
+func (b *fakeBackend) respond(pattern string, status int, data any) {
+	b.handle(pattern, func(w http.ResponseWriter, r *http.Request) {
+		wire.WriteData(w, status, data)
+	})
+}
+
+func (b *fakeBackend) client() *cosignclient.Client {
+	return b.clientWith(cosignclient.Options{})
+}
+
+// clientWith returns a client for the backend with opts' other settings.
+func (b *fakeBackend) clientWith(opts cosignclient.Options) *cosignclient.Client {
+	opts.BaseURL = b.server.URL
+	return cosignclient.New(opts)
+}
@@ -0,0 +1,426 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"cosign/internal/service"
+	"cosign/pkg/cosignclient"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxImportBytes = 10 << 20
+	maxImportRows  = 50000
+	exportPageSize = 500
+	csvJobTTL      = time.Hour
+)
+
+var (
+	ErrImportEmpty       = errors.New("the csv file has no rows below its header")
+	ErrImportTooManyRows = fmt.Errorf("the csv file can hold at most %d rows", maxImportRows)
+	ErrImportUnmapped    = errors.New("choose a column for name, email and location")
+)
+
+// jobStatus is where a CSV job stands. Imports go from uploaded through
+// checking to checked, where the preview is shown, then importing; exports
+// start at exporting. Both end done or failed.
+type jobStatus string
+
+const (
+	jobUploaded  jobStatus = "uploaded"
+	jobChecking  jobStatus = "checking"
+	jobChecked   jobStatus = "checked"
+	jobImporting jobStatus = "importing"
+	jobExporting jobStatus = "exporting"
+	jobDone      jobStatus = "done"
+	jobFailed    jobStatus = "failed"
+)
+
+func (s jobStatus) running() bool {
+	return s == jobChecking || s == jobImporting || s == jobExporting
+}
+
+// importFields are the signature fields a CSV column can be mapped to,
+// with the header names each is guessed from.
+var importFields = []struct {
+	name    string
+	label   string
+	headers []string
+}{
+	{"name", "Name", []string{"name", "full name", "full_name", "signer", "signer name"}},
+	{"email", "Email", []string{"email", "e-mail", "email address", "email_address", "mail"}},
+	{"location", "Location", []string{"location", "city", "place", "region", "town"}},
+}
+
+// importMapping holds the column index for each of importFields, or -1.
+type importMapping map[string]int
+
+// importRecord is one data row of an uploaded file and the line it
+// starts on.
+type importRecord struct {
+	line   int
+	fields []string
+}
+
+// importRow is an importRecord mapped to a signature, with what the API
+// made of it.
+type importRow struct {
+	Line      int
+	Signature service.CreateSignatureRequest
+	Location  string
+	Error     string
+	Imported  bool
+}
+
+// csvJob is an import or export running on behalf of one user; only they
+// can see it. Everything below mu changes while the job runs.
+type csvJob struct {
+	id         string
+	campaignID string
+	userID     int64
+	createdAt  time.Time
+	filter     service.SignatureFilter
+
+	filename string
+	header   []string
+	records  []importRecord
+
+	mu       sync.Mutex
+	status   jobStatus
+	mapping  importMapping
+	rows     []importRow
+	done     int
+	total    int
+	err      string
+	exported []byte
+}
+
+// csvJobSnapshot is a copy of a job's state that is safe to render.
+type csvJobSnapshot struct {
+	ID         string
+	CampaignID string
+	Filename   string
+	Header     []string
+	Status     jobStatus
+	Mapping    importMapping
+	Rows       []importRow
+	Done       int
+	Total      int
+	Err        string
+	Exported   []byte
+}
+
+func (j *csvJob) snapshot() csvJobSnapshot {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return csvJobSnapshot{
+		ID:         j.id,
+		CampaignID: j.campaignID,
+		Filename:   j.filename,
+		Header:     j.header,
+		Status:     j.status,
+		Mapping:    j.mapping,
+		Rows:       j.rows,
+		Done:       j.done,
+		Total:      j.total,
+		Err:        j.err,
+		Exported:   j.exported,
+	}
+}
+
+// start moves the job to status if it is in one of from, so a job runs
+// at most once at a time.
+func (j *csvJob) start(status jobStatus, from ...jobStatus) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if !slices.Contains(from, j.status) {
+		return false
+	}
+	j.status = status
+	j.done, j.total, j.err = 0, 0, ""
+	return true
+}
+
+func (j *csvJob) progress(done, total int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.done, j.total = done, total
+}
+
+func (j *csvJob) finish(status jobStatus, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.status = status
+	if err != nil {
+		j.status = jobFailed
+		j.err = err.Error()
+	}
+}
+
+// csvJobs keeps jobs in memory for an hour after they start.
+type csvJobs struct {
+	mu   sync.Mutex
+	jobs map[string]*csvJob
+}
+
+func newCSVJobs() *csvJobs {
+	return &csvJobs{jobs: make(map[string]*csvJob)}
+}
+
+func (c *csvJobs) add(job *csvJob) error {
+	id, err := newToken()
+	if err != nil {
+		return err
+	}
+	job.id = id
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, existing := range c.jobs {
+		if time.Since(existing.createdAt) > csvJobTTL {
+			delete(c.jobs, id)
+		}
+	}
+	c.jobs[job.id] = job
+	return nil
+}
+
+// get returns the job only to the user who started it, on its campaign.
+func (c *csvJobs) get(id, campaignID string, userID int64) *csvJob {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	job, ok := c.jobs[id]
+	if !ok || job.campaignID != campaignID || job.userID != userID {
+		return nil
+	}
+	return job
+}
+
+// parseImportFile reads an uploaded CSV whose first row names its columns.
+func parseImportFile(r io.Reader) ([]string, []importRecord, error) {
+	reader := csv.NewReader(io.LimitReader(r, maxImportBytes+1))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, ErrImportEmpty
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("read csv: %w", err)
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	var records []importRecord
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("read csv: %w", err)
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(records) == maxImportRows {
+			return nil, nil, ErrImportTooManyRows
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, importRecord{line: line, fields: record})
+	}
+	if reader.InputOffset() > maxImportBytes {
+		return nil, nil, fmt.Errorf("the csv file can be at most %d MB", maxImportBytes>>20)
+	}
+	if len(records) == 0 {
+		return nil, nil, ErrImportEmpty
+	}
+	return header, records, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// guessImportMapping maps each field to the first column whose header
+// names it.
+func guessImportMapping(header []string) importMapping {
+	mapping := importMapping{}
+	for _, field := range importFields {
+		mapping[field.name] = -1
+		for idx, column := range header {
+			column = strings.ToLower(strings.TrimSpace(column))
+			if mapping[field.name] < 0 && slices.Contains(field.headers, column) {
+				mapping[field.name] = idx
+			}
+		}
+	}
+	return mapping
+}
+
+func (m importMapping) complete(columns int) bool {
+	for _, field := range importFields {
+		idx, ok := m[field.name]
+		if !ok || idx < 0 || idx >= columns {
+			return false
+		}
+	}
+	return true
+}
+
+func (m importMapping) row(record importRecord) importRow {
+	field := func(name string) string {
+		if idx := m[name]; idx >= 0 && idx < len(record.fields) {
+			return strings.TrimSpace(record.fields[idx])
+		}
+		return ""
+	}
+
+	return importRow{
+		Line: record.line,
+		Signature: service.CreateSignatureRequest{
+			Name:     field("name"),
+			Email:    field("email"),
+			Location: field("location"),
+		},
+	}
+}
+
+// checkImport maps every record and dry-runs it against the API in
+// batches, so each row is judged by the same rules, including the
+// campaign's location presets, that a real import applies.
+func (s *Server) checkImport(ctx context.Context, job *csvJob) {
+	snapshot := job.snapshot()
+	rows := make([]importRow, len(job.records))
+	for idx, record := range job.records {
+		rows[idx] = snapshot.Mapping.row(record)
+	}
+
+	err := s.runImportBatches(ctx, job, rows, true)
+	job.mu.Lock()
+	job.rows = rows
+	job.mu.Unlock()
+	job.finish(jobChecked, err)
+}
+
+// commitImport imports the rows that passed the check. The API checks them
+// again, so a row that became a duplicate since is still rejected.
+func (s *Server) commitImport(ctx context.Context, job *csvJob) {
+	snapshot := job.snapshot()
+
+	var accepted []importRow
+	for _, row := range snapshot.Rows {
+		if row.Error == "" {
+			accepted = append(accepted, row)
+		}
+	}
+
+	err := s.runImportBatches(ctx, job, accepted, false)
+
+	results := make(map[int]importRow, len(accepted))
+	for _, row := range accepted {
+		results[row.Line] = row
+	}
+	rows := make([]importRow, len(snapshot.Rows))
+	for idx, row := range snapshot.Rows {
+		if result, ok := results[row.Line]; ok {
+			row = result
+		}
+		rows[idx] = row
+	}
+
+	job.mu.Lock()
+	job.rows = rows
+	job.mu.Unlock()
+	job.finish(jobDone, err)
+}
+
+func (s *Server) runImportBatches(ctx context.Context, job *csvJob, rows []importRow, dryRun bool) error {
+	job.progress(0, len(rows))
+	for start := 0; start < len(rows); start += cosignclient.MaxImportBatch {
+		batch := rows[start:min(start+cosignclient.MaxImportBatch, len(rows))]
+
+		req := service.ImportSignaturesRequest{DryRun: dryRun}
+		for _, row := range batch {
+			req.Signatures = append(req.Signatures, row.Signature)
+		}
+
+		response, err := s.client.ImportSignatures(ctx, job.campaignID, req)
+		if err != nil {
+			return err
+		}
+		for _, result := range response.Results {
+			if result.Index < 0 || result.Index >= len(batch) {
+				continue
+			}
+			row := &batch[result.Index]
+			row.Location = result.Location
+			row.Imported = result.ID != 0
+			if result.Error != nil {
+				row.Error = result.Error.Message
+			}
+		}
+
+		job.progress(start+len(batch), len(rows))
+	}
+	return nil
+}
+
+// exportSignatures writes the campaign's signatures matching the job's
+// filter to CSV, in the columns the CLI's export uses.
+func (s *Server) exportSignatures(ctx context.Context, job *csvJob) {
+	var body bytes.Buffer
+	writer := csv.NewWriter(&body)
+	_ = writer.Write([]string{"id", "name", "email", "location", "created_at"})
+
+	err := func() error {
+		for offset := 0; ; offset += exportPageSize {
+			page, err := s.client.ListSignaturesMatching(ctx, job.campaignID, job.filter, exportPageSize, offset)
+			if err != nil {
+				return err
+			}
+
+			for _, signature := range page.Signatures {
+				_ = writer.Write([]string{
+					strconv.FormatInt(signature.ID, 10),
+					signature.Name,
+					signature.Email,
+					signature.Location,
+					time.Unix(signature.CreatedAt, 0).UTC().Format(time.RFC3339),
+				})
+			}
+
+			done := offset + len(page.Signatures)
+			job.progress(done, max(page.Total, done))
+			if len(page.Signatures) == 0 || done >= page.Total {
+				break
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	}()
+
+	job.mu.Lock()
+	if err == nil {
+		job.exported = body.Bytes()
+	}
+	job.mu.Unlock()
+	job.finish(jobDone, err)
+}
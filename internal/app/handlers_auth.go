@@ -0,0 +1,95 @@
+package app
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+)
+
+func (s *Server) handleLoginPage(w http.ResponseWriter, r *http.Request) {
+	next := safeRedirect(r.URL.Query().Get("next"))
+
+	session, err := s.currentSession(r)
+	if err == nil && session != nil {
+		http.Redirect(w, r, next, http.StatusSeeOther)
+		return
+	}
+
+	s.renderLoginPage(w, r, http.StatusOK, LoginPageView{Next: next})
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	username := strings.TrimSpace(r.FormValue("username"))
+	password := r.FormValue("password")
+	view := LoginPageView{
+		Username: username,
+		Next:     safeRedirect(r.FormValue("next")),
+	}
+
+	cookie, err := r.Cookie(loginCookieName)
+	if err != nil || !tokensEqual(r.FormValue(csrfFormField), cookie.Value) {
+		view.Error = "Your sign-in form expired. Please try again."
+		s.renderLoginPage(w, r, http.StatusForbidden, view)
+		return
+	}
+
+	if !s.allowLogin(r, username) {
+		view.Error = "Too many sign-in attempts. Please wait a minute and try again."
+		s.renderLoginPage(w, r, http.StatusTooManyRequests, view)
+		return
+	}
+
+	user, err := authenticate(s.auth, username, password)
+	if errors.Is(err, ErrInvalidCredentials) {
+		view.Error = "Invalid username or password."
+		s.renderLoginPage(w, r, http.StatusUnauthorized, view)
+		return
+	}
+	if err != nil {
+		log.Printf("dashboard login: %v", err)
+		view.Error = "Sign-in failed. Please try again."
+		s.renderLoginPage(w, r, http.StatusInternalServerError, view)
+		return
+	}
+
+	if err := s.startSession(w, r, user); err != nil {
+		log.Printf("start dashboard session: %v", err)
+		view.Error = "Sign-in failed. Please try again."
+		s.renderLoginPage(w, r, http.StatusInternalServerError, view)
+		return
+	}
+
+	http.SetCookie(w, s.cookie(r, loginCookieName, "", "/login", 0))
+	http.Redirect(w, r, view.Next, http.StatusSeeOther)
+}
+
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if session := sessionFromContext(r.Context()); session != nil {
+		if err := s.auth.DeleteDashboardSession(session.ID); err != nil {
+			log.Printf("delete dashboard session: %v", err)
+		}
+	}
+
+	http.SetCookie(w, s.cookie(r, sessionCookieName, "", "/", 0))
+	if requestContext(r).IsHTMX {
+		w.Header().Set("HX-Redirect", "/login")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// renderLoginPage renders the sign-in form with a fresh login CSRF token,
+// kept in a cookie scoped to /login until a sign-in succeeds.
+func (s *Server) renderLoginPage(w http.ResponseWriter, r *http.Request, statusCode int, view LoginPageView) {
+	token, err := newToken()
+	if err != nil {
+		http.Error(w, "failed to render page", http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, s.cookie(r, loginCookieName, token, "/login", loginCookieMaxAge))
+	view.CSRFToken = token
+	s.renderer.RenderLoginPage(w, r, statusCode, view)
+}
@@ -10,13 +10,13 @@ func (s *Server) handleCampaigns(w http.ResponseWriter, r *http.Request) {
 	page := parsePageQuery(r, "page")
 
 	if ctx.IsHTMX {
-		view := s.loadCampaignsRegion(page, "", "")
-		s.renderer.RenderCampaignsRegion(w, http.StatusOK, view)
+		view := s.loadCampaignsRegion(r.Context(), page, "", "")
+		s.renderer.RenderCampaignsRegion(w, r, http.StatusOK, view)
 		return
 	}
 
-	view := s.loadCampaignsPage(page, "", "")
-	s.renderer.RenderCampaignsPage(w, http.StatusOK, view)
+	view := s.loadCampaignsPage(r.Context(), page, "", "")
+	s.renderer.RenderCampaignsPage(w, r, http.StatusOK, view)
 }
 
 func (s *Server) handleCreateCampaign(w http.ResponseWriter, r *http.Request) {
@@ -25,18 +25,18 @@ func (s *Server) handleCreateCampaign(w http.ResponseWriter, r *http.Request) {
 
 	name := strings.TrimSpace(r.FormValue("name"))
 	if name == "" {
-		s.renderCampaignsError(w, ctx, http.StatusBadRequest, page, "campaign name cannot be empty", name)
+		s.renderCampaignsError(w, r, ctx, http.StatusBadRequest, page, "campaign name cannot be empty", name)
 		return
 	}
 
-	if err := s.createCampaign(name); err != nil {
-		s.renderCampaignsError(w, ctx, statusFromError(err), page, err.Error(), name)
+	if err := s.createCampaign(r.Context(), name); err != nil {
+		s.renderCampaignsError(w, r, ctx, statusFromError(err), page, err.Error(), name)
 		return
 	}
 
 	if ctx.IsHTMX {
-		view := s.loadCampaignsRegion(1, "", "")
-		s.renderer.RenderCampaignsRegion(w, http.StatusOK, view)
+		view := s.loadCampaignsRegion(r.Context(), 1, "", "")
+		s.renderer.RenderCampaignsRegion(w, r, http.StatusOK, view)
 		return
 	}
 
@@ -49,18 +49,18 @@ func (s *Server) handleDeleteCampaign(w http.ResponseWriter, r *http.Request) {
 
 	campaignID := campaignIDFromPath(r)
 	if campaignID == "" {
-		s.renderCampaignsError(w, ctx, http.StatusBadRequest, page, "campaign id required", "")
+		s.renderCampaignsError(w, r, ctx, http.StatusBadRequest, page, "campaign id required", "")
 		return
 	}
 
-	if err := s.deleteCampaign(campaignID); err != nil {
-		s.renderCampaignsError(w, ctx, statusFromError(err), page, err.Error(), "")
+	if err := s.deleteCampaign(r.Context(), campaignID); err != nil {
+		s.renderCampaignsError(w, r, ctx, statusFromError(err), page, err.Error(), "")
 		return
 	}
 
 	if ctx.IsHTMX {
-		view := s.loadCampaignsRegion(page, "", "")
-		s.renderer.RenderCampaignsRegion(w, http.StatusOK, view)
+		view := s.loadCampaignsRegion(r.Context(), page, "", "")
+		s.renderer.RenderCampaignsRegion(w, r, http.StatusOK, view)
 		return
 	}
 
@@ -69,6 +69,7 @@ func (s *Server) handleDeleteCampaign(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) renderCampaignsError(
 	w http.ResponseWriter,
+	r *http.Request,
 	ctx RequestContext,
 	statusCode int,
 	page int,
@@ -76,11 +77,11 @@ func (s *Server) renderCampaignsError(
 	name string,
 ) {
 	if ctx.IsHTMX {
-		view := s.loadCampaignsRegion(page, name, formError)
-		s.renderer.RenderCampaignsRegion(w, statusCode, view)
+		view := s.loadCampaignsRegion(r.Context(), page, name, formError)
+		s.renderer.RenderCampaignsRegion(w, r, statusCode, view)
 		return
 	}
 
-	view := s.loadCampaignsPage(page, name, formError)
-	s.renderer.RenderCampaignsPage(w, statusCode, view)
+	view := s.loadCampaignsPage(r.Context(), page, name, formError)
+	s.renderer.RenderCampaignsPage(w, r, statusCode, view)
 }
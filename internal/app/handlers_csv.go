@@ -0,0 +1,241 @@
+package app
+
+import (
+	"context"
+	"cosign/internal/service"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func (s *Server) handleImportPage(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	s.renderImport(w, r, http.StatusOK, NewImportPanelView(campaignID, nil, ""))
+}
+
+// handleUploadImport reads the file and guesses which column holds each
+// field; nothing is checked until the user confirms the mapping.
+func (s *Server) handleUploadImport(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+
+	file, header, err := r.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) || (err == nil && header.Size > maxImportBytes) {
+		if file != nil {
+			file.Close()
+		}
+		formError := fmt.Sprintf("the csv file can be at most %d MB", maxImportBytes>>20)
+		s.renderImport(w, r, http.StatusRequestEntityTooLarge, NewImportPanelView(campaignID, nil, formError))
+		return
+	}
+	if err != nil {
+		s.renderImport(w, r, http.StatusBadRequest, NewImportPanelView(campaignID, nil, "choose a csv file to upload"))
+		return
+	}
+	defer file.Close()
+
+	columns, records, err := parseImportFile(file)
+	if err != nil {
+		s.renderImport(w, r, http.StatusBadRequest, NewImportPanelView(campaignID, nil, err.Error()))
+		return
+	}
+
+	job := &csvJob{
+		campaignID: campaignID,
+		userID:     sessionFromContext(r.Context()).UserID,
+		createdAt:  s.clock(),
+		filename:   header.Filename,
+		header:     columns,
+		records:    records,
+		status:     jobUploaded,
+		mapping:    guessImportMapping(columns),
+	}
+	if err := s.jobs.add(job); err != nil {
+		s.renderImport(w, r, http.StatusInternalServerError, NewImportPanelView(campaignID, nil, err.Error()))
+		return
+	}
+
+	snapshot := job.snapshot()
+	s.renderImport(w, r, http.StatusOK, NewImportPanelView(campaignID, &snapshot, ""))
+}
+
+// handleCheckImport saves the chosen mapping and starts the dry run. It can
+// be repeated with a different mapping until the rows are imported.
+func (s *Server) handleCheckImport(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	job := s.importJob(w, r)
+	if job == nil {
+		return
+	}
+
+	mapping := importMapping{}
+	for _, field := range importFields {
+		idx, err := strconv.Atoi(strings.TrimSpace(r.FormValue(field.name)))
+		if err != nil {
+			idx = -1
+		}
+		mapping[field.name] = idx
+	}
+
+	if !mapping.complete(len(job.header)) {
+		snapshot := job.snapshot()
+		snapshot.Mapping = mapping
+		s.renderImport(w, r, http.StatusBadRequest, NewImportPanelView(campaignID, &snapshot, ErrImportUnmapped.Error()))
+		return
+	}
+
+	if !job.start(jobChecking, jobUploaded, jobChecked) {
+		s.renderImportJob(w, r, http.StatusConflict, job, "this import can no longer be checked")
+		return
+	}
+	job.mu.Lock()
+	job.mapping = mapping
+	job.mu.Unlock()
+
+	go s.checkImport(context.WithoutCancel(r.Context()), job)
+	s.renderImportJob(w, r, http.StatusAccepted, job, "")
+}
+
+func (s *Server) handleCommitImport(w http.ResponseWriter, r *http.Request) {
+	job := s.importJob(w, r)
+	if job == nil {
+		return
+	}
+
+	if !job.start(jobImporting, jobChecked) {
+		s.renderImportJob(w, r, http.StatusConflict, job, "check the file before importing it")
+		return
+	}
+
+	go s.commitImport(context.WithoutCancel(r.Context()), job)
+	s.renderImportJob(w, r, http.StatusAccepted, job, "")
+}
+
+func (s *Server) handleImportStatus(w http.ResponseWriter, r *http.Request) {
+	job := s.importJob(w, r)
+	if job == nil {
+		return
+	}
+
+	s.renderImportJob(w, r, http.StatusOK, job, "")
+}
+
+// handleStartExport collects the campaign's signatures in the background;
+// the panel polls until the file can be downloaded.
+func (s *Server) handleStartExport(w http.ResponseWriter, r *http.Request) {
+	job, err := s.startExport(r, service.SignatureFilter{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !requestContext(r).IsHTMX {
+		http.Redirect(w, r, NewExportPanelView(job.snapshot()).StatusPath, http.StatusSeeOther)
+		return
+	}
+	s.renderer.RenderExportPanel(w, r, http.StatusOK, NewExportPanelView(job.snapshot()))
+}
+
+// startExport exports the signatures of the request's campaign that match
+// filter, in the background.
+func (s *Server) startExport(r *http.Request, filter service.SignatureFilter) (*csvJob, error) {
+	job := &csvJob{
+		campaignID: campaignIDFromPath(r),
+		userID:     sessionFromContext(r.Context()).UserID,
+		createdAt:  s.clock(),
+		filter:     filter,
+		status:     jobExporting,
+	}
+	if err := s.jobs.add(job); err != nil {
+		return nil, err
+	}
+
+	go s.exportSignatures(context.WithoutCancel(r.Context()), job)
+	return job, nil
+}
+
+func (s *Server) handleExportStatus(w http.ResponseWriter, r *http.Request) {
+	job := s.exportJob(w, r)
+	if job == nil {
+		return
+	}
+
+	view := NewExportPanelView(job.snapshot())
+	if requestContext(r).IsHTMX {
+		s.renderer.RenderExportPanel(w, r, http.StatusOK, view)
+		return
+	}
+
+	page := s.loadCSVPage(r.Context(), job.campaignID)
+	page.Export = &view
+	page.Polling = view.Polling
+	s.renderer.RenderCSVPage(w, r, http.StatusOK, page)
+}
+
+func (s *Server) handleDownloadExport(w http.ResponseWriter, r *http.Request) {
+	job := s.exportJob(w, r)
+	if job == nil {
+		return
+	}
+
+	snapshot := job.snapshot()
+	if snapshot.Status != jobDone {
+		http.Error(w, "the export is not ready", http.StatusConflict)
+		return
+	}
+
+	filename := "signatures-" + snapshot.CampaignID + ".csv"
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(snapshot.Exported)
+}
+
+// importJob and exportJob find the caller's own job on the campaign in the
+// path, answering 404 when there is none.
+func (s *Server) importJob(w http.ResponseWriter, r *http.Request) *csvJob {
+	job := s.pathJob(r)
+	if job == nil || job.records == nil {
+		http.NotFound(w, r)
+		return nil
+	}
+	return job
+}
+
+func (s *Server) exportJob(w http.ResponseWriter, r *http.Request) *csvJob {
+	job := s.pathJob(r)
+	if job == nil || job.records != nil {
+		http.NotFound(w, r)
+		return nil
+	}
+	return job
+}
+
+func (s *Server) pathJob(r *http.Request) *csvJob {
+	session := sessionFromContext(r.Context())
+	if session == nil {
+		return nil
+	}
+	return s.jobs.get(r.PathValue("job_id"), campaignIDFromPath(r), session.UserID)
+}
+
+func (s *Server) renderImportJob(w http.ResponseWriter, r *http.Request, statusCode int, job *csvJob, formError string) {
+	snapshot := job.snapshot()
+	s.renderImport(w, r, statusCode, NewImportPanelView(job.campaignID, &snapshot, formError))
+}
+
+// renderImport answers htmx with the panel and a 200, so htmx swaps it in
+// even when it carries an error, and full page requests with statusCode.
+func (s *Server) renderImport(w http.ResponseWriter, r *http.Request, statusCode int, view ImportPanelView) {
+	if requestContext(r).IsHTMX {
+		s.renderer.RenderImportPanel(w, r, http.StatusOK, view)
+		return
+	}
+
+	page := s.loadCSVPage(r.Context(), view.CampaignID)
+	page.Import = &view
+	page.Polling = view.Polling
+	s.renderer.RenderCSVPage(w, r, statusCode, page)
+}
@@ -0,0 +1,260 @@
+package app
+
+import (
+	"bytes"
+	"cosign/internal/service"
+	"cosign/pkg/cosignclient"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+var (
+	importStatusPattern = regexp.MustCompile(`hx-get="(/campaigns/[^/]+/import/[^"]+)"`)
+	importCheckPattern  = regexp.MustCompile(`action="(/campaigns/[^/]+/import/[^"]+)/check"`)
+	exportStatusPattern = regexp.MustCompile(`hx-get="(/campaigns/[^/]+/export/[^"]+)"`)
+)
+
+type csvBackend struct {
+	signatures []*service.Signature
+}
+
+// newCSVBackend fakes the campaign, listing and import routes, rejecting
+// emails without an @ and emails already taken, as the API would.
+func newCSVBackend(t *testing.T, csv *csvBackend) *cosignclient.Client {
+	t.Helper()
+	backend := newFakeBackend(t)
+	backend.respond("GET /admin/campaigns/cmp-1", http.StatusOK, service.Campaign{ID: "cmp-1", Name: "Import"})
+	backend.handle("GET /admin/campaigns/cmp-1/signatures", func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		page := csv.signatures[min(offset, len(csv.signatures)):min(offset+limit, len(csv.signatures))]
+		wire.WriteData(w, http.StatusOK, service.Signatures{Signatures: page, Total: len(csv.signatures), Limit: limit, Offset: offset})
+	})
+	backend.handle("POST /admin/campaigns/cmp-1/signatures/import", func(w http.ResponseWriter, r *http.Request) {
+		var req service.ImportSignaturesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			wire.WriteError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		seen := map[string]bool{}
+		for _, signature := range csv.signatures {
+			seen[signature.Email] = true
+		}
+		response := service.ImportSignaturesResponse{}
+		for idx, row := range req.Signatures {
+			result := service.ImportSignatureResult{Index: idx}
+			switch {
+			case !strings.Contains(row.Email, "@"):
+				result.Error = &service.APIError{Code: service.CodeInvalidEmail, Message: "invalid email"}
+			case seen[row.Email]:
+				result.Error = &service.APIError{Code: service.CodeDuplicateEmail, Message: "email already signed"}
+			default:
+				seen[row.Email] = true
+				result.Location = strings.ToUpper(row.Location)
+				if !req.DryRun {
+					result.ID = int64(len(csv.signatures) + 1)
+					csv.signatures = append(csv.signatures, &service.Signature{
+						ID:       result.ID,
+						Name:     row.Name,
+						Email:    row.Email,
+						Location: result.Location,
+					})
+				}
+			}
+			if result.Error != nil {
+				response.Rejected++
+			} else {
+				response.Accepted++
+			}
+			response.Results = append(response.Results, result)
+		}
+		wire.WriteData(w, http.StatusOK, response)
+	})
+	return backend.client()
+}
+
+func uploadRequest(t *testing.T, target, filename, body string) *http.Request {
+	t.Helper()
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	_, _ = part.Write([]byte(body))
+	_ = writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, target, &form)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("HX-Request", "true")
+	return signedIn(req)
+}
+
+func htmxGet(target string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("HX-Request", "true")
+	return signedIn(req)
+}
+
+// pollUntil fetches target until its panel stops polling.
+func pollUntil(t *testing.T, handler http.Handler, target string) string {
+	t.Helper()
+
+	for range 200 {
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, htmxGet(target))
+		if res.Code != http.StatusOK {
+			t.Fatalf("poll %s: got %d: %s", target, res.Code, res.Body.String())
+		}
+		if !strings.Contains(res.Body.String(), `hx-trigger="every 1s"`) {
+			return res.Body.String()
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("poll %s: job did not finish", target)
+	return ""
+}
+
+func TestImportMapsChecksAndCommitsRows(t *testing.T) {
+	csv := &csvBackend{}
+	handler := newTestServer(t, newCSVBackend(t, csv)).BuildRouter()
+	importPath := "/campaigns/cmp-1/import"
+
+	file := "\ufeffCity,Full Name,E-mail\r\n" +
+		"Brooklyn,Ada,ada@example.com\r\n" +
+		"\r\n" +
+		"Queens,Bob,not-an-email\r\n" +
+		"Queens,Ada Again,ada@example.com\r\n" +
+		"Bronx,Cy,cy@example.com\r\n"
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, uploadRequest(t, importPath, "signers.csv", file))
+	body := res.Body.String()
+	if res.Code != http.StatusOK || !strings.Contains(body, `<option value="1" selected>Full Name</option>`) {
+		t.Fatalf("expected the mapping form with guessed columns, got %d: %s", res.Code, body)
+	}
+	match := importCheckPattern.FindStringSubmatch(body)
+	if match == nil {
+		t.Fatalf("expected a check form, got %s", body)
+	}
+	jobPath := match[1]
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, jobPath+"/check", url.Values{"name": {"1"}, "email": {"2"}}))
+	if !strings.Contains(res.Body.String(), ErrImportUnmapped.Error()) {
+		t.Fatalf("expected an incomplete mapping to be refused, got %s", res.Body.String())
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, jobPath+"/check", url.Values{"name": {"1"}, "email": {"2"}, "location": {"0"}}))
+	if match := importStatusPattern.FindStringSubmatch(res.Body.String()); match == nil || match[1] != jobPath {
+		t.Fatalf("expected a polling panel, got %s", res.Body.String())
+	}
+
+	body = pollUntil(t, handler, jobPath)
+	for _, want := range []string{"2 rows are ready to import and 2 will be skipped", "<td><span class=\"mono\">4</span></td>", "invalid email", "email already signed"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in the preview, got %s", want, body)
+		}
+	}
+	if len(csv.signatures) != 0 {
+		t.Fatalf("expected the check to store nothing, got %d signatures", len(csv.signatures))
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, jobPath+"/commit", nil))
+	body = pollUntil(t, handler, jobPath)
+	if !strings.Contains(body, "Imported 2 signatures; 2 rows were skipped") {
+		t.Fatalf("expected the import summary, got %s", body)
+	}
+
+	if len(csv.signatures) != 2 || csv.signatures[1].Location != "BRONX" {
+		t.Fatalf("expected 2 imported signatures, got %+v", csv.signatures)
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, jobPath+"/commit", nil))
+	if !strings.Contains(res.Body.String(), "check the file before importing it") {
+		t.Fatalf("expected a finished import not to run again, got %s", res.Body.String())
+	}
+}
+
+func TestImportRejectsUnreadableFiles(t *testing.T) {
+	csv := &csvBackend{}
+	handler := newTestServer(t, newCSVBackend(t, csv)).BuildRouter()
+	importPath := "/campaigns/cmp-1/import"
+
+	for _, tc := range []struct {
+		file string
+		want string
+	}{
+		{"name,email,location\n", ErrImportEmpty.Error()},
+		{"name,email\n\"unclosed,a@example.com\n", "read csv"},
+	} {
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, uploadRequest(t, importPath, "bad.csv", tc.file))
+		if !strings.Contains(res.Body.String(), tc.want) || !strings.Contains(res.Body.String(), `name="file"`) {
+			t.Fatalf("expected %q with the upload form, got %s", tc.want, res.Body.String())
+		}
+	}
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, uploadRequest(t, importPath, "big.csv", strings.Repeat("a", maxUploadBytes+1)))
+	if !strings.Contains(res.Body.String(), "the csv file can be at most 10 MB") {
+		t.Fatalf("expected an oversized upload refused, got %s", res.Body.String())
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, htmxGet(importPath+"/missing"))
+	if res.Code != http.StatusNotFound {
+		t.Fatalf("expected an unknown job to be missing, got %d", res.Code)
+	}
+}
+
+func TestExportDownloadsEverySignature(t *testing.T) {
+	csv := &csvBackend{signatures: []*service.Signature{
+		{ID: 1, Name: "Ada", Email: "ada@example.com", Location: "Brooklyn", CreatedAt: 1700000000},
+		{ID: 2, Name: "Bob", Email: "bob@example.com", Location: "Queens", CreatedAt: 1700000060},
+	}}
+	server, _ := newTestServerWithRoles(t, newCSVBackend(t, csv), time.Now, RoleAssignment{CampaignID: "cmp-1", Role: RoleViewer})
+	handler := server.BuildRouter()
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, "/campaigns/cmp-1/export", nil))
+	match := exportStatusPattern.FindStringSubmatch(res.Body.String())
+	if match == nil {
+		t.Fatalf("expected a polling export panel, got %d: %s", res.Code, res.Body.String())
+	}
+
+	body := pollUntil(t, handler, match[1])
+	if !strings.Contains(body, "Download CSV") {
+		t.Fatalf("expected a download link, got %s", body)
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, signedIn(httptest.NewRequest(http.MethodGet, match[1]+"/download", nil)))
+	if res.Code != http.StatusOK || !strings.HasPrefix(res.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("expected a csv download, got %d %q", res.Code, res.Header().Get("Content-Type"))
+	}
+	lines := strings.Split(strings.TrimSpace(res.Body.String()), "\n")
+	if len(lines) != 3 || lines[0] != "id,name,email,location,created_at" || lines[2] != "2,Bob,bob@example.com,Queens,2023-11-14T22:14:20Z" {
+		t.Fatalf("expected a header and two signatures, got %q", res.Body.String())
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, uploadRequest(t, "/campaigns/cmp-1/import", "signers.csv", "name,email,location\nA,a@example.com,Here\n"))
+	if res.Code != http.StatusForbidden {
+		t.Fatalf("expected viewers not to import, got %d", res.Code)
+	}
+}
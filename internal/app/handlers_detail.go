@@ -1,8 +1,8 @@
 package app
 
 import (
+	"cosign/internal/service"
 	"net/http"
-	"strings"
 )
 
 func (s *Server) handleCampaignDetailPage(w http.ResponseWriter, r *http.Request) {
@@ -12,19 +12,19 @@ func (s *Server) handleCampaignDetailPage(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	locMode, locIndex := parseLocationsMode(r, "mode", "index")
+	locMode, locID := parseLocationsMode(r, "mode", "id")
 	state := CampaignDetailPageState{
 		Locations: LocationsPanelState{
-			Mode:      locMode,
-			EditIndex: locIndex,
+			Mode:   locMode,
+			EditID: locID,
 		},
 		Signatures: SignaturesPanelState{
 			Page: parsePageQuery(r, "page"),
 		},
 	}
 
-	view, status := s.loadCampaignDetailPage(campaignID, state)
-	s.renderer.RenderCampaignDetailPage(w, status, view)
+	view, status := s.loadCampaignDetailPage(r.Context(), campaignID, state)
+	s.renderer.RenderCampaignDetailPage(w, r, status, view)
 }
 
 func (s *Server) handleUpdateCampaign(w http.ResponseWriter, r *http.Request) {
@@ -35,31 +35,43 @@ func (s *Server) handleUpdateCampaign(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	name := strings.TrimSpace(r.FormValue("name"))
-	existing, err := s.getCampaign(campaignID)
-	if err != nil {
-		s.renderCampaignUpdateError(w, r, ctx, statusFromError(err), campaignID, name, err.Error())
+	form := parseCampaignPanelForm(r)
+	if form.Name == "" {
+		form.FormError = "campaign name cannot be empty"
+		s.renderCampaignUpdateError(w, r, ctx, http.StatusBadRequest, campaignID, form)
 		return
 	}
 
-	if name == "" {
-		s.renderCampaignUpdateError(w, r, ctx, http.StatusBadRequest, campaignID, name, "campaign name cannot be empty")
-		return
+	req := service.UpdateCampaignRequest{
+		Name:       form.Name,
+		Letter:     &form.Letter,
+		Goal:       &form.Goal,
+		Theme:      &form.Theme,
+		SuccessURL: &form.SuccessURL,
+		ErrorURL:   &form.ErrorURL,
 	}
-
-	if err := s.updateCampaign(campaignID, name, existing.AllowCustomText); err != nil {
-		s.renderCampaignUpdateError(w, r, ctx, statusFromError(err), campaignID, name, err.Error())
+	if form.Revision > 0 {
+		req.Revision = &form.Revision
+	}
+	if _, err := s.updateCampaign(r.Context(), campaignID, req); err != nil {
+		form.FormError = err.Error()
+		form.FieldErrors = formFieldErrors(err)
+		if isConflictError(err) {
+			form.FormError = conflictMessage
+			form.Conflict = true
+		}
+		s.renderCampaignUpdateError(w, r, ctx, statusFromError(err), campaignID, form)
 		return
 	}
 
 	if ctx.IsHTMX {
-		campaign, err := s.getCampaign(campaignID)
+		campaign, err := s.getCampaign(r.Context(), campaignID)
 		panel := NewCampaignPanelView(campaign, err)
 		status := http.StatusOK
 		if err != nil {
 			status = statusFromError(err)
 		}
-		s.renderer.RenderCampaignPanel(w, status, panel)
+		s.renderer.RenderCampaignPanel(w, r, status, panel)
 		return
 	}
 
@@ -72,39 +84,31 @@ func (s *Server) renderCampaignUpdateError(
 	ctx RequestContext,
 	statusCode int,
 	campaignID string,
-	name string,
-	formError string,
+	form CampaignPanelState,
 ) {
 	if ctx.IsHTMX {
-		campaign, err := s.getCampaign(campaignID)
-		panel := NewCampaignPanelView(campaign, err)
-		if name != "" {
-			panel.Name = name
-		}
-		panel.FormError = formError
-		s.renderer.RenderCampaignPanel(w, statusCode, panel)
+		campaign, err := s.getCampaign(r.Context(), campaignID)
+		panel := NewCampaignPanelView(campaign, err).WithState(form)
+		s.renderer.RenderCampaignPanel(w, r, statusCode, panel)
 		return
 	}
 
-	locMode, locIndex := parseLocationsMode(r, "mode", "index")
+	locMode, locID := parseLocationsMode(r, "mode", "id")
 	state := CampaignDetailPageState{
-		Campaign: CampaignPanelState{
-			Name:      name,
-			FormError: formError,
-		},
+		Campaign: form,
 		Locations: LocationsPanelState{
-			Mode:      locMode,
-			EditIndex: locIndex,
+			Mode:   locMode,
+			EditID: locID,
 		},
 		Signatures: SignaturesPanelState{
 			Page: parsePageQuery(r, "page"),
 		},
 	}
 
-	view, status := s.loadCampaignDetailPage(campaignID, state)
+	view, status := s.loadCampaignDetailPage(r.Context(), campaignID, state)
 	if status == http.StatusOK {
 		status = statusCode
 	}
 
-	s.renderer.RenderCampaignDetailPage(w, status, view)
+	s.renderer.RenderCampaignDetailPage(w, r, status, view)
 }
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"cosign/internal/service"
+	"cosign/pkg/cosignclient"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+func TestHandleUpdateCampaignShowsConflict(t *testing.T) {
+	var received service.UpdateCampaignRequest
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/admin/campaigns/cmp-1":
+			if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+				wire.WriteError(w, http.StatusBadRequest, "invalid request body")
+				return
+			}
+			wire.WriteError(w, http.StatusPreconditionFailed, service.ErrRevisionConflict.Error())
+		case r.Method == http.MethodGet && r.URL.Path == "/admin/campaigns/cmp-1":
+			wire.WriteData(w, http.StatusOK, service.Campaign{ID: "cmp-1", Name: "Theirs", Revision: 4})
+		default:
+			wire.WriteError(w, http.StatusNotFound, "not found")
+		}
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, cosignclient.New(cosignclient.Options{BaseURL: backend.URL}))
+
+	form := url.Values{
+		"name":     {"Mine"},
+		"revision": {"3"},
+	}
+	req := httptest.NewRequest(http.MethodPatch, "/campaigns/cmp-1", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+
+	res := httptest.NewRecorder()
+	server.BuildRouter().ServeHTTP(res, signedIn(req))
+
+	if received.Revision == nil || *received.Revision != 3 {
+		t.Fatalf("expected the form revision to be sent, got %v", received.Revision)
+	}
+	if res.Code != http.StatusConflict {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusConflict, res.Code, res.Body.String())
+	}
+
+	body := res.Body.String()
+	for _, want := range []string{
+		"changed elsewhere",
+		`href="/campaigns/cmp-1">Reload</a>`,
+		`value="Mine"`,
+		`name="revision" value="3"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in conflict panel, got %s", want, body)
+		}
+	}
+}
+
+func TestHandleUpdateCampaignHighlightsInvalidField(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]service.APIError{
+			"error": {
+				Code:    service.CodeInvalidThemeColor,
+				Message: service.ErrInvalidThemeColor.Error(),
+				Fields: []service.FieldError{{
+					Field:   "theme.background_color",
+					Code:    service.CodeInvalidThemeColor,
+					Message: service.ErrInvalidThemeColor.Error(),
+				}},
+			},
+		})
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, cosignclient.New(cosignclient.Options{BaseURL: backend.URL}))
+
+	form := url.Values{
+		"name":             {"Fields"},
+		"primary_color":    {"#123abc"},
+		"background_color": {"blue"},
+	}
+	req := httptest.NewRequest(http.MethodPatch, "/campaigns/cmp-1", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+
+	res := httptest.NewRecorder()
+	server.BuildRouter().ServeHTTP(res, signedIn(req))
+
+	if res.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusBadRequest, res.Code, res.Body.String())
+	}
+	body := res.Body.String()
+	if !strings.Contains(body, `name="background_color" aria-invalid="true"`) {
+		t.Fatalf("expected background color to be marked invalid, got %s", body)
+	}
+	if strings.Contains(body, `name="primary_color" aria-invalid="true"`) {
+		t.Fatalf("expected primary color to stay valid, got %s", body)
+	}
+}
@@ -2,7 +2,9 @@ package app
 
 import (
 	"cosign/internal/service"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -14,25 +16,25 @@ func (s *Server) handleLocations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	mode, editIndex := parseLocationsMode(r, "mode", "index")
+	mode, editID := parseLocationsMode(r, "mode", "id")
 	state := LocationsPanelState{
-		Mode:      mode,
-		EditIndex: editIndex,
+		Mode:   mode,
+		EditID: editID,
 	}
 
 	if ctx.IsHTMX {
-		panel, status := s.loadLocationsPanel(campaignID, state)
-		s.renderer.RenderLocationsPanel(w, status, panel)
+		panel, status := s.loadLocationsPanel(r.Context(), campaignID, state)
+		s.renderer.RenderLocationsPanel(w, r, status, panel)
 		return
 	}
 
-	view, status := s.loadCampaignDetailPage(campaignID, CampaignDetailPageState{
+	view, status := s.loadCampaignDetailPage(r.Context(), campaignID, CampaignDetailPageState{
 		Locations: state,
 		Signatures: SignaturesPanelState{
 			Page: parsePageQuery(r, "page"),
 		},
 	})
-	s.renderer.RenderCampaignDetailPage(w, status, view)
+	s.renderer.RenderCampaignDetailPage(w, r, status, view)
 }
 
 func (s *Server) handleCreateLocation(w http.ResponseWriter, r *http.Request) {
@@ -44,48 +46,103 @@ func (s *Server) handleCreateLocation(w http.ResponseWriter, r *http.Request) {
 	}
 
 	value := strings.TrimSpace(r.FormValue("value"))
+	parent := strings.TrimSpace(r.FormValue("parent"))
+	aliases := strings.TrimSpace(r.FormValue("aliases"))
+	rawLat, rawLon := r.FormValue("latitude"), r.FormValue("longitude")
+	draft := LocationsPanelState{
+		Mode:         "new",
+		DraftValue:   value,
+		DraftParent:  parent,
+		DraftAliases: aliases,
+		DraftLat:     rawLat,
+		DraftLon:     rawLon,
+	}
 	if value == "" {
-		s.renderLocationsError(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, LocationsPanelState{
-			Mode:       "new",
-			EditIndex:  -1,
-			DraftValue: value,
-			FormError:  "location cannot be empty",
-		})
+		draft.FormError = "location cannot be empty"
+		s.renderLocationsError(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, draft)
 		return
 	}
 
-	locations, err := s.getCampaignLocations(campaignID)
+	latitude, longitude, err := parseCoordinates(rawLat, rawLon)
 	if err != nil {
-		s.renderLocationsError(w, r, ctx.IsHTMX, statusFromError(err), campaignID, LocationsPanelState{
-			Mode:       "new",
-			EditIndex:  -1,
-			DraftValue: value,
-			FormError:  err.Error(),
-		})
+		draft.FormError = err.Error()
+		s.renderLocationsError(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, draft)
 		return
 	}
 
-	locations = append(locations, service.LocationOption{Value: value})
-	if err := s.setCampaignLocations(campaignID, locations); err != nil {
-		s.renderLocationsError(w, r, ctx.IsHTMX, statusFromError(err), campaignID, LocationsPanelState{
-			Mode:       "new",
-			EditIndex:  -1,
-			DraftValue: value,
-			FormError:  err.Error(),
+	if err := s.createLocation(r.Context(), campaignID, service.LocationOption{
+		Value:           value,
+		Parent:          parent,
+		CountryCode:     strings.TrimSpace(r.FormValue("country_code")),
+		SubdivisionCode: strings.TrimSpace(r.FormValue("subdivision_code")),
+		Aliases:         parseAliases(aliases),
+		Latitude:        latitude,
+		Longitude:       longitude,
+	}); err != nil {
+		draft.FormError = err.Error()
+		s.renderLocationsError(w, r, ctx.IsHTMX, statusFromError(err), campaignID, draft)
+		return
+	}
+
+	s.renderLocationsSuccess(w, r, ctx.IsHTMX, campaignID)
+}
+
+func (s *Server) handleUpdateLocation(w http.ResponseWriter, r *http.Request) {
+	ctx := requestContext(r)
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	locationID, err := parsePathID(r, "location_id")
+	if err != nil {
+		s.renderLocationsError(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, LocationsPanelState{
+			FormError: "invalid location id",
 		})
 		return
 	}
 
-	if ctx.IsHTMX {
-		panel, status := s.loadLocationsPanel(campaignID, LocationsPanelState{EditIndex: -1})
-		s.renderer.RenderLocationsPanel(w, status, panel)
+	value := strings.TrimSpace(r.FormValue("value"))
+	aliases := strings.TrimSpace(r.FormValue("aliases"))
+	rawLat, rawLon := r.FormValue("latitude"), r.FormValue("longitude")
+	draft := LocationsPanelState{
+		Mode:         "edit",
+		EditID:       locationID,
+		DraftValue:   value,
+		DraftAliases: aliases,
+		DraftLat:     rawLat,
+		DraftLon:     rawLon,
+	}
+	if value == "" {
+		draft.FormError = "location cannot be empty"
+		s.renderLocationsError(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, draft)
 		return
 	}
 
-	http.Redirect(w, r, campaignLocationsPath(campaignID), http.StatusSeeOther)
+	latitude, longitude, err := parseCoordinates(rawLat, rawLon)
+	if err != nil {
+		draft.FormError = err.Error()
+		s.renderLocationsError(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, draft)
+		return
+	}
+
+	if err := s.updateLocation(r.Context(), campaignID, locationID, service.UpdateLocationRequest{
+		Value:            &value,
+		Aliases:          parseAliases(aliases),
+		Latitude:         latitude,
+		Longitude:        longitude,
+		ClearCoordinates: latitude == nil,
+	}); err != nil {
+		draft.FormError = err.Error()
+		s.renderLocationsError(w, r, ctx.IsHTMX, statusFromError(err), campaignID, draft)
+		return
+	}
+
+	s.renderLocationsSuccess(w, r, ctx.IsHTMX, campaignID)
 }
 
-func (s *Server) handleUpdateLocation(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleDeleteLocation(w http.ResponseWriter, r *http.Request) {
 	ctx := requestContext(r)
 	campaignID := campaignIDFromPath(r)
 	if campaignID == "" {
@@ -93,66 +150,122 @@ func (s *Server) handleUpdateLocation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	index, err := parsePathIndex(r, "index")
+	locationID, err := parsePathID(r, "location_id")
 	if err != nil {
 		s.renderLocationsError(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, LocationsPanelState{
-			EditIndex: -1,
-			FormError: "invalid location index",
+			FormError: "invalid location id",
 		})
 		return
 	}
 
-	value := strings.TrimSpace(r.FormValue("value"))
-	if value == "" {
+	if err := s.deleteLocation(r.Context(), campaignID, locationID); err != nil {
+		s.renderLocationsError(w, r, ctx.IsHTMX, statusFromError(err), campaignID, LocationsPanelState{
+			FormError: err.Error(),
+		})
+		return
+	}
+
+	s.renderLocationsSuccess(w, r, ctx.IsHTMX, campaignID)
+}
+
+// handleMoveLocation shifts a location one place up or down among its
+// siblings.
+func (s *Server) handleMoveLocation(w http.ResponseWriter, r *http.Request) {
+	ctx := requestContext(r)
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	locationID, err := parsePathID(r, "location_id")
+	if err != nil {
 		s.renderLocationsError(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, LocationsPanelState{
-			Mode:       "edit",
-			EditIndex:  index,
-			DraftValue: value,
-			FormError:  "location cannot be empty",
+			FormError: "invalid location id",
 		})
 		return
 	}
 
-	locations, err := s.getCampaignLocations(campaignID)
+	locations, err := s.getCampaignLocations(r.Context(), campaignID)
 	if err != nil {
 		s.renderLocationsError(w, r, ctx.IsHTMX, statusFromError(err), campaignID, LocationsPanelState{
-			Mode:       "edit",
-			EditIndex:  index,
-			DraftValue: value,
-			FormError:  err.Error(),
+			FormError: err.Error(),
 		})
 		return
 	}
 
-	if index < 0 || index >= len(locations) {
-		s.renderLocationsError(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, LocationsPanelState{
-			EditIndex: -1,
+	parentID, index, found := siblingPosition(locations, locationID)
+	if !found {
+		s.renderLocationsError(w, r, ctx.IsHTMX, http.StatusNotFound, campaignID, LocationsPanelState{
 			FormError: "location not found",
 		})
 		return
 	}
 
-	locations[index].Value = value
-	if err := s.setCampaignLocations(campaignID, locations); err != nil {
+	// positions are 1-based; index is 0-based
+	position := index
+	if r.FormValue("direction") == "down" {
+		position = index + 2
+	}
+	if position < 1 {
+		position = 1
+	}
+
+	if err := s.moveLocation(r.Context(), campaignID, locationID, service.MoveLocationRequest{
+		ParentID: parentID,
+		Position: position,
+	}); err != nil {
 		s.renderLocationsError(w, r, ctx.IsHTMX, statusFromError(err), campaignID, LocationsPanelState{
-			Mode:       "edit",
-			EditIndex:  index,
-			DraftValue: value,
-			FormError:  err.Error(),
+			FormError: err.Error(),
 		})
 		return
 	}
 
-	if ctx.IsHTMX {
-		panel, status := s.loadLocationsPanel(campaignID, LocationsPanelState{EditIndex: -1})
-		s.renderer.RenderLocationsPanel(w, status, panel)
+	s.renderLocationsSuccess(w, r, ctx.IsHTMX, campaignID)
+}
+
+func (s *Server) handleMergeLocation(w http.ResponseWriter, r *http.Request) {
+	ctx := requestContext(r)
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		http.NotFound(w, r)
 		return
 	}
 
-	http.Redirect(w, r, campaignLocationsPath(campaignID), http.StatusSeeOther)
+	locationID, err := parsePathID(r, "location_id")
+	if err != nil {
+		s.renderLocationsError(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, LocationsPanelState{
+			FormError: "invalid location id",
+		})
+		return
+	}
+
+	intoID, err := strconv.ParseInt(strings.TrimSpace(r.FormValue("into_id")), 10, 64)
+	if err != nil || intoID <= 0 {
+		s.renderLocationsError(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, LocationsPanelState{
+			Mode:      "edit",
+			EditID:    locationID,
+			FormError: "choose a location to merge into",
+		})
+		return
+	}
+
+	if err := s.mergeLocation(r.Context(), campaignID, locationID, service.MergeLocationRequest{IntoID: intoID}); err != nil {
+		s.renderLocationsError(w, r, ctx.IsHTMX, statusFromError(err), campaignID, LocationsPanelState{
+			Mode:      "edit",
+			EditID:    locationID,
+			FormError: err.Error(),
+		})
+		return
+	}
+
+	s.renderLocationsSuccess(w, r, ctx.IsHTMX, campaignID)
 }
 
-func (s *Server) handleDeleteLocation(w http.ResponseWriter, r *http.Request) {
+// handleNormalizeLocations applies the checked rows of the normalize form.
+// Each row posts a value and a location_id; "apply" lists the checked row
+// indexes.
+func (s *Server) handleNormalizeLocations(w http.ResponseWriter, r *http.Request) {
 	ctx := requestContext(r)
 	campaignID := campaignIDFromPath(r)
 	if campaignID == "" {
@@ -160,51 +273,97 @@ func (s *Server) handleDeleteLocation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	index, err := parsePathIndex(r, "index")
-	if err != nil {
+	if err := r.ParseForm(); err != nil {
 		s.renderLocationsError(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, LocationsPanelState{
-			EditIndex: -1,
-			FormError: "invalid location index",
+			Mode:      "normalize",
+			FormError: "invalid form",
 		})
 		return
 	}
 
-	locations, err := s.getCampaignLocations(campaignID)
-	if err != nil {
+	values := r.PostForm["value"]
+	locationIDs := r.PostForm["location_id"]
+	var req service.NormalizeLocationsRequest
+	for _, raw := range r.PostForm["apply"] {
+		idx, err := strconv.Atoi(raw)
+		if err != nil || idx < 0 || idx >= len(values) || idx >= len(locationIDs) {
+			continue
+		}
+		locationID, err := strconv.ParseInt(locationIDs[idx], 10, 64)
+		if err != nil || locationID <= 0 {
+			continue
+		}
+		req.Mappings = append(req.Mappings, service.LocationMapping{
+			Value:      values[idx],
+			LocationID: locationID,
+		})
+	}
+
+	if len(req.Mappings) == 0 {
+		s.renderLocationsError(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, LocationsPanelState{
+			Mode:      "normalize",
+			FormError: "select at least one location with a preset to apply",
+		})
+		return
+	}
+
+	if err := s.normalizeLocations(r.Context(), campaignID, req); err != nil {
 		s.renderLocationsError(w, r, ctx.IsHTMX, statusFromError(err), campaignID, LocationsPanelState{
-			EditIndex: -1,
+			Mode:      "normalize",
 			FormError: err.Error(),
 		})
 		return
 	}
 
-	if index < 0 || index >= len(locations) {
+	s.renderLocationsSuccess(w, r, ctx.IsHTMX, campaignID)
+}
+
+// handleImportLocationCoordinates loads an uploaded gazetteer CSV and
+// reports how many presets it placed.
+func (s *Server) handleImportLocationCoordinates(w http.ResponseWriter, r *http.Request) {
+	ctx := requestContext(r)
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	file, _, err := r.FormFile("gazetteer")
+	if err != nil {
 		s.renderLocationsError(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, LocationsPanelState{
-			EditIndex: -1,
-			FormError: "location not found",
+			FormError: "choose a gazetteer csv file",
 		})
 		return
 	}
+	defer file.Close()
 
-	updated := make([]service.LocationOption, 0, len(locations)-1)
-	updated = append(updated, locations[:index]...)
-	updated = append(updated, locations[index+1:]...)
+	coordinates, err := service.ParseGazetteerCSV(file)
+	if err != nil {
+		s.renderLocationsError(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, LocationsPanelState{
+			FormError: err.Error(),
+		})
+		return
+	}
 
-	if err := s.setCampaignLocations(campaignID, updated); err != nil {
+	result, err := s.importLocationCoordinates(r.Context(), campaignID, service.ImportCoordinatesRequest{Coordinates: coordinates})
+	if err != nil {
 		s.renderLocationsError(w, r, ctx.IsHTMX, statusFromError(err), campaignID, LocationsPanelState{
-			EditIndex: -1,
 			FormError: err.Error(),
 		})
 		return
 	}
 
-	if ctx.IsHTMX {
-		panel, status := s.loadLocationsPanel(campaignID, LocationsPanelState{EditIndex: -1})
-		s.renderer.RenderLocationsPanel(w, status, panel)
+	if !ctx.IsHTMX {
+		http.Redirect(w, r, campaignLocationsPath(campaignID), http.StatusSeeOther)
 		return
 	}
 
-	http.Redirect(w, r, campaignLocationsPath(campaignID), http.StatusSeeOther)
+	notice := fmt.Sprintf("Coordinates set for %d locations.", result.Updated)
+	if len(result.Unmatched) > 0 {
+		notice += " No preset matched: " + strings.Join(result.Unmatched, ", ") + "."
+	}
+	panel, status := s.loadLocationsPanel(r.Context(), campaignID, LocationsPanelState{Notice: notice})
+	s.renderer.RenderLocationsPanel(w, r, status, panel)
 }
 
 func (s *Server) handleUpdateLocationsSettings(w http.ResponseWriter, r *http.Request) {
@@ -216,27 +375,72 @@ func (s *Server) handleUpdateLocationsSettings(w http.ResponseWriter, r *http.Re
 	}
 
 	allowCustomText := r.FormValue("allow_custom_text") == "on"
+	locationLevel := parseNonNegative(r.FormValue("location_level"))
 
-	campaign, err := s.getCampaign(campaignID)
+	// the rule is a partial update the API applies to the latest campaign,
+	// so it sends no revision and cannot clobber the details form
+	campaign, err := s.updateCampaign(r.Context(), campaignID, service.UpdateCampaignRequest{
+		AllowCustomText: &allowCustomText,
+		LocationLevel:   &locationLevel,
+	})
 	if err != nil {
 		s.renderLocationsError(w, r, ctx.IsHTMX, statusFromError(err), campaignID, LocationsPanelState{
-			EditIndex: -1,
 			FormError: err.Error(),
 		})
 		return
 	}
 
-	if err := s.updateCampaign(campaignID, campaign.Name, allowCustomText); err != nil {
-		s.renderLocationsError(w, r, ctx.IsHTMX, statusFromError(err), campaignID, LocationsPanelState{
-			EditIndex: -1,
-			FormError: err.Error(),
-		})
+	if !ctx.IsHTMX {
+		http.Redirect(w, r, campaignLocationsPath(campaignID), http.StatusSeeOther)
 		return
 	}
 
-	if ctx.IsHTMX {
-		panel, status := s.loadLocationsPanel(campaignID, LocationsPanelState{EditIndex: -1})
-		s.renderer.RenderLocationsPanel(w, status, panel)
+	// the details form on the same page holds the campaign revision, which
+	// this update just advanced
+	panel, status := s.loadLocationsPanel(r.Context(), campaignID, LocationsPanelState{})
+	s.renderer.RenderLocationsSettings(w, r, status, LocationsSettingsView{
+		Panel:            panel,
+		CampaignRevision: campaign.Revision,
+	})
+}
+
+// siblingPosition finds a location's parent and its index among siblings.
+func siblingPosition(locations []service.LocationOption, id int64) (int64, int, bool) {
+	var parentID int64
+	found := false
+	for _, loc := range locations {
+		if loc.ID == id {
+			parentID = loc.ParentID
+			found = true
+			break
+		}
+	}
+	if !found {
+		return 0, 0, false
+	}
+
+	index := 0
+	for _, loc := range locations {
+		if loc.ParentID != parentID {
+			continue
+		}
+		if loc.ID == id {
+			return parentID, index, true
+		}
+		index++
+	}
+	return 0, 0, false
+}
+
+func (s *Server) renderLocationsSuccess(
+	w http.ResponseWriter,
+	r *http.Request,
+	isHTMX bool,
+	campaignID string,
+) {
+	if isHTMX {
+		panel, status := s.loadLocationsPanel(r.Context(), campaignID, LocationsPanelState{})
+		s.renderer.RenderLocationsPanel(w, r, status, panel)
 		return
 	}
 
@@ -252,11 +456,11 @@ func (s *Server) renderLocationsError(
 	state LocationsPanelState,
 ) {
 	if isHTMX {
-		panel, status := s.loadLocationsPanel(campaignID, state)
+		panel, status := s.loadLocationsPanel(r.Context(), campaignID, state)
 		if status == http.StatusOK {
 			status = statusCode
 		}
-		s.renderer.RenderLocationsPanel(w, status, panel)
+		s.renderer.RenderLocationsPanel(w, r, status, panel)
 		return
 	}
 
@@ -267,10 +471,10 @@ func (s *Server) renderLocationsError(
 		},
 	}
 
-	view, status := s.loadCampaignDetailPage(campaignID, detailState)
+	view, status := s.loadCampaignDetailPage(r.Context(), campaignID, detailState)
 	if status == http.StatusOK {
 		status = statusCode
 	}
 
-	s.renderer.RenderCampaignDetailPage(w, status, view)
+	s.renderer.RenderCampaignDetailPage(w, r, status, view)
 }
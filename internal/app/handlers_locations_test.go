@@ -0,0 +1,146 @@
+package app
+
+import (
+	"bytes"
+	"cosign/internal/service"
+	"cosign/pkg/cosignclient"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+func TestHandleNormalizeLocationsSendsCheckedMappings(t *testing.T) {
+	var received service.NormalizeLocationsRequest
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/admin/campaigns/cmp-1/locations/normalize":
+			if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+				wire.WriteError(w, http.StatusBadRequest, "invalid request body")
+				return
+			}
+			wire.WriteData(w, http.StatusOK, service.NormalizeLocationsResponse{Rewritten: 3})
+		default:
+			wire.WriteError(w, http.StatusNotFound, "not found")
+		}
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, cosignclient.New(cosignclient.Options{BaseURL: backend.URL}))
+
+	form := url.Values{
+		"value":       {"nyc", "bostn", "atlantis"},
+		"location_id": {"4", "5", ""},
+		"apply":       {"1", "2"},
+	}
+
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/campaigns/cmp-1/locations/normalize",
+		strings.NewReader(form.Encode()),
+	)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res := httptest.NewRecorder()
+	server.BuildRouter().ServeHTTP(res, signedIn(req))
+
+	if res.Code != http.StatusSeeOther {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusSeeOther, res.Code, res.Body.String())
+	}
+	if len(received.Mappings) != 1 {
+		t.Fatalf("expected only the checked row with a preset, got %+v", received.Mappings)
+	}
+	if got := received.Mappings[0]; got.Value != "bostn" || got.LocationID != 5 {
+		t.Fatalf("unexpected mapping: %+v", got)
+	}
+}
+
+func TestHandleLocationsNormalizeModeRendersSuggestions(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/admin/campaigns/cmp-1":
+			wire.WriteData(w, http.StatusOK, service.Campaign{ID: "cmp-1", AllowCustomText: true})
+		case "/admin/campaigns/cmp-1/locations":
+			wire.WriteData(w, http.StatusOK, service.CampaignLocationsResponse{
+				Locations: []service.LocationOption{{ID: 7, Value: "Boston"}},
+			})
+		case "/admin/campaigns/cmp-1/locations/suggestions":
+			wire.WriteData(w, http.StatusOK, service.LocationSuggestionsResponse{
+				Suggestions: []service.LocationSuggestion{{Value: "bostn", Count: 2, LocationID: 7, Suggestion: "Boston", Distance: 1}},
+			})
+		default:
+			wire.WriteError(w, http.StatusNotFound, "not found")
+		}
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, cosignclient.New(cosignclient.Options{BaseURL: backend.URL}))
+
+	req := httptest.NewRequest(http.MethodGet, "/campaigns/cmp-1/locations?mode=normalize", nil)
+	req.Header.Set("HX-Request", "true")
+
+	res := httptest.NewRecorder()
+	server.BuildRouter().ServeHTTP(res, signedIn(req))
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, res.Code, res.Body.String())
+	}
+	body := res.Body.String()
+	if !strings.Contains(body, `name="value" value="bostn"`) || !strings.Contains(body, `<option value="7" selected>Boston</option>`) {
+		t.Fatalf("expected suggestion row with preselected preset, got body: %q", body)
+	}
+}
+
+func TestHandleImportLocationCoordinatesParsesUpload(t *testing.T) {
+	var received service.ImportCoordinatesRequest
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/admin/campaigns/cmp-1/locations/coordinates":
+			if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+				wire.WriteError(w, http.StatusBadRequest, "invalid request body")
+				return
+			}
+			wire.WriteData(w, http.StatusOK, service.ImportCoordinatesResponse{Updated: 1, Unmatched: []string{"Springfield"}})
+		case "/admin/campaigns/cmp-1":
+			wire.WriteData(w, http.StatusOK, service.Campaign{ID: "cmp-1"})
+		case "/admin/campaigns/cmp-1/locations":
+			wire.WriteData(w, http.StatusOK, service.CampaignLocationsResponse{})
+		default:
+			wire.WriteError(w, http.StatusNotFound, "not found")
+		}
+	}))
+	defer backend.Close()
+
+	server := newTestServer(t, cosignclient.New(cosignclient.Options{BaseURL: backend.URL}))
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("gazetteer", "places.csv")
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	part.Write([]byte("name,lat,lng\nBoston,42.3601,-71.0589\nSpringfield,39.78,-89.65\n"))
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/campaigns/cmp-1/locations/coordinates", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("HX-Request", "true")
+
+	res := httptest.NewRecorder()
+	server.BuildRouter().ServeHTTP(res, signedIn(req))
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, res.Code, res.Body.String())
+	}
+	if len(received.Coordinates) != 2 || received.Coordinates[0].Value != "Boston" || received.Coordinates[0].Longitude != -71.0589 {
+		t.Fatalf("unexpected coordinates sent: %+v", received.Coordinates)
+	}
+	if !strings.Contains(res.Body.String(), "No preset matched: Springfield.") {
+		t.Fatalf("expected unmatched notice, got body: %q", res.Body.String())
+	}
+}
@@ -0,0 +1,33 @@
+package app
+
+import "net/http"
+
+func (s *Server) handleOverview(w http.ResponseWriter, r *http.Request) {
+	page := parsePageQuery(r, "page")
+	view := s.loadOverviewPanel(r.Context(), page)
+
+	if requestContext(r).IsHTMX {
+		s.renderer.RenderOverviewPanel(w, r, http.StatusOK, view)
+		return
+	}
+
+	s.renderer.RenderOverviewPage(w, r, http.StatusOK, OverviewPageView{Overview: view})
+}
+
+func (s *Server) handleActivity(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	state := ActivityPanelState{Days: parseActivityDays(r, "days")}
+	if requestContext(r).IsHTMX {
+		view := s.loadActivityPanel(r.Context(), campaignID, state)
+		s.renderer.RenderActivityPanel(w, r, http.StatusOK, view)
+		return
+	}
+
+	view, status := s.loadCampaignDetailPage(r.Context(), campaignID, CampaignDetailPageState{Activity: state})
+	s.renderer.RenderCampaignDetailPage(w, r, status, view)
+}
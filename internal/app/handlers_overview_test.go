@@ -0,0 +1,133 @@
+package app
+
+import (
+	"cosign/internal/service"
+	"cosign/pkg/cosignclient"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+func newStatsBackend(t *testing.T, days *[]int) *cosignclient.Client {
+	t.Helper()
+	campaigns := []*service.Campaign{
+		{ID: "cmp-1", Name: "Parks"},
+		{ID: "cmp-2", Name: "Libraries"},
+	}
+	backend := newFakeBackend(t)
+	backend.respond("/admin/campaigns", http.StatusOK, service.Campaigns{Campaigns: campaigns, Total: len(campaigns), Limit: 10})
+	backend.respond("/admin/campaigns/cmp-1", http.StatusOK, campaigns[0])
+	backend.respond("/admin/campaigns/cmp-2", http.StatusOK, campaigns[1])
+	backend.handle("/admin/campaigns/cmp-1/signatures/stats", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.URL.Query().Get("days"))
+		*days = append(*days, n)
+		stats := service.SignatureStats{
+			Total:   12,
+			Last24h: 3,
+			Last7d:  9,
+			TopLocations: []service.LocationCount{
+				{Location: "Brooklyn", Count: 9},
+				{Location: "Queens", Count: 3},
+			},
+		}
+		for idx := range n {
+			stats.Days = append(stats.Days, service.DailySignatureCount{Date: "2026-10-" + strconv.Itoa(10+idx%20), Count: idx % 3})
+		}
+		wire.WriteData(w, http.StatusOK, stats)
+	})
+	backend.handle("/admin/campaigns/cmp-2/signatures/stats", func(w http.ResponseWriter, r *http.Request) {
+		wire.WriteError(w, http.StatusInternalServerError, "stats unavailable")
+	})
+	backend.respond("/admin/campaigns/{id}/locations", http.StatusOK, service.CampaignLocationsResponse{})
+	backend.respond("/admin/campaigns/{id}/signatures", http.StatusOK, service.Signatures{Signatures: []*service.Signature{}, Limit: 10})
+	return backend.client()
+}
+
+func TestOverviewListsCampaignCountsAndSparklines(t *testing.T) {
+	var days []int
+	server, _ := newTestServerWithRoles(t, newStatsBackend(t, &days), time.Now,
+		RoleAssignment{CampaignID: "cmp-1", Role: RoleViewer},
+	)
+
+	res := httptest.NewRecorder()
+	server.BuildRouter().ServeHTTP(res, signedIn(httptest.NewRequest(http.MethodGet, "/overview", nil)))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected overview page, got %d: %s", res.Code, res.Body.String())
+	}
+
+	body := res.Body.String()
+	for _, want := range []string{
+		`href="/campaigns/cmp-1">Parks</a>`,
+		`<td class="numeric">12</td>`,
+		`<td class="numeric">3</td>`,
+		`<td class="numeric">9</td>`,
+		`<polyline points="`,
+		`href="/overview"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q on the overview, got %s", want, body)
+		}
+	}
+	if strings.Contains(body, "Libraries") {
+		t.Fatalf("expected only campaigns the user holds a role on")
+	}
+	if len(days) != 1 || days[0] != overviewDays {
+		t.Fatalf("expected stats for %d days, got %v", overviewDays, days)
+	}
+}
+
+func TestOverviewShowsStatsErrorsPerRow(t *testing.T) {
+	var days []int
+	server := newTestServer(t, newStatsBackend(t, &days))
+
+	req := httptest.NewRequest(http.MethodGet, "/overview", nil)
+	req.Header.Set("HX-Request", "true")
+	res := httptest.NewRecorder()
+	server.BuildRouter().ServeHTTP(res, signedIn(req))
+
+	body := res.Body.String()
+	if !strings.HasPrefix(strings.TrimSpace(body), `<section id="overview-panel"`) {
+		t.Fatalf("expected only the panel for htmx, got %s", body)
+	}
+	if !strings.Contains(body, "Parks") || !strings.Contains(body, `<td colspan="4" class="error">stats unavailable</td>`) {
+		t.Fatalf("expected both rows, one with its error, got %s", body)
+	}
+}
+
+func TestActivityPanelChartsChosenRange(t *testing.T) {
+	var days []int
+	server := newTestServer(t, newStatsBackend(t, &days))
+	handler := server.BuildRouter()
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, signedIn(httptest.NewRequest(http.MethodGet, "/campaigns/cmp-1", nil)))
+	body := res.Body.String()
+	if !strings.Contains(body, `id="activity-panel"`) || strings.Count(body, `class="chart-bar"`) != defaultActivityDays {
+		t.Fatalf("expected a %d day chart on the detail page, got %s", defaultActivityDays, body)
+	}
+	if !strings.Contains(body, "Brooklyn") || !strings.Contains(body, "width: 75%") {
+		t.Fatalf("expected the top locations with their share, got %s", body)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/campaigns/cmp-1/activity?days=7", nil)
+	req.Header.Set("HX-Request", "true")
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, signedIn(req))
+	body = res.Body.String()
+	if strings.Count(body, `class="chart-bar"`) != 7 || !strings.Contains(body, `aria-current="true">7 days`) {
+		t.Fatalf("expected a 7 day chart, got %s", body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/campaigns/cmp-1/activity?days=1000", nil)
+	req.Header.Set("HX-Request", "true")
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, signedIn(req))
+	if days[len(days)-1] != defaultActivityDays {
+		t.Fatalf("expected an unknown range to fall back to %d days, got %v", defaultActivityDays, days)
+	}
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+)
+
+func (s *Server) handleSettingsPage(w http.ResponseWriter, r *http.Request) {
+	view := s.loadSettingsPage(r.Context(), APIKeysPanelState{}, CORSPanelState{})
+	s.renderer.RenderSettingsPage(w, r, http.StatusOK, view)
+}
+
+func (s *Server) handleAPIKeys(w http.ResponseWriter, r *http.Request) {
+	if !requestContext(r).IsHTMX {
+		s.handleSettingsPage(w, r)
+		return
+	}
+
+	view := s.loadAPIKeysPanel(r.Context(), APIKeysPanelState{})
+	s.renderer.RenderAPIKeysPanel(w, r, http.StatusOK, view)
+}
+
+// handleCreateAPIKey renders the new token in place rather than
+// redirecting, since the API never returns it again.
+func (s *Server) handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	token, err := s.createAPIKey(r.Context())
+	if err != nil {
+		s.renderAPIKeys(w, r, statusFromError(err), APIKeysPanelState{FormError: err.Error()})
+		return
+	}
+
+	s.renderAPIKeys(w, r, http.StatusCreated, APIKeysPanelState{NewToken: token})
+}
+
+func (s *Server) handleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := requestContext(r)
+
+	keyID := strings.TrimSpace(r.PathValue("key_id"))
+	if keyID == "" {
+		s.renderAPIKeys(w, r, http.StatusBadRequest, APIKeysPanelState{FormError: "api key id required"})
+		return
+	}
+	if keyID == s.client.APIKeyID() {
+		s.renderAPIKeys(w, r, http.StatusBadRequest, APIKeysPanelState{FormError: "the dashboard uses this key, so it cannot be revoked here"})
+		return
+	}
+
+	if err := s.revokeAPIKey(r.Context(), keyID); err != nil {
+		s.renderAPIKeys(w, r, statusFromError(err), APIKeysPanelState{FormError: err.Error()})
+		return
+	}
+
+	if ctx.IsHTMX {
+		view := s.loadAPIKeysPanel(r.Context(), APIKeysPanelState{})
+		s.renderer.RenderAPIKeysPanel(w, r, http.StatusOK, view)
+		return
+	}
+
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
+
+func (s *Server) handleCORS(w http.ResponseWriter, r *http.Request) {
+	if !requestContext(r).IsHTMX {
+		s.handleSettingsPage(w, r)
+		return
+	}
+
+	view := s.loadCORSPanel(r.Context(), CORSPanelState{})
+	s.renderer.RenderCORSPanel(w, r, http.StatusOK, view)
+}
+
+// handleUpdateCORS replaces the allowlist with the submitted origins, one
+// per line.
+func (s *Server) handleUpdateCORS(w http.ResponseWriter, r *http.Request) {
+	ctx := requestContext(r)
+	text := r.FormValue("origins")
+
+	if err := s.setCORSOrigins(r.Context(), strings.Fields(text)); err != nil {
+		s.renderCORS(w, r, statusFromError(err), CORSPanelState{Origins: text, FormError: err.Error()})
+		return
+	}
+
+	if ctx.IsHTMX {
+		view := s.loadCORSPanel(r.Context(), CORSPanelState{Saved: true})
+		s.renderer.RenderCORSPanel(w, r, http.StatusOK, view)
+		return
+	}
+
+	http.Redirect(w, r, "/settings", http.StatusSeeOther)
+}
+
+// renderAPIKeys answers htmx with the panel and a 200, so htmx swaps it in
+// even when it carries an error, and full page requests with statusCode.
+func (s *Server) renderAPIKeys(w http.ResponseWriter, r *http.Request, statusCode int, state APIKeysPanelState) {
+	if requestContext(r).IsHTMX {
+		view := s.loadAPIKeysPanel(r.Context(), state)
+		s.renderer.RenderAPIKeysPanel(w, r, http.StatusOK, view)
+		return
+	}
+
+	view := s.loadSettingsPage(r.Context(), state, CORSPanelState{})
+	s.renderer.RenderSettingsPage(w, r, statusCode, view)
+}
+
+func (s *Server) renderCORS(w http.ResponseWriter, r *http.Request, statusCode int, state CORSPanelState) {
+	if requestContext(r).IsHTMX {
+		view := s.loadCORSPanel(r.Context(), state)
+		s.renderer.RenderCORSPanel(w, r, http.StatusOK, view)
+		return
+	}
+
+	view := s.loadSettingsPage(r.Context(), APIKeysPanelState{}, state)
+	s.renderer.RenderSettingsPage(w, r, statusCode, view)
+}
@@ -0,0 +1,210 @@
+package app
+
+import (
+	"cosign/internal/service"
+	"cosign/pkg/cosignclient"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+type settingsBackend struct {
+	keys    []string
+	origins []cosignclient.AllowedOrigin
+}
+
+// newSettingsBackend fakes the key and origin settings routes, rejecting
+// origins that are not http urls as the API would.
+func newSettingsBackend(t *testing.T, settings *settingsBackend) *fakeBackend {
+	t.Helper()
+	backend := newFakeBackend(t)
+	backend.handle("GET /settings/keys", func(w http.ResponseWriter, r *http.Request) {
+		keys := service.APIKeys{Keys: []*service.APIKey{}}
+		for _, id := range settings.keys {
+			keys.Keys = append(keys.Keys, &service.APIKey{ID: id, CreatedAt: 1700000000})
+		}
+		wire.WriteData(w, http.StatusOK, keys)
+	})
+	backend.handle("POST /settings/keys", func(w http.ResponseWriter, r *http.Request) {
+		settings.keys = append(settings.keys, "fresh")
+		wire.WriteData(w, http.StatusCreated, "fresh.secret-token")
+	})
+	backend.handle("DELETE /settings/keys/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		settings.keys = slices.DeleteFunc(settings.keys, func(key string) bool { return key == id })
+		w.WriteHeader(http.StatusNoContent)
+	})
+	backend.handle("GET /settings/cors", func(w http.ResponseWriter, r *http.Request) {
+		wire.WriteData(w, http.StatusOK, settings.origins)
+	})
+	backend.handle("PUT /settings/cors", func(w http.ResponseWriter, r *http.Request) {
+		var origins []cosignclient.AllowedOrigin
+		if err := json.NewDecoder(r.Body).Decode(&origins); err != nil {
+			wire.WriteError(w, http.StatusBadRequest, "Malformed JSON")
+			return
+		}
+		for _, origin := range origins {
+			if !strings.HasPrefix(origin.URL, "http") {
+				wire.WriteError(w, http.StatusBadRequest, "Invalid Origin URL")
+				return
+			}
+		}
+		settings.origins = origins
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return backend
+}
+
+func settingsRequest(method, target string, form url.Values) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	return signedIn(req)
+}
+
+func TestSettingsPageListsKeysAndOrigins(t *testing.T) {
+	settings := &settingsBackend{
+		keys:    []string{"dash", "ci"},
+		origins: []cosignclient.AllowedOrigin{{URL: "https://example.org"}},
+	}
+	backend := newSettingsBackend(t, settings)
+	server := newTestServer(t, backend.clientWith(cosignclient.Options{APIKey: "dash.secret"}))
+
+	res := httptest.NewRecorder()
+	server.BuildRouter().ServeHTTP(res, signedIn(httptest.NewRequest(http.MethodGet, "/settings", nil)))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected settings page, got %d: %s", res.Code, res.Body.String())
+	}
+
+	body := res.Body.String()
+	for _, want := range []string{
+		`id="api-keys-panel"`,
+		`id="cors-panel"`,
+		`action="/settings/keys/ci"`,
+		"Used by this dashboard",
+		"https://example.org",
+		`href="/settings"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q on settings page, got %s", want, body)
+		}
+	}
+	if strings.Contains(body, `action="/settings/keys/dash"`) {
+		t.Fatalf("expected the dashboard's own key not to be revocable")
+	}
+}
+
+func TestCreateAPIKeyShowsTokenOnce(t *testing.T) {
+	settings := &settingsBackend{}
+	backend := newSettingsBackend(t, settings)
+	server := newTestServer(t, backend.client())
+	handler := server.BuildRouter()
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, "/settings/keys", nil))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected panel, got %d", res.Code)
+	}
+	if !strings.Contains(res.Body.String(), "fresh.secret-token") || strings.Contains(res.Body.String(), `id="cors-panel"`) {
+		t.Fatalf("expected only the keys panel with the new token, got %s", res.Body.String())
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodGet, "/settings/keys", nil))
+	if strings.Contains(res.Body.String(), "fresh.secret-token") {
+		t.Fatalf("expected the token to be shown only once")
+	}
+	if !strings.Contains(res.Body.String(), `action="/settings/keys/fresh"`) {
+		t.Fatalf("expected the new key in the list, got %s", res.Body.String())
+	}
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	settings := &settingsBackend{keys: []string{"dash", "ci"}}
+	backend := newSettingsBackend(t, settings)
+	server := newTestServer(t, backend.clientWith(cosignclient.Options{APIKey: "dash.secret"}))
+	handler := server.BuildRouter()
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, "/settings/keys/ci", url.Values{"_method": {"DELETE"}}))
+	if res.Code != http.StatusOK || strings.Contains(res.Body.String(), `action="/settings/keys/ci"`) {
+		t.Fatalf("expected ci key revoked, got %d: %s", res.Code, res.Body.String())
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, "/settings/keys/dash", url.Values{"_method": {"DELETE"}}))
+	if !strings.Contains(res.Body.String(), "cannot be revoked here") {
+		t.Fatalf("expected the dashboard's own key to be refused, got %s", res.Body.String())
+	}
+	if !slices.Contains(settings.keys, "dash") {
+		t.Fatalf("expected the dashboard's own key to remain")
+	}
+}
+
+func TestUpdateCORSOrigins(t *testing.T) {
+	settings := &settingsBackend{origins: []cosignclient.AllowedOrigin{{URL: "https://old.example"}}}
+	backend := newSettingsBackend(t, settings)
+	server := newTestServer(t, backend.client())
+	handler := server.BuildRouter()
+
+	form := url.Values{"_method": {"PATCH"}, "origins": {"https://a.example\r\n\r\n  https://b.example  \n"}}
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, "/settings/cors", form))
+	if res.Code != http.StatusOK || !strings.Contains(res.Body.String(), "Saved.") {
+		t.Fatalf("expected saved cors panel, got %d: %s", res.Code, res.Body.String())
+	}
+	want := []cosignclient.AllowedOrigin{{URL: "https://a.example"}, {URL: "https://b.example"}}
+	if !slices.Equal(settings.origins, want) {
+		t.Fatalf("expected origins %v, got %v", want, settings.origins)
+	}
+
+	form = url.Values{"_method": {"PATCH"}, "origins": {"not-a-url"}}
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, "/settings/cors", form))
+	body := res.Body.String()
+	if !strings.Contains(body, "Invalid Origin URL") || !strings.Contains(body, ">not-a-url</textarea>") {
+		t.Fatalf("expected the error with the submitted origins kept, got %s", body)
+	}
+	if !slices.Equal(settings.origins, want) {
+		t.Fatalf("expected origins unchanged after a failed save, got %v", settings.origins)
+	}
+}
+
+func TestSettingsRequireGlobalOwner(t *testing.T) {
+	settings := &settingsBackend{keys: []string{"ci"}}
+	backend := newSettingsBackend(t, settings)
+	server, _ := newTestServerWithRoles(t, backend.client(), time.Now,
+		RoleAssignment{Role: RoleEditor},
+		RoleAssignment{CampaignID: "cmp-1", Role: RoleOwner},
+	)
+	handler := server.BuildRouter()
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, signedIn(httptest.NewRequest(http.MethodGet, "/campaigns/cmp-1/signatures", nil)))
+	if strings.Contains(res.Body.String(), `href="/settings"`) {
+		t.Fatalf("expected the settings link to be hidden")
+	}
+
+	for _, req := range []*http.Request{
+		signedIn(httptest.NewRequest(http.MethodGet, "/settings", nil)),
+		settingsRequest(http.MethodPost, "/settings/keys", nil),
+		settingsRequest(http.MethodPost, "/settings/keys/ci", url.Values{"_method": {"DELETE"}}),
+		settingsRequest(http.MethodPost, "/settings/cors", url.Values{"_method": {"PATCH"}}),
+	} {
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, req)
+		if res.Code != http.StatusForbidden {
+			t.Fatalf("%s %s: expected 403, got %d", req.Method, req.URL.Path, res.Code)
+		}
+	}
+	if len(settings.keys) != 1 {
+		t.Fatalf("expected keys unchanged, got %v", settings.keys)
+	}
+}
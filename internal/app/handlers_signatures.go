@@ -1,6 +1,8 @@
 package app
 
 import (
+	"cosign/internal/service"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -14,26 +16,29 @@ func (s *Server) handleSignatures(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	page := parsePageQuery(r, "page")
+	state := SignaturesPanelState{
+		Page:   parsePageQuery(r, "page"),
+		Filter: parseSignatureFilter(r),
+	}
 	if ctx.IsHTMX {
 		panel := NewSignaturesPanelView(
 			campaignID,
-			s.loadSignaturesTable(campaignID, page),
-			SignaturesPanelState{Page: page},
+			s.loadSignaturesTable(r.Context(), campaignID, state.Filter, state.Page),
+			state,
 		)
-		s.renderer.RenderSignaturesPanel(w, http.StatusOK, panel)
+		s.renderer.RenderSignaturesPanel(w, r, http.StatusOK, panel)
 		return
 	}
 
-	locMode, locIndex := parseLocationsMode(r, "mode", "index")
-	view, status := s.loadCampaignDetailPage(campaignID, CampaignDetailPageState{
+	locMode, locID := parseLocationsMode(r, "mode", "id")
+	view, status := s.loadCampaignDetailPage(r.Context(), campaignID, CampaignDetailPageState{
 		Locations: LocationsPanelState{
-			Mode:      locMode,
-			EditIndex: locIndex,
+			Mode:   locMode,
+			EditID: locID,
 		},
-		Signatures: SignaturesPanelState{Page: page},
+		Signatures: state,
 	})
-	s.renderer.RenderCampaignDetailPage(w, status, view)
+	s.renderer.RenderCampaignDetailPage(w, r, status, view)
 }
 
 func (s *Server) handleCreateSignature(w http.ResponseWriter, r *http.Request) {
@@ -58,23 +63,24 @@ func (s *Server) handleCreateSignature(w http.ResponseWriter, r *http.Request) {
 
 	if name == "" || email == "" || location == "" {
 		state.FormError = "name, email, and location are required"
-		s.renderSignaturesError(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, state)
+		s.renderSignatures(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, state)
 		return
 	}
 
-	if err := s.createSignature(campaignID, name, email, location); err != nil {
+	if err := s.createSignature(r.Context(), campaignID, name, email, location); err != nil {
 		state.FormError = err.Error()
-		s.renderSignaturesError(w, r, ctx.IsHTMX, statusFromError(err), campaignID, state)
+		state.FieldErrors = formFieldErrors(err)
+		s.renderSignatures(w, r, ctx.IsHTMX, statusFromError(err), campaignID, state)
 		return
 	}
 
 	if ctx.IsHTMX {
 		panel := NewSignaturesPanelView(
 			campaignID,
-			s.loadSignaturesTable(campaignID, 1),
+			s.loadSignaturesTable(r.Context(), campaignID, service.SignatureFilter{}, 1),
 			SignaturesPanelState{Page: 1},
 		)
-		s.renderer.RenderSignaturesPanel(w, http.StatusOK, panel)
+		s.renderer.RenderSignaturesPanel(w, r, http.StatusOK, panel)
 		return
 	}
 
@@ -91,37 +97,174 @@ func (s *Server) handleDeleteSignature(w http.ResponseWriter, r *http.Request) {
 
 	signatureID, err := strconv.ParseInt(strings.TrimSpace(r.PathValue("signature_id")), 10, 64)
 	if err != nil {
-		s.renderSignaturesError(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, SignaturesPanelState{
+		s.renderSignatures(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, SignaturesPanelState{
 			Page:      1,
 			FormError: "invalid signature id",
 		})
 		return
 	}
 
-	page := max(parsePageQuery(r, "page"), 1)
+	state := SignaturesPanelState{
+		Page:   max(parsePageQuery(r, "page"), 1),
+		Filter: parseSignatureFilter(r),
+	}
 
-	if err := s.deleteSignature(campaignID, signatureID); err != nil {
-		s.renderSignaturesError(w, r, ctx.IsHTMX, statusFromError(err), campaignID, SignaturesPanelState{
-			Page:      page,
-			FormError: err.Error(),
-		})
+	if err := s.deleteSignature(r.Context(), campaignID, signatureID); err != nil {
+		state.FormError = err.Error()
+		s.renderSignatures(w, r, ctx.IsHTMX, statusFromError(err), campaignID, state)
 		return
 	}
 
 	if ctx.IsHTMX {
 		panel := NewSignaturesPanelView(
 			campaignID,
-			s.loadSignaturesTable(campaignID, page),
-			SignaturesPanelState{Page: page},
+			s.loadSignaturesTable(r.Context(), campaignID, state.Filter, state.Page),
+			state,
 		)
-		s.renderer.RenderSignaturesPanel(w, http.StatusOK, panel)
+		s.renderer.RenderSignaturesPanel(w, r, http.StatusOK, panel)
 		return
 	}
 
-	http.Redirect(w, r, signaturesPagePath(campaignID, page), http.StatusSeeOther)
+	http.Redirect(w, r, signaturesListPath(campaignID, state.Filter, state.Page), http.StatusSeeOther)
 }
 
-func (s *Server) renderSignaturesError(
+// bulkActionRoles is the role each bulk action needs, and so the role it
+// takes to undo one; the routes only ask for a viewer, since viewers may
+// export.
+var bulkActionRoles = map[string]Role{
+	service.BulkActionHide:   RoleEditor,
+	service.BulkActionUnhide: RoleEditor,
+	service.BulkActionDelete: RoleOwner,
+	bulkActionExport:         RoleViewer,
+}
+
+const bulkActionExport = "export"
+
+// handleBulkSignatures applies an action to the checked signatures, or to
+// every signature matching the panel's filter when all_matching is set.
+func (s *Server) handleBulkSignatures(w http.ResponseWriter, r *http.Request) {
+	ctx := requestContext(r)
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	state := SignaturesPanelState{
+		Page:   max(parsePageQuery(r, "page"), 1),
+		Filter: parseSignatureFilter(r),
+	}
+
+	action := strings.TrimSpace(r.FormValue("action"))
+	role, ok := bulkActionRoles[action]
+	if !ok {
+		state.FormError = "choose a bulk action"
+		s.renderSignatures(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, state)
+		return
+	}
+	if !accessFromContext(r.Context()).Can(campaignID, role) {
+		s.renderForbidden(w, r, role)
+		return
+	}
+
+	target := service.SignatureFilter{IDs: parseSignatureIDs(r, "ids")}
+	if r.FormValue("all_matching") != "" {
+		target = state.Filter
+	} else if len(target.IDs) == 0 {
+		state.FormError = "select at least one signature"
+		s.renderSignatures(w, r, ctx.IsHTMX, http.StatusBadRequest, campaignID, state)
+		return
+	}
+
+	if action == bulkActionExport {
+		job, err := s.startExport(r, target)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		export := NewExportPanelView(job.snapshot())
+		if !ctx.IsHTMX {
+			http.Redirect(w, r, export.StatusPath, http.StatusSeeOther)
+			return
+		}
+		state.Export = &export
+		s.renderSignatures(w, r, ctx.IsHTMX, http.StatusOK, campaignID, state)
+		return
+	}
+
+	req := service.BulkSignaturesRequest{Action: action}
+	if target.IDs != nil {
+		req.IDs = target.IDs
+	} else {
+		req.Filter = &target
+	}
+	response, err := s.bulkSignatures(r.Context(), campaignID, req)
+	if err != nil {
+		state.FormError = err.Error()
+		s.renderSignatures(w, r, ctx.IsHTMX, statusFromError(err), campaignID, state)
+		return
+	}
+
+	state.Notice = bulkNotice(response.Action, response.Affected)
+	state.UndoID = response.OperationID
+	state.UndoUntil = response.UndoUntil
+	s.renderSignatures(w, r, ctx.IsHTMX, http.StatusOK, campaignID, state)
+}
+
+func (s *Server) handleUndoBulkSignatures(w http.ResponseWriter, r *http.Request) {
+	ctx := requestContext(r)
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	state := SignaturesPanelState{
+		Page:   max(parsePageQuery(r, "page"), 1),
+		Filter: parseSignatureFilter(r),
+	}
+
+	operationID := r.PathValue("operation_id")
+	op, err := s.getSignatureBulkOperation(r.Context(), campaignID, operationID)
+	if err != nil {
+		state.FormError = err.Error()
+		s.renderSignatures(w, r, ctx.IsHTMX, statusFromError(err), campaignID, state)
+		return
+	}
+	role, ok := bulkActionRoles[op.Action]
+	if !ok {
+		role = RoleOwner
+	}
+	if !accessFromContext(r.Context()).Can(campaignID, role) {
+		s.renderForbidden(w, r, role)
+		return
+	}
+
+	response, err := s.undoBulkSignatures(r.Context(), campaignID, operationID)
+	if err != nil {
+		state.FormError = err.Error()
+		s.renderSignatures(w, r, ctx.IsHTMX, statusFromError(err), campaignID, state)
+		return
+	}
+
+	state.Notice = "Undone: " + signatureCount(response.Restored) + " restored."
+	s.renderSignatures(w, r, ctx.IsHTMX, http.StatusOK, campaignID, state)
+}
+
+func bulkNotice(action string, affected int) string {
+	if affected == 0 {
+		return "No signatures matched."
+	}
+
+	format := map[string]string{
+		service.BulkActionDelete: "Moved %s to the trash.",
+		service.BulkActionHide:   "Hid %s.",
+		service.BulkActionUnhide: "Unhid %s.",
+	}[action]
+	return fmt.Sprintf(format, signatureCount(affected))
+}
+
+func (s *Server) renderSignatures(
 	w http.ResponseWriter,
 	r *http.Request,
 	isHTMX bool,
@@ -135,26 +278,33 @@ func (s *Server) renderSignaturesError(
 	if isHTMX {
 		panel := NewSignaturesPanelView(
 			campaignID,
-			s.loadSignaturesTable(campaignID, page),
+			s.loadSignaturesTable(r.Context(), campaignID, state.Filter, page),
 			state,
 		)
-		s.renderer.RenderSignaturesPanel(w, http.StatusOK, panel)
+		s.renderer.RenderSignaturesPanel(w, r, http.StatusOK, panel)
 		return
 	}
 
-	locMode, locIndex := parseLocationsMode(r, "mode", "index")
+	locMode, locID := parseLocationsMode(r, "mode", "id")
 	detailState := CampaignDetailPageState{
 		Locations: LocationsPanelState{
-			Mode:      locMode,
-			EditIndex: locIndex,
+			Mode:   locMode,
+			EditID: locID,
 		},
 		Signatures: state,
 	}
 
-	view, status := s.loadCampaignDetailPage(campaignID, detailState)
+	view, status := s.loadCampaignDetailPage(r.Context(), campaignID, detailState)
 	if status == http.StatusOK {
 		status = statusCode
 	}
 
-	s.renderer.RenderCampaignDetailPage(w, status, view)
+	s.renderer.RenderCampaignDetailPage(w, r, status, view)
+}
+
+func signatureCount(n int) string {
+	if n == 1 {
+		return "1 signature"
+	}
+	return itoa(n) + " signatures"
 }
@@ -2,11 +2,14 @@ package app
 
 import (
 	"cosign/internal/service"
+	"cosign/pkg/cosignclient"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"git.sr.ht/~jakintosh/command-go/pkg/wire"
 )
@@ -24,12 +27,7 @@ func TestHandleCreateSignatureHTMXErrorRendersPanel(t *testing.T) {
 	}))
 	defer backend.Close()
 
-	server, err := New(Options{
-		Client: wire.Client{BaseURL: backend.URL},
-	})
-	if err != nil {
-		t.Fatalf("new dashboard server: %v", err)
-	}
+	server := newTestServer(t, cosignclient.New(cosignclient.Options{BaseURL: backend.URL}))
 
 	form := url.Values{
 		"name":     {"Alice"},
@@ -46,7 +44,7 @@ func TestHandleCreateSignatureHTMXErrorRendersPanel(t *testing.T) {
 	req.Header.Set("HX-Request", "true")
 
 	res := httptest.NewRecorder()
-	server.BuildRouter().ServeHTTP(res, req)
+	server.BuildRouter().ServeHTTP(res, signedIn(req))
 
 	if res.Code != http.StatusOK {
 		t.Fatalf("expected status %d, got %d", http.StatusOK, res.Code)
@@ -60,3 +58,131 @@ func TestHandleCreateSignatureHTMXErrorRendersPanel(t *testing.T) {
 		t.Fatalf("expected error message in panel, got body: %q", body)
 	}
 }
+
+type bulkBackend struct {
+	bulk  *service.BulkSignaturesRequest
+	undo  string
+	lists []url.Values
+}
+
+// newBulkBackend fakes the listing, bulk and undo routes; every bulk
+// action affects two signatures. Operation op-1 hid them and op-2 deleted
+// them.
+func newBulkBackend(t *testing.T, bulk *bulkBackend) *cosignclient.Client {
+	t.Helper()
+	backend := newFakeBackend(t)
+	backend.handle("GET /admin/campaigns/cmp-1/signatures", func(w http.ResponseWriter, r *http.Request) {
+		bulk.lists = append(bulk.lists, r.URL.Query())
+		wire.WriteData(w, http.StatusOK, service.Signatures{
+			Signatures: []*service.Signature{
+				{ID: 1, Name: "Ada", Email: "ada@example.org", Location: "Brooklyn"},
+				{ID: 2, Name: "Bob", Email: "bob@example.org", Location: "Queens", Hidden: true},
+			},
+			Total: 2,
+			Limit: 10,
+		})
+	})
+	backend.handle("POST /admin/campaigns/cmp-1/signatures/bulk", func(w http.ResponseWriter, r *http.Request) {
+		bulk.bulk = &service.BulkSignaturesRequest{}
+		_ = json.NewDecoder(r.Body).Decode(bulk.bulk)
+		wire.WriteData(w, http.StatusOK, service.BulkSignaturesResponse{OperationID: "op-1", Action: bulk.bulk.Action, Affected: 2, UndoUntil: 1700000600})
+	})
+	backend.respond("GET /admin/campaigns/cmp-1/signatures/bulk/op-1", http.StatusOK,
+		service.SignatureBulkOperation{ID: "op-1", CampaignID: "cmp-1", Action: service.BulkActionHide})
+	backend.respond("GET /admin/campaigns/cmp-1/signatures/bulk/op-2", http.StatusOK,
+		service.SignatureBulkOperation{ID: "op-2", CampaignID: "cmp-1", Action: service.BulkActionDelete})
+	backend.handle("POST /admin/campaigns/cmp-1/signatures/bulk/{operation}/undo", func(w http.ResponseWriter, r *http.Request) {
+		bulk.undo = r.PathValue("operation")
+		wire.WriteData(w, http.StatusOK, service.BulkUndoResponse{OperationID: bulk.undo, Action: service.BulkActionHide, Restored: 2})
+	})
+	return backend.client()
+}
+
+func TestBulkHideSendsCheckedIDsAndOffersUndo(t *testing.T) {
+	bulk := &bulkBackend{}
+	server := newTestServer(t, newBulkBackend(t, bulk))
+	handler := server.BuildRouter()
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, "/campaigns/cmp-1/signatures/bulk", url.Values{
+		"action":     {"hide"},
+		"ids":        {"1", "2", "x"},
+		"q":          {"example"},
+		"visibility": {"visible"},
+	}))
+	body := res.Body.String()
+	if res.Code != http.StatusOK || !strings.Contains(body, "Hid 2 signatures.") {
+		t.Fatalf("expected a bulk notice, got %d: %s", res.Code, body)
+	}
+	if bulk.bulk == nil || bulk.bulk.Action != "hide" || len(bulk.bulk.IDs) != 2 || bulk.bulk.Filter != nil {
+		t.Fatalf("expected the checked ids sent, got %+v", bulk.bulk)
+	}
+	if !strings.Contains(body, `hx-post="/campaigns/cmp-1/signatures/bulk/op-1/undo"`) {
+		t.Fatalf("expected an undo form, got %s", body)
+	}
+	if !strings.Contains(body, `value="example"`) || !strings.Contains(body, "(hidden)") {
+		t.Fatalf("expected the filter kept and hidden rows marked, got %s", body)
+	}
+	last := bulk.lists[len(bulk.lists)-1]
+	if last.Get("q") != "example" || last.Get("visibility") != "visible" {
+		t.Fatalf("expected the panel reloaded with its filter, got %v", last)
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, "/campaigns/cmp-1/signatures/bulk/op-1/undo", url.Values{}))
+	if bulk.undo != "op-1" || !strings.Contains(res.Body.String(), "Undone: 2 signatures restored.") {
+		t.Fatalf("expected the operation undone, got %d: %s", res.Code, res.Body.String())
+	}
+}
+
+func TestBulkActionsSelectAllMatchingAndCheckRoles(t *testing.T) {
+	bulk := &bulkBackend{}
+	server, _ := newTestServerWithRoles(t, newBulkBackend(t, bulk), time.Now, RoleAssignment{CampaignID: "cmp-1", Role: RoleEditor})
+	handler := server.BuildRouter()
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, "/campaigns/cmp-1/signatures/bulk", url.Values{
+		"action":       {"unhide"},
+		"all_matching": {"1"},
+		"ids":          {"1"},
+		"q":            {"example.org"},
+	}))
+	if res.Code != http.StatusOK || bulk.bulk == nil || bulk.bulk.IDs != nil || bulk.bulk.Filter == nil || bulk.bulk.Filter.Query != "example.org" {
+		t.Fatalf("expected the filter sent instead of ids, got %d %+v", res.Code, bulk.bulk)
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, "/campaigns/cmp-1/signatures/bulk", url.Values{"action": {"delete"}, "ids": {"1"}}))
+	if res.Code != http.StatusForbidden {
+		t.Fatalf("expected editors not to bulk delete, got %d", res.Code)
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, "/campaigns/cmp-1/signatures/bulk/op-2/undo", url.Values{}))
+	if res.Code != http.StatusForbidden || bulk.undo != "" {
+		t.Fatalf("expected editors not to undo a bulk delete, got %d and %q", res.Code, bulk.undo)
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, "/campaigns/cmp-1/signatures/bulk/op-1/undo", url.Values{}))
+	if bulk.undo != "op-1" || !strings.Contains(res.Body.String(), "Undone: 2 signatures restored.") {
+		t.Fatalf("expected editors to undo a bulk hide, got %d: %s", res.Code, res.Body.String())
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, "/campaigns/cmp-1/signatures/bulk", url.Values{"action": {"hide"}}))
+	if !strings.Contains(res.Body.String(), "select at least one signature") {
+		t.Fatalf("expected an empty selection rejected, got %s", res.Body.String())
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, "/campaigns/cmp-1/signatures/bulk", url.Values{"action": {"export"}, "ids": {"2"}}))
+	match := exportStatusPattern.FindStringSubmatch(res.Body.String())
+	if match == nil || !strings.Contains(res.Body.String(), `id="signatures-panel"`) {
+		t.Fatalf("expected the panel with a polling export, got %s", res.Body.String())
+	}
+	pollUntil(t, handler, match[1])
+	if last := bulk.lists[len(bulk.lists)-1]; last.Get("ids") != "2" {
+		t.Fatalf("expected only the selected signature exported, got %v", last)
+	}
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func (s *Server) handleCampaignTrash(w http.ResponseWriter, r *http.Request) {
+	s.renderCampaignTrash(w, r, http.StatusOK, CampaignTrashPanelState{Page: parsePageQuery(r, "page")})
+}
+
+func (s *Server) handleRestoreCampaign(w http.ResponseWriter, r *http.Request) {
+	state := CampaignTrashPanelState{Page: max(parsePageQuery(r, "page"), 1)}
+
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		state.FormError = "campaign id required"
+		s.renderCampaignTrash(w, r, http.StatusBadRequest, state)
+		return
+	}
+
+	campaign, err := s.restoreCampaign(r.Context(), campaignID)
+	if err != nil {
+		state.FormError = err.Error()
+		s.renderCampaignTrash(w, r, statusFromError(err), state)
+		return
+	}
+
+	if !requestContext(r).IsHTMX {
+		http.Redirect(w, r, campaignDetailPath(campaign.ID), http.StatusSeeOther)
+		return
+	}
+
+	state.Notice = "Restored " + campaign.Name + "."
+	s.renderCampaignTrash(w, r, http.StatusOK, state)
+}
+
+func (s *Server) renderCampaignTrash(
+	w http.ResponseWriter,
+	r *http.Request,
+	statusCode int,
+	state CampaignTrashPanelState,
+) {
+	view := s.loadCampaignTrashPanel(r.Context(), state)
+	if requestContext(r).IsHTMX {
+		s.renderer.RenderCampaignTrashPanel(w, r, http.StatusOK, view)
+		return
+	}
+
+	if view.Error != "" && statusCode == http.StatusOK {
+		statusCode = http.StatusBadGateway
+	}
+	s.renderer.RenderCampaignTrashPage(w, r, statusCode, CampaignTrashPageView{Trash: view})
+}
+
+func (s *Server) handleSignatureTrash(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.renderSignatureTrash(w, r, http.StatusOK, campaignID, SignatureTrashPanelState{Page: parsePageQuery(r, "page")})
+}
+
+func (s *Server) handleRestoreSignature(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	state := SignatureTrashPanelState{Page: max(parsePageQuery(r, "page"), 1)}
+	signatureID, err := strconv.ParseInt(strings.TrimSpace(r.PathValue("signature_id")), 10, 64)
+	if err != nil {
+		state.FormError = "invalid signature id"
+		s.renderSignatureTrash(w, r, http.StatusBadRequest, campaignID, state)
+		return
+	}
+
+	signature, err := s.restoreSignature(r.Context(), campaignID, signatureID)
+	if err != nil {
+		state.FormError = err.Error()
+		s.renderSignatureTrash(w, r, statusFromError(err), campaignID, state)
+		return
+	}
+
+	if !requestContext(r).IsHTMX {
+		http.Redirect(w, r, campaignTrashPath(campaignID, state.Page), http.StatusSeeOther)
+		return
+	}
+
+	state.Notice = "Restored the signature from " + signature.Email + "."
+	s.renderSignatureTrash(w, r, http.StatusOK, campaignID, state)
+}
+
+func (s *Server) renderSignatureTrash(
+	w http.ResponseWriter,
+	r *http.Request,
+	statusCode int,
+	campaignID string,
+	state SignatureTrashPanelState,
+) {
+	view, loadStatus := s.loadSignatureTrashPanel(r.Context(), campaignID, state)
+	if requestContext(r).IsHTMX {
+		s.renderer.RenderSignatureTrashPanel(w, r, http.StatusOK, view)
+		return
+	}
+
+	if statusCode == http.StatusOK {
+		statusCode = loadStatus
+	}
+	s.renderer.RenderSignatureTrashPage(w, r, statusCode, SignatureTrashPageView{Trash: view})
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"cosign/internal/service"
+	"cosign/pkg/cosignclient"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+type trashBackend struct {
+	campaigns  []*service.Campaign
+	signatures []*service.Signature
+	restored   []string
+}
+
+// newTrashBackend fakes the trash listings and restore routes; a restore
+// takes the item out of the listing.
+func newTrashBackend(t *testing.T, trash *trashBackend) *cosignclient.Client {
+	t.Helper()
+	backend := newFakeBackend(t)
+	backend.handle("GET /admin/campaigns/trash", func(w http.ResponseWriter, r *http.Request) {
+		wire.WriteData(w, http.StatusOK, service.Campaigns{Campaigns: trash.campaigns, Total: len(trash.campaigns), Limit: 100})
+	})
+	backend.handle("POST /admin/campaigns/{id}/restore", func(w http.ResponseWriter, r *http.Request) {
+		trash.restored = append(trash.restored, r.URL.Path)
+		for idx, campaign := range trash.campaigns {
+			if campaign.ID == r.PathValue("id") {
+				trash.campaigns = slices.Delete(trash.campaigns, idx, idx+1)
+				wire.WriteData(w, http.StatusOK, campaign)
+				return
+			}
+		}
+		wire.WriteError(w, http.StatusNotFound, "not found")
+	})
+	backend.handle("POST /admin/campaigns/cmp-1/signatures/7/restore", func(w http.ResponseWriter, r *http.Request) {
+		trash.restored = append(trash.restored, r.URL.Path)
+		if len(trash.signatures) == 0 {
+			wire.WriteError(w, http.StatusNotFound, "not found")
+			return
+		}
+		signature := trash.signatures[0]
+		trash.signatures = nil
+		wire.WriteData(w, http.StatusOK, signature)
+	})
+	backend.respond("GET /admin/campaigns/cmp-1", http.StatusOK, service.Campaign{ID: "cmp-1", Name: "Parks"})
+	backend.handle("GET /admin/campaigns/cmp-1/signatures/trash", func(w http.ResponseWriter, r *http.Request) {
+		wire.WriteData(w, http.StatusOK, service.Signatures{Signatures: trash.signatures, Total: len(trash.signatures), Limit: 10})
+	})
+	return backend.client()
+}
+
+func TestCampaignTrashListsOwnedCampaignsAndRestores(t *testing.T) {
+	trash := &trashBackend{campaigns: []*service.Campaign{
+		{ID: "cmp-1", Name: "Parks", DeletedAt: 1700000000, PurgeAt: 1702592000},
+		{ID: "cmp-2", Name: "Libraries", DeletedAt: 1700000000, PurgeAt: 1702592000},
+		{ID: "cmp-3", Name: "Transit", DeletedAt: 1700000000, PurgeAt: 1702592000},
+	}}
+	server, _ := newTestServerWithRoles(t, newTrashBackend(t, trash), time.Now,
+		RoleAssignment{CampaignID: "cmp-1", Role: RoleOwner},
+		RoleAssignment{CampaignID: "cmp-2", Role: RoleEditor},
+	)
+	handler := server.BuildRouter()
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, signedIn(httptest.NewRequest(http.MethodGet, "/trash", nil)))
+	body := res.Body.String()
+	if res.Code != http.StatusOK || !strings.Contains(body, "Parks") || !strings.Contains(body, "2023-12-14T22:13:20Z") {
+		t.Fatalf("expected the owned campaign with its purge time, got %d: %s", res.Code, body)
+	}
+	if strings.Contains(body, "Libraries") || strings.Contains(body, "Transit") {
+		t.Fatalf("expected campaigns the user does not own left out, got %s", body)
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, "/campaigns/cmp-2/restore", url.Values{}))
+	if res.Code != http.StatusForbidden || len(trash.restored) != 0 {
+		t.Fatalf("expected an editor refused a restore, got %d and %v", res.Code, trash.restored)
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, "/campaigns/cmp-1/restore", url.Values{"page": {"1"}}))
+	body = res.Body.String()
+	if res.Code != http.StatusOK || !strings.Contains(body, "Restored Parks.") || !strings.Contains(body, "The trash is empty.") {
+		t.Fatalf("expected the restore noted and the trash emptied, got %d: %s", res.Code, body)
+	}
+}
+
+func TestSignatureTrashRestoresSignatures(t *testing.T) {
+	trash := &trashBackend{signatures: []*service.Signature{
+		{ID: 7, Name: "Ada", Email: "ada@example.org", Location: "Brooklyn", DeletedAt: 1700000000, PurgeAt: 1702592000},
+	}}
+	server := newTestServer(t, newTrashBackend(t, trash))
+	handler := server.BuildRouter()
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, signedIn(httptest.NewRequest(http.MethodGet, "/campaigns/cmp-1/trash", nil)))
+	body := res.Body.String()
+	if res.Code != http.StatusOK || !strings.Contains(body, "ada@example.org") || !strings.Contains(body, `action="/campaigns/cmp-1/signatures/7/restore"`) {
+		t.Fatalf("expected the trashed signature with a restore form, got %d: %s", res.Code, body)
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, "/campaigns/cmp-1/signatures/7/restore", url.Values{}))
+	body = res.Body.String()
+	if res.Code != http.StatusOK || !strings.Contains(body, "Restored the signature from ada@example.org.") {
+		t.Fatalf("expected the restore noted, got %d: %s", res.Code, body)
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, settingsRequest(http.MethodPost, "/campaigns/cmp-1/signatures/7/restore", url.Values{}))
+	if res.Code != http.StatusOK || !strings.Contains(res.Body.String(), `class="error">not found`) {
+		t.Fatalf("expected a second restore to report the error on the panel, got %d: %s", res.Code, res.Body.String())
+	}
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, signedIn(httptest.NewRequest(http.MethodGet, "/campaigns/cmp-9/trash", nil)))
+	if res.Code != http.StatusNotFound {
+		t.Fatalf("expected a missing campaign to 404, got %d", res.Code)
+	}
+}
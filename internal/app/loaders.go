@@ -1,62 +1,74 @@
 package app
 
-import "net/http"
+import (
+	"context"
+	"cosign/internal/service"
+	"net/http"
+)
 
 func (s *Server) loadCampaignsPage(
+	ctx context.Context,
 	page int,
 	name string,
 	formError string,
 ) CampaignsPageView {
 	return CampaignsPageView{
-		Campaigns: s.loadCampaignsRegion(page, name, formError),
+		Campaigns: s.loadCampaignsRegion(ctx, page, name, formError),
 	}
 }
 
 func (s *Server) loadCampaignsRegion(
+	ctx context.Context,
 	page int,
 	name string,
 	formError string,
 ) CampaignsRegionView {
 	return NewCampaignsRegionView(
-		s.loadCampaignsTable(page),
+		s.loadCampaignsTable(ctx, page),
 		name,
 		formError,
 	)
 }
 
-func (s *Server) loadCampaignsTable(page int) CampaignsTableView {
+func (s *Server) loadCampaignsTable(ctx context.Context, page int) CampaignsTableView {
 	if page < 1 {
 		page = 1
 	}
 
 	offset := (page - 1) * s.pageSize
-	campaigns, err := s.listCampaigns(s.pageSize, offset)
+	campaigns, err := s.listCampaigns(ctx, s.pageSize, offset)
 	view := NewCampaignsTableView(campaigns, page, err)
 
 	if view.Pagination.TotalPages > 0 && page > view.Pagination.TotalPages {
-		return s.loadCampaignsTable(view.Pagination.TotalPages)
+		return s.loadCampaignsTable(ctx, view.Pagination.TotalPages)
 	}
 
 	return view
 }
 
-func (s *Server) loadSignaturesTable(campaignID string, page int) SignaturesTableView {
+func (s *Server) loadSignaturesTable(
+	ctx context.Context,
+	campaignID string,
+	filter service.SignatureFilter,
+	page int,
+) SignaturesTableView {
 	if page < 1 {
 		page = 1
 	}
 
 	offset := (page - 1) * s.pageSize
-	signatures, err := s.listSignatures(campaignID, s.pageSize, offset)
-	view := NewSignaturesTableView(campaignID, signatures, page, err)
+	signatures, err := s.listSignatures(ctx, campaignID, filter, s.pageSize, offset)
+	view := NewSignaturesTableView(campaignID, signatures, filter, page, err)
 
 	if view.Pagination.TotalPages > 0 && page > view.Pagination.TotalPages {
-		return s.loadSignaturesTable(campaignID, view.Pagination.TotalPages)
+		return s.loadSignaturesTable(ctx, campaignID, filter, view.Pagination.TotalPages)
 	}
 
 	return view
 }
 
 func (s *Server) loadCampaignDetailPage(
+	ctx context.Context,
 	campaignID string,
 	state CampaignDetailPageState,
 ) (CampaignDetailPageView, int) {
@@ -64,7 +76,7 @@ func (s *Server) loadCampaignDetailPage(
 		state.Signatures.Page = 1
 	}
 
-	campaign, campaignErr := s.getCampaign(campaignID)
+	campaign, campaignErr := s.getCampaign(ctx, campaignID)
 	campaignView := NewCampaignPanelView(campaign, campaignErr)
 	if campaignErr != nil {
 		if isNotFoundError(campaignErr) {
@@ -73,14 +85,9 @@ func (s *Server) loadCampaignDetailPage(
 		return CampaignDetailPageView{Campaign: campaignView}, http.StatusBadGateway
 	}
 
-	if state.Campaign.Name != "" {
-		campaignView.Name = state.Campaign.Name
-	}
-	if state.Campaign.FormError != "" {
-		campaignView.FormError = state.Campaign.FormError
-	}
+	campaignView = campaignView.WithState(state.Campaign)
 
-	locations, locationsErr := s.getCampaignLocations(campaignID)
+	locations, locationsErr := s.getCampaignLocations(ctx, campaignID)
 	locationsView := NewLocationsPanelView(
 		campaignID,
 		campaign.AllowCustomText,
@@ -88,22 +95,112 @@ func (s *Server) loadCampaignDetailPage(
 		state.Locations,
 		locationsErr,
 	)
+	locationsView.LocationLevel = campaign.LocationLevel
+	if state.Locations.Mode == "normalize" {
+		locationsView = locationsView.WithSuggestions(s.getLocationSuggestions(ctx, campaignID))
+	}
 
-	sigTable := s.loadSignaturesTable(campaignID, state.Signatures.Page)
+	sigTable := s.loadSignaturesTable(ctx, campaignID, state.Signatures.Filter, state.Signatures.Page)
 	sigPanel := NewSignaturesPanelView(campaignID, sigTable, state.Signatures)
 
 	return CampaignDetailPageView{
 		Campaign:   campaignView,
+		Activity:   s.loadActivityPanel(ctx, campaignID, state.Activity),
 		Locations:  locationsView,
 		Signatures: sigPanel,
 	}, http.StatusOK
 }
 
+func (s *Server) loadActivityPanel(
+	ctx context.Context,
+	campaignID string,
+	state ActivityPanelState,
+) ActivityPanelView {
+	if state.Days < 1 {
+		state.Days = defaultActivityDays
+	}
+
+	stats, err := s.getSignatureStats(ctx, campaignID, state.Days)
+	return NewActivityPanelView(campaignID, stats, state, err)
+}
+
+// loadOverviewPanel lists a page of campaigns like the campaigns table,
+// fetching each one's stats for its row.
+func (s *Server) loadOverviewPanel(ctx context.Context, page int) OverviewPanelView {
+	if page < 1 {
+		page = 1
+	}
+
+	offset := (page - 1) * s.pageSize
+	campaigns, err := s.listCampaigns(ctx, s.pageSize, offset)
+	if err != nil {
+		return NewOverviewPanelView(nil, nil, nil, page, err)
+	}
+
+	stats := make(map[string]*service.SignatureStats, len(campaigns.Campaigns))
+	statsErrs := make(map[string]error)
+	for _, campaign := range campaigns.Campaigns {
+		campaignStats, err := s.getSignatureStats(ctx, campaign.ID, overviewDays)
+		if err != nil {
+			statsErrs[campaign.ID] = err
+			continue
+		}
+		stats[campaign.ID] = campaignStats
+	}
+
+	view := NewOverviewPanelView(campaigns, stats, statsErrs, page, nil)
+	if view.Pagination.TotalPages > 0 && page > view.Pagination.TotalPages {
+		return s.loadOverviewPanel(ctx, view.Pagination.TotalPages)
+	}
+	return view
+}
+
+// loadCampaignTrashPanel lists a page of the trashed campaigns the user
+// may restore, stepping back when a restore emptied the last page.
+func (s *Server) loadCampaignTrashPanel(ctx context.Context, state CampaignTrashPanelState) CampaignTrashPanelView {
+	state.Page = max(state.Page, 1)
+
+	offset := (state.Page - 1) * s.pageSize
+	campaigns, err := s.listDeletedCampaigns(ctx, s.pageSize, offset)
+	view := NewCampaignTrashPanelView(campaigns, state, err)
+	if err == nil && view.Pagination.TotalPages > 0 && state.Page > view.Pagination.TotalPages {
+		state.Page = view.Pagination.TotalPages
+		return s.loadCampaignTrashPanel(ctx, state)
+	}
+	return view
+}
+
+func (s *Server) loadSignatureTrashPanel(
+	ctx context.Context,
+	campaignID string,
+	state SignatureTrashPanelState,
+) (SignatureTrashPanelView, int) {
+	state.Page = max(state.Page, 1)
+
+	campaign, err := s.getCampaign(ctx, campaignID)
+	if err != nil {
+		return NewSignatureTrashPanelView(nil, nil, state, err), statusFromError(err)
+	}
+
+	offset := (state.Page - 1) * s.pageSize
+	signatures, err := s.listDeletedSignatures(ctx, campaignID, s.pageSize, offset)
+	view := NewSignatureTrashPanelView(campaign, signatures, state, err)
+	if err != nil {
+		return view, statusFromError(err)
+	}
+	if view.Pagination.TotalPages > 0 && state.Page > view.Pagination.TotalPages {
+		state.Page = view.Pagination.TotalPages
+		return s.loadSignatureTrashPanel(ctx, campaignID, state)
+	}
+	return view, http.StatusOK
+}
+
 func (s *Server) loadLocationsPanel(
+	ctx context.Context,
 	campaignID string,
 	state LocationsPanelState,
 ) (LocationsPanelView, int) {
-	campaign, campaignErr := s.getCampaign(campaignID)
+	campaign, campaignErr := s.getCampaign(ctx, campaignID)
 	if campaignErr != nil {
 		view := NewLocationsPanelView(campaignID, false, nil, state, campaignErr)
 		if isNotFoundError(campaignErr) {
@@ -112,7 +209,7 @@ func (s *Server) loadLocationsPanel(
 		return view, http.StatusBadGateway
 	}
 
-	locations, err := s.getCampaignLocations(campaignID)
+	locations, err := s.getCampaignLocations(ctx, campaignID)
 	view := NewLocationsPanelView(
 		campaignID,
 		campaign.AllowCustomText,
@@ -120,6 +217,7 @@ func (s *Server) loadLocationsPanel(
 		state,
 		err,
 	)
+	view.LocationLevel = campaign.LocationLevel
 	if err != nil {
 		if isNotFoundError(err) {
 			return view, http.StatusNotFound
@@ -127,13 +225,17 @@ func (s *Server) loadLocationsPanel(
 		return view, http.StatusBadGateway
 	}
 
-	if state.Mode == "edit" && (state.EditIndex < 0 || state.EditIndex >= len(locations)) {
+	if state.Mode == "normalize" {
+		view = view.WithSuggestions(s.getLocationSuggestions(ctx, campaignID))
+	}
+
+	editing := false
+	for _, row := range view.Rows {
+		editing = editing || row.IsEditing
+	}
+	if state.Mode == "edit" && !editing {
 		view.FormError = "location not found"
 		view.Error = ""
-		for idx := range view.Rows {
-			view.Rows[idx].IsEditing = false
-			view.Rows[idx].EditValue = ""
-		}
 		return view, http.StatusBadRequest
 	}
 
@@ -143,3 +245,37 @@ func (s *Server) loadLocationsPanel(
 
 	return view, http.StatusOK
 }
+
+func (s *Server) loadSettingsPage(
+	ctx context.Context,
+	keys APIKeysPanelState,
+	cors CORSPanelState,
+) SettingsPageView {
+	return SettingsPageView{
+		Keys: s.loadAPIKeysPanel(ctx, keys),
+		CORS: s.loadCORSPanel(ctx, cors),
+	}
+}
+
+func (s *Server) loadAPIKeysPanel(ctx context.Context, state APIKeysPanelState) APIKeysPanelView {
+	keys, err := s.listAPIKeys(ctx)
+	return NewAPIKeysPanelView(keys, s.client.APIKeyID(), state, err)
+}
+
+func (s *Server) loadCORSPanel(ctx context.Context, state CORSPanelState) CORSPanelView {
+	origins, err := s.listCORSOrigins(ctx)
+	return NewCORSPanelView(origins, state, err)
+}
+
+// loadCSVPage names the campaign an import or export page belongs to,
+// falling back to its ID when the API cannot be reached.
+func (s *Server) loadCSVPage(ctx context.Context, campaignID string) CSVPageView {
+	view := CSVPageView{
+		CampaignName: campaignID,
+		CampaignPath: campaignDetailPath(campaignID),
+	}
+	if campaign, err := s.getCampaign(ctx, campaignID); err == nil {
+		view.CampaignName = campaign.Name
+	}
+	return view
+}
@@ -1,8 +1,10 @@
 package app
 
 import (
+	"cosign/internal/service"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -21,35 +23,133 @@ func parsePageQuery(r *http.Request, key string) int {
 	return page
 }
 
-func parseLocationsMode(r *http.Request, modeKey, indexKey string) (string, int) {
+func parseLocationsMode(r *http.Request, modeKey, idKey string) (string, int64) {
 	mode := strings.ToLower(strings.TrimSpace(r.URL.Query().Get(modeKey)))
-	if mode != "new" && mode != "edit" {
-		return "", -1
+	if mode != "new" && mode != "edit" && mode != "normalize" {
+		return "", 0
 	}
 
-	if mode == "new" {
-		return mode, -1
+	if mode != "edit" {
+		return mode, 0
 	}
 
-	indexRaw := strings.TrimSpace(r.URL.Query().Get(indexKey))
-	index, err := strconv.Atoi(indexRaw)
-	if err != nil || index < 0 {
-		return "", -1
+	idRaw := strings.TrimSpace(r.URL.Query().Get(idKey))
+	id, err := strconv.ParseInt(idRaw, 10, 64)
+	if err != nil || id <= 0 {
+		return "", 0
 	}
 
-	return mode, index
+	return mode, id
 }
 
-func parsePathIndex(r *http.Request, pathKey string) (int, error) {
-	indexRaw := strings.TrimSpace(r.PathValue(pathKey))
-	index, err := strconv.Atoi(indexRaw)
-	if err != nil || index < 0 {
-		return 0, fmt.Errorf("invalid index")
+// parseAliases splits a comma separated list, always returning a non-nil
+// slice so an empty field clears aliases.
+func parseAliases(raw string) []string {
+	aliases := []string{}
+	for _, alias := range strings.Split(raw, ",") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+// parseCoordinates reads an optional latitude/longitude pair; both blank
+// means no coordinates.
+func parseCoordinates(rawLat, rawLon string) (*float64, *float64, error) {
+	rawLat, rawLon = strings.TrimSpace(rawLat), strings.TrimSpace(rawLon)
+	if rawLat == "" && rawLon == "" {
+		return nil, nil, nil
+	}
+
+	lat, latErr := strconv.ParseFloat(rawLat, 64)
+	lon, lonErr := strconv.ParseFloat(rawLon, 64)
+	if latErr != nil || lonErr != nil {
+		return nil, nil, fmt.Errorf("latitude and longitude must both be numbers")
+	}
+	return &lat, &lon, nil
+}
+
+func parsePathID(r *http.Request, pathKey string) (int64, error) {
+	idRaw := strings.TrimSpace(r.PathValue(pathKey))
+	id, err := strconv.ParseInt(idRaw, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid id")
 	}
 
-	return index, nil
+	return id, nil
 }
 
 func campaignIDFromPath(r *http.Request) string {
 	return strings.TrimSpace(r.PathValue("campaign_id"))
 }
+
+func parseCampaignPanelForm(r *http.Request) CampaignPanelState {
+	return CampaignPanelState{
+		Submitted: true,
+		Name:      strings.TrimSpace(r.FormValue("name")),
+		Letter:    strings.TrimSpace(r.FormValue("letter")),
+		Goal:      parseNonNegative(r.FormValue("goal")),
+		Theme: service.CampaignTheme{
+			PrimaryColor:    strings.TrimSpace(r.FormValue("primary_color")),
+			BackgroundColor: strings.TrimSpace(r.FormValue("background_color")),
+			LogoURL:         strings.TrimSpace(r.FormValue("logo_url")),
+		},
+		SuccessURL: strings.TrimSpace(r.FormValue("success_url")),
+		ErrorURL:   strings.TrimSpace(r.FormValue("error_url")),
+		Revision:   parseRevision(r.FormValue("revision")),
+	}
+}
+
+func parseRevision(raw string) int64 {
+	revision, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil || revision < 1 {
+		return 0
+	}
+	return revision
+}
+
+func parseNonNegative(raw string) int {
+	goal, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || goal < 0 {
+		return 0
+	}
+	return goal
+}
+
+// parseActivityDays reads one of activityRanges from key, or the default.
+func parseActivityDays(r *http.Request, key string) int {
+	days, err := strconv.Atoi(strings.TrimSpace(r.URL.Query().Get(key)))
+	if err != nil || !slices.Contains(activityRanges, days) {
+		return defaultActivityDays
+	}
+	return days
+}
+
+// parseSignatureFilter reads the signatures panel's search and visibility;
+// an unknown visibility shows every signature.
+func parseSignatureFilter(r *http.Request) service.SignatureFilter {
+	filter := service.SignatureFilter{
+		Query:      strings.TrimSpace(r.FormValue("q")),
+		Visibility: strings.TrimSpace(r.FormValue("visibility")),
+	}
+	if filter.Visibility != service.VisibilityVisible && filter.Visibility != service.VisibilityHidden {
+		filter.Visibility = ""
+	}
+	return filter
+}
+
+// parseSignatureIDs reads the selected signature checkboxes, skipping
+// anything that is not an id.
+func parseSignatureIDs(r *http.Request, key string) []int64 {
+	_ = r.ParseForm()
+
+	var ids []int64
+	for _, raw := range r.Form[key] {
+		id, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if err == nil && id > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"cosign/internal/service"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -76,15 +77,40 @@ func campaignsPagePath(page int) string {
 }
 
 func signaturesPagePath(campaignID string, page int) string {
+	return signaturesListPath(campaignID, service.SignatureFilter{}, page)
+}
+
+// signaturesListPath is a page of the signatures panel narrowed by the
+// panel's search and visibility.
+func signaturesListPath(campaignID string, filter service.SignatureFilter, page int) string {
+	return campaignDetailPath(campaignID) + "/signatures?" + signaturesQuery(filter, page)
+}
+
+func signaturesQuery(filter service.SignatureFilter, page int) string {
+	query := url.Values{"page": {strconv.Itoa(max(page, 1))}}
+	if filter.Query != "" {
+		query.Set("q", filter.Query)
+	}
+	if filter.Visibility != "" {
+		query.Set("visibility", filter.Visibility)
+	}
+	return query.Encode()
+}
+
+func trashPagePath(page int) string {
+	if page < 1 {
+		page = 1
+	}
+
+	return fmt.Sprintf("/trash?page=%d", page)
+}
+
+func campaignTrashPath(campaignID string, page int) string {
 	if page < 1 {
 		page = 1
 	}
 
-	return fmt.Sprintf(
-		"/campaigns/%s/signatures?page=%d",
-		url.PathEscape(campaignID),
-		page,
-	)
+	return fmt.Sprintf("%s/trash?page=%d", campaignDetailPath(campaignID), page)
 }
 
 func campaignLocationsPath(campaignID string) string {
@@ -0,0 +1,169 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+var ErrInvalidRole = errors.New("role must be viewer, editor or owner")
+
+// Role is what a dashboard user may do, globally or within one campaign.
+// Each role includes the ones below it: viewers read campaigns and their
+// signatures, editors also change them, and owners also delete.
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleOwner  Role = "owner"
+)
+
+var roleRanks = map[Role]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+func ParseRole(raw string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(raw)))
+	if _, ok := roleRanks[role]; !ok {
+		return "", ErrInvalidRole
+	}
+	return role, nil
+}
+
+// Includes reports whether r grants everything other does.
+func (r Role) Includes(other Role) bool {
+	return r != "" && roleRanks[r] >= roleRanks[other]
+}
+
+// RoleAssignment grants Role on CampaignID, or on every campaign when
+// CampaignID is empty.
+type RoleAssignment struct {
+	CampaignID string `json:"campaign_id,omitempty"`
+	Role       Role   `json:"role"`
+}
+
+// Access is a user's effective roles.
+type Access struct {
+	global    Role
+	campaigns map[string]Role
+}
+
+func NewAccess(assignments []RoleAssignment) Access {
+	access := Access{campaigns: map[string]Role{}}
+	for _, assignment := range assignments {
+		if assignment.CampaignID == "" {
+			access.global = assignment.Role
+			continue
+		}
+		access.campaigns[assignment.CampaignID] = assignment.Role
+	}
+	return access
+}
+
+// RoleFor is the stronger of the user's global role and their role on
+// campaignID; an empty campaignID asks for the global role alone.
+func (a Access) RoleFor(campaignID string) Role {
+	role := a.global
+	if campaignRole, ok := a.campaigns[campaignID]; ok && campaignID != "" && !role.Includes(campaignRole) {
+		role = campaignRole
+	}
+	return role
+}
+
+func (a Access) Can(campaignID string, role Role) bool {
+	return a.RoleFor(campaignID).Includes(role)
+}
+
+// AllCampaigns reports whether a global role lets the user see every
+// campaign, rather than only those in CampaignIDs.
+func (a Access) AllCampaigns() bool {
+	return a.global != ""
+}
+
+func (a Access) CampaignIDs() []string {
+	ids := make([]string, 0, len(a.campaigns))
+	for id := range a.campaigns {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func accessFromContext(ctx context.Context) Access {
+	if session := sessionFromContext(ctx); session != nil {
+		return session.Access
+	}
+	return Access{}
+}
+
+// GrantRole sets a user's role globally, or on one campaign.
+func GrantRole(store AuthStore, username string, assignment RoleAssignment) error {
+	if _, err := ParseRole(string(assignment.Role)); err != nil {
+		return err
+	}
+
+	user, err := getUser(store, username)
+	if err != nil {
+		return err
+	}
+	return store.SetDashboardUserRole(user.ID, strings.TrimSpace(assignment.CampaignID), assignment.Role)
+}
+
+// RevokeRole removes a user's global role, or their role on one campaign.
+func RevokeRole(store AuthStore, username string, campaignID string) error {
+	user, err := getUser(store, username)
+	if err != nil {
+		return err
+	}
+	return store.DeleteDashboardUserRole(user.ID, strings.TrimSpace(campaignID))
+}
+
+// ListUsers returns every user with their role assignments.
+func ListUsers(store AuthStore) ([]*User, error) {
+	users, err := store.ListDashboardUsers()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		roles, err := store.ListDashboardUserRoles(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		user.Roles = roles
+	}
+	return users, nil
+}
+
+func getUser(store AuthStore, username string) (*User, error) {
+	username, err := NormalizeUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	return store.GetDashboardUser(username)
+}
+
+// require serves next only when the signed-in user holds role on the
+// request's campaign, or globally on routes without one.
+func (s *Server) require(role Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !accessFromContext(r.Context()).Can(campaignIDFromPath(r), role) {
+			s.renderForbidden(w, r, role)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) renderForbidden(w http.ResponseWriter, r *http.Request, role Role) {
+	view := ForbiddenPageView{
+		Required: string(role),
+		Current:  string(accessFromContext(r.Context()).RoleFor(campaignIDFromPath(r))),
+	}
+	s.renderer.RenderForbiddenPage(w, r, http.StatusForbidden, view)
+}
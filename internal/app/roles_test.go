@@ -0,0 +1,172 @@
+package app
+
+import (
+	"cosign/internal/service"
+	"cosign/pkg/cosignclient"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+func TestAccessCombinesGlobalAndCampaignRoles(t *testing.T) {
+	access := NewAccess([]RoleAssignment{
+		{Role: RoleViewer},
+		{CampaignID: "cmp-1", Role: RoleOwner},
+	})
+
+	if got := access.RoleFor("cmp-1"); got != RoleOwner {
+		t.Fatalf("expected owner on cmp-1, got %q", got)
+	}
+	if got := access.RoleFor("cmp-2"); got != RoleViewer {
+		t.Fatalf("expected the global viewer role on cmp-2, got %q", got)
+	}
+	if access.Can("", RoleEditor) {
+		t.Fatalf("expected a campaign role not to grant global rights")
+	}
+	if !access.AllCampaigns() {
+		t.Fatalf("expected a global role to see every campaign")
+	}
+
+	if NewAccess(nil).Can("cmp-1", RoleViewer) {
+		t.Fatalf("expected no roles to grant nothing")
+	}
+	if _, err := ParseRole("admin"); err != ErrInvalidRole {
+		t.Fatalf("expected invalid role error, got %v", err)
+	}
+}
+
+func newCampaignDetailBackend(t *testing.T, calls *[]string) *httptest.Server {
+	t.Helper()
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls = append(*calls, r.Method+" "+r.URL.Path)
+		switch {
+		case r.URL.Path == "/admin/campaigns/cmp-1":
+			wire.WriteData(w, http.StatusOK, service.Campaign{ID: "cmp-1", Name: "Mine", Revision: 1})
+		case r.URL.Path == "/admin/campaigns/cmp-1/locations":
+			wire.WriteData(w, http.StatusOK, service.CampaignLocationsResponse{Locations: []service.LocationOption{{ID: 1, Value: "Here"}}})
+		case r.URL.Path == "/admin/campaigns/cmp-1/signatures":
+			wire.WriteData(w, http.StatusOK, service.Signatures{
+				Signatures: []*service.Signature{{ID: 7, Name: "Ada", Email: "ada@example.com", Location: "Here"}},
+				Total:      1,
+				Limit:      10,
+			})
+		default:
+			wire.WriteError(w, http.StatusNotFound, "not found")
+		}
+	}))
+	t.Cleanup(backend.Close)
+	return backend
+}
+
+func TestViewerSeesSignaturesWithoutActions(t *testing.T) {
+	var calls []string
+	backend := newCampaignDetailBackend(t, &calls)
+	server, _ := newTestServerWithRoles(t, cosignclient.New(cosignclient.Options{BaseURL: backend.URL}), time.Now,
+		RoleAssignment{CampaignID: "cmp-1", Role: RoleViewer},
+	)
+	handler := server.BuildRouter()
+
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, signedIn(httptest.NewRequest(http.MethodGet, "/campaigns/cmp-1", nil)))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected detail page, got %d: %s", res.Code, res.Body.String())
+	}
+
+	body := res.Body.String()
+	if !strings.Contains(body, "ada@example.com") {
+		t.Fatalf("expected signatures on the page")
+	}
+	for _, hidden := range []string{
+		"Save Campaign",
+		"Delete Campaign",
+		"Add Signature",
+		"Delete this signature?",
+		"New Location",
+		"Update Rule",
+	} {
+		if strings.Contains(body, hidden) {
+			t.Fatalf("expected %q to be hidden from viewers", hidden)
+		}
+	}
+	if !strings.Contains(body, `class="plain-fieldset" disabled`) {
+		t.Fatalf("expected read-only campaign fields")
+	}
+}
+
+func TestForbiddenActionsRenderForbiddenPage(t *testing.T) {
+	var calls []string
+	backend := newCampaignDetailBackend(t, &calls)
+	server, _ := newTestServerWithRoles(t, cosignclient.New(cosignclient.Options{BaseURL: backend.URL}), time.Now,
+		RoleAssignment{CampaignID: "cmp-1", Role: RoleEditor},
+	)
+	handler := server.BuildRouter()
+
+	for _, tc := range []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodPost, "/campaigns/cmp-1/signatures/7", "_method=DELETE"},
+		{http.MethodPost, "/campaigns/cmp-1", "_method=DELETE"},
+		{http.MethodPost, "/campaigns/cmp-1/locations/1", "_method=DELETE"},
+		{http.MethodPost, "/campaigns", "name=New"},
+		{http.MethodGet, "/campaigns/cmp-2", ""},
+		{http.MethodPost, "/campaigns/cmp-2/signatures", "name=Ada"},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		res := httptest.NewRecorder()
+		handler.ServeHTTP(res, signedIn(req))
+
+		if res.Code != http.StatusForbidden {
+			t.Fatalf("%s %s: expected 403, got %d", tc.method, tc.path, res.Code)
+		}
+		if !strings.Contains(res.Body.String(), "Not allowed") {
+			t.Fatalf("%s %s: expected the forbidden page, got %s", tc.method, tc.path, res.Body.String())
+		}
+	}
+	if len(calls) != 0 {
+		t.Fatalf("expected no API calls for forbidden actions, got %v", calls)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/campaigns/cmp-1/signatures", strings.NewReader("name=Ada&email=ada@example.com&location=Here"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, signedIn(req))
+	if res.Code == http.StatusForbidden {
+		t.Fatalf("expected editors to add signatures")
+	}
+}
+
+func TestCampaignListShowsOnlyAssignedCampaigns(t *testing.T) {
+	var calls []string
+	backend := newCampaignDetailBackend(t, &calls)
+	server, _ := newTestServerWithRoles(t, cosignclient.New(cosignclient.Options{BaseURL: backend.URL}), time.Now,
+		RoleAssignment{CampaignID: "cmp-1", Role: RoleViewer},
+		RoleAssignment{CampaignID: "cmp-gone", Role: RoleOwner},
+	)
+
+	res := httptest.NewRecorder()
+	server.BuildRouter().ServeHTTP(res, signedIn(httptest.NewRequest(http.MethodGet, "/campaigns", nil)))
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected campaigns page, got %d", res.Code)
+	}
+
+	body := res.Body.String()
+	if !strings.Contains(body, `href="/campaigns/cmp-1">Mine</a>`) {
+		t.Fatalf("expected the assigned campaign, got %s", body)
+	}
+	if strings.Contains(body, "Create Campaign") {
+		t.Fatalf("expected campaign creation to need a global role")
+	}
+	for _, call := range calls {
+		if call == "GET /admin/campaigns" {
+			t.Fatalf("expected no full campaign listing, got %v", calls)
+		}
+	}
+}
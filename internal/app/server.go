@@ -1,26 +1,55 @@
 package app
 
 import (
+	"context"
+	"cosign/internal/httpserver"
+	"cosign/pkg/cosignclient"
+	"errors"
+	"net"
 	"net/http"
 	"strings"
-
-	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+	"sync"
+	"time"
 )
 
 const defaultPageSize = 10
 
 type Options struct {
-	Client   wire.Client
+	Client   *cosignclient.Client
 	PageSize int
+
+	// Auth stores the dashboard users and their sessions.
+	Auth AuthStore
+	// SessionTTL is how long a sign-in lasts; zero means 12 hours.
+	SessionTTL time.Duration
+	// SecureCookies marks cookies Secure even when the request did not
+	// arrive over TLS, e.g. behind a TLS-terminating proxy.
+	SecureCookies bool
+	Clock         func() time.Time
 }
 
 type Server struct {
-	client   wire.Client
+	client   *cosignclient.Client
 	renderer *Renderer
 	pageSize int
+
+	auth          AuthStore
+	sessionTTL    time.Duration
+	secureCookies bool
+	clock         func() time.Time
+
+	loginLimiters      map[string]*loginLimiter
+	loginLimitersSwept time.Time
+	loginLimitersMu    sync.Mutex
+
+	jobs *csvJobs
 }
 
 func New(opts Options) (*Server, error) {
+	if opts.Auth == nil {
+		return nil, errors.New("app: auth store required")
+	}
+
 	renderer, err := NewRenderer()
 	if err != nil {
 		return nil, err
@@ -31,15 +60,34 @@ func New(opts Options) (*Server, error) {
 		pageSize = defaultPageSize
 	}
 
+	sessionTTL := opts.SessionTTL
+	if sessionTTL <= 0 {
+		sessionTTL = defaultSessionTTL
+	}
+
+	clock := opts.Clock
+	if clock == nil {
+		clock = time.Now
+	}
+
 	return &Server{
 		client:   opts.Client,
 		renderer: renderer,
 		pageSize: pageSize,
+
+		auth:          opts.Auth,
+		sessionTTL:    sessionTTL,
+		secureCookies: opts.SecureCookies,
+		clock:         clock,
+		loginLimiters: make(map[string]*loginLimiter),
+		jobs:          newCSVJobs(),
 	}, nil
 }
 
-func (s *Server) Serve(addr string) error {
-	return http.ListenAndServe(addr, s.BuildRouter())
+// Serve runs the dashboard on ln until ctx ends, then drains in-flight
+// requests.
+func (s *Server) Serve(ctx context.Context, ln net.Listener, opts httpserver.Options) error {
+	return httpserver.Serve(ctx, ln, s.BuildRouter(), opts)
 }
 
 func (s *Server) BuildRouter() http.Handler {
@@ -47,12 +95,22 @@ func (s *Server) BuildRouter() http.Handler {
 
 	static := http.StripPrefix("/static/", s.renderer.StaticHandler())
 	mux.Handle("GET /static/", static)
+	s.registerAuthRoutes(mux)
 	s.registerCampaignRoutes(mux)
 	s.registerCampaignDetailRoutes(mux)
 	s.registerLocationRoutes(mux)
 	s.registerSignatureRoutes(mux)
+	s.registerCSVRoutes(mux)
+	s.registerTrashRoutes(mux)
+	s.registerSettingsRoutes(mux)
 
-	return withMethodOverride(mux)
+	return s.withSession(withMethodOverride(mux))
+}
+
+func (s *Server) registerAuthRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /login", s.handleLoginPage)
+	mux.HandleFunc("POST /login", s.handleLogin)
+	mux.HandleFunc("POST /logout", s.handleLogout)
 }
 
 func (s *Server) registerCampaignRoutes(mux *http.ServeMux) {
@@ -62,27 +120,68 @@ func (s *Server) registerCampaignRoutes(mux *http.ServeMux) {
 	})
 
 	mux.HandleFunc("GET /campaigns", s.handleCampaigns)
-	mux.HandleFunc("POST /campaigns", s.handleCreateCampaign)
-	mux.HandleFunc("DELETE /campaigns/{campaign_id}", s.handleDeleteCampaign)
+	mux.HandleFunc("GET /overview", s.handleOverview)
+	mux.HandleFunc("POST /campaigns", s.require(RoleEditor, s.handleCreateCampaign))
+	mux.HandleFunc("DELETE /campaigns/{campaign_id}", s.require(RoleOwner, s.handleDeleteCampaign))
 }
 
 func (s *Server) registerCampaignDetailRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("GET /campaigns/{campaign_id}", s.handleCampaignDetailPage)
-	mux.HandleFunc("PATCH /campaigns/{campaign_id}", s.handleUpdateCampaign)
+	mux.HandleFunc("GET /campaigns/{campaign_id}", s.require(RoleViewer, s.handleCampaignDetailPage))
+	mux.HandleFunc("PATCH /campaigns/{campaign_id}", s.require(RoleEditor, s.handleUpdateCampaign))
+	mux.HandleFunc("GET /campaigns/{campaign_id}/activity", s.require(RoleViewer, s.handleActivity))
 }
 
 func (s *Server) registerLocationRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("GET /campaigns/{campaign_id}/locations", s.handleLocations)
-	mux.HandleFunc("POST /campaigns/{campaign_id}/locations", s.handleCreateLocation)
-	mux.HandleFunc("PATCH /campaigns/{campaign_id}/locations/{index}", s.handleUpdateLocation)
-	mux.HandleFunc("DELETE /campaigns/{campaign_id}/locations/{index}", s.handleDeleteLocation)
-	mux.HandleFunc("PATCH /campaigns/{campaign_id}/locations/settings", s.handleUpdateLocationsSettings)
+	mux.HandleFunc("GET /campaigns/{campaign_id}/locations", s.require(RoleViewer, s.handleLocations))
+	mux.HandleFunc("POST /campaigns/{campaign_id}/locations", s.require(RoleEditor, s.handleCreateLocation))
+	mux.HandleFunc("PATCH /campaigns/{campaign_id}/locations/{location_id}", s.require(RoleEditor, s.handleUpdateLocation))
+	mux.HandleFunc("DELETE /campaigns/{campaign_id}/locations/{location_id}", s.require(RoleOwner, s.handleDeleteLocation))
+	mux.HandleFunc("POST /campaigns/{campaign_id}/locations/{location_id}/move", s.require(RoleEditor, s.handleMoveLocation))
+	mux.HandleFunc("POST /campaigns/{campaign_id}/locations/{location_id}/merge", s.require(RoleEditor, s.handleMergeLocation))
+	mux.HandleFunc("POST /campaigns/{campaign_id}/locations/normalize", s.require(RoleEditor, s.handleNormalizeLocations))
+	mux.HandleFunc("POST /campaigns/{campaign_id}/locations/coordinates", s.require(RoleEditor, s.handleImportLocationCoordinates))
+	mux.HandleFunc("PATCH /campaigns/{campaign_id}/locations/settings", s.require(RoleEditor, s.handleUpdateLocationsSettings))
 }
 
 func (s *Server) registerSignatureRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("GET /campaigns/{campaign_id}/signatures", s.handleSignatures)
-	mux.HandleFunc("POST /campaigns/{campaign_id}/signatures", s.handleCreateSignature)
-	mux.HandleFunc("DELETE /campaigns/{campaign_id}/signatures/{signature_id}", s.handleDeleteSignature)
+	mux.HandleFunc("GET /campaigns/{campaign_id}/signatures", s.require(RoleViewer, s.handleSignatures))
+	mux.HandleFunc("POST /campaigns/{campaign_id}/signatures", s.require(RoleEditor, s.handleCreateSignature))
+	mux.HandleFunc("DELETE /campaigns/{campaign_id}/signatures/{signature_id}", s.require(RoleOwner, s.handleDeleteSignature))
+	mux.HandleFunc("POST /campaigns/{campaign_id}/signatures/bulk", s.require(RoleViewer, s.handleBulkSignatures))
+	mux.HandleFunc("POST /campaigns/{campaign_id}/signatures/bulk/{operation_id}/undo", s.require(RoleViewer, s.handleUndoBulkSignatures))
+}
+
+// registerTrashRoutes serves the trash. Restoring takes the owner role that
+// deleting did; the campaign trash lists only campaigns the user owns.
+func (s *Server) registerTrashRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /trash", s.handleCampaignTrash)
+	mux.HandleFunc("POST /campaigns/{campaign_id}/restore", s.require(RoleOwner, s.handleRestoreCampaign))
+	mux.HandleFunc("GET /campaigns/{campaign_id}/trash", s.require(RoleOwner, s.handleSignatureTrash))
+	mux.HandleFunc("POST /campaigns/{campaign_id}/signatures/{signature_id}/restore", s.require(RoleOwner, s.handleRestoreSignature))
+}
+
+// registerCSVRoutes serves imports, which add signatures and so need an
+// editor, and exports, which any viewer may take.
+func (s *Server) registerCSVRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /campaigns/{campaign_id}/import", s.require(RoleEditor, s.handleImportPage))
+	mux.HandleFunc("POST /campaigns/{campaign_id}/import", s.require(RoleEditor, s.handleUploadImport))
+	mux.HandleFunc("GET /campaigns/{campaign_id}/import/{job_id}", s.require(RoleEditor, s.handleImportStatus))
+	mux.HandleFunc("POST /campaigns/{campaign_id}/import/{job_id}/check", s.require(RoleEditor, s.handleCheckImport))
+	mux.HandleFunc("POST /campaigns/{campaign_id}/import/{job_id}/commit", s.require(RoleEditor, s.handleCommitImport))
+	mux.HandleFunc("POST /campaigns/{campaign_id}/export", s.require(RoleViewer, s.handleStartExport))
+	mux.HandleFunc("GET /campaigns/{campaign_id}/export/{job_id}", s.require(RoleViewer, s.handleExportStatus))
+	mux.HandleFunc("GET /campaigns/{campaign_id}/export/{job_id}/download", s.require(RoleViewer, s.handleDownloadExport))
+}
+
+// registerSettingsRoutes serves the API's key and CORS settings, which are
+// not tied to a campaign and so need a global owner.
+func (s *Server) registerSettingsRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /settings", s.require(RoleOwner, s.handleSettingsPage))
+	mux.HandleFunc("GET /settings/keys", s.require(RoleOwner, s.handleAPIKeys))
+	mux.HandleFunc("POST /settings/keys", s.require(RoleOwner, s.handleCreateAPIKey))
+	mux.HandleFunc("DELETE /settings/keys/{key_id}", s.require(RoleOwner, s.handleRevokeAPIKey))
+	mux.HandleFunc("GET /settings/cors", s.require(RoleOwner, s.handleCORS))
+	mux.HandleFunc("PATCH /settings/cors", s.require(RoleOwner, s.handleUpdateCORS))
 }
 
 func withMethodOverride(next http.Handler) http.Handler {
@@ -0,0 +1,275 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	sessionCookieName  = "cosign_session"
+	loginCookieName    = "cosign_login"
+	csrfFormField      = "_csrf"
+	csrfHeader         = "X-CSRF-Token"
+	defaultSessionTTL  = 12 * time.Hour
+	loginCookieMaxAge  = time.Hour
+	loginAttemptsBurst = 5
+	loginAttemptEvery  = 12 * time.Second
+	loginLimiterIdle   = time.Hour
+	maxFormBytes       = 1 << 20
+	maxUploadBytes     = maxImportBytes + 1<<20
+)
+
+type sessionKey struct{}
+
+func sessionFromContext(ctx context.Context) *Session {
+	session, _ := ctx.Value(sessionKey{}).(*Session)
+	return session
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func tokensEqual(a, b string) bool {
+	return a != "" && subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// withSession requires a signed-in user for everything but the login page
+// and static assets, and a matching CSRF token on every unsafe request. It
+// runs before withMethodOverride, so overridden forms are POSTs here and
+// are checked too. Unsafe request bodies are capped before anything reads
+// them.
+func (s *Server) withSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isSafeMethod(r.Method) {
+			limit := int64(maxFormBytes)
+			if isMultipart(r) {
+				limit = maxUploadBytes
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+		}
+
+		if r.URL.Path == "/login" || strings.HasPrefix(r.URL.Path, "/static/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		session, err := s.currentSession(r)
+		if err != nil {
+			log.Printf("load dashboard session: %v", err)
+			http.Error(w, "failed to load session", http.StatusInternalServerError)
+			return
+		}
+		if session == nil {
+			s.redirectToLogin(w, r)
+			return
+		}
+
+		roles, err := s.auth.ListDashboardUserRoles(session.UserID)
+		if err != nil {
+			log.Printf("load dashboard roles: %v", err)
+			http.Error(w, "failed to load session", http.StatusInternalServerError)
+			return
+		}
+		session.Access = NewAccess(roles)
+
+		if !isSafeMethod(r.Method) {
+			if !tokensEqual(csrfTokenFrom(r), session.CSRFToken) {
+				http.Error(w, "invalid or missing CSRF token", http.StatusForbidden)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), sessionKey{}, session)))
+	})
+}
+
+// csrfTokenFrom reads the token from the header, falling back to the form
+// field. Multipart bodies are left for the handler to parse, so uploads
+// must send the header; htmx does for every page that sets hx-headers.
+func csrfTokenFrom(r *http.Request) string {
+	if token := r.Header.Get(csrfHeader); token != "" {
+		return token
+	}
+	if isMultipart(r) {
+		return ""
+	}
+	return r.FormValue(csrfFormField)
+}
+
+func isMultipart(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "multipart/form-data"
+}
+
+func (s *Server) currentSession(r *http.Request) (*Session, error) {
+	cookie, err := r.Cookie(sessionCookieName)
+	if err != nil || cookie.Value == "" {
+		return nil, nil
+	}
+
+	session, err := s.auth.GetDashboardSession(hashToken(cookie.Value))
+	if errors.Is(err, ErrSessionNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s.clock().Unix() >= session.ExpiresAt {
+		_ = s.auth.DeleteDashboardSession(session.ID)
+		return nil, nil
+	}
+	return session, nil
+}
+
+func (s *Server) redirectToLogin(w http.ResponseWriter, r *http.Request) {
+	target := "/login"
+	if r.Method == http.MethodGet && r.URL.Path != "/" {
+		target += "?next=" + url.QueryEscape(r.URL.RequestURI())
+	}
+
+	if requestContext(r).IsHTMX {
+		w.Header().Set("HX-Redirect", target)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+// startSession signs user in and sets the session cookie.
+func (s *Server) startSession(w http.ResponseWriter, r *http.Request, user *User) error {
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+	csrfToken, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	now := s.clock()
+	if err := s.auth.DeleteExpiredDashboardSessions(now.Unix()); err != nil {
+		return err
+	}
+	session := Session{
+		ID:        hashToken(token),
+		UserID:    user.ID,
+		Username:  user.Username,
+		CSRFToken: csrfToken,
+		CreatedAt: now.Unix(),
+		ExpiresAt: now.Add(s.sessionTTL).Unix(),
+	}
+	if err := s.auth.InsertDashboardSession(session); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, s.cookie(r, sessionCookieName, token, "/", s.sessionTTL))
+	return nil
+}
+
+func (s *Server) cookie(r *http.Request, name, value, path string, maxAge time.Duration) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		HttpOnly: true,
+		Secure:   s.secureCookies || r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if maxAge > 0 {
+		cookie.MaxAge = int(maxAge.Seconds())
+	} else {
+		cookie.MaxAge = -1
+	}
+	return cookie
+}
+
+// loginLimiter is one key's attempt budget and when it was last spent.
+type loginLimiter struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// allowLogin spends one login attempt for the client address and one for
+// the username from that address, so a host cannot guess freely at one
+// account or across many. Usernames are keyed per host so that unknown
+// names from a single client cannot fill the table.
+func (s *Server) allowLogin(r *http.Request, username string) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	username = strings.ToLower(strings.TrimSpace(username))
+
+	s.loginLimitersMu.Lock()
+	defer s.loginLimitersMu.Unlock()
+
+	now := s.clock()
+	s.evictIdleLoginLimiters(now)
+	allowHost := s.loginLimiterFor("host:"+host, now).Allow()
+	allowUser := s.loginLimiterFor("user:"+host+"|"+username, now).Allow()
+	return allowHost && allowUser
+}
+
+// loginLimiterFor must be called with loginLimitersMu held.
+func (s *Server) loginLimiterFor(key string, now time.Time) *rate.Limiter {
+	entry, ok := s.loginLimiters[key]
+	if !ok {
+		entry = &loginLimiter{limiter: rate.NewLimiter(rate.Every(loginAttemptEvery), loginAttemptsBurst)}
+		s.loginLimiters[key] = entry
+	}
+	entry.lastUsed = now
+	return entry.limiter
+}
+
+// evictIdleLoginLimiters drops limiters unused for loginLimiterIdle; by
+// then their budget has refilled, so forgetting them changes nothing. It
+// sweeps at most once a minute.
+func (s *Server) evictIdleLoginLimiters(now time.Time) {
+	if now.Sub(s.loginLimitersSwept) < time.Minute {
+		return
+	}
+	s.loginLimitersSwept = now
+
+	for key, entry := range s.loginLimiters {
+		if now.Sub(entry.lastUsed) >= loginLimiterIdle {
+			delete(s.loginLimiters, key)
+		}
+	}
+}
+
+// safeRedirect keeps post-login redirects on this site.
+func safeRedirect(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/campaigns"
+	}
+	return next
+}
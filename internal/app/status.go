@@ -1,32 +1,28 @@
 package app
 
 import (
+	"cosign/pkg/cosignclient"
 	"net/http"
-	"strings"
 )
 
+// statusFromError passes the API's client errors through to the browser.
+// A stale revision surfaces as a conflict, and anything the API could not
+// answer is a bad gateway.
 func statusFromError(err error) int {
 	if err == nil {
 		return http.StatusOK
 	}
 
-	msg := strings.ToLower(err.Error())
-	if strings.Contains(msg, "bad request") ||
-		strings.Contains(msg, "invalid") ||
-		strings.Contains(msg, "required") {
-		return http.StatusBadRequest
+	if isConflictError(err) {
+		return http.StatusConflict
 	}
 
-	if strings.Contains(msg, "unauthorized") {
-		return http.StatusUnauthorized
+	apiErr, ok := cosignclient.AsError(err)
+	if !ok || apiErr.Status >= http.StatusInternalServerError {
+		return http.StatusBadGateway
 	}
-
-	if strings.Contains(msg, "forbidden") || strings.Contains(msg, "csrf") {
-		return http.StatusForbidden
-	}
-
-	if isNotFoundError(err) {
-		return http.StatusNotFound
+	if apiErr.Status >= http.StatusBadRequest {
+		return apiErr.Status
 	}
 
 	return http.StatusBadGateway
@@ -16,8 +16,32 @@ type Renderer struct {
 	staticFS  fs.FS
 }
 
+// requestFuncs are the template functions that depend on the signed-in
+// session and its roles; templates are cloned per render to bind them.
+func requestFuncs(session *Session) template.FuncMap {
+	var username, csrfToken string
+	var access Access
+	if session != nil {
+		username = session.Username
+		csrfToken = session.CSRFToken
+		access = session.Access
+	}
+
+	return template.FuncMap{
+		"currentUser": func() string { return username },
+		"csrfToken":   func() string { return csrfToken },
+		"canCreate":   func() bool { return access.Can("", RoleEditor) },
+		"canEdit":     func(campaignID string) bool { return access.Can(campaignID, RoleEditor) },
+		"canDelete":   func(campaignID string) bool { return access.Can(campaignID, RoleOwner) },
+		"canSettings": func() bool { return access.Can("", RoleOwner) },
+		"csrfField": func() template.HTML {
+			return template.HTML(`<input type="hidden" name="` + csrfFormField + `" value="` + template.HTMLEscapeString(csrfToken) + `">`)
+		},
+	}
+}
+
 func NewRenderer() (*Renderer, error) {
-	tmpl, err := template.ParseFS(assets, "templates/*.html")
+	tmpl, err := template.New("").Funcs(requestFuncs(nil)).ParseFS(assets, "templates/*.html")
 	if err != nil {
 		return nil, err
 	}
@@ -35,12 +59,20 @@ func NewRenderer() (*Renderer, error) {
 
 func (r *Renderer) renderTemplate(
 	w http.ResponseWriter,
+	req *http.Request,
 	statusCode int,
 	name string,
 	data any,
 ) {
+	tmpl, err := r.templates.Clone()
+	if err != nil {
+		http.Error(w, "failed to render page", http.StatusInternalServerError)
+		return
+	}
+	tmpl.Funcs(requestFuncs(sessionFromContext(req.Context())))
+
 	var body bytes.Buffer
-	if err := r.templates.ExecuteTemplate(&body, name, data); err != nil {
+	if err := tmpl.ExecuteTemplate(&body, name, data); err != nil {
 		http.Error(w, "failed to render page", http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"cosign/internal/service"
+	"fmt"
+	"net/http"
+)
+
+// activityRanges are the chart spans, in days, the panel offers.
+var activityRanges = []int{7, 30, 90}
+
+const defaultActivityDays = 30
+
+type ActivityPanelState struct {
+	Days int
+}
+
+type ActivityRangeView struct {
+	Days   int
+	Path   string
+	Active bool
+}
+
+type LocationBarView struct {
+	Location string
+	Count    int
+	Percent  int
+}
+
+type ActivityPanelView struct {
+	CampaignID string
+	Days       int
+	Total      int
+	Last24h    int
+	Last7d     int
+	Chart      ChartView
+	Locations  []LocationBarView
+	Ranges     []ActivityRangeView
+	Error      string
+}
+
+func NewActivityPanelView(
+	campaignID string,
+	stats *service.SignatureStats,
+	state ActivityPanelState,
+	err error,
+) ActivityPanelView {
+	view := ActivityPanelView{CampaignID: campaignID, Days: state.Days}
+	for _, days := range activityRanges {
+		view.Ranges = append(view.Ranges, ActivityRangeView{
+			Days:   days,
+			Path:   campaignActivityPath(campaignID, days),
+			Active: days == state.Days,
+		})
+	}
+
+	if err != nil {
+		view.Error = err.Error()
+		return view
+	}
+	if stats == nil {
+		view.Error = "failed to load signature stats"
+		return view
+	}
+
+	view.Total = stats.Total
+	view.Last24h = stats.Last24h
+	view.Last7d = stats.Last7d
+	view.Chart = NewChartView(stats.Days)
+
+	for _, location := range stats.TopLocations {
+		percent := 0
+		if stats.Total > 0 {
+			percent = location.Count * 100 / stats.Total
+		}
+		view.Locations = append(view.Locations, LocationBarView{
+			Location: location.Location,
+			Count:    location.Count,
+			Percent:  percent,
+		})
+	}
+
+	return view
+}
+
+func campaignActivityPath(campaignID string, days int) string {
+	return fmt.Sprintf("%s/activity?days=%d", campaignDetailPath(campaignID), days)
+}
+
+func (r *Renderer) RenderActivityPanel(
+	w http.ResponseWriter,
+	req *http.Request,
+	statusCode int,
+	view ActivityPanelView,
+) {
+	r.renderTemplate(w, req, statusCode, "activity_panel", view)
+}
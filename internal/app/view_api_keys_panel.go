@@ -0,0 +1,76 @@
+package app
+
+import (
+	"cosign/internal/service"
+	"net/http"
+	"net/url"
+)
+
+type APIKeyRowView struct {
+	ID         string
+	CreatedAt  string
+	LastUsedAt string
+	RevokePath string
+	// InUse marks the key this dashboard calls the API with, which cannot
+	// be revoked from here.
+	InUse bool
+}
+
+type APIKeysPanelState struct {
+	// NewToken is the token of a key just created; it is shown once.
+	NewToken  string
+	FormError string
+}
+
+type APIKeysPanelView struct {
+	Keys       []APIKeyRowView
+	NewToken   string
+	FormError  string
+	Error      string
+	CreatePath string
+}
+
+func NewAPIKeysPanelView(
+	keys []*service.APIKey,
+	inUseID string,
+	state APIKeysPanelState,
+	err error,
+) APIKeysPanelView {
+	view := APIKeysPanelView{
+		NewToken:   state.NewToken,
+		FormError:  state.FormError,
+		CreatePath: "/settings/keys",
+	}
+
+	if err != nil {
+		view.Error = err.Error()
+		return view
+	}
+
+	rows := make([]APIKeyRowView, 0, len(keys))
+	for _, key := range keys {
+		if key == nil {
+			continue
+		}
+
+		rows = append(rows, APIKeyRowView{
+			ID:         key.ID,
+			CreatedAt:  formatUnixTime(key.CreatedAt),
+			LastUsedAt: formatUnixTime(key.LastUsedAt),
+			RevokePath: "/settings/keys/" + url.PathEscape(key.ID),
+			InUse:      key.ID == inUseID,
+		})
+	}
+
+	view.Keys = rows
+	return view
+}
+
+func (r *Renderer) RenderAPIKeysPanel(
+	w http.ResponseWriter,
+	req *http.Request,
+	statusCode int,
+	view APIKeysPanelView,
+) {
+	r.renderTemplate(w, req, statusCode, "api_keys_panel", view)
+}
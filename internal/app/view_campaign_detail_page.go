@@ -4,20 +4,23 @@ import "net/http"
 
 type CampaignDetailPageState struct {
 	Campaign   CampaignPanelState
+	Activity   ActivityPanelState
 	Locations  LocationsPanelState
 	Signatures SignaturesPanelState
 }
 
 type CampaignDetailPageView struct {
 	Campaign   CampaignPanelView
+	Activity   ActivityPanelView
 	Locations  LocationsPanelView
 	Signatures SignaturesPanelView
 }
 
 func (r *Renderer) RenderCampaignDetailPage(
 	w http.ResponseWriter,
+	req *http.Request,
 	statusCode int,
 	view CampaignDetailPageView,
 ) {
-	r.renderTemplate(w, statusCode, "campaign_detail_page", view)
+	r.renderTemplate(w, req, statusCode, "campaign_detail_page", view)
 }
@@ -8,16 +8,34 @@ import (
 type CampaignPanelView struct {
 	ID              string
 	Name            string
+	Letter          string
+	Goal            int
 	AllowCustomText bool
+	Theme           service.CampaignTheme
+	SuccessURL      string
+	ErrorURL        string
 	CreatedAt       string
+	Revision        int64
 	FormError       string
+	FieldErrors     map[string]string
+	Conflict        bool
 	UpdatePath      string
 	DeletePath      string
+	ReloadPath      string
 }
 
 type CampaignPanelState struct {
-	Name      string
-	FormError string
+	Submitted   bool
+	Name        string
+	Letter      string
+	Goal        int
+	Theme       service.CampaignTheme
+	SuccessURL  string
+	ErrorURL    string
+	Revision    int64
+	FormError   string
+	FieldErrors map[string]string
+	Conflict    bool
 }
 
 func NewCampaignPanelView(
@@ -37,17 +55,51 @@ func NewCampaignPanelView(
 	return CampaignPanelView{
 		ID:              campaign.ID,
 		Name:            campaign.Name,
+		Letter:          campaign.Letter,
+		Goal:            campaign.Goal,
 		AllowCustomText: campaign.AllowCustomText,
+		Theme:           campaign.Theme,
+		SuccessURL:      campaign.SuccessURL,
+		ErrorURL:        campaign.ErrorURL,
 		CreatedAt:       formatUnixTime(campaign.CreatedAt),
+		Revision:        campaign.Revision,
 		UpdatePath:      path,
 		DeletePath:      path,
+		ReloadPath:      path,
 	}
 }
 
+// WithState overlays submitted form values so a failed update re-renders
+// what the user typed.
+func (v CampaignPanelView) WithState(state CampaignPanelState) CampaignPanelView {
+	if state.Submitted {
+		if state.Name != "" {
+			v.Name = state.Name
+		}
+		v.Letter = state.Letter
+		v.Goal = state.Goal
+		v.Theme = state.Theme
+		v.SuccessURL = state.SuccessURL
+		v.ErrorURL = state.ErrorURL
+	}
+	if state.FormError != "" {
+		v.FormError = state.FormError
+	}
+	v.FieldErrors = state.FieldErrors
+	// keep the stale revision on conflict so resubmitting cannot overwrite
+	// the other edit until the user reloads
+	if state.Conflict {
+		v.Conflict = true
+		v.Revision = state.Revision
+	}
+	return v
+}
+
 func (r *Renderer) RenderCampaignPanel(
 	w http.ResponseWriter,
+	req *http.Request,
 	statusCode int,
 	view CampaignPanelView,
 ) {
-	r.renderTemplate(w, statusCode, "campaign_panel", view)
+	r.renderTemplate(w, req, statusCode, "campaign_panel", view)
 }
@@ -8,8 +8,9 @@ type CampaignsPageView struct {
 
 func (r *Renderer) RenderCampaignsPage(
 	w http.ResponseWriter,
+	req *http.Request,
 	statusCode int,
 	view CampaignsPageView,
 ) {
-	r.renderTemplate(w, statusCode, "campaigns_page", view)
+	r.renderTemplate(w, req, statusCode, "campaigns_page", view)
 }
@@ -24,8 +24,9 @@ func NewCampaignsRegionView(
 
 func (r *Renderer) RenderCampaignsRegion(
 	w http.ResponseWriter,
+	req *http.Request,
 	statusCode int,
 	view CampaignsRegionView,
 ) {
-	r.renderTemplate(w, statusCode, "campaigns_region", view)
+	r.renderTemplate(w, req, statusCode, "campaigns_region", view)
 }
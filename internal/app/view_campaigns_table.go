@@ -72,8 +72,9 @@ func NewCampaignsTableView(
 
 func (r *Renderer) RenderCampaignsTable(
 	w http.ResponseWriter,
+	req *http.Request,
 	statusCode int,
 	view CampaignsTableView,
 ) {
-	r.renderTemplate(w, statusCode, "campaigns_table", view)
+	r.renderTemplate(w, req, statusCode, "campaigns_table", view)
 }
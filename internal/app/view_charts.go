@@ -0,0 +1,117 @@
+package app
+
+import (
+	"cosign/internal/service"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	chartWidth      = 640
+	chartHeight     = 180
+	chartTop        = 12
+	chartBottom     = 22
+	chartBarGap     = 2
+	sparklineWidth  = 120
+	sparklineHeight = 28
+)
+
+type ChartBarView struct {
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+	Date   string
+	Count  int
+}
+
+// ChartView lays out one bar per day, scaled so the busiest day fills the
+// plot; the SVG itself is drawn by the template.
+type ChartView struct {
+	Width     int
+	Height    int
+	Baseline  float64
+	Bars      []ChartBarView
+	Max       int
+	FirstDate string
+	LastDate  string
+}
+
+func NewChartView(days []service.DailySignatureCount) ChartView {
+	view := ChartView{
+		Width:    chartWidth,
+		Height:   chartHeight,
+		Baseline: chartHeight - chartBottom,
+	}
+	if len(days) == 0 {
+		return view
+	}
+
+	for _, day := range days {
+		view.Max = max(view.Max, day.Count)
+	}
+	view.FirstDate = days[0].Date
+	view.LastDate = days[len(days)-1].Date
+
+	plotHeight := view.Baseline - chartTop
+	slot := float64(chartWidth) / float64(len(days))
+	barWidth := max(slot-chartBarGap, 1)
+	for idx, day := range days {
+		height := 0.0
+		if view.Max > 0 {
+			height = plotHeight * float64(day.Count) / float64(view.Max)
+		}
+		view.Bars = append(view.Bars, ChartBarView{
+			X:      roundCoord(float64(idx) * slot),
+			Y:      roundCoord(view.Baseline - height),
+			Width:  roundCoord(barWidth),
+			Height: roundCoord(height),
+			Date:   day.Date,
+			Count:  day.Count,
+		})
+	}
+	return view
+}
+
+type SparklineView struct {
+	Width  int
+	Height int
+	Points string
+	Label  string
+}
+
+// NewSparklineView joins the daily counts into a polyline, flat along the
+// bottom when there are none.
+func NewSparklineView(days []service.DailySignatureCount) SparklineView {
+	view := SparklineView{Width: sparklineWidth, Height: sparklineHeight}
+	if len(days) == 0 {
+		return view
+	}
+
+	peak, sum := 0, 0
+	for _, day := range days {
+		peak = max(peak, day.Count)
+		sum += day.Count
+	}
+	view.Label = fmt.Sprintf("%d signatures over %d days", sum, len(days))
+
+	step := 0.0
+	if len(days) > 1 {
+		step = float64(sparklineWidth) / float64(len(days)-1)
+	}
+	points := make([]string, 0, len(days))
+	for idx, day := range days {
+		y := float64(sparklineHeight - 1)
+		if peak > 0 {
+			y -= float64(sparklineHeight-2) * float64(day.Count) / float64(peak)
+		}
+		points = append(points, fmt.Sprintf("%g,%g", roundCoord(float64(idx)*step), roundCoord(y)))
+	}
+	view.Points = strings.Join(points, " ")
+	return view
+}
+
+func roundCoord(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"cosign/internal/service"
+	"testing"
+)
+
+func TestNewChartViewScalesBarsToBusiestDay(t *testing.T) {
+	view := NewChartView([]service.DailySignatureCount{
+		{Date: "2026-10-16", Count: 2},
+		{Date: "2026-10-17", Count: 0},
+		{Date: "2026-10-18", Count: 4},
+	})
+
+	if view.Max != 4 || view.FirstDate != "2026-10-16" || view.LastDate != "2026-10-18" {
+		t.Fatalf("unexpected chart summary: %+v", view)
+	}
+	if len(view.Bars) != 3 {
+		t.Fatalf("expected one bar per day, got %d", len(view.Bars))
+	}
+
+	plot := view.Baseline - chartTop
+	busiest, empty, half := view.Bars[2], view.Bars[1], view.Bars[0]
+	if busiest.Height != plot || busiest.Y != chartTop {
+		t.Fatalf("expected the busiest day to fill the plot, got %+v", busiest)
+	}
+	if empty.Height != 0 || empty.Y != view.Baseline {
+		t.Fatalf("expected an empty day to sit on the baseline, got %+v", empty)
+	}
+	if half.Height != roundCoord(plot/2) || half.X != 0 || busiest.X <= empty.X {
+		t.Fatalf("unexpected bar layout: %+v", view.Bars)
+	}
+}
+
+func TestNewSparklineViewJoinsPoints(t *testing.T) {
+	view := NewSparklineView([]service.DailySignatureCount{
+		{Date: "2026-10-17", Count: 0},
+		{Date: "2026-10-18", Count: 3},
+	})
+
+	if view.Points != "0,27 120,1" {
+		t.Fatalf("unexpected points: %q", view.Points)
+	}
+	if view.Label != "3 signatures over 2 days" {
+		t.Fatalf("unexpected label: %q", view.Label)
+	}
+
+	flat := NewSparklineView([]service.DailySignatureCount{{Count: 0}, {Count: 0}})
+	if flat.Points != "0,27 120,27" {
+		t.Fatalf("expected a flat line without signatures, got %q", flat.Points)
+	}
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+)
+
+type CORSPanelState struct {
+	// Origins is the submitted allowlist text, kept when saving fails.
+	Origins   string
+	FormError string
+	Saved     bool
+}
+
+type CORSPanelView struct {
+	Origins    []string
+	Text       string
+	FormError  string
+	Error      string
+	Saved      bool
+	UpdatePath string
+}
+
+func NewCORSPanelView(
+	origins []string,
+	state CORSPanelState,
+	err error,
+) CORSPanelView {
+	view := CORSPanelView{
+		Origins:    origins,
+		Text:       strings.Join(origins, "\n"),
+		FormError:  state.FormError,
+		Saved:      state.Saved,
+		UpdatePath: "/settings/cors",
+	}
+	if state.FormError != "" {
+		view.Text = state.Origins
+	}
+
+	if err != nil {
+		view.Error = err.Error()
+	}
+	return view
+}
+
+func (r *Renderer) RenderCORSPanel(
+	w http.ResponseWriter,
+	req *http.Request,
+	statusCode int,
+	view CORSPanelView,
+) {
+	r.renderTemplate(w, req, statusCode, "cors_panel", view)
+}
@@ -0,0 +1,197 @@
+package app
+
+import (
+	"net/http"
+	"net/url"
+)
+
+const (
+	importProblemLimit = 100
+	importSampleLimit  = 10
+)
+
+type ProgressView struct {
+	Done    int
+	Total   int
+	Percent int
+}
+
+func NewProgressView(done, total int) ProgressView {
+	percent := 100
+	if total > 0 {
+		percent = done * 100 / total
+	}
+	return ProgressView{Done: done, Total: total, Percent: percent}
+}
+
+type ImportColumnView struct {
+	Index int
+	Name  string
+}
+
+type ImportFieldView struct {
+	Name     string
+	Label    string
+	Selected int
+}
+
+type ImportRowView struct {
+	Line     int
+	Name     string
+	Email    string
+	Location string
+	Resolved string
+	Error    string
+}
+
+// ImportPanelView walks through an import: Status is empty before a file
+// is uploaded, then follows the job.
+type ImportPanelView struct {
+	CampaignID string
+	Status     string
+	Filename   string
+	FormError  string
+	Columns    []ImportColumnView
+	Fields     []ImportFieldView
+	Progress   ProgressView
+	Error      string
+	Polling    bool
+
+	Accepted     int
+	Rejected     int
+	Imported     int
+	Problems     []ImportRowView
+	MoreProblems int
+	Sample       []ImportRowView
+
+	UploadPath     string
+	CheckPath      string
+	CommitPath     string
+	StatusPath     string
+	SignaturesPath string
+}
+
+func NewImportPanelView(campaignID string, job *csvJobSnapshot, formError string) ImportPanelView {
+	importPath := campaignDetailPath(campaignID) + "/import"
+	view := ImportPanelView{
+		CampaignID:     campaignID,
+		FormError:      formError,
+		UploadPath:     importPath,
+		SignaturesPath: signaturesPagePath(campaignID, 1),
+	}
+	if job == nil {
+		return view
+	}
+
+	jobPath := importPath + "/" + url.PathEscape(job.ID)
+	view.Status = string(job.Status)
+	view.Filename = job.Filename
+	view.Progress = NewProgressView(job.Done, job.Total)
+	view.Error = job.Err
+	view.Polling = job.Status.running()
+	view.CheckPath = jobPath + "/check"
+	view.CommitPath = jobPath + "/commit"
+	view.StatusPath = jobPath
+
+	for idx, name := range job.Header {
+		view.Columns = append(view.Columns, ImportColumnView{Index: idx, Name: name})
+	}
+	for _, field := range importFields {
+		view.Fields = append(view.Fields, ImportFieldView{
+			Name:     field.name,
+			Label:    field.label,
+			Selected: job.Mapping[field.name],
+		})
+	}
+
+	for _, row := range job.Rows {
+		rowView := ImportRowView{
+			Line:     row.Line,
+			Name:     row.Signature.Name,
+			Email:    row.Signature.Email,
+			Location: row.Signature.Location,
+			Resolved: row.Location,
+			Error:    row.Error,
+		}
+
+		switch {
+		case row.Error != "":
+			view.Rejected++
+			if len(view.Problems) < importProblemLimit {
+				view.Problems = append(view.Problems, rowView)
+			} else {
+				view.MoreProblems++
+			}
+		default:
+			view.Accepted++
+			if row.Imported {
+				view.Imported++
+			}
+			if len(view.Sample) < importSampleLimit {
+				view.Sample = append(view.Sample, rowView)
+			}
+		}
+	}
+
+	return view
+}
+
+type ExportPanelView struct {
+	CampaignID   string
+	Status       string
+	Progress     ProgressView
+	Error        string
+	Polling      bool
+	StatusPath   string
+	DownloadPath string
+}
+
+func NewExportPanelView(job csvJobSnapshot) ExportPanelView {
+	jobPath := campaignDetailPath(job.CampaignID) + "/export/" + url.PathEscape(job.ID)
+	return ExportPanelView{
+		CampaignID:   job.CampaignID,
+		Status:       string(job.Status),
+		Progress:     NewProgressView(job.Done, job.Total),
+		Error:        job.Err,
+		Polling:      job.Status.running(),
+		StatusPath:   jobPath,
+		DownloadPath: jobPath + "/download",
+	}
+}
+
+// CSVPageView frames an import or export panel as a full page, for
+// browsers without htmx and for the import screen itself.
+type CSVPageView struct {
+	CampaignName string
+	CampaignPath string
+	Polling      bool
+	Import       *ImportPanelView
+	Export       *ExportPanelView
+}
+
+func (r *Renderer) RenderCSVPage(
+	w http.ResponseWriter,
+	req *http.Request,
+	statusCode int,
+	view CSVPageView,
+) {
+	r.renderTemplate(w, req, statusCode, "csv_page", view)
+}
+
+func (r *Renderer) RenderImportPanel(
+	w http.ResponseWriter,
+	req *http.Request,
+	statusCode int,
+	view ImportPanelView,
+) {
+	r.renderTemplate(w, req, statusCode, "import_panel", view)
+}
+
+func (r *Renderer) RenderExportPanel(
+	w http.ResponseWriter,
+	req *http.Request,
+	statusCode int,
+	view ExportPanelView,
+) {
+	r.renderTemplate(w, req, statusCode, "export_panel", view)
+}
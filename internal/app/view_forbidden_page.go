@@ -0,0 +1,17 @@
+package app
+
+import "net/http"
+
+type ForbiddenPageView struct {
+	Required string
+	Current  string
+}
+
+func (r *Renderer) RenderForbiddenPage(
+	w http.ResponseWriter,
+	req *http.Request,
+	statusCode int,
+	view ForbiddenPageView,
+) {
+	r.renderTemplate(w, req, statusCode, "forbidden_page", view)
+}
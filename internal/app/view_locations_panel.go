@@ -3,38 +3,83 @@ package app
 import (
 	"cosign/internal/service"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 type LocationRowView struct {
+	ID          int64
+	Value       string
+	Depth       int
+	Codes       string
+	Aliases     string
+	Coordinates string
+	IsEditing   bool
+	EditValue   string
+	EditAliases string
+	EditLat     string
+	EditLon     string
+	UpdatePath  string
+	DeletePath  string
+	EditPath    string
+	CancelPath  string
+	MovePath    string
+	MergePath   string
+	CanMoveUp   bool
+	CanMoveDown bool
+}
+
+type LocationTargetView struct {
+	ID    int64
+	Value string
+}
+
+type LocationSuggestionView struct {
 	Index      int
 	Value      string
-	IsEditing  bool
-	EditValue  string
-	UpdatePath string
-	DeletePath string
-	EditPath   string
-	CancelPath string
+	Count      int
+	LocationID int64
+	Distance   int
 }
 
 type LocationsPanelState struct {
-	Mode       string
-	EditIndex  int
-	DraftValue string
-	FormError  string
+	Mode         string
+	EditID       int64
+	DraftValue   string
+	DraftParent  string
+	DraftAliases string
+	DraftLat     string
+	DraftLon     string
+	FormError    string
+	Notice       string
 }
 
 type LocationsPanelView struct {
 	CampaignID         string
 	Rows               []LocationRowView
 	AllowCustomText    bool
+	LocationLevel      int
 	Error              string
 	FormError          string
+	Notice             string
 	NewMode            bool
 	NewValue           string
+	NewParent          string
+	NewAliases         string
+	NewLat             string
+	NewLon             string
+	CoordinatesPath    string
+	ParentOptions      []string
 	UpdateSettingsPath string
 	CreatePath         string
 	NewPath            string
 	CancelNewPath      string
+	Targets            []LocationTargetView
+	NormalizeMode      bool
+	NormalizeOpenPath  string
+	NormalizePath      string
+	Suggestions        []LocationSuggestionView
+	SuggestionsError   string
 }
 
 func NewLocationsPanelView(
@@ -50,10 +95,14 @@ func NewLocationsPanelView(
 		CampaignID:         campaignID,
 		AllowCustomText:    allowCustomText,
 		FormError:          state.FormError,
+		Notice:             state.Notice,
 		UpdateSettingsPath: locationsPath + "/settings",
 		CreatePath:         locationsPath,
 		NewPath:            locationsPath + "?mode=new",
 		CancelNewPath:      locationsPath,
+		NormalizeOpenPath:  locationsPath + "?mode=normalize",
+		NormalizePath:      locationsPath + "/normalize",
+		CoordinatesPath:    locationsPath + "/coordinates",
 	}
 
 	if err != nil {
@@ -61,42 +110,132 @@ func NewLocationsPanelView(
 		return view
 	}
 
+	parents := make(map[string]string, len(locations))
+	siblings := make(map[int64][]int64, len(locations))
+	for _, loc := range locations {
+		parents[loc.Value] = loc.Parent
+		siblings[loc.ParentID] = append(siblings[loc.ParentID], loc.ID)
+	}
+
 	rows := make([]LocationRowView, 0, len(locations))
-	for idx, loc := range locations {
-		rowBasePath := locationsPath + "/" + itoa(idx)
+	for _, loc := range locations {
+		depth := 0
+		for parent := loc.Parent; parent != "" && depth < len(locations); parent = parents[parent] {
+			depth++
+		}
+
+		codes := loc.CountryCode
+		if loc.SubdivisionCode != "" {
+			codes = strings.TrimSpace(codes + " " + loc.SubdivisionCode)
+		}
+
+		lat, lon := "", ""
+		if loc.Latitude != nil && loc.Longitude != nil {
+			lat = formatCoordinate(*loc.Latitude)
+			lon = formatCoordinate(*loc.Longitude)
+		}
+
+		group := siblings[loc.ParentID]
+		rowBasePath := locationsPath + "/" + itoa64(loc.ID)
 		row := LocationRowView{
-			Index:      idx,
-			Value:      loc.Value,
-			UpdatePath: rowBasePath,
-			DeletePath: rowBasePath,
-			EditPath:   locationsPath + "?mode=edit&index=" + itoa(idx),
-			CancelPath: locationsPath,
+			ID:          loc.ID,
+			Value:       loc.Value,
+			Depth:       depth,
+			Codes:       codes,
+			Aliases:     strings.Join(loc.Aliases, ", "),
+			Coordinates: strings.Trim(lat+", "+lon, ", "),
+			UpdatePath:  rowBasePath,
+			DeletePath:  rowBasePath,
+			EditPath:    locationsPath + "?mode=edit&id=" + itoa64(loc.ID),
+			CancelPath:  locationsPath,
+			MovePath:    rowBasePath + "/move",
+			MergePath:   rowBasePath + "/merge",
+			CanMoveUp:   len(group) > 0 && group[0] != loc.ID,
+			CanMoveDown: len(group) > 0 && group[len(group)-1] != loc.ID,
 		}
 
-		if state.Mode == "edit" && idx == state.EditIndex {
+		if state.Mode == "edit" && loc.ID == state.EditID {
 			row.IsEditing = true
 			row.EditValue = loc.Value
+			row.EditAliases = row.Aliases
+			row.EditLat = lat
+			row.EditLon = lon
 			if state.DraftValue != "" {
 				row.EditValue = state.DraftValue
+				row.EditAliases = state.DraftAliases
+				row.EditLat = state.DraftLat
+				row.EditLon = state.DraftLon
 			}
 		}
 
 		rows = append(rows, row)
+		view.ParentOptions = append(view.ParentOptions, loc.Value)
+		view.Targets = append(view.Targets, LocationTargetView{ID: loc.ID, Value: loc.Value})
 	}
 
 	view.Rows = rows
 	if state.Mode == "new" {
 		view.NewMode = true
 		view.NewValue = state.DraftValue
+		view.NewParent = state.DraftParent
+		view.NewAliases = state.DraftAliases
+		view.NewLat = state.DraftLat
+		view.NewLon = state.DraftLon
 	}
 
 	return view
 }
 
+func formatCoordinate(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// WithSuggestions fills the normalize form with free-text locations and
+// their closest presets.
+func (v LocationsPanelView) WithSuggestions(
+	suggestions []service.LocationSuggestion,
+	err error,
+) LocationsPanelView {
+	v.NormalizeMode = true
+	if err != nil {
+		v.SuggestionsError = err.Error()
+		return v
+	}
+
+	v.Suggestions = make([]LocationSuggestionView, 0, len(suggestions))
+	for idx, suggestion := range suggestions {
+		v.Suggestions = append(v.Suggestions, LocationSuggestionView{
+			Index:      idx,
+			Value:      suggestion.Value,
+			Count:      suggestion.Count,
+			LocationID: suggestion.LocationID,
+			Distance:   suggestion.Distance,
+		})
+	}
+	return v
+}
+
+// LocationsSettingsView re-renders the locations panel along with an
+// out-of-band swap of the details form's revision.
+type LocationsSettingsView struct {
+	Panel            LocationsPanelView
+	CampaignRevision int64
+}
+
+func (r *Renderer) RenderLocationsSettings(
+	w http.ResponseWriter,
+	req *http.Request,
+	statusCode int,
+	view LocationsSettingsView,
+) {
+	r.renderTemplate(w, req, statusCode, "locations_settings", view)
+}
+
 func (r *Renderer) RenderLocationsPanel(
 	w http.ResponseWriter,
+	req *http.Request,
 	statusCode int,
 	view LocationsPanelView,
 ) {
-	r.renderTemplate(w, statusCode, "locations_panel", view)
+	r.renderTemplate(w, req, statusCode, "locations_panel", view)
 }
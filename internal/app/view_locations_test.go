@@ -9,8 +9,8 @@ func TestNewLocationsPanelViewBuildsRowActionPaths(t *testing.T) {
 	view := NewLocationsPanelView(
 		"cmp-1",
 		false,
-		[]service.LocationOption{{Value: "Boston"}},
-		LocationsPanelState{Mode: "edit", EditIndex: 0, DraftValue: "Somerville"},
+		[]service.LocationOption{{ID: 7, Value: "Boston"}},
+		LocationsPanelState{Mode: "edit", EditID: 7, DraftValue: "Somerville"},
 		nil,
 	)
 
@@ -19,13 +19,13 @@ func TestNewLocationsPanelViewBuildsRowActionPaths(t *testing.T) {
 	}
 
 	row := view.Rows[0]
-	if row.UpdatePath != "/campaigns/cmp-1/locations/0" {
+	if row.UpdatePath != "/campaigns/cmp-1/locations/7" {
 		t.Fatalf("unexpected update path: %q", row.UpdatePath)
 	}
-	if row.DeletePath != "/campaigns/cmp-1/locations/0" {
+	if row.DeletePath != "/campaigns/cmp-1/locations/7" {
 		t.Fatalf("unexpected delete path: %q", row.DeletePath)
 	}
-	if row.EditPath != "/campaigns/cmp-1/locations?mode=edit&index=0" {
+	if row.EditPath != "/campaigns/cmp-1/locations?mode=edit&id=7" {
 		t.Fatalf("unexpected edit path: %q", row.EditPath)
 	}
 	if !row.IsEditing {
@@ -58,3 +58,47 @@ func TestNewLocationsPanelViewBuildsNewModePaths(t *testing.T) {
 		t.Fatalf("unexpected cancel new path: %q", view.CancelNewPath)
 	}
 }
+
+func TestLocationsPanelViewWithSuggestions(t *testing.T) {
+	view := NewLocationsPanelView(
+		"cmp-1",
+		true,
+		[]service.LocationOption{{ID: 7, Value: "Boston"}},
+		LocationsPanelState{Mode: "normalize"},
+		nil,
+	).WithSuggestions([]service.LocationSuggestion{
+		{Value: "bostn", Count: 2, LocationID: 7, Suggestion: "Boston", Distance: 1},
+		{Value: "Atlantis", Count: 1},
+	}, nil)
+
+	if !view.NormalizeMode || view.NormalizePath != "/campaigns/cmp-1/locations/normalize" {
+		t.Fatalf("unexpected normalize view: %+v", view)
+	}
+	if len(view.Suggestions) != 2 || view.Suggestions[1].Index != 1 || view.Suggestions[0].LocationID != 7 {
+		t.Fatalf("unexpected suggestions: %+v", view.Suggestions)
+	}
+	if view.Rows[0].MergePath != "/campaigns/cmp-1/locations/7/merge" {
+		t.Fatalf("unexpected merge path: %q", view.Rows[0].MergePath)
+	}
+	if len(view.Targets) != 1 || view.Targets[0].ID != 7 {
+		t.Fatalf("unexpected merge targets: %+v", view.Targets)
+	}
+}
+
+func TestNewLocationsPanelViewShowsAliases(t *testing.T) {
+	view := NewLocationsPanelView(
+		"cmp-1",
+		true,
+		[]service.LocationOption{{ID: 7, Value: "New York City", Aliases: []string{"nyc", "New York, NY"}}},
+		LocationsPanelState{Mode: "edit", EditID: 7},
+		nil,
+	)
+
+	row := view.Rows[0]
+	if row.Aliases != "nyc, New York, NY" {
+		t.Fatalf("unexpected aliases: %q", row.Aliases)
+	}
+	if row.EditAliases != row.Aliases {
+		t.Fatalf("expected edit form to start from saved aliases, got %q", row.EditAliases)
+	}
+}
@@ -0,0 +1,19 @@
+package app
+
+import "net/http"
+
+type LoginPageView struct {
+	Username  string
+	Next      string
+	Error     string
+	CSRFToken string
+}
+
+func (r *Renderer) RenderLoginPage(
+	w http.ResponseWriter,
+	req *http.Request,
+	statusCode int,
+	view LoginPageView,
+) {
+	r.renderTemplate(w, req, statusCode, "login_page", view)
+}
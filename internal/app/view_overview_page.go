@@ -0,0 +1,110 @@
+package app
+
+import (
+	"cosign/internal/service"
+	"fmt"
+	"net/http"
+)
+
+// overviewDays is how far back each overview sparkline reaches.
+const overviewDays = 14
+
+type OverviewRowView struct {
+	ID         string
+	Name       string
+	DetailPath string
+	Total      int
+	Last24h    int
+	Last7d     int
+	Sparkline  SparklineView
+	Error      string
+}
+
+type OverviewPanelView struct {
+	Rows         []OverviewRowView
+	Pagination   PaginationView
+	Error        string
+	PrevPagePath string
+	NextPagePath string
+}
+
+type OverviewPageView struct {
+	Overview OverviewPanelView
+}
+
+// NewOverviewPanelView pairs each campaign with its stats; a campaign whose
+// stats failed to load still gets a row, carrying the error.
+func NewOverviewPanelView(
+	response *service.Campaigns,
+	stats map[string]*service.SignatureStats,
+	statsErrs map[string]error,
+	page int,
+	err error,
+) OverviewPanelView {
+	view := OverviewPanelView{}
+	if err != nil {
+		view.Error = err.Error()
+		return view
+	}
+	if response == nil {
+		view.Error = "failed to load campaigns"
+		return view
+	}
+
+	for _, campaign := range response.Campaigns {
+		if campaign == nil {
+			continue
+		}
+
+		row := OverviewRowView{
+			ID:         campaign.ID,
+			Name:       campaign.Name,
+			DetailPath: campaignDetailPath(campaign.ID),
+		}
+		if statsErr := statsErrs[campaign.ID]; statsErr != nil {
+			row.Error = statsErr.Error()
+		} else if campaignStats := stats[campaign.ID]; campaignStats != nil {
+			row.Total = campaignStats.Total
+			row.Last24h = campaignStats.Last24h
+			row.Last7d = campaignStats.Last7d
+			row.Sparkline = NewSparklineView(campaignStats.Days)
+		}
+		view.Rows = append(view.Rows, row)
+	}
+
+	view.Pagination = NewPaginationView(page, response.Limit, response.Total)
+	if view.Pagination.HasPrev {
+		view.PrevPagePath = overviewPagePath(view.Pagination.PrevPage)
+	}
+	if view.Pagination.HasNext {
+		view.NextPagePath = overviewPagePath(view.Pagination.NextPage)
+	}
+
+	return view
+}
+
+func overviewPagePath(page int) string {
+	if page < 1 {
+		page = 1
+	}
+
+	return fmt.Sprintf("/overview?page=%d", page)
+}
+
+func (r *Renderer) RenderOverviewPage(
+	w http.ResponseWriter,
+	req *http.Request,
+	statusCode int,
+	view OverviewPageView,
+) {
+	r.renderTemplate(w, req, statusCode, "overview_page", view)
+}
+
+func (r *Renderer) RenderOverviewPanel(
+	w http.ResponseWriter,
+	req *http.Request,
+	statusCode int,
+	view OverviewPanelView,
+) {
+	r.renderTemplate(w, req, statusCode, "overview_panel", view)
+}
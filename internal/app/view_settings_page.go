@@ -0,0 +1,17 @@
+package app
+
+import "net/http"
+
+type SettingsPageView struct {
+	Keys APIKeysPanelView
+	CORS CORSPanelView
+}
+
+func (r *Renderer) RenderSettingsPage(
+	w http.ResponseWriter,
+	req *http.Request,
+	statusCode int,
+	view SettingsPageView,
+) {
+	r.renderTemplate(w, req, statusCode, "settings_page", view)
+}
@@ -1,23 +1,47 @@
 package app
 
-import "net/http"
+import (
+	"cosign/internal/service"
+	"net/http"
+	"net/url"
+)
 
+// SignaturesPanelState is what the panel shows besides the signatures
+// themselves. Notice reports a finished bulk action, which UndoID can undo
+// until UndoUntil; Export is an export of selected signatures.
 type SignaturesPanelState struct {
-	Page      int
-	Name      string
-	Email     string
-	Location  string
-	FormError string
+	Page        int
+	Filter      service.SignatureFilter
+	Name        string
+	Email       string
+	Location    string
+	FormError   string
+	FieldErrors map[string]string
+	Notice      string
+	UndoID      string
+	UndoUntil   int64
+	Export      *ExportPanelView
 }
 
 type SignaturesPanelView struct {
-	CampaignID string
-	Name       string
-	Email      string
-	Location   string
-	FormError  string
-	CreatePath string
-	Table      SignaturesTableView
+	CampaignID  string
+	Query       string
+	Visibility  string
+	Name        string
+	Email       string
+	Location    string
+	FormError   string
+	FieldErrors map[string]string
+	Notice      string
+	UndoPath    string
+	UndoUntil   string
+	FilterPath  string
+	CreatePath  string
+	ImportPath  string
+	ExportPath  string
+	TrashPath   string
+	Export      *ExportPanelView
+	Table       SignaturesTableView
 }
 
 func NewSignaturesPanelView(
@@ -25,21 +49,36 @@ func NewSignaturesPanelView(
 	table SignaturesTableView,
 	state SignaturesPanelState,
 ) SignaturesPanelView {
-	return SignaturesPanelView{
-		CampaignID: campaignID,
-		Name:       state.Name,
-		Email:      state.Email,
-		Location:   state.Location,
-		FormError:  state.FormError,
-		CreatePath: campaignDetailPath(campaignID) + "/signatures",
-		Table:      table,
+	view := SignaturesPanelView{
+		CampaignID:  campaignID,
+		Query:       state.Filter.Query,
+		Visibility:  state.Filter.Visibility,
+		Name:        state.Name,
+		Email:       state.Email,
+		Location:    state.Location,
+		FormError:   state.FormError,
+		FieldErrors: state.FieldErrors,
+		Notice:      state.Notice,
+		FilterPath:  campaignDetailPath(campaignID) + "/signatures",
+		CreatePath:  campaignDetailPath(campaignID) + "/signatures",
+		ImportPath:  campaignDetailPath(campaignID) + "/import",
+		ExportPath:  campaignDetailPath(campaignID) + "/export",
+		TrashPath:   campaignTrashPath(campaignID, 1),
+		Export:      state.Export,
+		Table:       table,
 	}
+	if state.UndoID != "" {
+		view.UndoPath = campaignDetailPath(campaignID) + "/signatures/bulk/" + url.PathEscape(state.UndoID) + "/undo"
+		view.UndoUntil = formatUnixTime(state.UndoUntil)
+	}
+	return view
 }
 
 func (r *Renderer) RenderSignaturesPanel(
 	w http.ResponseWriter,
+	req *http.Request,
 	statusCode int,
 	view SignaturesPanelView,
 ) {
-	r.renderTemplate(w, statusCode, "signatures_panel", view)
+	r.renderTemplate(w, req, statusCode, "signatures_panel", view)
 }
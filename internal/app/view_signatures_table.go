@@ -10,12 +10,17 @@ type SignatureRowView struct {
 	Name       string
 	Email      string
 	Location   string
+	SignedAs   string
+	Hidden     bool
 	CreatedAt  string
 	DeletePath string
 }
 
 type SignaturesTableView struct {
 	CampaignID   string
+	Query        string
+	Visibility   string
+	BulkPath     string
 	Signatures   []SignatureRowView
 	Pagination   PaginationView
 	CurrentPage  int
@@ -27,11 +32,15 @@ type SignaturesTableView struct {
 func NewSignaturesTableView(
 	campaignID string,
 	response *service.Signatures,
+	filter service.SignatureFilter,
 	page int,
 	err error,
 ) SignaturesTableView {
 	view := SignaturesTableView{
 		CampaignID:  campaignID,
+		Query:       filter.Query,
+		Visibility:  filter.Visibility,
+		BulkPath:    campaignDetailPath(campaignID) + "/signatures/bulk",
 		CurrentPage: page,
 	}
 
@@ -51,11 +60,18 @@ func NewSignaturesTableView(
 			continue
 		}
 
+		signedAs := ""
+		if signature.LocationRaw != "" && signature.LocationRaw != signature.Location {
+			signedAs = signature.LocationRaw
+		}
+
 		rows = append(rows, SignatureRowView{
 			ID:         signature.ID,
 			Name:       signature.Name,
 			Email:      signature.Email,
 			Location:   signature.Location,
+			SignedAs:   signedAs,
+			Hidden:     signature.Hidden,
 			CreatedAt:  formatUnixTime(signature.CreatedAt),
 			DeletePath: campaignDetailPath(campaignID) + "/signatures/" + itoa64(signature.ID),
 		})
@@ -65,11 +81,11 @@ func NewSignaturesTableView(
 	view.Pagination = NewPaginationView(page, response.Limit, response.Total)
 
 	if view.Pagination.HasPrev {
-		view.PrevPagePath = signaturesPagePath(campaignID, view.Pagination.PrevPage)
+		view.PrevPagePath = signaturesListPath(campaignID, filter, view.Pagination.PrevPage)
 	}
 
 	if view.Pagination.HasNext {
-		view.NextPagePath = signaturesPagePath(campaignID, view.Pagination.NextPage)
+		view.NextPagePath = signaturesListPath(campaignID, filter, view.Pagination.NextPage)
 	}
 
 	return view
@@ -77,8 +93,9 @@ func NewSignaturesTableView(
 
 func (r *Renderer) RenderSignaturesTable(
 	w http.ResponseWriter,
+	req *http.Request,
 	statusCode int,
 	view SignaturesTableView,
 ) {
-	r.renderTemplate(w, statusCode, "signatures_table", view)
+	r.renderTemplate(w, req, statusCode, "signatures_table", view)
 }
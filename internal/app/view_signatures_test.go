@@ -20,7 +20,7 @@ func TestNewSignaturesTableViewBuildsDeleteAndPagerPaths(t *testing.T) {
 		Limit: 10,
 	}
 
-	view := NewSignaturesTableView("cmp-1", response, 2, nil)
+	view := NewSignaturesTableView("cmp-1", response, service.SignatureFilter{}, 2, nil)
 
 	if len(view.Signatures) != 1 {
 		t.Fatalf("expected one signature row, got %d", len(view.Signatures))
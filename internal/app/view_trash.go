@@ -0,0 +1,204 @@
+package app
+
+import (
+	"cosign/internal/service"
+	"net/http"
+	"strconv"
+)
+
+type TrashCampaignRowView struct {
+	ID          string
+	Name        string
+	DeletedAt   string
+	PurgeAt     string
+	RestorePath string
+}
+
+type CampaignTrashPanelView struct {
+	Rows         []TrashCampaignRowView
+	Pagination   PaginationView
+	CurrentPage  int
+	Notice       string
+	FormError    string
+	Error        string
+	PrevPagePath string
+	NextPagePath string
+}
+
+type CampaignTrashPageView struct {
+	Trash CampaignTrashPanelView
+}
+
+// CampaignTrashPanelState carries what a restore leaves on the trash
+// panel.
+type CampaignTrashPanelState struct {
+	Page      int
+	Notice    string
+	FormError string
+}
+
+func NewCampaignTrashPanelView(
+	response *service.Campaigns,
+	state CampaignTrashPanelState,
+	err error,
+) CampaignTrashPanelView {
+	view := CampaignTrashPanelView{
+		CurrentPage: state.Page,
+		Notice:      state.Notice,
+		FormError:   state.FormError,
+	}
+	if err != nil {
+		view.Error = err.Error()
+		return view
+	}
+	if response == nil {
+		view.Error = "failed to load the trash"
+		return view
+	}
+
+	for _, campaign := range response.Campaigns {
+		if campaign == nil {
+			continue
+		}
+		view.Rows = append(view.Rows, TrashCampaignRowView{
+			ID:          campaign.ID,
+			Name:        campaign.Name,
+			DeletedAt:   formatUnixTime(campaign.DeletedAt),
+			PurgeAt:     formatUnixTime(campaign.PurgeAt),
+			RestorePath: campaignDetailPath(campaign.ID) + "/restore",
+		})
+	}
+
+	view.Pagination = NewPaginationView(state.Page, response.Limit, response.Total)
+	if view.Pagination.HasPrev {
+		view.PrevPagePath = trashPagePath(view.Pagination.PrevPage)
+	}
+	if view.Pagination.HasNext {
+		view.NextPagePath = trashPagePath(view.Pagination.NextPage)
+	}
+
+	return view
+}
+
+type TrashSignatureRowView struct {
+	ID          int64
+	Name        string
+	Email       string
+	Location    string
+	DeletedAt   string
+	PurgeAt     string
+	RestorePath string
+}
+
+type SignatureTrashPanelView struct {
+	CampaignID   string
+	CampaignName string
+	DetailPath   string
+	Rows         []TrashSignatureRowView
+	Pagination   PaginationView
+	CurrentPage  int
+	Notice       string
+	FormError    string
+	Error        string
+	PrevPagePath string
+	NextPagePath string
+}
+
+type SignatureTrashPageView struct {
+	Trash SignatureTrashPanelView
+}
+
+// SignatureTrashPanelState carries what a restore leaves on a campaign's
+// trash panel.
+type SignatureTrashPanelState struct {
+	Page      int
+	Notice    string
+	FormError string
+}
+
+func NewSignatureTrashPanelView(
+	campaign *service.Campaign,
+	response *service.Signatures,
+	state SignatureTrashPanelState,
+	err error,
+) SignatureTrashPanelView {
+	view := SignatureTrashPanelView{
+		CurrentPage: state.Page,
+		Notice:      state.Notice,
+		FormError:   state.FormError,
+	}
+	if campaign != nil {
+		view.CampaignID = campaign.ID
+		view.CampaignName = campaign.Name
+		view.DetailPath = campaignDetailPath(campaign.ID)
+	}
+	if err != nil {
+		view.Error = err.Error()
+		return view
+	}
+	if campaign == nil || response == nil {
+		view.Error = "failed to load the trash"
+		return view
+	}
+
+	for _, signature := range response.Signatures {
+		if signature == nil {
+			continue
+		}
+		view.Rows = append(view.Rows, TrashSignatureRowView{
+			ID:          signature.ID,
+			Name:        signature.Name,
+			Email:       signature.Email,
+			Location:    signature.Location,
+			DeletedAt:   formatUnixTime(signature.DeletedAt),
+			PurgeAt:     formatUnixTime(signature.PurgeAt),
+			RestorePath: campaignDetailPath(campaign.ID) + "/signatures/" + strconv.FormatInt(signature.ID, 10) + "/restore",
+		})
+	}
+
+	view.Pagination = NewPaginationView(state.Page, response.Limit, response.Total)
+	if view.Pagination.HasPrev {
+		view.PrevPagePath = campaignTrashPath(campaign.ID, view.Pagination.PrevPage)
+	}
+	if view.Pagination.HasNext {
+		view.NextPagePath = campaignTrashPath(campaign.ID, view.Pagination.NextPage)
+	}
+
+	return view
+}
+
+func (r *Renderer) RenderCampaignTrashPage(
+	w http.ResponseWriter,
+	req *http.Request,
+	statusCode int,
+	view CampaignTrashPageView,
+) {
+	r.renderTemplate(w, req, statusCode, "campaign_trash_page", view)
+}
+
+func (r *Renderer) RenderCampaignTrashPanel(
+	w http.ResponseWriter,
+	req *http.Request,
+	statusCode int,
+	view CampaignTrashPanelView,
+) {
+	r.renderTemplate(w, req, statusCode, "campaign_trash_panel", view)
+}
+
+func (r *Renderer) RenderSignatureTrashPage(
+	w http.ResponseWriter,
+	req *http.Request,
+	statusCode int,
+	view SignatureTrashPageView,
+) {
+	r.renderTemplate(w, req, statusCode, "signature_trash_page", view)
+}
+
+func (r *Renderer) RenderSignatureTrashPanel(
+	w http.ResponseWriter,
+	req *http.Request,
+	statusCode int,
+	view SignatureTrashPanelView,
+) {
+	r.renderTemplate(w, req, statusCode, "signature_trash_panel", view)
+}
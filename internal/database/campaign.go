@@ -38,28 +38,27 @@ func (db *DB) GetCampaign(
 	error,
 ) {
 	row := db.Conn.QueryRow(`
-		SELECT id, name, allow_custom_text, created_at
+		SELECT `+campaignColumns+`
 		FROM campaigns
-		WHERE id = ?1`,
+		WHERE id = ?1 AND deleted_at = 0`,
 		id,
 	)
 
-	var campaign service.Campaign
-	var allowInt int
-	if err := row.Scan(
-		&campaign.ID,
-		&campaign.Name,
-		&allowInt,
-		&campaign.CreatedAt,
-	); err != nil {
+	campaign, err := scanCampaign(row)
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, service.ErrCampaignNotFound
 		}
 		return nil, fmt.Errorf("get campaign: %w", err)
 	}
 
-	campaign.AllowCustomText = allowInt == 1
-	return &campaign, nil
+	translations, err := getCampaignTranslations(db.Conn, id)
+	if err != nil {
+		return nil, err
+	}
+	campaign.Translations = translations
+
+	return campaign, nil
 }
 
 func (db *DB) ListCampaigns(
@@ -70,8 +69,9 @@ func (db *DB) ListCampaigns(
 	error,
 ) {
 	rows, err := db.Conn.Query(`
-		SELECT id, name, allow_custom_text, created_at
+		SELECT `+campaignColumns+`
 		FROM campaigns
+		WHERE deleted_at = 0
 		ORDER BY created_at DESC
 		LIMIT ?1 OFFSET ?2`,
 		limit,
@@ -84,18 +84,11 @@ func (db *DB) ListCampaigns(
 
 	var campaigns []*service.Campaign
 	for rows.Next() {
-		var campaign service.Campaign
-		var allowInt int
-		if err := rows.Scan(
-			&campaign.ID,
-			&campaign.Name,
-			&allowInt,
-			&campaign.CreatedAt,
-		); err != nil {
+		campaign, err := scanCampaign(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan campaign: %w", err)
 		}
-		campaign.AllowCustomText = allowInt == 1
-		campaigns = append(campaigns, &campaign)
+		campaigns = append(campaigns, campaign)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -111,7 +104,8 @@ func (db *DB) CountCampaigns() (
 ) {
 	row := db.Conn.QueryRow(`
 		SELECT COUNT(*)
-		FROM campaigns`,
+		FROM campaigns
+		WHERE deleted_at = 0`,
 	)
 
 	var count int
@@ -121,24 +115,51 @@ func (db *DB) CountCampaigns() (
 	return count, nil
 }
 
+// UpdateCampaign writes the campaign and advances its revision. A non-zero
+// campaign.Revision must still be current, or ErrRevisionConflict is
+// returned.
 func (db *DB) UpdateCampaign(
-	id string,
-	name string,
-	allowCustomText bool,
+	campaign service.Campaign,
 ) error {
 	allowInt := 0
-	if allowCustomText {
+	if campaign.AllowCustomText {
 		allowInt = 1
 	}
 
-	result, err := db.Conn.Exec(`
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin update campaign transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
 		UPDATE campaigns
 		SET name = ?1,
-			allow_custom_text = ?2
-		WHERE id = ?3`,
-		name,
+			allow_custom_text = ?2,
+			letter = ?3,
+			theme_primary_color = ?4,
+			theme_background_color = ?5,
+			theme_logo_url = ?6,
+			success_url = ?7,
+			error_url = ?8,
+			goal = ?9,
+			language = ?10,
+			location_level = ?11,
+			revision = revision + 1
+		WHERE id = ?12 AND deleted_at = 0 AND (?13 = 0 OR revision = ?13)`,
+		campaign.Name,
 		allowInt,
-		id,
+		campaign.Letter,
+		campaign.Theme.PrimaryColor,
+		campaign.Theme.BackgroundColor,
+		campaign.Theme.LogoURL,
+		campaign.SuccessURL,
+		campaign.ErrorURL,
+		campaign.Goal,
+		campaign.Language,
+		campaign.LocationLevel,
+		campaign.ID,
+		campaign.Revision,
 	)
 	if err != nil {
 		return fmt.Errorf("update campaign: %w", err)
@@ -149,19 +170,35 @@ func (db *DB) UpdateCampaign(
 		return fmt.Errorf("rows affected for campaign update: %w", err)
 	}
 	if rowsAffected == 0 {
-		return service.ErrCampaignNotFound
+		if err := verifyCampaignExists(tx, campaign.ID); err != nil {
+			return err
+		}
+		return service.ErrRevisionConflict
+	}
+
+	if err := replaceCampaignTranslations(tx, campaign.ID, campaign.Translations); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit update campaign: %w", err)
 	}
 
 	return nil
 }
 
+// DeleteCampaign moves the campaign to the trash. Its signatures stay
+// until PurgeDeleted removes the campaign for good.
 func (db *DB) DeleteCampaign(
 	id string,
+	deletedAt int64,
 ) error {
 	result, err := db.Conn.Exec(`
-		DELETE FROM campaigns
-		WHERE id = ?1`,
+		UPDATE campaigns
+		SET deleted_at = ?2
+		WHERE id = ?1 AND deleted_at = 0`,
 		id,
+		deletedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("delete campaign: %w", err)
@@ -178,107 +215,115 @@ func (db *DB) DeleteCampaign(
 	return nil
 }
 
-func (db *DB) GetCampaignLocations(
-	campaignID string,
-) (
-	[]*service.LocationOption,
-	error,
-) {
-	row := db.Conn.QueryRow(`
-		SELECT COUNT(*)
-		FROM campaigns
-		WHERE id = ?1`,
-		campaignID,
+func (db *DB) RestoreCampaign(
+	id string,
+) error {
+	result, err := db.Conn.Exec(`
+		UPDATE campaigns
+		SET deleted_at = 0
+		WHERE id = ?1 AND deleted_at != 0`,
+		id,
 	)
+	if err != nil {
+		return fmt.Errorf("restore campaign: %w", err)
+	}
 
-	var exists int
-	if err := row.Scan(&exists); err != nil {
-		return nil, fmt.Errorf("verify campaign exists: %w", err)
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected for campaign restore: %w", err)
 	}
-	if exists == 0 {
-		return nil, service.ErrCampaignNotFound
+	if rowsAffected == 0 {
+		return service.ErrCampaignNotFound
 	}
 
+	return nil
+}
+
+func (db *DB) ListDeletedCampaigns(
+	limit int,
+	offset int,
+) (
+	[]*service.Campaign,
+	error,
+) {
 	rows, err := db.Conn.Query(`
-		SELECT id, value, display_order
-		FROM locations
-		WHERE campaign_id = ?1
-		ORDER BY display_order ASC`,
-		campaignID,
+		SELECT `+campaignColumns+`
+		FROM campaigns
+		WHERE deleted_at != 0
+		ORDER BY deleted_at DESC, id ASC
+		LIMIT ?1 OFFSET ?2`,
+		limit,
+		offset,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("get campaign locations: %w", err)
+		return nil, fmt.Errorf("list deleted campaigns: %w", err)
 	}
 	defer rows.Close()
 
-	var options []*service.LocationOption
+	var campaigns []*service.Campaign
 	for rows.Next() {
-		var opt service.LocationOption
-		if err := rows.Scan(
-			&opt.ID,
-			&opt.Value,
-			&opt.DisplayOrder,
-		); err != nil {
-			return nil, fmt.Errorf("scan campaign location: %w", err)
+		campaign, err := scanCampaign(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan deleted campaign: %w", err)
 		}
-		options = append(options, &opt)
+		campaigns = append(campaigns, campaign)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate campaign locations: %w", err)
+		return nil, fmt.Errorf("iterate deleted campaigns: %w", err)
 	}
 
-	return options, nil
+	return campaigns, nil
 }
 
-func (db *DB) ReplaceCampaignLocations(
-	campaignID string,
-	options []service.LocationOption,
-) error {
-	tx, err := db.Conn.Begin()
-	if err != nil {
-		return fmt.Errorf("begin replace locations transaction: %w", err)
-	}
-	defer tx.Rollback()
-
-	row := tx.QueryRow(`
+func (db *DB) CountDeletedCampaigns() (
+	int,
+	error,
+) {
+	row := db.Conn.QueryRow(`
 		SELECT COUNT(*)
 		FROM campaigns
-		WHERE id = ?1`,
-		campaignID,
+		WHERE deleted_at != 0`,
 	)
 
-	var exists int
-	if err := row.Scan(&exists); err != nil {
-		return fmt.Errorf("verify campaign exists: %w", err)
-	}
-	if exists == 0 {
-		return service.ErrCampaignNotFound
-	}
-
-	if _, err := tx.Exec(`
-		DELETE FROM locations
-		WHERE campaign_id = ?1`,
-		campaignID,
-	); err != nil {
-		return fmt.Errorf("clear campaign locations: %w", err)
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return 0, fmt.Errorf("count deleted campaigns: %w", err)
 	}
+	return count, nil
+}
 
-	for _, opt := range options {
-		if _, err := tx.Exec(`
-			INSERT INTO locations (campaign_id, value, display_order)
-			VALUES (?1, ?2, ?3)`,
-			campaignID,
-			opt.Value,
-			opt.DisplayOrder,
-		); err != nil {
-			return fmt.Errorf("insert campaign location: %w", err)
-		}
-	}
+const campaignColumns = `id, name, letter, allow_custom_text, theme_primary_color, theme_background_color, theme_logo_url, success_url, error_url, goal, language, location_level, created_at, revision, locations_revision, deleted_at`
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit replace locations: %w", err)
+func scanCampaign(
+	row rowScanner,
+) (
+	*service.Campaign,
+	error,
+) {
+	var campaign service.Campaign
+	var allowInt int
+	if err := row.Scan(
+		&campaign.ID,
+		&campaign.Name,
+		&campaign.Letter,
+		&allowInt,
+		&campaign.Theme.PrimaryColor,
+		&campaign.Theme.BackgroundColor,
+		&campaign.Theme.LogoURL,
+		&campaign.SuccessURL,
+		&campaign.ErrorURL,
+		&campaign.Goal,
+		&campaign.Language,
+		&campaign.LocationLevel,
+		&campaign.CreatedAt,
+		&campaign.Revision,
+		&campaign.LocationsRevision,
+		&campaign.DeletedAt,
+	); err != nil {
+		return nil, err
 	}
 
-	return nil
+	campaign.AllowCustomText = allowInt == 1
+	return &campaign, nil
 }
@@ -0,0 +1,300 @@
+package database
+
+import (
+	"cosign/internal/app"
+	"database/sql"
+	"fmt"
+)
+
+func (db *DB) InsertDashboardUser(
+	username string,
+	passwordHash string,
+	createdAt int64,
+) (
+	int64,
+	error,
+) {
+	result, err := db.Conn.Exec(`
+		INSERT INTO dashboard_users (username, password_hash, created_at)
+		VALUES (?1, ?2, ?3)
+		ON CONFLICT(username) DO NOTHING`,
+		username,
+		passwordHash,
+		createdAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert dashboard user: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected for dashboard user insert: %w", err)
+	}
+	if rowsAffected == 0 {
+		return 0, app.ErrUserExists
+	}
+
+	return result.LastInsertId()
+}
+
+func (db *DB) GetDashboardUser(
+	username string,
+) (
+	*app.User,
+	error,
+) {
+	row := db.Conn.QueryRow(`
+		SELECT id, username, password_hash, created_at
+		FROM dashboard_users
+		WHERE username = ?1`,
+		username,
+	)
+
+	var user app.User
+	if err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, app.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("get dashboard user: %w", err)
+	}
+	return &user, nil
+}
+
+func (db *DB) ListDashboardUsers() (
+	[]*app.User,
+	error,
+) {
+	rows, err := db.Conn.Query(`
+		SELECT id, username, password_hash, created_at
+		FROM dashboard_users
+		ORDER BY username ASC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list dashboard users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*app.User{}
+	for rows.Next() {
+		var user app.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan dashboard user: %w", err)
+		}
+		users = append(users, &user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate dashboard users: %w", err)
+	}
+	return users, nil
+}
+
+func (db *DB) UpdateDashboardUserPassword(
+	username string,
+	passwordHash string,
+) error {
+	result, err := db.Conn.Exec(`
+		UPDATE dashboard_users
+		SET password_hash = ?2
+		WHERE username = ?1`,
+		username,
+		passwordHash,
+	)
+	if err != nil {
+		return fmt.Errorf("update dashboard user password: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected for dashboard user password update: %w", err)
+	}
+	if rowsAffected == 0 {
+		return app.ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (db *DB) DeleteDashboardUser(
+	username string,
+) error {
+	result, err := db.Conn.Exec(`
+		DELETE FROM dashboard_users
+		WHERE username = ?1`,
+		username,
+	)
+	if err != nil {
+		return fmt.Errorf("delete dashboard user: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected for dashboard user delete: %w", err)
+	}
+	if rowsAffected == 0 {
+		return app.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// SetDashboardUserRole grants role to the user on campaignID, or globally
+// when campaignID is empty, replacing any role they held there.
+func (db *DB) SetDashboardUserRole(
+	userID int64,
+	campaignID string,
+	role app.Role,
+) error {
+	_, err := db.Conn.Exec(`
+		INSERT INTO dashboard_user_roles (user_id, campaign_id, role)
+		VALUES (?1, ?2, ?3)
+		ON CONFLICT(user_id, campaign_id) DO UPDATE SET role = excluded.role`,
+		userID,
+		campaignID,
+		string(role),
+	)
+	if err != nil {
+		return fmt.Errorf("set dashboard user role: %w", err)
+	}
+	return nil
+}
+
+func (db *DB) DeleteDashboardUserRole(
+	userID int64,
+	campaignID string,
+) error {
+	_, err := db.Conn.Exec(`
+		DELETE FROM dashboard_user_roles
+		WHERE user_id = ?1 AND campaign_id = ?2`,
+		userID,
+		campaignID,
+	)
+	if err != nil {
+		return fmt.Errorf("delete dashboard user role: %w", err)
+	}
+	return nil
+}
+
+func (db *DB) ListDashboardUserRoles(
+	userID int64,
+) (
+	[]app.RoleAssignment,
+	error,
+) {
+	rows, err := db.Conn.Query(`
+		SELECT campaign_id, role
+		FROM dashboard_user_roles
+		WHERE user_id = ?1
+		ORDER BY campaign_id ASC`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list dashboard user roles: %w", err)
+	}
+	defer rows.Close()
+
+	roles := []app.RoleAssignment{}
+	for rows.Next() {
+		var assignment app.RoleAssignment
+		var role string
+		if err := rows.Scan(&assignment.CampaignID, &role); err != nil {
+			return nil, fmt.Errorf("scan dashboard user role: %w", err)
+		}
+		assignment.Role = app.Role(role)
+		roles = append(roles, assignment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate dashboard user roles: %w", err)
+	}
+	return roles, nil
+}
+
+func (db *DB) InsertDashboardSession(
+	session app.Session,
+) error {
+	_, err := db.Conn.Exec(`
+		INSERT INTO dashboard_sessions (id, user_id, csrf_token, created_at, expires_at)
+		VALUES (?1, ?2, ?3, ?4, ?5)`,
+		session.ID,
+		session.UserID,
+		session.CSRFToken,
+		session.CreatedAt,
+		session.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert dashboard session: %w", err)
+	}
+	return nil
+}
+
+func (db *DB) GetDashboardSession(
+	id string,
+) (
+	*app.Session,
+	error,
+) {
+	row := db.Conn.QueryRow(`
+		SELECT s.id, s.user_id, u.username, s.csrf_token, s.created_at, s.expires_at
+		FROM dashboard_sessions s
+		JOIN dashboard_users u ON u.id = s.user_id
+		WHERE s.id = ?1`,
+		id,
+	)
+
+	var session app.Session
+	if err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.Username,
+		&session.CSRFToken,
+		&session.CreatedAt,
+		&session.ExpiresAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, app.ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("get dashboard session: %w", err)
+	}
+	return &session, nil
+}
+
+func (db *DB) DeleteDashboardSession(
+	id string,
+) error {
+	_, err := db.Conn.Exec(`
+		DELETE FROM dashboard_sessions
+		WHERE id = ?1`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("delete dashboard session: %w", err)
+	}
+	return nil
+}
+
+func (db *DB) DeleteDashboardUserSessions(
+	userID int64,
+) error {
+	_, err := db.Conn.Exec(`
+		DELETE FROM dashboard_sessions
+		WHERE user_id = ?1`,
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("delete dashboard user sessions: %w", err)
+	}
+	return nil
+}
+
+func (db *DB) DeleteExpiredDashboardSessions(
+	now int64,
+) error {
+	_, err := db.Conn.Exec(`
+		DELETE FROM dashboard_sessions
+		WHERE expires_at <= ?1`,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("delete expired dashboard sessions: %w", err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"git.sr.ht/~jakintosh/command-go/pkg/cors"
@@ -18,6 +19,8 @@ type DB struct {
 	Conn      *sql.DB
 	KeysStore *keys.SQLStore
 	CORSStore *cors.SQLStore
+
+	wal bool
 }
 
 type migration struct {
@@ -58,6 +61,288 @@ var migrations = []migration{
 			CREATE INDEX IF NOT EXISTS idx_signatures_campaign_created ON signatures(campaign_id, created_at);
 		`,
 	},
+	{
+		version: 2,
+		sql: `
+			CREATE TABLE IF NOT EXISTS webhooks (
+				id TEXT PRIMARY KEY,
+				campaign_id TEXT REFERENCES campaigns(id) ON DELETE CASCADE,
+				url TEXT NOT NULL,
+				secret TEXT NOT NULL,
+				events TEXT NOT NULL,
+				created_at INTEGER NOT NULL
+			);
+			CREATE INDEX IF NOT EXISTS idx_webhooks_campaign ON webhooks(campaign_id);
+
+			CREATE TABLE IF NOT EXISTS webhook_deliveries (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				webhook_id TEXT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+				event TEXT NOT NULL,
+				payload TEXT NOT NULL,
+				status TEXT NOT NULL,
+				attempts INTEGER NOT NULL DEFAULT 0,
+				next_attempt_at INTEGER NOT NULL,
+				last_status_code INTEGER NOT NULL DEFAULT 0,
+				last_error TEXT NOT NULL DEFAULT '',
+				created_at INTEGER NOT NULL,
+				updated_at INTEGER NOT NULL
+			);
+			CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+			CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at);
+		`,
+	},
+	{
+		version: 3,
+		sql: `
+			ALTER TABLE campaigns ADD COLUMN letter TEXT NOT NULL DEFAULT '';
+			ALTER TABLE campaigns ADD COLUMN theme_primary_color TEXT NOT NULL DEFAULT '';
+			ALTER TABLE campaigns ADD COLUMN theme_background_color TEXT NOT NULL DEFAULT '';
+			ALTER TABLE campaigns ADD COLUMN theme_logo_url TEXT NOT NULL DEFAULT '';
+		`,
+	},
+	{
+		version: 4,
+		sql: `
+			ALTER TABLE campaigns ADD COLUMN success_url TEXT NOT NULL DEFAULT '';
+			ALTER TABLE campaigns ADD COLUMN error_url TEXT NOT NULL DEFAULT '';
+		`,
+	},
+	{
+		version: 5,
+		sql: `
+			ALTER TABLE campaigns ADD COLUMN goal INTEGER NOT NULL DEFAULT 0;
+		`,
+	},
+	{
+		version: 6,
+		sql: `
+			ALTER TABLE campaigns ADD COLUMN language TEXT NOT NULL DEFAULT 'en';
+
+			CREATE TABLE IF NOT EXISTS campaign_translations (
+				campaign_id TEXT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+				lang TEXT NOT NULL,
+				name TEXT NOT NULL DEFAULT '',
+				letter TEXT NOT NULL DEFAULT '',
+				PRIMARY KEY (campaign_id, lang)
+			);
+
+			CREATE TABLE IF NOT EXISTS location_translations (
+				campaign_id TEXT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+				value TEXT NOT NULL,
+				lang TEXT NOT NULL,
+				label TEXT NOT NULL,
+				PRIMARY KEY (campaign_id, value, lang)
+			);
+		`,
+	},
+	{
+		version: 7,
+		sql: `
+			ALTER TABLE campaigns ADD COLUMN location_level INTEGER NOT NULL DEFAULT 0;
+
+			ALTER TABLE locations ADD COLUMN parent_id INTEGER REFERENCES locations(id) ON DELETE CASCADE;
+			ALTER TABLE locations ADD COLUMN country_code TEXT NOT NULL DEFAULT '';
+			ALTER TABLE locations ADD COLUMN subdivision_code TEXT NOT NULL DEFAULT '';
+			CREATE INDEX IF NOT EXISTS idx_locations_parent ON locations(parent_id);
+
+			CREATE INDEX IF NOT EXISTS idx_signatures_campaign_location ON signatures(campaign_id, location);
+		`,
+	},
+	{
+		version: 8,
+		sql: `
+			CREATE TABLE IF NOT EXISTS location_labels (
+				location_id INTEGER NOT NULL REFERENCES locations(id) ON DELETE CASCADE,
+				lang TEXT NOT NULL,
+				label TEXT NOT NULL,
+				PRIMARY KEY (location_id, lang)
+			);
+
+			INSERT INTO location_labels (location_id, lang, label)
+			SELECT l.id, t.lang, t.label
+			FROM location_translations t
+			JOIN locations l ON l.campaign_id = t.campaign_id AND l.value = t.value;
+
+			DROP TABLE location_translations;
+		`,
+	},
+	{
+		version: 9,
+		sql: `
+			CREATE TABLE IF NOT EXISTS location_aliases (
+				location_id INTEGER NOT NULL REFERENCES locations(id) ON DELETE CASCADE,
+				alias TEXT NOT NULL,
+				PRIMARY KEY (location_id, alias)
+			);
+
+			ALTER TABLE signatures ADD COLUMN location_raw TEXT NOT NULL DEFAULT '';
+		`,
+	},
+	{
+		version: 10,
+		sql: `
+			ALTER TABLE locations ADD COLUMN latitude REAL;
+			ALTER TABLE locations ADD COLUMN longitude REAL;
+		`,
+	},
+	{
+		version: 11,
+		sql: `
+			ALTER TABLE campaigns ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;
+			ALTER TABLE campaigns ADD COLUMN locations_revision INTEGER NOT NULL DEFAULT 1;
+		`,
+	},
+	{
+		version: 12,
+		sql: `
+			CREATE TABLE idempotency_keys (
+				key TEXT PRIMARY KEY,
+				fingerprint TEXT NOT NULL,
+				status INTEGER NOT NULL DEFAULT 0,
+				headers TEXT NOT NULL DEFAULT '',
+				body BLOB,
+				created_at INTEGER NOT NULL,
+				expires_at INTEGER NOT NULL
+			);
+
+			CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+		`,
+	},
+	{
+		version: 13,
+		sql: `
+			CREATE TABLE dashboard_users (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				username TEXT NOT NULL UNIQUE,
+				password_hash TEXT NOT NULL,
+				created_at INTEGER NOT NULL
+			);
+
+			CREATE TABLE dashboard_sessions (
+				id TEXT PRIMARY KEY,
+				user_id INTEGER NOT NULL REFERENCES dashboard_users(id) ON DELETE CASCADE,
+				csrf_token TEXT NOT NULL,
+				created_at INTEGER NOT NULL,
+				expires_at INTEGER NOT NULL
+			);
+
+			CREATE INDEX idx_dashboard_sessions_user ON dashboard_sessions(user_id);
+			CREATE INDEX idx_dashboard_sessions_expires_at ON dashboard_sessions(expires_at);
+		`,
+	},
+	{
+		version: 14,
+		sql: `
+			CREATE TABLE dashboard_user_roles (
+				user_id INTEGER NOT NULL REFERENCES dashboard_users(id) ON DELETE CASCADE,
+				campaign_id TEXT NOT NULL DEFAULT '',
+				role TEXT NOT NULL,
+				PRIMARY KEY (user_id, campaign_id)
+			);
+
+			INSERT INTO dashboard_user_roles (user_id, campaign_id, role)
+			SELECT id, '', 'owner' FROM dashboard_users;
+		`,
+	},
+	{
+		version: 15,
+		sql: `
+			ALTER TABLE signatures ADD COLUMN hidden INTEGER NOT NULL DEFAULT 0;
+
+			CREATE TABLE signature_bulk_operations (
+				id TEXT PRIMARY KEY,
+				campaign_id TEXT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+				action TEXT NOT NULL,
+				signatures TEXT NOT NULL,
+				created_at INTEGER NOT NULL,
+				expires_at INTEGER NOT NULL
+			);
+
+			CREATE INDEX idx_signature_bulk_operations_expires_at ON signature_bulk_operations(expires_at);
+		`,
+	},
+	{
+		version: 16,
+		sql: `
+			ALTER TABLE campaigns ADD COLUMN deleted_at INTEGER NOT NULL DEFAULT 0;
+			ALTER TABLE signatures ADD COLUMN deleted_at INTEGER NOT NULL DEFAULT 0;
+
+			CREATE INDEX idx_campaigns_deleted_at ON campaigns(deleted_at);
+			CREATE INDEX idx_signatures_deleted_at ON signatures(deleted_at);
+		`,
+	},
+	{
+		// SQLite cannot drop a table constraint, so the table is rebuilt to
+		// hold emails unique among active signatures only; trashed ones stay
+		// until the sweeper purges them. The id sequence is carried over so
+		// purged ids are not handed out again.
+		version: 17,
+		sql: `
+			CREATE TABLE signatures_new (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				campaign_id TEXT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+				name TEXT NOT NULL,
+				email TEXT NOT NULL,
+				location TEXT NOT NULL,
+				created_at INTEGER NOT NULL,
+				location_raw TEXT NOT NULL DEFAULT '',
+				hidden INTEGER NOT NULL DEFAULT 0,
+				deleted_at INTEGER NOT NULL DEFAULT 0
+			);
+
+			INSERT INTO signatures_new (id, campaign_id, name, email, location, created_at, location_raw, hidden, deleted_at)
+			SELECT id, campaign_id, name, email, location, created_at, location_raw, hidden, deleted_at
+			FROM signatures;
+
+			DELETE FROM sqlite_sequence WHERE name = 'signatures_new';
+			INSERT INTO sqlite_sequence (name, seq)
+			SELECT 'signatures_new', seq FROM sqlite_sequence WHERE name = 'signatures';
+
+			DROP TABLE signatures;
+			ALTER TABLE signatures_new RENAME TO signatures;
+
+			CREATE INDEX idx_signatures_campaign ON signatures(campaign_id);
+			CREATE INDEX idx_signatures_campaign_created ON signatures(campaign_id, created_at);
+			CREATE INDEX idx_signatures_campaign_location ON signatures(campaign_id, location);
+			CREATE INDEX idx_signatures_deleted_at ON signatures(deleted_at);
+			CREATE UNIQUE INDEX idx_signatures_campaign_email ON signatures(campaign_id, email) WHERE deleted_at = 0;
+		`,
+	},
+	{
+		version: 18,
+		sql: `
+			CREATE TABLE campaign_milestones (
+				campaign_id TEXT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+				milestone INTEGER NOT NULL,
+				reached_at INTEGER NOT NULL,
+				PRIMARY KEY (campaign_id, milestone)
+			);
+		`,
+	},
+	{
+		// Keys are scoped to the route they were sent to. Existing keys
+		// cannot be assigned a route, and they expire within a day anyway,
+		// so the table is recreated empty.
+		version: 19,
+		sql: `
+			DROP TABLE idempotency_keys;
+
+			CREATE TABLE idempotency_keys (
+				key TEXT NOT NULL,
+				method TEXT NOT NULL,
+				path TEXT NOT NULL,
+				fingerprint TEXT NOT NULL,
+				status INTEGER NOT NULL DEFAULT 0,
+				headers TEXT NOT NULL DEFAULT '',
+				body BLOB,
+				created_at INTEGER NOT NULL,
+				expires_at INTEGER NOT NULL,
+				PRIMARY KEY (key, method, path)
+			);
+
+			CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+		`,
+	},
 }
 
 func Open(
@@ -97,14 +382,24 @@ func Open(
 		Conn:      conn,
 		KeysStore: keyStore,
 		CORSStore: corsStore,
+		wal:       opts.WAL,
 	}, nil
 }
 
+// Close checkpoints the write-ahead log into the database file, so a clean
+// shutdown leaves no -wal file behind, and closes the connection.
 func (db *DB) Close() error {
 	if db == nil || db.Conn == nil {
 		return nil
 	}
-	return db.Conn.Close()
+
+	var checkpointErr error
+	if db.wal {
+		if _, err := db.Conn.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+			checkpointErr = fmt.Errorf("checkpoint wal: %w", err)
+		}
+	}
+	return errors.Join(checkpointErr, db.Conn.Close())
 }
 
 func configure(
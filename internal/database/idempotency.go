@@ -0,0 +1,162 @@
+package database
+
+import (
+	"cosign/internal/service"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// ClaimIdempotencyKey drops expired keys and then records the key as in
+// flight for its route. It reports false when an unexpired record already
+// holds the key on that route.
+func (db *DB) ClaimIdempotencyKey(
+	key string,
+	method string,
+	path string,
+	fingerprint string,
+	createdAt int64,
+	expiresAt int64,
+) (
+	bool,
+	error,
+) {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return false, fmt.Errorf("begin claim idempotency key transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		DELETE FROM idempotency_keys
+		WHERE expires_at <= ?1`,
+		createdAt,
+	); err != nil {
+		return false, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO idempotency_keys (key, method, path, fingerprint, created_at, expires_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+		ON CONFLICT(key, method, path) DO NOTHING`,
+		key,
+		method,
+		path,
+		fingerprint,
+		createdAt,
+		expiresAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("claim idempotency key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("rows affected for idempotency key claim: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit claim idempotency key: %w", err)
+	}
+
+	return rowsAffected == 1, nil
+}
+
+func (db *DB) GetIdempotencyKey(
+	key string,
+	method string,
+	path string,
+) (
+	*service.IdempotencyRecord,
+	error,
+) {
+	row := db.Conn.QueryRow(`
+		SELECT key, method, path, fingerprint, status, headers, body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE key = ?1 AND method = ?2 AND path = ?3`,
+		key,
+		method,
+		path,
+	)
+
+	var record service.IdempotencyRecord
+	var headers string
+	if err := row.Scan(
+		&record.Key,
+		&record.Method,
+		&record.Path,
+		&record.Fingerprint,
+		&record.Status,
+		&headers,
+		&record.Body,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, service.ErrIdempotencyKeyNotFound
+		}
+		return nil, fmt.Errorf("get idempotency key: %w", err)
+	}
+
+	if headers != "" {
+		if err := json.Unmarshal([]byte(headers), &record.Headers); err != nil {
+			return nil, fmt.Errorf("decode idempotency headers: %w", err)
+		}
+	}
+
+	return &record, nil
+}
+
+// CompleteIdempotencyKey stores the response for a claimed key.
+func (db *DB) CompleteIdempotencyKey(
+	record service.IdempotencyRecord,
+) error {
+	headers, err := json.Marshal(record.Headers)
+	if err != nil {
+		return fmt.Errorf("encode idempotency headers: %w", err)
+	}
+
+	result, err := db.Conn.Exec(`
+		UPDATE idempotency_keys
+		SET status = ?1,
+			headers = ?2,
+			body = ?3
+		WHERE key = ?4 AND method = ?5 AND path = ?6`,
+		record.Status,
+		string(headers),
+		record.Body,
+		record.Key,
+		record.Method,
+		record.Path,
+	)
+	if err != nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected for idempotency key completion: %w", err)
+	}
+	if rowsAffected == 0 {
+		return service.ErrIdempotencyKeyNotFound
+	}
+
+	return nil
+}
+
+func (db *DB) DeleteIdempotencyKey(
+	key string,
+	method string,
+	path string,
+) error {
+	if _, err := db.Conn.Exec(`
+		DELETE FROM idempotency_keys
+		WHERE key = ?1 AND method = ?2 AND path = ?3`,
+		key,
+		method,
+		path,
+	); err != nil {
+		return fmt.Errorf("delete idempotency key: %w", err)
+	}
+	return nil
+}
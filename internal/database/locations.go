@@ -0,0 +1,866 @@
+package database
+
+import (
+	"cosign/internal/service"
+	"database/sql"
+	"fmt"
+)
+
+const locationColumns = `l.id, l.value, l.display_order, COALESCE(l.parent_id, 0), COALESCE(p.value, ''), l.country_code, l.subdivision_code, l.latitude, l.longitude`
+
+func (db *DB) GetCampaignLocations(
+	campaignID string,
+) (
+	[]*service.LocationOption,
+	error,
+) {
+	if err := verifyCampaignExists(db.Conn, campaignID); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Conn.Query(`
+		SELECT `+locationColumns+`
+		FROM locations l
+		LEFT JOIN locations p ON p.id = l.parent_id
+		WHERE l.campaign_id = ?1
+		ORDER BY l.display_order ASC, l.id ASC`,
+		campaignID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get campaign locations: %w", err)
+	}
+	defer rows.Close()
+
+	var options []*service.LocationOption
+	for rows.Next() {
+		opt, err := scanLocation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan campaign location: %w", err)
+		}
+		options = append(options, opt)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate campaign locations: %w", err)
+	}
+
+	labels, err := getLocationLabels(db.Conn, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := getLocationAliases(db.Conn, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	for _, opt := range options {
+		opt.Labels = labels[opt.ID]
+		opt.Aliases = aliases[opt.ID]
+	}
+
+	return options, nil
+}
+
+func (db *DB) GetCampaignLocation(
+	campaignID string,
+	id int64,
+) (
+	*service.LocationOption,
+	error,
+) {
+	row := db.Conn.QueryRow(`
+		SELECT `+locationColumns+`
+		FROM locations l
+		LEFT JOIN locations p ON p.id = l.parent_id
+		WHERE l.campaign_id = ?1 AND l.id = ?2`,
+		campaignID,
+		id,
+	)
+
+	opt, err := scanLocation(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, service.ErrLocationNotFound
+		}
+		return nil, fmt.Errorf("get campaign location: %w", err)
+	}
+
+	labels, err := getLocationLabels(db.Conn, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := getLocationAliases(db.Conn, campaignID)
+	if err != nil {
+		return nil, err
+	}
+	opt.Labels = labels[opt.ID]
+	opt.Aliases = aliases[opt.ID]
+
+	return opt, nil
+}
+
+// ReplaceCampaignLocations updates rows whose value is still present in
+// place, so their ids survive a full replace, then inserts new values and
+// deletes the rest. A non-zero revision must match the current location
+// set revision.
+func (db *DB) ReplaceCampaignLocations(
+	campaignID string,
+	options []service.LocationOption,
+	revision int64,
+) error {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin replace locations transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := bumpLocationsRevision(tx, campaignID, revision); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(`
+		SELECT id, value
+		FROM locations
+		WHERE campaign_id = ?1`,
+		campaignID,
+	)
+	if err != nil {
+		return fmt.Errorf("get existing locations: %w", err)
+	}
+	existing := make(map[string]int64)
+	for rows.Next() {
+		var id int64
+		var value string
+		if err := rows.Scan(&id, &value); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan existing location: %w", err)
+		}
+		existing[value] = id
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate existing locations: %w", err)
+	}
+
+	// options arrive with parents ahead of their children
+	ids := make(map[string]int64, len(options))
+	for _, opt := range options {
+		if opt.Parent != "" {
+			parentID, ok := ids[opt.Parent]
+			if !ok {
+				return fmt.Errorf("replace campaign location: parent %q not written", opt.Parent)
+			}
+			opt.ParentID = parentID
+		} else {
+			opt.ParentID = 0
+		}
+
+		if id, ok := existing[opt.Value]; ok {
+			opt.ID = id
+			if err := updateLocation(tx, campaignID, opt); err != nil {
+				return err
+			}
+		} else {
+			id, err := insertLocation(tx, campaignID, opt)
+			if err != nil {
+				return err
+			}
+			opt.ID = id
+		}
+		ids[opt.Value] = opt.ID
+
+		if err := replaceLocationLabels(tx, opt.ID, opt.Labels); err != nil {
+			return err
+		}
+		if err := replaceLocationAliases(tx, opt.ID, opt.Aliases); err != nil {
+			return err
+		}
+	}
+
+	for value, id := range existing {
+		if _, kept := ids[value]; kept {
+			continue
+		}
+		if _, err := tx.Exec(`
+			DELETE FROM locations
+			WHERE campaign_id = ?1 AND id = ?2`,
+			campaignID,
+			id,
+		); err != nil {
+			return fmt.Errorf("delete campaign location: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit replace locations: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) InsertCampaignLocation(
+	campaignID string,
+	option service.LocationOption,
+) (
+	int64,
+	error,
+) {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin insert location transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := bumpLocationsRevision(tx, campaignID, 0); err != nil {
+		return 0, err
+	}
+
+	id, err := insertLocation(tx, campaignID, option)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := replaceLocationLabels(tx, id, option.Labels); err != nil {
+		return 0, err
+	}
+	if err := replaceLocationAliases(tx, id, option.Aliases); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit insert location: %w", err)
+	}
+
+	return id, nil
+}
+
+func (db *DB) UpdateCampaignLocation(
+	campaignID string,
+	option service.LocationOption,
+) error {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin update location transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	previous, err := getLocationValue(tx, campaignID, option.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := updateLocation(tx, campaignID, option); err != nil {
+		return err
+	}
+
+	// a rename carries existing signatures along with it
+	if previous != option.Value {
+		if _, err := rewriteSignatureLocations(tx, campaignID, previous, option.Value); err != nil {
+			return err
+		}
+	}
+
+	if err := replaceLocationLabels(tx, option.ID, option.Labels); err != nil {
+		return err
+	}
+	if err := replaceLocationAliases(tx, option.ID, option.Aliases); err != nil {
+		return err
+	}
+	if err := bumpLocationsRevision(tx, campaignID, 0); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit update location: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) DeleteCampaignLocation(
+	campaignID string,
+	id int64,
+) error {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin delete location transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		DELETE FROM locations
+		WHERE campaign_id = ?1 AND id = ?2`,
+		campaignID,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("delete campaign location: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected for location delete: %w", err)
+	}
+	if rowsAffected == 0 {
+		return service.ErrLocationNotFound
+	}
+
+	if err := bumpLocationsRevision(tx, campaignID, 0); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit delete location: %w", err)
+	}
+
+	return nil
+}
+
+// MergeCampaignLocation folds the source location into the target:
+// signatures are rewritten to the target's value, the source's children are
+// appended under the target, and the source is deleted. It returns the
+// number of rewritten signatures.
+func (db *DB) MergeCampaignLocation(
+	campaignID string,
+	sourceID int64,
+	targetID int64,
+) (
+	int,
+	error,
+) {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin merge location transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sourceValue, err := getLocationValue(tx, campaignID, sourceID)
+	if err != nil {
+		return 0, err
+	}
+	targetValue, err := getLocationValue(tx, campaignID, targetID)
+	if err != nil {
+		return 0, err
+	}
+
+	var lastOrder int
+	if err := tx.QueryRow(`
+		SELECT COALESCE(MAX(display_order), 0)
+		FROM locations
+		WHERE campaign_id = ?1 AND parent_id = ?2`,
+		campaignID,
+		targetID,
+	).Scan(&lastOrder); err != nil {
+		return 0, fmt.Errorf("get merge target children: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE locations
+		SET parent_id = ?1,
+			display_order = display_order + ?2
+		WHERE campaign_id = ?3 AND parent_id = ?4`,
+		targetID,
+		lastOrder,
+		campaignID,
+		sourceID,
+	); err != nil {
+		return 0, fmt.Errorf("reparent merged location children: %w", err)
+	}
+
+	rewritten, err := rewriteSignatureLocations(tx, campaignID, sourceValue, targetValue)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM locations
+		WHERE campaign_id = ?1 AND id = ?2`,
+		campaignID,
+		sourceID,
+	); err != nil {
+		return 0, fmt.Errorf("delete merged location: %w", err)
+	}
+
+	if err := bumpLocationsRevision(tx, campaignID, 0); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit merge location: %w", err)
+	}
+
+	return rewritten, nil
+}
+
+// RemapSignatureLocations rewrites signatures whose location matches a key
+// of mappings to the mapped value, all in one transaction.
+func (db *DB) RemapSignatureLocations(
+	campaignID string,
+	mappings map[string]string,
+) (
+	int,
+	error,
+) {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin remap locations transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := verifyCampaignExists(tx, campaignID); err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for from, to := range mappings {
+		rewritten, err := rewriteSignatureLocations(tx, campaignID, from, to)
+		if err != nil {
+			return 0, err
+		}
+		total += rewritten
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit remap locations: %w", err)
+	}
+
+	return total, nil
+}
+
+// SetLocationCoordinates updates only the coordinates of the given
+// locations, matched by id.
+func (db *DB) SetLocationCoordinates(
+	campaignID string,
+	options []service.LocationOption,
+) error {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin location coordinates transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := bumpLocationsRevision(tx, campaignID, 0); err != nil {
+		return err
+	}
+
+	for _, opt := range options {
+		if _, err := tx.Exec(`
+			UPDATE locations
+			SET latitude = ?1,
+				longitude = ?2
+			WHERE campaign_id = ?3 AND id = ?4`,
+			opt.Latitude,
+			opt.Longitude,
+			campaignID,
+			opt.ID,
+		); err != nil {
+			return fmt.Errorf("set location coordinates: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit location coordinates: %w", err)
+	}
+
+	return nil
+}
+
+// MoveCampaignLocation sets the location's parent and renumbers the new
+// siblings in the given order.
+func (db *DB) MoveCampaignLocation(
+	campaignID string,
+	id int64,
+	parentID int64,
+	siblingIDs []int64,
+) error {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin move location transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE locations
+		SET parent_id = ?1
+		WHERE campaign_id = ?2 AND id = ?3`,
+		nullableID(parentID),
+		campaignID,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("move campaign location: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected for location move: %w", err)
+	}
+	if rowsAffected == 0 {
+		return service.ErrLocationNotFound
+	}
+
+	for idx, siblingID := range siblingIDs {
+		if _, err := tx.Exec(`
+			UPDATE locations
+			SET display_order = ?1
+			WHERE campaign_id = ?2 AND id = ?3`,
+			idx+1,
+			campaignID,
+			siblingID,
+		); err != nil {
+			return fmt.Errorf("reorder campaign location: %w", err)
+		}
+	}
+
+	if err := bumpLocationsRevision(tx, campaignID, 0); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit move location: %w", err)
+	}
+
+	return nil
+}
+
+func insertLocation(
+	tx *sql.Tx,
+	campaignID string,
+	opt service.LocationOption,
+) (
+	int64,
+	error,
+) {
+	result, err := tx.Exec(`
+		INSERT INTO locations (campaign_id, value, display_order, parent_id, country_code, subdivision_code, latitude, longitude)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8)`,
+		campaignID,
+		opt.Value,
+		opt.DisplayOrder,
+		nullableID(opt.ParentID),
+		opt.CountryCode,
+		opt.SubdivisionCode,
+		opt.Latitude,
+		opt.Longitude,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert campaign location: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("campaign location id: %w", err)
+	}
+	return id, nil
+}
+
+func updateLocation(
+	tx *sql.Tx,
+	campaignID string,
+	opt service.LocationOption,
+) error {
+	result, err := tx.Exec(`
+		UPDATE locations
+		SET value = ?1,
+			display_order = ?2,
+			parent_id = ?3,
+			country_code = ?4,
+			subdivision_code = ?5,
+			latitude = ?6,
+			longitude = ?7
+		WHERE campaign_id = ?8 AND id = ?9`,
+		opt.Value,
+		opt.DisplayOrder,
+		nullableID(opt.ParentID),
+		opt.CountryCode,
+		opt.SubdivisionCode,
+		opt.Latitude,
+		opt.Longitude,
+		campaignID,
+		opt.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update campaign location: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected for location update: %w", err)
+	}
+	if rowsAffected == 0 {
+		return service.ErrLocationNotFound
+	}
+
+	return nil
+}
+
+func getLocationValue(
+	tx *sql.Tx,
+	campaignID string,
+	id int64,
+) (
+	string,
+	error,
+) {
+	var value string
+	if err := tx.QueryRow(`
+		SELECT value
+		FROM locations
+		WHERE campaign_id = ?1 AND id = ?2`,
+		campaignID,
+		id,
+	).Scan(&value); err != nil {
+		if err == sql.ErrNoRows {
+			return "", service.ErrLocationNotFound
+		}
+		return "", fmt.Errorf("get campaign location value: %w", err)
+	}
+	return value, nil
+}
+
+func rewriteSignatureLocations(
+	tx *sql.Tx,
+	campaignID string,
+	from string,
+	to string,
+) (
+	int,
+	error,
+) {
+	result, err := tx.Exec(`
+		UPDATE signatures
+		SET location = ?1
+		WHERE campaign_id = ?2 AND location = ?3`,
+		to,
+		campaignID,
+		from,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("rewrite signature locations: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected for signature rewrite: %w", err)
+	}
+	return int(rowsAffected), nil
+}
+
+// getLocationLabels returns translated labels keyed by location id, then
+// language.
+func getLocationLabels(
+	q querier,
+	campaignID string,
+) (
+	map[int64]map[string]string,
+	error,
+) {
+	rows, err := q.Query(`
+		SELECT ll.location_id, ll.lang, ll.label
+		FROM location_labels ll
+		JOIN locations l ON l.id = ll.location_id
+		WHERE l.campaign_id = ?1`,
+		campaignID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get location labels: %w", err)
+	}
+	defer rows.Close()
+
+	labels := make(map[int64]map[string]string)
+	for rows.Next() {
+		var id int64
+		var lang, label string
+		if err := rows.Scan(&id, &lang, &label); err != nil {
+			return nil, fmt.Errorf("scan location label: %w", err)
+		}
+		if labels[id] == nil {
+			labels[id] = make(map[string]string)
+		}
+		labels[id][lang] = label
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate location labels: %w", err)
+	}
+
+	return labels, nil
+}
+
+func replaceLocationLabels(
+	tx *sql.Tx,
+	locationID int64,
+	labels map[string]string,
+) error {
+	if _, err := tx.Exec(`
+		DELETE FROM location_labels
+		WHERE location_id = ?1`,
+		locationID,
+	); err != nil {
+		return fmt.Errorf("clear location labels: %w", err)
+	}
+
+	for lang, label := range labels {
+		if _, err := tx.Exec(`
+			INSERT INTO location_labels (location_id, lang, label)
+			VALUES (?1, ?2, ?3)`,
+			locationID,
+			lang,
+			label,
+		); err != nil {
+			return fmt.Errorf("insert location label: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// getLocationAliases returns aliases keyed by location id, in the order
+// they were saved.
+func getLocationAliases(
+	q querier,
+	campaignID string,
+) (
+	map[int64][]string,
+	error,
+) {
+	rows, err := q.Query(`
+		SELECT la.location_id, la.alias
+		FROM location_aliases la
+		JOIN locations l ON l.id = la.location_id
+		WHERE l.campaign_id = ?1
+		ORDER BY la.rowid ASC`,
+		campaignID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get location aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := make(map[int64][]string)
+	for rows.Next() {
+		var id int64
+		var alias string
+		if err := rows.Scan(&id, &alias); err != nil {
+			return nil, fmt.Errorf("scan location alias: %w", err)
+		}
+		aliases[id] = append(aliases[id], alias)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate location aliases: %w", err)
+	}
+
+	return aliases, nil
+}
+
+func replaceLocationAliases(
+	tx *sql.Tx,
+	locationID int64,
+	aliases []string,
+) error {
+	if _, err := tx.Exec(`
+		DELETE FROM location_aliases
+		WHERE location_id = ?1`,
+		locationID,
+	); err != nil {
+		return fmt.Errorf("clear location aliases: %w", err)
+	}
+
+	for _, alias := range aliases {
+		if _, err := tx.Exec(`
+			INSERT INTO location_aliases (location_id, alias)
+			VALUES (?1, ?2)`,
+			locationID,
+			alias,
+		); err != nil {
+			return fmt.Errorf("insert location alias: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// bumpLocationsRevision advances the campaign's location set revision. A
+// non-zero expected revision must match the current one, or
+// ErrRevisionConflict is returned.
+func bumpLocationsRevision(
+	tx *sql.Tx,
+	campaignID string,
+	expected int64,
+) error {
+	result, err := tx.Exec(`
+		UPDATE campaigns
+		SET locations_revision = locations_revision + 1
+		WHERE id = ?1 AND deleted_at = 0 AND (?2 = 0 OR locations_revision = ?2)`,
+		campaignID,
+		expected,
+	)
+	if err != nil {
+		return fmt.Errorf("bump locations revision: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected for locations revision: %w", err)
+	}
+	if rowsAffected == 0 {
+		if err := verifyCampaignExists(tx, campaignID); err != nil {
+			return err
+		}
+		return service.ErrRevisionConflict
+	}
+	return nil
+}
+
+func verifyCampaignExists(
+	q rowQuerier,
+	campaignID string,
+) error {
+	row := q.QueryRow(`
+		SELECT COUNT(*)
+		FROM campaigns
+		WHERE id = ?1 AND deleted_at = 0`,
+		campaignID,
+	)
+
+	var exists int
+	if err := row.Scan(&exists); err != nil {
+		return fmt.Errorf("verify campaign exists: %w", err)
+	}
+	if exists == 0 {
+		return service.ErrCampaignNotFound
+	}
+	return nil
+}
+
+func scanLocation(
+	row rowScanner,
+) (
+	*service.LocationOption,
+	error,
+) {
+	var opt service.LocationOption
+	var latitude, longitude sql.NullFloat64
+	if err := row.Scan(
+		&opt.ID,
+		&opt.Value,
+		&opt.DisplayOrder,
+		&opt.ParentID,
+		&opt.Parent,
+		&opt.CountryCode,
+		&opt.SubdivisionCode,
+		&latitude,
+		&longitude,
+	); err != nil {
+		return nil, err
+	}
+	if latitude.Valid && longitude.Valid {
+		opt.Latitude = &latitude.Float64
+		opt.Longitude = &longitude.Float64
+	}
+	return &opt, nil
+}
+
+func nullableID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id > 0}
+}
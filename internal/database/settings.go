@@ -0,0 +1,40 @@
+package database
+
+import (
+	"cosign/internal/service"
+	"database/sql"
+	"fmt"
+)
+
+// ListAPIKeys reads the api_key table owned by the keys package, which
+// has no listing of its own.
+func (db *DB) ListAPIKeys() (
+	[]*service.APIKey,
+	error,
+) {
+	rows, err := db.Conn.Query(`
+		SELECT id, created, last_used
+		FROM api_key
+		ORDER BY created, id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*service.APIKey{}
+	for rows.Next() {
+		var key service.APIKey
+		var createdAt, lastUsedAt sql.NullInt64
+		if err := rows.Scan(&key.ID, &createdAt, &lastUsedAt); err != nil {
+			return nil, fmt.Errorf("scan api key: %w", err)
+		}
+		key.CreatedAt = createdAt.Int64
+		key.LastUsedAt = lastUsedAt.Int64
+		keys = append(keys, &key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,250 @@
+package database
+
+import (
+	"cosign/internal/service"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+)
+
+// ApplySignatureBulkOperation applies op to the signatures matching filter
+// and keeps them, as they were, for undo. It returns the signatures it
+// changed; when none match, no operation is stored. Expired operations are
+// dropped along the way.
+func (db *DB) ApplySignatureBulkOperation(
+	op service.SignatureBulkOperation,
+	filter service.SignatureFilter,
+) (
+	[]*service.Signature,
+	error,
+) {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin bulk signature transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		DELETE FROM signature_bulk_operations
+		WHERE expires_at <= ?1`,
+		op.CreatedAt,
+	); err != nil {
+		return nil, fmt.Errorf("delete expired bulk operations: %w", err)
+	}
+
+	rows, err := tx.Query(`
+		SELECT id, name, email, location, location_raw, hidden, created_at, deleted_at
+		FROM signatures
+		WHERE `+signatureFilterWhere+`
+		ORDER BY created_at DESC, id DESC`,
+		signatureFilterArgs(op.CampaignID, filter)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select bulk signatures: %w", err)
+	}
+	matched, err := scanSignatures(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var affected []*service.Signature
+	ids := []int64{}
+	for _, signature := range matched {
+		if op.Action == service.BulkActionHide && signature.Hidden ||
+			op.Action == service.BulkActionUnhide && !signature.Hidden {
+			continue
+		}
+		affected = append(affected, signature)
+		ids = append(ids, signature.ID)
+	}
+	if len(affected) == 0 {
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit bulk signature transaction: %w", err)
+		}
+		return nil, nil
+	}
+
+	encodedIDs, err := json.Marshal(ids)
+	if err != nil {
+		return nil, fmt.Errorf("encode bulk signature ids: %w", err)
+	}
+
+	if op.Action == service.BulkActionDelete {
+		_, err = tx.Exec(`
+			UPDATE signatures
+			SET deleted_at = ?3
+			WHERE campaign_id = ?1 AND id IN (SELECT value FROM json_each(?2))`,
+			op.CampaignID,
+			string(encodedIDs),
+			op.CreatedAt,
+		)
+	} else {
+		_, err = tx.Exec(`
+			UPDATE signatures
+			SET hidden = ?3
+			WHERE campaign_id = ?1 AND id IN (SELECT value FROM json_each(?2))`,
+			op.CampaignID,
+			string(encodedIDs),
+			op.Action == service.BulkActionHide,
+		)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s bulk signatures: %w", op.Action, err)
+	}
+
+	snapshot, err := json.Marshal(affected)
+	if err != nil {
+		return nil, fmt.Errorf("encode bulk signatures: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO signature_bulk_operations (id, campaign_id, action, signatures, created_at, expires_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6)`,
+		op.ID,
+		op.CampaignID,
+		op.Action,
+		string(snapshot),
+		op.CreatedAt,
+		op.ExpiresAt,
+	); err != nil {
+		return nil, fmt.Errorf("insert bulk operation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit bulk signature transaction: %w", err)
+	}
+
+	return affected, nil
+}
+
+func (db *DB) GetSignatureBulkOperation(
+	campaignID string,
+	id string,
+) (
+	*service.SignatureBulkOperation,
+	error,
+) {
+	op := service.SignatureBulkOperation{ID: id, CampaignID: campaignID}
+	if err := db.Conn.QueryRow(`
+		SELECT action, created_at, expires_at
+		FROM signature_bulk_operations
+		WHERE id = ?1 AND campaign_id = ?2`,
+		id,
+		campaignID,
+	).Scan(
+		&op.Action,
+		&op.CreatedAt,
+		&op.ExpiresAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, service.ErrBulkOperationNotFound
+		}
+		return nil, fmt.Errorf("get bulk operation: %w", err)
+	}
+
+	return &op, nil
+}
+
+// UndoSignatureBulkOperation puts back the signatures an unexpired
+// operation changed and forgets the operation. Deleted signatures come
+// back from the trash unless they have been purged or restored since, or
+// their email has signed again; it returns the signatures it put back.
+func (db *DB) UndoSignatureBulkOperation(
+	campaignID string,
+	id string,
+	now int64,
+) (
+	*service.SignatureBulkOperation,
+	[]*service.Signature,
+	error,
+) {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin bulk undo transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	op := service.SignatureBulkOperation{ID: id, CampaignID: campaignID}
+	var snapshot string
+	if err := tx.QueryRow(`
+		SELECT action, signatures, created_at, expires_at
+		FROM signature_bulk_operations
+		WHERE id = ?1 AND campaign_id = ?2`,
+		id,
+		campaignID,
+	).Scan(
+		&op.Action,
+		&snapshot,
+		&op.CreatedAt,
+		&op.ExpiresAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, service.ErrBulkOperationNotFound
+		}
+		return nil, nil, fmt.Errorf("get bulk operation: %w", err)
+	}
+	if op.ExpiresAt <= now {
+		return nil, nil, service.ErrBulkUndoExpired
+	}
+
+	var signatures []*service.Signature
+	if err := json.Unmarshal([]byte(snapshot), &signatures); err != nil {
+		return nil, nil, fmt.Errorf("decode bulk signatures: %w", err)
+	}
+
+	var restored []*service.Signature
+	for _, signature := range signatures {
+		var result sql.Result
+		if op.Action == service.BulkActionDelete {
+			result, err = tx.Exec(`
+				UPDATE signatures
+				SET deleted_at = 0
+				WHERE campaign_id = ?1 AND id = ?2 AND deleted_at != 0
+					AND NOT EXISTS (
+						SELECT 1
+						FROM signatures AS active
+						WHERE active.campaign_id = signatures.campaign_id
+							AND active.email = signatures.email
+							AND active.deleted_at = 0
+					)`,
+				campaignID,
+				signature.ID,
+			)
+		} else {
+			result, err = tx.Exec(`
+				UPDATE signatures
+				SET hidden = ?3
+				WHERE campaign_id = ?1 AND id = ?2`,
+				campaignID,
+				signature.ID,
+				signature.Hidden,
+			)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("restore bulk signature: %w", err)
+		}
+
+		changed, err := result.RowsAffected()
+		if err != nil {
+			return nil, nil, fmt.Errorf("rows affected for bulk signature restore: %w", err)
+		}
+		if changed > 0 {
+			restored = append(restored, signature)
+		}
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM signature_bulk_operations
+		WHERE id = ?1`,
+		id,
+	); err != nil {
+		return nil, nil, fmt.Errorf("delete bulk operation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("commit bulk undo transaction: %w", err)
+	}
+
+	return &op, restored, nil
+}
@@ -3,23 +3,29 @@ package database
 import (
 	"cosign/internal/service"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
 )
 
+// InsertSignature adds a signature. Emails are unique among a campaign's
+// active signatures only, so one in the trash does not block signing again.
 func (db *DB) InsertSignature(
 	campaignID string,
 	name string,
 	email string,
 	location string,
+	locationRaw string,
 	createdAt int64,
 ) (int64, error) {
 	result, err := db.Conn.Exec(`
-		INSERT INTO signatures (campaign_id, name, email, location, created_at)
-		VALUES (?1, ?2, ?3, ?4, ?5)`,
+		INSERT INTO signatures (campaign_id, name, email, location, location_raw, created_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6)`,
 		campaignID,
 		name,
 		email,
 		location,
+		locationRaw,
 		createdAt,
 	)
 	if err != nil {
@@ -28,26 +34,61 @@ func (db *DB) InsertSignature(
 	return result.LastInsertId()
 }
 
+// signatureFilterWhere matches the arguments of signatureFilterArgs from
+// ?2 on; an empty argument leaves its condition out. Signatures in the
+// trash never match.
+const signatureFilterWhere = `
+	campaign_id = ?1
+	AND deleted_at = 0
+	AND (?2 = '' OR name LIKE ?2 ESCAPE '\' OR email LIKE ?2 ESCAPE '\')
+	AND (?3 = '' OR location = ?3)
+	AND (?4 = '' OR hidden = (?4 = 'hidden'))
+	AND (?5 = '' OR id IN (SELECT value FROM json_each(?5)))`
+
+func signatureFilterArgs(campaignID string, filter service.SignatureFilter) []any {
+	pattern := ""
+	if filter.Query != "" {
+		escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(filter.Query)
+		pattern = "%" + escaped + "%"
+	}
+
+	ids := ""
+	if filter.IDs != nil {
+		encoded, _ := json.Marshal(filter.IDs)
+		ids = string(encoded)
+	}
+
+	return []any{campaignID, pattern, filter.Location, filter.Visibility, ids}
+}
+
 func (db *DB) ListSignatures(
 	campaignID string,
+	filter service.SignatureFilter,
 	limit int,
 	offset int,
 ) ([]*service.Signature, error) {
+	args := append(signatureFilterArgs(campaignID, filter), limit, offset)
 	rows, err := db.Conn.Query(`
-		SELECT id, name, email, location, created_at
+		SELECT id, name, email, location, location_raw, hidden, created_at, deleted_at
 		FROM signatures
-		WHERE campaign_id = ?1
-		ORDER BY created_at DESC
-		LIMIT ?2 OFFSET ?3`,
-		campaignID,
-		limit,
-		offset,
+		WHERE `+signatureFilterWhere+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?6 OFFSET ?7`,
+		args...,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("list signatures: %w", err)
 	}
 	defer rows.Close()
 
+	signatures, err := scanSignatures(rows)
+	if err != nil {
+		return nil, err
+	}
+	return signatures, nil
+}
+
+func scanSignatures(rows *sql.Rows) ([]*service.Signature, error) {
 	var signatures []*service.Signature
 	for rows.Next() {
 		var s service.Signature
@@ -56,7 +97,10 @@ func (db *DB) ListSignatures(
 			&s.Name,
 			&s.Email,
 			&s.Location,
+			&s.LocationRaw,
+			&s.Hidden,
 			&s.CreatedAt,
+			&s.DeletedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan signature: %w", err)
 		}
@@ -72,6 +116,7 @@ func (db *DB) ListSignatures(
 
 func (db *DB) CountSignatures(
 	campaignID string,
+	filter service.SignatureFilter,
 ) (
 	int,
 	error,
@@ -79,8 +124,8 @@ func (db *DB) CountSignatures(
 	row := db.Conn.QueryRow(`
 		SELECT COUNT(*)
 		FROM signatures
-		WHERE campaign_id = ?1`,
-		campaignID,
+		WHERE `+signatureFilterWhere,
+		signatureFilterArgs(campaignID, filter)...,
 	)
 
 	var count int
@@ -90,15 +135,103 @@ func (db *DB) CountSignatures(
 	return count, nil
 }
 
+func (db *DB) CountSignaturesByLocation(
+	campaignID string,
+) (
+	map[string]int,
+	error,
+) {
+	rows, err := db.Conn.Query(`
+		SELECT location, COUNT(*)
+		FROM signatures
+		WHERE campaign_id = ?1 AND hidden = 0 AND deleted_at = 0
+		GROUP BY location`,
+		campaignID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("count signatures by location: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var location string
+		var count int
+		if err := rows.Scan(&location, &count); err != nil {
+			return nil, fmt.Errorf("scan location count: %w", err)
+		}
+		counts[location] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate location counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// CountSignaturesByMappedLocation groups signatures by location, joined to
+// the preset they name; custom text has no location id or coordinates.
+func (db *DB) CountSignaturesByMappedLocation(
+	campaignID string,
+) (
+	[]service.LocationSignatureCount,
+	error,
+) {
+	rows, err := db.Conn.Query(`
+		SELECT s.location, COALESCE(l.id, 0), l.latitude, l.longitude, COUNT(*)
+		FROM signatures s
+		LEFT JOIN locations l ON l.campaign_id = s.campaign_id AND l.value = s.location
+		WHERE s.campaign_id = ?1 AND s.hidden = 0 AND s.deleted_at = 0
+		GROUP BY s.location, l.id
+		ORDER BY COUNT(*) DESC, s.location ASC`,
+		campaignID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("count signatures by mapped location: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []service.LocationSignatureCount
+	for rows.Next() {
+		var count service.LocationSignatureCount
+		var latitude, longitude sql.NullFloat64
+		if err := rows.Scan(
+			&count.Value,
+			&count.LocationID,
+			&latitude,
+			&longitude,
+			&count.Count,
+		); err != nil {
+			return nil, fmt.Errorf("scan mapped location count: %w", err)
+		}
+		if latitude.Valid && longitude.Valid {
+			count.Latitude = &latitude.Float64
+			count.Longitude = &longitude.Float64
+		}
+		counts = append(counts, count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate mapped location counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// DeleteSignature moves the signature to the trash.
 func (db *DB) DeleteSignature(
 	campaignID string,
 	id int64,
+	deletedAt int64,
 ) error {
 	result, err := db.Conn.Exec(`
-		DELETE FROM signatures
-		WHERE campaign_id = ?1 AND id = ?2`,
+		UPDATE signatures
+		SET deleted_at = ?3
+		WHERE campaign_id = ?1 AND id = ?2 AND deleted_at = 0`,
 		campaignID,
 		id,
+		deletedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("delete signature: %w", err)
@@ -116,6 +249,105 @@ func (db *DB) DeleteSignature(
 	return nil
 }
 
+// RestoreSignature takes a signature out of the trash. It returns
+// ErrDuplicateEmail when the same email has signed again since.
+func (db *DB) RestoreSignature(
+	campaignID string,
+	id int64,
+) error {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin restore signature transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var email string
+	if err := tx.QueryRow(`
+		SELECT email
+		FROM signatures
+		WHERE campaign_id = ?1 AND id = ?2 AND deleted_at != 0`,
+		campaignID,
+		id,
+	).Scan(&email); err != nil {
+		if err == sql.ErrNoRows {
+			return service.ErrSignatureNotFound
+		}
+		return fmt.Errorf("get deleted signature: %w", err)
+	}
+
+	var active int
+	if err := tx.QueryRow(`
+		SELECT COUNT(*)
+		FROM signatures
+		WHERE campaign_id = ?1 AND email = ?2 AND deleted_at = 0`,
+		campaignID,
+		email,
+	).Scan(&active); err != nil {
+		return fmt.Errorf("check signature email: %w", err)
+	}
+	if active > 0 {
+		return service.ErrDuplicateEmail
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE signatures
+		SET deleted_at = 0
+		WHERE campaign_id = ?1 AND id = ?2`,
+		campaignID,
+		id,
+	); err != nil {
+		return fmt.Errorf("restore signature: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit restore signature: %w", err)
+	}
+	return nil
+}
+
+func (db *DB) ListDeletedSignatures(
+	campaignID string,
+	limit int,
+	offset int,
+) ([]*service.Signature, error) {
+	rows, err := db.Conn.Query(`
+		SELECT id, name, email, location, location_raw, hidden, created_at, deleted_at
+		FROM signatures
+		WHERE campaign_id = ?1 AND deleted_at != 0
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT ?2 OFFSET ?3`,
+		campaignID,
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list deleted signatures: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSignatures(rows)
+}
+
+func (db *DB) CountDeletedSignatures(
+	campaignID string,
+) (
+	int,
+	error,
+) {
+	row := db.Conn.QueryRow(`
+		SELECT COUNT(*)
+		FROM signatures
+		WHERE campaign_id = ?1 AND deleted_at != 0`,
+		campaignID,
+	)
+
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return 0, fmt.Errorf("count deleted signatures: %w", err)
+	}
+	return count, nil
+}
+
 func (db *DB) SignatureEmailExists(
 	campaignID string,
 	email string,
@@ -126,7 +358,7 @@ func (db *DB) SignatureEmailExists(
 	row := db.Conn.QueryRow(`
 		SELECT COUNT(*)
 		FROM signatures
-		WHERE campaign_id = ?1 AND email = ?2`,
+		WHERE campaign_id = ?1 AND email = ?2 AND deleted_at = 0`,
 		campaignID,
 		email,
 	)
@@ -146,9 +378,9 @@ func (db *DB) GetSignature(
 	error,
 ) {
 	row := db.Conn.QueryRow(`
-		SELECT id, name, email, location, created_at
+		SELECT id, name, email, location, location_raw, hidden, created_at, deleted_at
 		FROM signatures
-		WHERE campaign_id = ?1 AND id = ?2`,
+		WHERE campaign_id = ?1 AND id = ?2 AND deleted_at = 0`,
 		campaignID,
 		id,
 	)
@@ -159,7 +391,10 @@ func (db *DB) GetSignature(
 		&s.Name,
 		&s.Email,
 		&s.Location,
+		&s.LocationRaw,
+		&s.Hidden,
 		&s.CreatedAt,
+		&s.DeletedAt,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, service.ErrSignatureNotFound
@@ -169,3 +404,63 @@ func (db *DB) GetSignature(
 
 	return &s, nil
 }
+
+func (db *DB) CountSignaturesSince(
+	campaignID string,
+	since int64,
+) (
+	int,
+	error,
+) {
+	row := db.Conn.QueryRow(`
+		SELECT COUNT(*)
+		FROM signatures
+		WHERE campaign_id = ?1 AND hidden = 0 AND deleted_at = 0 AND created_at >= ?2`,
+		campaignID,
+		since,
+	)
+
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return 0, fmt.Errorf("count signatures since: %w", err)
+	}
+	return count, nil
+}
+
+// CountSignaturesByDay counts signatures from since on, keyed by their
+// UTC date as YYYY-MM-DD.
+func (db *DB) CountSignaturesByDay(
+	campaignID string,
+	since int64,
+) (
+	map[string]int,
+	error,
+) {
+	rows, err := db.Conn.Query(`
+		SELECT strftime('%Y-%m-%d', created_at, 'unixepoch') AS day, COUNT(*)
+		FROM signatures
+		WHERE campaign_id = ?1 AND hidden = 0 AND deleted_at = 0 AND created_at >= ?2
+		GROUP BY day`,
+		campaignID,
+		since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("count signatures by day: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var day string
+		var count int
+		if err := rows.Scan(&day, &count); err != nil {
+			return nil, fmt.Errorf("scan day count: %w", err)
+		}
+		counts[day] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate day counts: %w", err)
+	}
+	return counts, nil
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"cosign/internal/service"
+	"database/sql"
+	"fmt"
+)
+
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+type rowQuerier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func getCampaignTranslations(
+	q querier,
+	campaignID string,
+) (
+	map[string]service.CampaignTranslation,
+	error,
+) {
+	rows, err := q.Query(`
+		SELECT lang, name, letter
+		FROM campaign_translations
+		WHERE campaign_id = ?1
+		ORDER BY lang ASC`,
+		campaignID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get campaign translations: %w", err)
+	}
+	defer rows.Close()
+
+	var translations map[string]service.CampaignTranslation
+	for rows.Next() {
+		var lang string
+		var translation service.CampaignTranslation
+		if err := rows.Scan(&lang, &translation.Name, &translation.Letter); err != nil {
+			return nil, fmt.Errorf("scan campaign translation: %w", err)
+		}
+		if translations == nil {
+			translations = make(map[string]service.CampaignTranslation)
+		}
+		translations[lang] = translation
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate campaign translations: %w", err)
+	}
+
+	return translations, nil
+}
+
+func replaceCampaignTranslations(
+	tx *sql.Tx,
+	campaignID string,
+	translations map[string]service.CampaignTranslation,
+) error {
+	if _, err := tx.Exec(`
+		DELETE FROM campaign_translations
+		WHERE campaign_id = ?1`,
+		campaignID,
+	); err != nil {
+		return fmt.Errorf("clear campaign translations: %w", err)
+	}
+
+	for lang, translation := range translations {
+		if _, err := tx.Exec(`
+			INSERT INTO campaign_translations (campaign_id, lang, name, letter)
+			VALUES (?1, ?2, ?3, ?4)`,
+			campaignID,
+			lang,
+			translation.Name,
+			translation.Letter,
+		); err != nil {
+			return fmt.Errorf("insert campaign translation: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"fmt"
+)
+
+// PurgeDeleted permanently removes campaigns and signatures moved to the
+// trash at or before before. A purged campaign takes everything under it,
+// including signatures still outside the trash. It returns how many
+// campaigns and signatures were in the trash and are gone.
+func (db *DB) PurgeDeleted(
+	before int64,
+) (
+	int,
+	int,
+	error,
+) {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return 0, 0, fmt.Errorf("begin purge transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	signatures, err := tx.Exec(`
+		DELETE FROM signatures
+		WHERE deleted_at != 0 AND deleted_at <= ?1`,
+		before,
+	)
+	if err != nil {
+		return 0, 0, fmt.Errorf("purge deleted signatures: %w", err)
+	}
+	signatureCount, err := signatures.RowsAffected()
+	if err != nil {
+		return 0, 0, fmt.Errorf("rows affected for signature purge: %w", err)
+	}
+
+	campaigns, err := tx.Exec(`
+		DELETE FROM campaigns
+		WHERE deleted_at != 0 AND deleted_at <= ?1`,
+		before,
+	)
+	if err != nil {
+		return 0, 0, fmt.Errorf("purge deleted campaigns: %w", err)
+	}
+	campaignCount, err := campaigns.RowsAffected()
+	if err != nil {
+		return 0, 0, fmt.Errorf("rows affected for campaign purge: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("commit purge transaction: %w", err)
+	}
+
+	return int(campaignCount), int(signatureCount), nil
+}
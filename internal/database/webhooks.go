@@ -0,0 +1,410 @@
+package database
+
+import (
+	"cosign/internal/service"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+func (db *DB) InsertWebhook(
+	webhook service.Webhook,
+) error {
+	var campaignID any
+	if webhook.CampaignID != "" {
+		campaignID = webhook.CampaignID
+	}
+
+	_, err := db.Conn.Exec(`
+		INSERT INTO webhooks (id, campaign_id, url, secret, events, created_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6)`,
+		webhook.ID,
+		campaignID,
+		webhook.URL,
+		webhook.Secret,
+		strings.Join(webhook.Events, ","),
+		webhook.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert webhook: %w", err)
+	}
+	return nil
+}
+
+func (db *DB) GetWebhook(
+	id string,
+) (
+	*service.Webhook,
+	error,
+) {
+	row := db.Conn.QueryRow(`
+		SELECT id, campaign_id, url, secret, events, created_at
+		FROM webhooks
+		WHERE id = ?1`,
+		id,
+	)
+
+	webhook, err := scanWebhook(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, service.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("get webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+func (db *DB) ListWebhooks(
+	campaignID string,
+) (
+	[]*service.Webhook,
+	error,
+) {
+	rows, err := db.Conn.Query(`
+		SELECT id, campaign_id, url, secret, events, created_at
+		FROM webhooks
+		WHERE ?1 = '' OR campaign_id = ?1
+		ORDER BY created_at DESC, id ASC`,
+		campaignID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	return scanWebhooks(rows)
+}
+
+func (db *DB) ListCampaignEventWebhooks(
+	campaignID string,
+) (
+	[]*service.Webhook,
+	error,
+) {
+	rows, err := db.Conn.Query(`
+		SELECT id, campaign_id, url, secret, events, created_at
+		FROM webhooks
+		WHERE campaign_id IS NULL OR campaign_id = ?1
+		ORDER BY created_at ASC, id ASC`,
+		campaignID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list campaign event webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	return scanWebhooks(rows)
+}
+
+func (db *DB) DeleteWebhook(
+	id string,
+) error {
+	result, err := db.Conn.Exec(`
+		DELETE FROM webhooks
+		WHERE id = ?1`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("delete webhook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected for webhook delete: %w", err)
+	}
+	if rowsAffected == 0 {
+		return service.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (db *DB) InsertWebhookDelivery(
+	webhookID string,
+	event string,
+	payload string,
+	createdAt int64,
+) (
+	int64,
+	error,
+) {
+	result, err := db.Conn.Exec(`
+		INSERT INTO webhook_deliveries (webhook_id, event, payload, status, next_attempt_at, created_at, updated_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?5, ?5)`,
+		webhookID,
+		event,
+		payload,
+		service.DeliveryStatusPending,
+		createdAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert webhook delivery: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// RecordMilestone notes that the campaign reached milestone and reports
+// whether this is the first time.
+func (db *DB) RecordMilestone(
+	campaignID string,
+	milestone int,
+	reachedAt int64,
+) (
+	bool,
+	error,
+) {
+	result, err := db.Conn.Exec(`
+		INSERT OR IGNORE INTO campaign_milestones (campaign_id, milestone, reached_at)
+		VALUES (?1, ?2, ?3)`,
+		campaignID,
+		milestone,
+		reachedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("record milestone: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("rows affected for milestone: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (db *DB) GetWebhookDelivery(
+	webhookID string,
+	id int64,
+) (
+	*service.WebhookDelivery,
+	error,
+) {
+	row := db.Conn.QueryRow(`
+		SELECT id, webhook_id, event, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, updated_at
+		FROM webhook_deliveries
+		WHERE webhook_id = ?1 AND id = ?2`,
+		webhookID,
+		id,
+	)
+
+	delivery, err := scanWebhookDelivery(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, service.ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("get webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+func (db *DB) ListWebhookDeliveries(
+	webhookID string,
+	status string,
+	limit int,
+	offset int,
+) (
+	[]*service.WebhookDelivery,
+	error,
+) {
+	rows, err := db.Conn.Query(`
+		SELECT id, webhook_id, event, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, updated_at
+		FROM webhook_deliveries
+		WHERE webhook_id = ?1 AND (?2 = '' OR status = ?2)
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?3 OFFSET ?4`,
+		webhookID,
+		status,
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+func (db *DB) CountWebhookDeliveries(
+	webhookID string,
+	status string,
+) (
+	int,
+	error,
+) {
+	row := db.Conn.QueryRow(`
+		SELECT COUNT(*)
+		FROM webhook_deliveries
+		WHERE webhook_id = ?1 AND (?2 = '' OR status = ?2)`,
+		webhookID,
+		status,
+	)
+
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return 0, fmt.Errorf("count webhook deliveries: %w", err)
+	}
+	return count, nil
+}
+
+func (db *DB) ListDueWebhookDeliveries(
+	now int64,
+	limit int,
+) (
+	[]*service.WebhookDelivery,
+	error,
+) {
+	rows, err := db.Conn.Query(`
+		SELECT id, webhook_id, event, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, updated_at
+		FROM webhook_deliveries
+		WHERE status = ?1 AND next_attempt_at <= ?2
+		ORDER BY next_attempt_at ASC, id ASC
+		LIMIT ?3`,
+		service.DeliveryStatusPending,
+		now,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list due webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+func (db *DB) UpdateWebhookDelivery(
+	delivery service.WebhookDelivery,
+) error {
+	result, err := db.Conn.Exec(`
+		UPDATE webhook_deliveries
+		SET status = ?1,
+			attempts = ?2,
+			next_attempt_at = ?3,
+			last_status_code = ?4,
+			last_error = ?5,
+			updated_at = ?6
+		WHERE id = ?7`,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastStatusCode,
+		delivery.LastError,
+		delivery.UpdatedAt,
+		delivery.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update webhook delivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected for webhook delivery update: %w", err)
+	}
+	if rowsAffected == 0 {
+		return service.ErrWebhookDeliveryNotFound
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWebhook(
+	row rowScanner,
+) (
+	*service.Webhook,
+	error,
+) {
+	var webhook service.Webhook
+	var campaignID sql.NullString
+	var events string
+	if err := row.Scan(
+		&webhook.ID,
+		&campaignID,
+		&webhook.URL,
+		&webhook.Secret,
+		&events,
+		&webhook.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	webhook.CampaignID = campaignID.String
+	if events != "" {
+		webhook.Events = strings.Split(events, ",")
+	}
+	return &webhook, nil
+}
+
+func scanWebhooks(
+	rows *sql.Rows,
+) (
+	[]*service.Webhook,
+	error,
+) {
+	var webhooks []*service.Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func scanWebhookDelivery(
+	row rowScanner,
+) (
+	*service.WebhookDelivery,
+	error,
+) {
+	var delivery service.WebhookDelivery
+	var payload string
+	if err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.Event,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastStatusCode,
+		&delivery.LastError,
+		&delivery.CreatedAt,
+		&delivery.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	delivery.Payload = []byte(payload)
+	return &delivery, nil
+}
+
+func scanWebhookDeliveries(
+	rows *sql.Rows,
+) (
+	[]*service.WebhookDelivery,
+	error,
+) {
+	var deliveries []*service.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,123 @@
+// Package httpserver runs the API and dashboard handlers behind an
+// http.Server with timeouts and header limits, and drains it gracefully
+// when its context ends.
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultReadHeaderTimeout = 5 * time.Second
+	DefaultReadTimeout       = 15 * time.Second
+	DefaultWriteTimeout      = 30 * time.Second
+	DefaultIdleTimeout       = 60 * time.Second
+	DefaultShutdownTimeout   = 20 * time.Second
+	DefaultMaxHeaderBytes    = 64 << 10
+)
+
+// Options configures the server; zero fields use the defaults above.
+type Options struct {
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+
+	// ShutdownTimeout bounds how long in-flight requests may finish after
+	// the context ends before their connections are closed.
+	ShutdownTimeout time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.ReadHeaderTimeout <= 0 {
+		o.ReadHeaderTimeout = DefaultReadHeaderTimeout
+	}
+	if o.ReadTimeout <= 0 {
+		o.ReadTimeout = DefaultReadTimeout
+	}
+	if o.WriteTimeout <= 0 {
+		o.WriteTimeout = DefaultWriteTimeout
+	}
+	if o.IdleTimeout <= 0 {
+		o.IdleTimeout = DefaultIdleTimeout
+	}
+	if o.MaxHeaderBytes <= 0 {
+		o.MaxHeaderBytes = DefaultMaxHeaderBytes
+	}
+	if o.ShutdownTimeout <= 0 {
+		o.ShutdownTimeout = DefaultShutdownTimeout
+	}
+	return o
+}
+
+// Serve handles connections on ln until ctx ends, then stops accepting and
+// waits up to ShutdownTimeout for in-flight requests. onShutdown hooks run
+// when the drain starts, so long-lived handlers such as event streams can
+// end on their own instead of holding the drain open.
+func Serve(
+	ctx context.Context,
+	ln net.Listener,
+	handler http.Handler,
+	opts Options,
+	onShutdown ...func(),
+) error {
+	opts = opts.withDefaults()
+
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: opts.ReadHeaderTimeout,
+		ReadTimeout:       opts.ReadTimeout,
+		WriteTimeout:      opts.WriteTimeout,
+		IdleTimeout:       opts.IdleTimeout,
+		MaxHeaderBytes:    opts.MaxHeaderBytes,
+	}
+	for _, hook := range onShutdown {
+		server.RegisterOnShutdown(hook)
+	}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(ln)
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down, draining connections for up to %s...", opts.ShutdownTimeout)
+	drainCtx, cancel := context.WithTimeout(context.Background(), opts.ShutdownTimeout)
+	defer cancel()
+
+	err := server.Shutdown(drainCtx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Printf("Shutdown deadline passed, closing remaining connections")
+		err = server.Close()
+	}
+	if serveErr := <-served; !errors.Is(serveErr, http.ErrServerClosed) {
+		return serveErr
+	}
+	return err
+}
+
+// ListenAndServe is Serve on a new TCP listener for addr.
+func ListenAndServe(
+	ctx context.Context,
+	addr string,
+	handler http.Handler,
+	opts Options,
+	onShutdown ...func(),
+) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return Serve(ctx, ln, handler, opts, onShutdown...)
+}
@@ -0,0 +1,112 @@
+package httpserver
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func startServer(t *testing.T, handler http.Handler, opts Options) (string, context.CancelFunc, <-chan error) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(ctx, ln, handler, opts)
+	}()
+	t.Cleanup(cancel)
+
+	return "http://" + ln.Addr().String(), cancel, done
+}
+
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+		}
+		_, _ = io.WriteString(w, "done")
+	})
+
+	baseURL, cancel, done := startServer(t, handler, Options{ShutdownTimeout: 5 * time.Second})
+
+	type result struct {
+		body string
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		res, err := http.Get(baseURL + "/slow")
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		responses <- result{body: string(body), err: err}
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		t.Fatalf("expected serve to wait for the in-flight request, returned %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: time.Second}
+	if _, err := client.Get(baseURL + "/fast"); err == nil {
+		t.Fatalf("expected new connections to be refused while draining")
+	}
+
+	close(release)
+	response := <-responses
+	if response.err != nil || response.body != "done" {
+		t.Fatalf("expected in-flight request to complete, got %q %v", response.body, response.err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+}
+
+func TestServeClosesConnectionsAfterDeadline(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})
+
+	baseURL, cancel, done := startServer(t, handler, Options{ShutdownTimeout: 50 * time.Millisecond})
+
+	requestErr := make(chan error, 1)
+	go func() {
+		res, err := http.Get(baseURL)
+		if err == nil {
+			res.Body.Close()
+		}
+		requestErr <- err
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("expected serve to return once the shutdown deadline passed")
+	}
+	if err := <-requestErr; err == nil {
+		t.Fatalf("expected the stuck request to be cut off")
+	}
+}
@@ -0,0 +1,143 @@
+package public
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"cosign/internal/service"
+)
+
+func (s *Server) handleCampaignPage(w http.ResponseWriter, r *http.Request) {
+	s.renderCampaign(w, r, http.StatusOK, false, SignFormState{})
+}
+
+func (s *Server) handleCampaignEmbed(w http.ResponseWriter, r *http.Request) {
+	s.renderCampaign(w, r, http.StatusOK, true, SignFormState{})
+}
+
+func (s *Server) handleSign(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	embed := strings.HasSuffix(r.URL.Path, "/embed")
+
+	form := SignFormState{
+		Name:     strings.TrimSpace(r.FormValue("name")),
+		Email:    strings.TrimSpace(r.FormValue("email")),
+		Location: strings.TrimSpace(r.FormValue("location")),
+	}
+
+	_, err := s.svc.CreateSignature(campaignID, form.Name, form.Email, form.Location)
+	if err != nil {
+		status := service.ErrorStatus(err)
+		if status == http.StatusNotFound {
+			lang := service.NegotiateLanguage(r, service.SupportedLanguages)
+			s.renderer.RenderErrorPage(w, status, lang, service.Message(lang, service.CodeCampaignNotFound))
+			return
+		}
+
+		form.FormError = service.ErrorCode(err)
+		s.renderCampaign(w, r, status, embed, form)
+		return
+	}
+
+	target := r.URL.Path + "?signed=1"
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		target += "&lang=" + url.QueryEscape(lang)
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+func (s *Server) renderCampaign(
+	w http.ResponseWriter,
+	r *http.Request,
+	statusCode int,
+	embed bool,
+	form SignFormState,
+) {
+	campaignID := campaignIDFromPath(r)
+	campaign, err := s.svc.GetCampaign(campaignID)
+	if err != nil {
+		lang := service.NegotiateLanguage(r, service.SupportedLanguages)
+		if errors.Is(err, service.ErrCampaignNotFound) {
+			s.renderer.RenderErrorPage(w, http.StatusNotFound, lang, service.Message(lang, service.CodeCampaignNotFound))
+			return
+		}
+		s.renderer.RenderErrorPage(w, http.StatusInternalServerError, lang, service.Message(lang, service.CodeInternalError))
+		return
+	}
+
+	lang := service.NegotiateLanguage(r, campaign.Languages())
+	w.Header().Set("Content-Language", lang)
+	w.Header().Add("Vary", "Accept-Language")
+
+	locations, err := s.svc.GetCampaignLocations(campaignID)
+	if err != nil {
+		s.renderer.RenderErrorPage(w, http.StatusInternalServerError, lang, service.Message(lang, service.CodeInternalError))
+		return
+	}
+
+	signatures, err := s.svc.ListPublicSignatures(campaignID, s.recentLimit)
+	if err != nil {
+		s.renderer.RenderErrorPage(w, http.StatusInternalServerError, lang, service.Message(lang, service.CodeInternalError))
+		return
+	}
+
+	view := NewCampaignPageView(
+		service.LocalizeCampaign(campaign, lang),
+		service.LocalizeLocations(locations, lang),
+		signatures,
+		s.apiPrefix,
+		embed,
+	)
+	if r.URL.Query().Get("lang") != "" {
+		view.ActionPath += "?lang=" + url.QueryEscape(lang)
+	}
+	if form.FormError != "" {
+		// FormError carries the error code until the language is known
+		form.FormError = view.T[form.FormError]
+	}
+	view.CardURL = requestOrigin(r) + s.apiPrefix + "/campaigns/" + url.PathEscape(campaignID) + "/card.png"
+	view.RecentLimit = s.recentLimit
+	view.Form = form
+	view.Signed = r.URL.Query().Get("signed") == "1"
+
+	frameAncestors := "'self'"
+	if embed {
+		frameAncestors = s.embedFrameAncestors()
+	}
+	w.Header().Set("Content-Security-Policy", "frame-ancestors "+frameAncestors)
+
+	s.renderer.RenderCampaignPage(w, statusCode, view)
+}
+
+// embedFrameAncestors lets the CORS allowlist double as the list of sites
+// allowed to frame the embed.
+func (s *Server) embedFrameAncestors() string {
+	ancestors := []string{"'self'"}
+	origins, err := s.svc.AllowedOrigins()
+	if err != nil {
+		return ancestors[0]
+	}
+
+	for _, origin := range origins {
+		if strings.ContainsAny(origin, " ;,'") {
+			continue
+		}
+		ancestors = append(ancestors, origin)
+	}
+	return strings.Join(ancestors, " ")
+}
+
+func requestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+func (s *Server) handleWidget(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	http.ServeFileFS(w, r, s.renderer.staticFS, "widget.js")
+}
@@ -0,0 +1,73 @@
+package public
+
+import (
+	"net/http"
+	"strings"
+
+	"cosign/internal/service"
+)
+
+const defaultRecentLimit = 10
+
+type Options struct {
+	Service     *service.Service
+	APIPrefix   string
+	RecentLimit int
+}
+
+// Server renders the hosted campaign pages, the iframe embed and the widget
+// script. It talks to the service directly since it runs inside `cosign serve`.
+type Server struct {
+	svc         *service.Service
+	renderer    *Renderer
+	apiPrefix   string
+	recentLimit int
+}
+
+func New(opts Options) (*Server, error) {
+	renderer, err := NewRenderer()
+	if err != nil {
+		return nil, err
+	}
+
+	recentLimit := opts.RecentLimit
+	if recentLimit < 1 {
+		recentLimit = defaultRecentLimit
+	}
+
+	return &Server{
+		svc:         opts.Service,
+		renderer:    renderer,
+		apiPrefix:   strings.TrimSuffix(opts.APIPrefix, "/"),
+		recentLimit: recentLimit,
+	}, nil
+}
+
+// Mounts returns the root patterns the public pages are served under.
+func (s *Server) Mounts() []service.Mount {
+	handler := s.BuildRouter()
+	return []service.Mount{
+		{Pattern: "/c/", Handler: handler},
+		{Pattern: "/public/", Handler: handler},
+		{Pattern: "/widget.js", Handler: handler},
+	}
+}
+
+func (s *Server) BuildRouter() http.Handler {
+	mux := http.NewServeMux()
+
+	static := http.StripPrefix("/public/", s.renderer.StaticHandler())
+	mux.Handle("GET /public/", static)
+	mux.HandleFunc("GET /widget.js", s.handleWidget)
+
+	mux.HandleFunc("GET /c/{campaign_id}", s.handleCampaignPage)
+	mux.HandleFunc("POST /c/{campaign_id}", s.svc.WithRateLimit(s.handleSign))
+	mux.HandleFunc("GET /c/{campaign_id}/embed", s.handleCampaignEmbed)
+	mux.HandleFunc("POST /c/{campaign_id}/embed", s.svc.WithRateLimit(s.handleSign))
+
+	return mux
+}
+
+func campaignIDFromPath(r *http.Request) string {
+	return strings.TrimSpace(r.PathValue("campaign_id"))
+}
@@ -0,0 +1,275 @@
+package public_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"cosign/internal/public"
+	"cosign/internal/service"
+	"cosign/internal/testutil"
+)
+
+func setupPublic(t *testing.T) (*service.Service, http.Handler) {
+	t.Helper()
+
+	svc := testutil.SetupService(t)
+	server, err := public.New(public.Options{Service: svc, APIPrefix: "/api/v1"})
+	if err != nil {
+		t.Fatalf("create public server: %v", err)
+	}
+
+	return svc, server.BuildRouter()
+}
+
+func createThemedCampaign(t *testing.T, svc *service.Service) *service.Campaign {
+	t.Helper()
+
+	campaign, err := svc.CreateCampaign("Save the Park")
+	if err != nil {
+		t.Fatalf("create campaign: %v", err)
+	}
+
+	campaign.Letter = "Dear council,\n\nPlease keep the park open."
+	campaign.Theme = service.CampaignTheme{PrimaryColor: "#aa3300", LogoURL: "https://example.org/logo.png"}
+	if err := svc.UpdateCampaign(*campaign); err != nil {
+		t.Fatalf("update campaign: %v", err)
+	}
+	if err := svc.SetCampaignLocations(campaign.ID, []service.LocationOption{{Value: "North"}, {Value: "South"}}); err != nil {
+		t.Fatalf("set locations: %v", err)
+	}
+
+	// reload so callers update against the current revision
+	campaign, err = svc.GetCampaign(campaign.ID)
+	if err != nil {
+		t.Fatalf("get campaign: %v", err)
+	}
+	return campaign
+}
+
+func postForm(handler http.Handler, path string, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func getPage(handler http.Handler, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestCampaignPageRendersLetterThemeAndForm(t *testing.T) {
+	svc, handler := setupPublic(t)
+	campaign := createThemedCampaign(t, svc)
+
+	rec := getPage(handler, "/c/"+campaign.ID)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"<h1>Save the Park</h1>",
+		"<p>Dear council,</p>",
+		"<p>Please keep the park open.</p>",
+		"--primary: #aa3300",
+		`src="https://example.org/logo.png"`,
+		`<datalist id="sign-locations">`,
+		`action="/c/` + campaign.ID + `"`,
+		`data-events="/api/v1/campaigns/` + campaign.ID + `/events"`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected page to contain %q", want)
+		}
+	}
+
+	if got := rec.Header().Get("Content-Security-Policy"); got != "frame-ancestors 'self'" {
+		t.Fatalf("unexpected page csp: %q", got)
+	}
+}
+
+func TestCampaignPageUsesSelectForStrictLocations(t *testing.T) {
+	svc, handler := setupPublic(t)
+	campaign := createThemedCampaign(t, svc)
+
+	campaign.AllowCustomText = false
+	if err := svc.UpdateCampaign(*campaign); err != nil {
+		t.Fatalf("update campaign: %v", err)
+	}
+
+	body := getPage(handler, "/c/"+campaign.ID).Body.String()
+	if !strings.Contains(body, `<select id="sign-location"`) || !strings.Contains(body, `<option value="North" >North</option>`) {
+		t.Fatalf("expected location select, got %s", body)
+	}
+}
+
+func TestSignFormCreatesSignatureAndRedirects(t *testing.T) {
+	svc, handler := setupPublic(t)
+	campaign := createThemedCampaign(t, svc)
+
+	rec := postForm(handler, "/c/"+campaign.ID, url.Values{
+		"name":     {"Alice Smith"},
+		"email":    {"alice@example.com"},
+		"location": {"North"},
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Location"); got != "/c/"+campaign.ID+"?signed=1" {
+		t.Fatalf("unexpected redirect: %q", got)
+	}
+
+	body := getPage(handler, "/c/"+campaign.ID+"?signed=1").Body.String()
+	if !strings.Contains(body, "Thank you for signing!") {
+		t.Fatalf("expected thank you notice")
+	}
+	if !strings.Contains(body, "<strong>Alice S.</strong>") || strings.Contains(body, "alice@example.com") {
+		t.Fatalf("expected public signer name without email")
+	}
+	if !strings.Contains(body, `<span id="signature-count">1</span> signature`) {
+		t.Fatalf("expected counter of 1")
+	}
+}
+
+func TestSignFormRerendersWithError(t *testing.T) {
+	svc, handler := setupPublic(t)
+	campaign := createThemedCampaign(t, svc)
+
+	rec := postForm(handler, "/c/"+campaign.ID+"/embed", url.Values{
+		"name":     {"Bob"},
+		"email":    {"not-an-email"},
+		"location": {"South"},
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+
+	body := rec.Body.String()
+	if !strings.Contains(body, service.Message(service.DefaultLanguage, service.CodeInvalidEmail)) {
+		t.Fatalf("expected form error in body")
+	}
+	if !strings.Contains(body, `value="Bob"`) || !strings.Contains(body, `action="/c/`+campaign.ID+`/embed"`) {
+		t.Fatalf("expected submitted values preserved in embed form")
+	}
+}
+
+func TestEmbedAllowsFramingFromCORSOrigins(t *testing.T) {
+	svc, handler := setupPublic(t)
+	campaign := createThemedCampaign(t, svc)
+
+	rec := getPage(handler, "/c/"+campaign.ID+"/embed")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Security-Policy"); got != "frame-ancestors 'self' http://test-origin" {
+		t.Fatalf("unexpected embed csp: %q", got)
+	}
+	if strings.Contains(rec.Body.String(), "Please keep the park open.") {
+		t.Fatalf("embed should not include the letter")
+	}
+}
+
+func TestUnknownCampaignRendersNotFound(t *testing.T) {
+	_, handler := setupPublic(t)
+
+	rec := getPage(handler, "/c/missing")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestWidgetScriptIsServed(t *testing.T) {
+	_, handler := setupPublic(t)
+
+	rec := getPage(handler, "/widget.js")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.Contains(got, "javascript") {
+		t.Fatalf("unexpected widget content type: %q", got)
+	}
+
+	body, _ := io.ReadAll(rec.Body)
+	if !strings.Contains(string(body), "/embed") {
+		t.Fatalf("expected widget to reference embed page")
+	}
+}
+
+func TestCampaignPageRendersNegotiatedLanguage(t *testing.T) {
+	svc, handler := setupPublic(t)
+	campaign := createThemedCampaign(t, svc)
+
+	campaign.Translations = map[string]service.CampaignTranslation{"fr": {Name: "Sauvez le parc"}}
+	if err := svc.UpdateCampaign(*campaign); err != nil {
+		t.Fatalf("update campaign: %v", err)
+	}
+	if err := svc.SetCampaignLocations(campaign.ID, []service.LocationOption{
+		{Value: "North", Labels: map[string]string{"fr": "Nord"}},
+	}); err != nil {
+		t.Fatalf("set locations: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/c/"+campaign.ID, nil)
+	req.Header.Set("Accept-Language", "fr-FR,fr;q=0.9,en;q=0.5")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		`<html lang="fr">`,
+		"<h1>Sauvez le parc</h1>",
+		`<option value="North">Nord</option>`,
+		">" + service.Message("fr", "ui_sign") + "</button>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected page to contain %q", want)
+		}
+	}
+
+	rec = postForm(handler, "/c/"+campaign.ID+"?lang=fr", url.Values{
+		"name":     {"Alice"},
+		"email":    {"not-an-email"},
+		"location": {"North"},
+	})
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	message := service.Message("fr", service.CodeInvalidEmail)
+	if !strings.Contains(rec.Body.String(), strings.ReplaceAll(message, "'", "&#39;")) {
+		t.Fatalf("expected french form error %q", message)
+	}
+}
+
+func TestCampaignPageOffersNestedLeavesWithPaths(t *testing.T) {
+	svc, handler := setupPublic(t)
+	campaign := createThemedCampaign(t, svc)
+
+	campaign.AllowCustomText = false
+	if err := svc.UpdateCampaign(*campaign); err != nil {
+		t.Fatalf("update campaign: %v", err)
+	}
+	if err := svc.SetCampaignLocations(campaign.ID, []service.LocationOption{
+		{Value: "Canada", CountryCode: "CA", Children: []service.LocationOption{
+			{Value: "Ontario", SubdivisionCode: "CA-ON", Children: []service.LocationOption{{Value: "Toronto"}}},
+		}},
+	}); err != nil {
+		t.Fatalf("set locations: %v", err)
+	}
+
+	body := getPage(handler, "/c/"+campaign.ID).Body.String()
+	if !strings.Contains(body, `<option value="Toronto" >Canada › Ontario › Toronto</option>`) {
+		t.Fatalf("expected leaf option labelled with its path")
+	}
+	if strings.Contains(body, `<option value="Ontario"`) {
+		t.Fatalf("expected branch locations to be omitted from the select")
+	}
+}
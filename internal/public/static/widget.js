@@ -0,0 +1,48 @@
+// Cosign embeddable widget.
+//
+// <script src="https://cosign.example.org/widget.js" data-campaign="CAMPAIGN_ID" async></script>
+//
+// Replaces each tagged script with an iframe of the campaign embed page and
+// keeps the iframe sized to its content.
+(function () {
+  "use strict";
+
+  var frames = [];
+
+  function mount(script) {
+    var campaign = script.getAttribute("data-campaign");
+    if (!campaign || script.getAttribute("data-cosign-mounted")) {
+      return;
+    }
+    script.setAttribute("data-cosign-mounted", "1");
+
+    var origin = new URL(script.src, window.location.href).origin;
+    var frame = document.createElement("iframe");
+    frame.src = origin + "/c/" + encodeURIComponent(campaign) + "/embed";
+    frame.title = "Sign the letter";
+    frame.loading = "lazy";
+    frame.style.width = "100%";
+    frame.style.border = "0";
+    frame.style.minHeight = "420px";
+    script.parentNode.insertBefore(frame, script.nextSibling);
+    frames.push({ frame: frame, origin: origin });
+  }
+
+  window.addEventListener("message", function (event) {
+    var data = event.data;
+    if (!data || data.type !== "cosign:resize") {
+      return;
+    }
+    for (var i = 0; i < frames.length; i++) {
+      var entry = frames[i];
+      if (entry.frame.contentWindow === event.source && entry.origin === event.origin) {
+        entry.frame.style.height = Math.ceil(data.height) + "px";
+      }
+    }
+  });
+
+  var scripts = document.querySelectorAll("script[data-campaign]");
+  for (var i = 0; i < scripts.length; i++) {
+    mount(scripts[i]);
+  }
+})();
@@ -0,0 +1,172 @@
+package public
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cosign/internal/service"
+)
+
+type CampaignPageView struct {
+	ID              string
+	Lang            string
+	T               map[string]string
+	Name            string
+	Paragraphs      []string
+	Theme           service.CampaignTheme
+	Count           int
+	Goal            int
+	GoalPercent     int
+	Recent          []RecentSignatureView
+	RecentLimit     int
+	Locations       []LocationChoice
+	StrictLocations bool
+	Form            SignFormState
+	Signed          bool
+	Embed           bool
+	ActionPath      string
+	EventsPath      string
+	CardURL         string
+}
+
+type LocationChoice struct {
+	Value string
+	Label string
+}
+
+type RecentSignatureView struct {
+	Name     string
+	Location string
+	SignedOn string
+}
+
+type SignFormState struct {
+	Name      string
+	Email     string
+	Location  string
+	FormError string
+}
+
+type ErrorPageView struct {
+	Lang    string
+	Status  int
+	Message string
+}
+
+func NewCampaignPageView(
+	campaign *service.Campaign,
+	locations []service.LocationOption,
+	signatures *service.PublicSignatures,
+	apiPrefix string,
+	embed bool,
+) CampaignPageView {
+	campaignPath := "/c/" + url.PathEscape(campaign.ID)
+	actionPath := campaignPath
+	if embed {
+		actionPath += "/embed"
+	}
+
+	byValue := make(map[string]service.LocationOption, len(locations))
+	for _, loc := range locations {
+		byValue[loc.Value] = loc
+	}
+
+	selectable := service.SelectableLocations(locations, campaign.LocationLevel)
+	choices := make([]LocationChoice, 0, len(selectable))
+	for _, loc := range selectable {
+		choices = append(choices, LocationChoice{Value: loc.Value, Label: locationPath(loc, byValue)})
+	}
+
+	var count int
+	var recent []RecentSignatureView
+	if signatures != nil {
+		count = signatures.Total
+		recent = make([]RecentSignatureView, 0, len(signatures.Signatures))
+		for _, signature := range signatures.Signatures {
+			recent = append(recent, RecentSignatureView{
+				Name:     signature.Name,
+				Location: signature.Location,
+				SignedOn: time.Unix(signature.CreatedAt, 0).UTC().Format("Jan 2, 2006"),
+			})
+		}
+	}
+
+	goalPercent := 0
+	if campaign.Goal > 0 {
+		goalPercent = min(count*100/campaign.Goal, 100)
+	}
+
+	return CampaignPageView{
+		ID:              campaign.ID,
+		Lang:            campaign.Language,
+		T:               service.Messages(campaign.Language),
+		Name:            campaign.Name,
+		Paragraphs:      letterParagraphs(campaign.Letter),
+		Theme:           campaign.Theme,
+		Count:           count,
+		Goal:            campaign.Goal,
+		GoalPercent:     goalPercent,
+		Recent:          recent,
+		Locations:       choices,
+		StrictLocations: !campaign.AllowCustomText && len(choices) > 0,
+		Embed:           embed,
+		ActionPath:      actionPath,
+		EventsPath:      apiPrefix + "/campaigns/" + url.PathEscape(campaign.ID) + "/events",
+	}
+}
+
+// locationPath labels nested options with their ancestors, e.g.
+// "Canada › Ontario › Toronto".
+func locationPath(loc service.LocationOption, byValue map[string]service.LocationOption) string {
+	parts := []string{optionLabel(loc)}
+	for parent := loc.Parent; parent != "" && len(parts) <= len(byValue); parent = byValue[parent].Parent {
+		parts = append([]string{optionLabel(byValue[parent])}, parts...)
+	}
+	return strings.Join(parts, " › ")
+}
+
+func optionLabel(loc service.LocationOption) string {
+	if loc.Label != "" {
+		return loc.Label
+	}
+	return loc.Value
+}
+
+// letterParagraphs splits the letter on blank lines; single newlines are
+// preserved by the stylesheet.
+func letterParagraphs(letter string) []string {
+	letter = strings.ReplaceAll(letter, "\r\n", "\n")
+
+	var paragraphs []string
+	for _, block := range strings.Split(letter, "\n\n") {
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+		paragraphs = append(paragraphs, block)
+	}
+	return paragraphs
+}
+
+func (r *Renderer) RenderCampaignPage(
+	w http.ResponseWriter,
+	statusCode int,
+	view CampaignPageView,
+) {
+	r.renderTemplate(w, statusCode, "campaign_page", view)
+}
+
+func (r *Renderer) RenderErrorPage(
+	w http.ResponseWriter,
+	statusCode int,
+	lang string,
+	message string,
+) {
+	r.renderTemplate(w, statusCode, "error_page", ErrorPageView{
+		Lang:    lang,
+		Status:  statusCode,
+		Message: message,
+	})
+}
@@ -0,0 +1,376 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	badgeStatsTTL     = 30 * time.Second
+	badgeCacheControl = "public, max-age=60"
+	badgeMaxLabelLen  = 40
+
+	cardWidth  = 1200
+	cardHeight = 630
+	cardMargin = 80
+)
+
+var (
+	cardDefaultPrimary    = color.RGBA{R: 0x1f, G: 0x6f, B: 0xeb, A: 0xff}
+	cardDefaultBackground = color.RGBA{R: 0xf6, G: 0xf7, B: 0xf9, A: 0xff}
+	cardText              = color.RGBA{R: 0x1d, G: 0x23, B: 0x30, A: 0xff}
+	cardTrack             = color.RGBA{R: 0xd7, G: 0xdc, B: 0xe4, A: 0xff}
+
+	// cardFont is Go Regular, which covers Latin, Greek and Cyrillic and so
+	// every supported language. Characters outside it are drawn as "?".
+	cardFont = sync.OnceValues(func() (*opentype.Font, error) {
+		return opentype.Parse(goregular.TTF)
+	})
+)
+
+type badgeStats struct {
+	campaign *Campaign
+	count    int
+	loadedAt time.Time
+}
+
+type renderedCard struct {
+	etag string
+	body []byte
+}
+
+// badgeCache keeps campaign counts and rendered cards in memory for a short
+// TTL so heavily embedded badges don't query SQLite on every view.
+type badgeCache struct {
+	mu    sync.Mutex
+	stats map[string]badgeStats
+	cards map[string]renderedCard
+}
+
+func newBadgeCache() *badgeCache {
+	return &badgeCache{
+		stats: make(map[string]badgeStats),
+		cards: make(map[string]renderedCard),
+	}
+}
+
+func (c *badgeCache) invalidate(campaignID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.stats, campaignID)
+	delete(c.cards, campaignID)
+}
+
+func (s *Service) badgeStats(campaignID string) (*Campaign, int, error) {
+	now := s.clock()
+
+	s.badges.mu.Lock()
+	cached, ok := s.badges.stats[campaignID]
+	s.badges.mu.Unlock()
+	if ok && now.Sub(cached.loadedAt) < badgeStatsTTL {
+		return cached.campaign, cached.count, nil
+	}
+
+	campaign, err := s.GetCampaign(campaignID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := s.store.CountSignatures(campaignID, visibleSignatures)
+	if err != nil {
+		return nil, 0, DatabaseError{Err: err}
+	}
+
+	s.badges.mu.Lock()
+	s.badges.stats[campaignID] = badgeStats{campaign: campaign, count: count, loadedAt: now}
+	s.badges.mu.Unlock()
+
+	return campaign, count, nil
+}
+
+func (s *Service) buildPublicBadgeRouter(mux *routeMux, _ Middleware) {
+	mux.HandleFunc("GET /{campaign_id}/badge.svg", s.handleBadge)
+	mux.HandleFunc("GET /{campaign_id}/card.png", s.handleCard)
+}
+
+func (s *Service) handleBadge(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	campaign, count, ok := s.loadBadgeStats(w, r, campaignID)
+	if !ok {
+		return
+	}
+
+	label := strings.TrimSpace(r.URL.Query().Get("label"))
+	if label == "" || len(label) > badgeMaxLabelLen {
+		label = "signatures"
+	}
+
+	value := formatCount(count)
+	if campaign.Goal > 0 {
+		value += " / " + formatCount(campaign.Goal)
+	}
+
+	etag := badgeETag("svg", campaign, count, label)
+	if writeNotModified(w, r, etag) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(renderBadgeSVG(label, value, themeColorOr(campaign.Theme.PrimaryColor, "#1f6feb")))
+}
+
+func (s *Service) handleCard(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	campaign, count, ok := s.loadBadgeStats(w, r, campaignID)
+	if !ok {
+		return
+	}
+
+	etag := badgeETag("png", campaign, count, "")
+	if writeNotModified(w, r, etag) {
+		return
+	}
+
+	s.badges.mu.Lock()
+	cached, hit := s.badges.cards[campaignID]
+	s.badges.mu.Unlock()
+
+	body := cached.body
+	if !hit || cached.etag != etag {
+		var err error
+		body, err = renderCardPNG(campaign, count)
+		if err != nil {
+			writePublicError(w, r, http.StatusInternalServerError, CodeInternalError)
+			return
+		}
+
+		s.badges.mu.Lock()
+		s.badges.cards[campaignID] = renderedCard{etag: etag, body: body}
+		s.badges.mu.Unlock()
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func (s *Service) loadBadgeStats(w http.ResponseWriter, r *http.Request, campaignID string) (*Campaign, int, bool) {
+	if campaignID == "" {
+		writePublicError(w, r, http.StatusBadRequest, CodeCampaignIDRequired)
+		return nil, 0, false
+	}
+
+	campaign, count, err := s.badgeStats(campaignID)
+	if err != nil {
+		writePublicError(w, r, ErrorStatus(err), ErrorCode(err))
+		return nil, 0, false
+	}
+
+	return campaign, count, true
+}
+
+func badgeETag(kind string, campaign *Campaign, count int, label string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf(
+		"%s|%s|%s|%d|%d|%s|%s|%s",
+		kind,
+		campaign.ID,
+		campaign.Name,
+		count,
+		campaign.Goal,
+		campaign.Theme.PrimaryColor,
+		campaign.Theme.BackgroundColor,
+		label,
+	)))
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// writeNotModified sets the caching headers and answers 304 when the client
+// already holds the current representation.
+func writeNotModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", badgeCacheControl)
+
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+func renderBadgeSVG(label, value, valueColor string) []byte {
+	// approximate Verdana 11px advance so text fits without measuring
+	const charWidth = 7
+	const padding = 10
+	labelWidth := len([]rune(label))*charWidth + padding*2
+	valueWidth := len([]rune(value))*charWidth + padding*2
+	total := labelWidth + valueWidth
+
+	label = html.EscapeString(label)
+	value = html.EscapeString(value)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`, total, label, value)
+	fmt.Fprintf(&b, `<title>%s: %s</title>`, label, value)
+	fmt.Fprintf(&b, `<clipPath id="r"><rect width="%d" height="20" rx="3"/></clipPath>`, total)
+	fmt.Fprintf(&b, `<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/><rect x="%d" width="%d" height="20" fill="%s"/></g>`, labelWidth, labelWidth, valueWidth, valueColor)
+	b.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	fmt.Fprintf(&b, `<text x="%d" y="14">%s</text>`, labelWidth/2, label)
+	fmt.Fprintf(&b, `<text x="%d" y="14">%s</text>`, labelWidth+valueWidth/2, value)
+	b.WriteString(`</g></svg>`)
+	return b.Bytes()
+}
+
+func renderCardPNG(campaign *Campaign, count int) ([]byte, error) {
+	primary := parseThemeColor(campaign.Theme.PrimaryColor, cardDefaultPrimary)
+	background := parseThemeColor(campaign.Theme.BackgroundColor, cardDefaultBackground)
+
+	img := image.NewRGBA(image.Rect(0, 0, cardWidth, cardHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, cardWidth, 16), image.NewUniform(primary), image.Point{}, draw.Src)
+
+	if err := drawText(img, cardMargin, 120, campaign.Name, 48, cardText); err != nil {
+		return nil, err
+	}
+
+	countLine := formatCount(count) + " signatures"
+	if err := drawText(img, cardMargin, 300, countLine, 72, primary); err != nil {
+		return nil, err
+	}
+
+	if campaign.Goal > 0 {
+		progress := float64(count) / float64(campaign.Goal)
+		if progress > 1 {
+			progress = 1
+		}
+
+		bar := image.Rect(cardMargin, 460, cardWidth-cardMargin, 500)
+		draw.Draw(img, bar, image.NewUniform(cardTrack), image.Point{}, draw.Src)
+		filled := bar
+		filled.Max.X = bar.Min.X + int(float64(bar.Dx())*progress)
+		draw.Draw(img, filled, image.NewUniform(primary), image.Point{}, draw.Src)
+
+		goalLine := fmt.Sprintf("%d%% of %s goal", int(progress*100), formatCount(campaign.Goal))
+		if err := drawText(img, cardMargin, 530, goalLine, 36, cardText); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// drawText draws text with its top at y, cut short with "..." where it
+// would run past the card's right margin.
+func drawText(dst *image.RGBA, x, y int, text string, size float64, c color.Color) error {
+	parsed, err := cardFont()
+	if err != nil {
+		return err
+	}
+	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return err
+	}
+	defer face.Close()
+
+	text = fitText(face, coveredText(face, text), cardWidth-cardMargin-x)
+	drawer := font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y+face.Metrics().Ascent.Ceil()),
+	}
+	drawer.DrawString(text)
+	return nil
+}
+
+// coveredText replaces the characters face has no glyph for, which would
+// otherwise be left out without a trace.
+func coveredText(face font.Face, text string) string {
+	return strings.Map(func(r rune) rune {
+		if _, ok := face.GlyphAdvance(r); !ok && !unicode.IsSpace(r) {
+			return '?'
+		}
+		return r
+	}, text)
+}
+
+func fitText(face font.Face, text string, width int) string {
+	if font.MeasureString(face, text).Ceil() <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		cut := strings.TrimRightFunc(string(runes), unicode.IsSpace) + "..."
+		if font.MeasureString(face, cut).Ceil() <= width {
+			return cut
+		}
+	}
+	return ""
+}
+
+func parseThemeColor(raw string, fallback color.RGBA) color.RGBA {
+	if !themeColorRegex.MatchString(raw) {
+		return fallback
+	}
+
+	hexValue := raw[1:]
+	if len(hexValue) == 3 {
+		hexValue = string([]byte{hexValue[0], hexValue[0], hexValue[1], hexValue[1], hexValue[2], hexValue[2]})
+	}
+
+	v, err := strconv.ParseUint(hexValue, 16, 32)
+	if err != nil {
+		return fallback
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+}
+
+func themeColorOr(raw, fallback string) string {
+	if themeColorRegex.MatchString(raw) {
+		return raw
+	}
+	return fallback
+}
+
+// formatCount renders n with thousands separators, e.g. 1234 becomes "1,234".
+func formatCount(n int) string {
+	raw := strconv.Itoa(n)
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+
+	var b strings.Builder
+	for i, digit := range raw {
+		if i > 0 && (len(raw)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
+}
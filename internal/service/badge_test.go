@@ -0,0 +1,162 @@
+package service_test
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cosign/internal/service"
+	"cosign/internal/testutil"
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+// cardNameBand is where the card draws the campaign name.
+var cardNameBand = image.Rect(80, 120, 1120, 190)
+
+func getImage(handler http.Handler, path, etag string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestBadgeShowsCountAndGoalWithETag(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Badge")
+
+	setGoal := wire.TestPut[service.Campaign](handler, "/admin/campaigns/"+campaign.ID, `{"goal":5000}`, authHeader())
+	setGoal.ExpectStatus(t, http.StatusOK)
+
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		sign := wire.TestPost[service.Signature](
+			handler,
+			"/campaigns/"+campaign.ID+"/signatures",
+			`{"name":"Signer","email":"`+email+`","location":"NYC"}`,
+		)
+		sign.ExpectStatus(t, http.StatusCreated)
+	}
+
+	rec := getImage(handler, "/campaigns/"+campaign.ID+"/badge.svg?label=%3Cscript%3E", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "image/svg+xml") {
+		t.Fatalf("unexpected content type: %q", got)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "2 / 5,000") {
+		t.Fatalf("expected count and goal in badge: %s", body)
+	}
+	if strings.Contains(body, "<script>") {
+		t.Fatalf("badge label was not escaped: %s", body)
+	}
+
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("expected etag")
+	}
+	cached := getImage(handler, "/campaigns/"+campaign.ID+"/badge.svg?label=%3Cscript%3E", etag)
+	if cached.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", cached.Code)
+	}
+
+	sign := wire.TestPost[service.Signature](
+		handler,
+		"/campaigns/"+campaign.ID+"/signatures",
+		`{"name":"Signer","email":"c@example.com","location":"NYC"}`,
+	)
+	sign.ExpectStatus(t, http.StatusCreated)
+
+	changed := getImage(handler, "/campaigns/"+campaign.ID+"/badge.svg?label=%3Cscript%3E", etag)
+	if changed.Code != http.StatusOK || !strings.Contains(changed.Body.String(), "3 / 5,000") {
+		t.Fatalf("expected fresh badge after new signature, got %d", changed.Code)
+	}
+}
+
+func TestCardRendersPNG(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Card")
+
+	rec := getImage(handler, "/campaigns/"+campaign.ID+"/card.png", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "image/png" {
+		t.Fatalf("unexpected content type: %q", got)
+	}
+
+	img, err := png.Decode(bytes.NewReader(rec.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+	if bounds := img.Bounds(); bounds.Dx() != 1200 || bounds.Dy() != 630 {
+		t.Fatalf("unexpected card size: %v", bounds)
+	}
+
+	cached := getImage(handler, "/campaigns/"+campaign.ID+"/card.png", rec.Header().Get("ETag"))
+	if cached.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", cached.Code)
+	}
+
+	missing := getImage(handler, "/campaigns/missing/card.png", "")
+	if missing.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown campaign, got %d", missing.Code)
+	}
+}
+
+func TestCardDrawsNonASCIINames(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+
+	for _, name := range []string{"ÉÑÜÇ", "Парк Ελλάδα", "公園"} {
+		campaign := createCampaign(t, handler, name)
+		rec := getImage(handler, "/campaigns/"+campaign.ID+"/card.png", "")
+		img, err := png.Decode(bytes.NewReader(rec.Body.Bytes()))
+		if err != nil {
+			t.Fatalf("%s: decode png: %v", name, err)
+		}
+
+		background := img.At(0, cardNameBand.Min.Y)
+		drawn := 0
+		for y := cardNameBand.Min.Y; y < cardNameBand.Max.Y; y++ {
+			for x := cardNameBand.Min.X; x < cardNameBand.Max.X; x++ {
+				if img.At(x, y) != background {
+					drawn++
+				}
+			}
+		}
+		if drawn < 100 {
+			t.Fatalf("%s: expected the name drawn, got %d pixels", name, drawn)
+		}
+	}
+}
+
+func TestBadgeErrorsAreLocalized(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+
+	for _, path := range []string{"/campaigns/cmp-missing/badge.svg", "/campaigns/cmp-missing/card.png"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Language", "fr")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("%s: expected 404, got %d", path, rec.Code)
+		}
+
+		apiErr := decodeAPIError(t, rec.Body.Bytes())
+		want := service.Message("fr", service.CodeCampaignNotFound)
+		if apiErr.Code != service.CodeCampaignNotFound || apiErr.Message != want {
+			t.Fatalf("%s: expected localized not found, got %+v", path, apiErr)
+		}
+	}
+}
@@ -1,46 +1,55 @@
 package service
 
 import (
+	"cosign/pkg/cosignapi"
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/url"
+	"regexp"
 	"strings"
 
 	"git.sr.ht/~jakintosh/command-go/pkg/wire"
 )
 
-type CreateCampaignRequest struct {
-	Name string `json:"name"`
-}
-
-type UpdateCampaignRequest struct {
-	Name            string `json:"name"`
-	AllowCustomText *bool  `json:"allow_custom_text"`
-}
-
-type CampaignLocationsRequest struct {
-	Locations []LocationOption `json:"locations"`
-}
+var themeColorRegex = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
 
-type CampaignLocationsResponse struct {
-	Locations []LocationOption `json:"locations"`
-}
+type (
+	CreateCampaignRequest     = cosignapi.CreateCampaignRequest
+	UpdateCampaignRequest     = cosignapi.UpdateCampaignRequest
+	CampaignLocationsRequest  = cosignapi.CampaignLocationsRequest
+	CampaignLocationsResponse = cosignapi.CampaignLocationsResponse
+)
 
-func (s *Service) buildPublicCampaignRouter(mux *http.ServeMux, mw Middleware) {
-	mux.HandleFunc("GET /{campaign_id}", mw.cors(s.handleGetCampaign))
-	mux.HandleFunc("OPTIONS /{campaign_id}", mw.cors(s.handleGetCampaign))
-	mux.HandleFunc("GET /{campaign_id}/locations", mw.cors(s.handleGetCampaignLocations))
-	mux.HandleFunc("OPTIONS /{campaign_id}/locations", mw.cors(s.handleGetCampaignLocations))
+func (s *Service) buildPublicCampaignRouter(mux *routeMux, mw Middleware) {
+	mux.HandleFunc("GET /{campaign_id}", mw.cors(s.handlePublicGetCampaign))
+	mux.HandleFunc("OPTIONS /{campaign_id}", mw.cors(s.handlePublicGetCampaign))
+	mux.HandleFunc("GET /{campaign_id}/locations", mw.cors(s.handlePublicGetCampaignLocations))
+	mux.HandleFunc("OPTIONS /{campaign_id}/locations", mw.cors(s.handlePublicGetCampaignLocations))
+	mux.HandleFunc("GET /{campaign_id}/locations/geojson", mw.cors(s.handleGetSignatureMap))
+	mux.HandleFunc("OPTIONS /{campaign_id}/locations/geojson", mw.cors(s.handleGetSignatureMap))
 }
 
-func (s *Service) buildAdminCampaignRouter(mux *http.ServeMux, _ Middleware) {
+func (s *Service) buildAdminCampaignRouter(mux *routeMux, mw Middleware) {
 	mux.HandleFunc("GET /{$}", s.handleListCampaigns)
-	mux.HandleFunc("POST /{$}", s.handleCreateCampaign)
+	mux.HandleFunc("POST /{$}", mw.idempotent(s.handleCreateCampaign))
+	mux.HandleFunc("GET /trash", s.handleListDeletedCampaigns)
 	mux.HandleFunc("GET /{campaign_id}", s.handleGetCampaign)
 	mux.HandleFunc("PUT /{campaign_id}", s.handleUpdateCampaign)
 	mux.HandleFunc("DELETE /{campaign_id}", s.handleDeleteCampaign)
+	mux.HandleFunc("POST /{campaign_id}/restore", s.handleRestoreCampaign)
 	mux.HandleFunc("GET /{campaign_id}/locations", s.handleGetCampaignLocations)
 	mux.HandleFunc("PUT /{campaign_id}/locations", s.handleUpdateCampaignLocations)
+	mux.HandleFunc("POST /{campaign_id}/locations", s.handleCreateCampaignLocation)
+	mux.HandleFunc("GET /{campaign_id}/locations/stats", s.handleGetCampaignLocationStats)
+	mux.HandleFunc("GET /{campaign_id}/locations/suggestions", s.handleGetLocationSuggestions)
+	mux.HandleFunc("POST /{campaign_id}/locations/normalize", s.handleNormalizeLocations)
+	mux.HandleFunc("POST /{campaign_id}/locations/coordinates", s.handleImportLocationCoordinates)
+	mux.HandleFunc("GET /{campaign_id}/locations/{location_id}", s.handleGetCampaignLocation)
+	mux.HandleFunc("PATCH /{campaign_id}/locations/{location_id}", s.handleUpdateCampaignLocation)
+	mux.HandleFunc("DELETE /{campaign_id}/locations/{location_id}", s.handleDeleteCampaignLocation)
+	mux.HandleFunc("POST /{campaign_id}/locations/{location_id}/move", s.handleMoveCampaignLocation)
+	mux.HandleFunc("POST /{campaign_id}/locations/{location_id}/merge", s.handleMergeCampaignLocation)
 }
 
 func (s *Service) CreateCampaign(name string) (*Campaign, error) {
@@ -61,10 +70,13 @@ func (s *Service) CreateCampaign(name string) (*Campaign, error) {
 	}
 
 	return &Campaign{
-		ID:              id,
-		Name:            name,
-		AllowCustomText: true,
-		CreatedAt:       createdAt,
+		ID:                id,
+		Name:              name,
+		Language:          DefaultLanguage,
+		AllowCustomText:   true,
+		CreatedAt:         createdAt,
+		Revision:          1,
+		LocationsRevision: 1,
 	}, nil
 }
 
@@ -105,25 +117,121 @@ func (s *Service) ListCampaigns(limit, offset int) (*Campaigns, error) {
 	}, nil
 }
 
-func (s *Service) UpdateCampaign(id, name string, allowCustomText bool) error {
-	name = strings.TrimSpace(name)
-	if name == "" {
+func (s *Service) UpdateCampaign(campaign Campaign) error {
+	campaign.Name = strings.TrimSpace(campaign.Name)
+	if campaign.Name == "" {
 		return ErrEmptyCampaignName
 	}
 
-	err := s.store.UpdateCampaign(id, name, allowCustomText)
+	campaign.Letter = strings.TrimSpace(campaign.Letter)
+	if campaign.Goal < 0 {
+		return ErrInvalidGoal
+	}
+	if campaign.LocationLevel < 0 {
+		return ErrInvalidLocationLevel
+	}
+
+	language, err := normalizeLanguageTag(campaign.Language)
 	if err != nil {
-		if errors.Is(err, ErrCampaignNotFound) {
+		return err
+	}
+	campaign.Language = language
+
+	translations, err := normalizeCampaignTranslations(campaign.Translations)
+	if err != nil {
+		return err
+	}
+	campaign.Translations = translations
+
+	theme, err := normalizeCampaignTheme(campaign.Theme)
+	if err != nil {
+		return err
+	}
+	campaign.Theme = theme
+
+	// redirects are checked against the allowlist only when they change, so
+	// dropping an origin does not block unrelated edits
+	current, err := s.GetCampaign(campaign.ID)
+	if err != nil {
+		return err
+	}
+	campaign.SuccessURL = strings.TrimSpace(campaign.SuccessURL)
+	campaign.ErrorURL = strings.TrimSpace(campaign.ErrorURL)
+	for _, target := range []struct{ field, value, stored string }{
+		{"success_url", campaign.SuccessURL, current.SuccessURL},
+		{"error_url", campaign.ErrorURL, current.ErrorURL},
+	} {
+		if target.value == "" || target.value == target.stored {
+			continue
+		}
+		if err := s.validateRedirectURL(target.value); err != nil {
+			return withField(target.field, err)
+		}
+	}
+
+	err = s.store.UpdateCampaign(campaign)
+	if err != nil {
+		if errors.Is(err, ErrCampaignNotFound) || errors.Is(err, ErrRevisionConflict) {
 			return err
 		}
 		return DatabaseError{Err: err}
 	}
 
+	s.badges.invalidate(campaign.ID)
+	if updated, err := s.store.GetCampaign(campaign.ID); err == nil {
+		s.enqueueWebhookEvent(campaign.ID, EventCampaignUpdated, updated)
+	}
+
+	return nil
+}
+
+func normalizeCampaignTheme(theme CampaignTheme) (CampaignTheme, error) {
+	theme.PrimaryColor = strings.TrimSpace(theme.PrimaryColor)
+	theme.BackgroundColor = strings.TrimSpace(theme.BackgroundColor)
+	theme.LogoURL = strings.TrimSpace(theme.LogoURL)
+
+	for _, color := range []struct{ field, value string }{
+		{"theme.primary_color", theme.PrimaryColor},
+		{"theme.background_color", theme.BackgroundColor},
+	} {
+		if color.value != "" && !themeColorRegex.MatchString(color.value) {
+			return CampaignTheme{}, withField(color.field, ErrInvalidThemeColor)
+		}
+	}
+
+	if theme.LogoURL != "" {
+		parsed, err := url.Parse(theme.LogoURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return CampaignTheme{}, ErrInvalidThemeLogoURL
+		}
+	}
+
+	return theme, nil
+}
+
+// validateRedirectURL only allows redirects back to origins in the CORS
+// allowlist so campaign redirects can't be used as open redirects.
+func (s *Service) validateRedirectURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidRedirectURL
+	}
+
+	allowed, err := s.cors.IsAllowed(parsed.Scheme + "://" + parsed.Host)
+	if err != nil {
+		return DatabaseError{Err: err}
+	}
+	if !allowed {
+		return ErrRedirectNotAllowed
+	}
+
 	return nil
 }
 
+// DeleteCampaign moves the campaign to the trash, where it can be restored
+// until the trash retention runs out.
 func (s *Service) DeleteCampaign(id string) error {
-	err := s.store.DeleteCampaign(id)
+	err := s.store.DeleteCampaign(id, s.clock().Unix())
 	if err != nil {
 		if errors.Is(err, ErrCampaignNotFound) {
 			return err
@@ -131,6 +239,9 @@ func (s *Service) DeleteCampaign(id string) error {
 		return DatabaseError{Err: err}
 	}
 
+	s.badges.invalidate(id)
+	s.broadcaster.drop(id)
+
 	return nil
 }
 
@@ -151,31 +262,24 @@ func (s *Service) GetCampaignLocations(campaignID string) ([]LocationOption, err
 		locations = append(locations, *opt)
 	}
 
-	return locations, nil
+	return flattenLocationTree(BuildLocationTree(locations)), nil
 }
 
 func (s *Service) SetCampaignLocations(campaignID string, options []LocationOption) error {
-	normalized := make([]LocationOption, 0, len(options))
-	for idx, loc := range options {
-		value := strings.TrimSpace(loc.Value)
-		if value == "" {
-			return ErrEmptyLocation
-		}
-
-		displayOrder := loc.DisplayOrder
-		if displayOrder <= 0 {
-			displayOrder = idx + 1
-		}
+	return s.SetCampaignLocationsAt(campaignID, options, 0)
+}
 
-		normalized = append(normalized, LocationOption{
-			Value:        value,
-			DisplayOrder: displayOrder,
-		})
+// SetCampaignLocationsAt replaces the location set only if its revision is
+// still the given one; zero replaces unconditionally.
+func (s *Service) SetCampaignLocationsAt(campaignID string, options []LocationOption, revision int64) error {
+	normalized, err := normalizeLocations(options)
+	if err != nil {
+		return err
 	}
 
-	err := s.store.ReplaceCampaignLocations(campaignID, normalized)
+	err = s.store.ReplaceCampaignLocations(campaignID, normalized, revision)
 	if err != nil {
-		if errors.Is(err, ErrCampaignNotFound) {
+		if errors.Is(err, ErrCampaignNotFound) || errors.Is(err, ErrRevisionConflict) {
 			return err
 		}
 		return DatabaseError{Err: err}
@@ -187,18 +291,13 @@ func (s *Service) SetCampaignLocations(campaignID string, options []LocationOpti
 func (s *Service) handleCreateCampaign(w http.ResponseWriter, r *http.Request) {
 	var req CreateCampaignRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		wire.WriteError(w, http.StatusBadRequest, "invalid request body")
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "invalid request body")
 		return
 	}
 
 	campaign, err := s.CreateCampaign(req.Name)
 	if err != nil {
-		switch {
-		case errors.Is(err, ErrEmptyCampaignName):
-			wire.WriteError(w, http.StatusBadRequest, err.Error())
-		default:
-			wire.WriteError(w, http.StatusInternalServerError, "failed to create campaign")
-		}
+		writeServiceError(w, err, "failed to create campaign")
 		return
 	}
 
@@ -208,13 +307,13 @@ func (s *Service) handleCreateCampaign(w http.ResponseWriter, r *http.Request) {
 func (s *Service) handleListCampaigns(w http.ResponseWriter, r *http.Request) {
 	limit, offset, malformed := wire.ParsePagination(r)
 	if malformed != nil {
-		wire.WriteError(w, http.StatusBadRequest, malformed.Error())
+		writeError(w, http.StatusBadRequest, CodeInvalidPagination, malformed.Error())
 		return
 	}
 
 	campaigns, err := s.ListCampaigns(limit, offset)
 	if err != nil {
-		wire.WriteError(w, http.StatusInternalServerError, "failed to list campaigns")
+		writeError(w, http.StatusInternalServerError, CodeInternalError, "failed to list campaigns")
 		return
 	}
 
@@ -224,7 +323,24 @@ func (s *Service) handleListCampaigns(w http.ResponseWriter, r *http.Request) {
 func (s *Service) handleGetCampaign(w http.ResponseWriter, r *http.Request) {
 	campaignID := campaignIDFromPath(r)
 	if campaignID == "" {
-		wire.WriteError(w, http.StatusBadRequest, "campaign id required")
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
+		return
+	}
+
+	campaign, err := s.GetCampaign(campaignID)
+	if err != nil {
+		writeServiceError(w, err, "failed to load campaign")
+		return
+	}
+
+	w.Header().Set("ETag", revisionETag(campaign.Revision))
+	wire.WriteData(w, http.StatusOK, campaign)
+}
+
+func (s *Service) handlePublicGetCampaign(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writePublicError(w, r, http.StatusBadRequest, CodeCampaignIDRequired)
 		return
 	}
 
@@ -232,85 +348,146 @@ func (s *Service) handleGetCampaign(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		switch {
 		case errors.Is(err, ErrCampaignNotFound):
-			wire.WriteError(w, http.StatusNotFound, "campaign not found")
+			writePublicError(w, r, http.StatusNotFound, CodeCampaignNotFound)
 		default:
-			wire.WriteError(w, http.StatusInternalServerError, "failed to load campaign")
+			writePublicError(w, r, http.StatusInternalServerError, CodeInternalError)
 		}
 		return
 	}
 
-	wire.WriteData(w, http.StatusOK, campaign)
+	lang := NegotiateLanguage(r, campaign.Languages())
+	w.Header().Set("Content-Language", lang)
+	w.Header().Add("Vary", "Accept-Language")
+	wire.WriteData(w, http.StatusOK, LocalizeCampaign(campaign, lang))
+}
+
+func (s *Service) handlePublicGetCampaignLocations(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writePublicError(w, r, http.StatusBadRequest, CodeCampaignIDRequired)
+		return
+	}
+
+	campaign, err := s.GetCampaign(campaignID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrCampaignNotFound):
+			writePublicError(w, r, http.StatusNotFound, CodeCampaignNotFound)
+		default:
+			writePublicError(w, r, http.StatusInternalServerError, CodeInternalError)
+		}
+		return
+	}
+
+	locations, err := s.GetCampaignLocations(campaignID)
+	if err != nil {
+		writePublicError(w, r, http.StatusInternalServerError, CodeInternalError)
+		return
+	}
+
+	lang := NegotiateLanguage(r, campaign.Languages())
+	w.Header().Set("Content-Language", lang)
+	w.Header().Add("Vary", "Accept-Language")
+	wire.WriteData(w, http.StatusOK, CampaignLocationsResponse{
+		Locations: BuildLocationTree(LocalizeLocations(locations, lang)),
+	})
 }
 
 func (s *Service) handleUpdateCampaign(w http.ResponseWriter, r *http.Request) {
 	campaignID := campaignIDFromPath(r)
 	if campaignID == "" {
-		wire.WriteError(w, http.StatusBadRequest, "campaign id required")
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
 		return
 	}
 
 	var req UpdateCampaignRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		wire.WriteError(w, http.StatusBadRequest, "invalid request body")
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "invalid request body")
 		return
 	}
 
 	existing, err := s.GetCampaign(campaignID)
 	if err != nil {
-		switch {
-		case errors.Is(err, ErrCampaignNotFound):
-			wire.WriteError(w, http.StatusNotFound, "campaign not found")
-		default:
-			wire.WriteError(w, http.StatusInternalServerError, "failed to load campaign")
-		}
+		writeServiceError(w, err, "failed to load campaign")
 		return
 	}
 
-	name := strings.TrimSpace(req.Name)
-	if name == "" {
-		name = existing.Name
-	}
-
-	allowCustomText := existing.AllowCustomText
-	if req.AllowCustomText != nil {
-		allowCustomText = *req.AllowCustomText
+	conditional, ok := checkRevision(r, req.Revision, existing.Revision)
+	if !ok {
+		writeServiceError(w, ErrRevisionConflict, "")
+		return
 	}
 
-	if err := s.UpdateCampaign(campaignID, name, allowCustomText); err != nil {
-		switch {
-		case errors.Is(err, ErrCampaignNotFound):
-			wire.WriteError(w, http.StatusNotFound, "campaign not found")
-		case errors.Is(err, ErrEmptyCampaignName):
-			wire.WriteError(w, http.StatusBadRequest, err.Error())
-		default:
-			wire.WriteError(w, http.StatusInternalServerError, "failed to update campaign")
+	// unconditional updates reapply their fields to a reloaded campaign when
+	// another write lands between the read and the write
+	for attempt := 1; ; attempt++ {
+		err = s.UpdateCampaign(applyCampaignUpdate(*existing, req))
+		if !errors.Is(err, ErrRevisionConflict) || conditional || attempt == maxUpdateAttempts {
+			break
+		}
+		if existing, err = s.GetCampaign(campaignID); err != nil {
+			break
 		}
+	}
+	if err != nil {
+		writeServiceError(w, err, "failed to update campaign")
 		return
 	}
 
 	updated, err := s.GetCampaign(campaignID)
 	if err != nil {
-		wire.WriteError(w, http.StatusInternalServerError, "failed to reload campaign")
+		writeError(w, http.StatusInternalServerError, CodeInternalError, "failed to reload campaign")
 		return
 	}
 
+	w.Header().Set("ETag", revisionETag(updated.Revision))
 	wire.WriteData(w, http.StatusOK, updated)
 }
 
+func applyCampaignUpdate(campaign Campaign, req UpdateCampaignRequest) Campaign {
+	if name := strings.TrimSpace(req.Name); name != "" {
+		campaign.Name = name
+	}
+	if req.Letter != nil {
+		campaign.Letter = *req.Letter
+	}
+	if req.Goal != nil {
+		campaign.Goal = *req.Goal
+	}
+	if req.Language != nil {
+		campaign.Language = *req.Language
+	}
+	if req.Translations != nil {
+		campaign.Translations = req.Translations
+	}
+	if req.AllowCustomText != nil {
+		campaign.AllowCustomText = *req.AllowCustomText
+	}
+	if req.LocationLevel != nil {
+		campaign.LocationLevel = *req.LocationLevel
+	}
+	if req.Theme != nil {
+		campaign.Theme = *req.Theme
+	}
+	if req.SuccessURL != nil {
+		campaign.SuccessURL = *req.SuccessURL
+	}
+	if req.ErrorURL != nil {
+		campaign.ErrorURL = *req.ErrorURL
+	}
+
+	return campaign
+}
+
 func (s *Service) handleDeleteCampaign(w http.ResponseWriter, r *http.Request) {
 	campaignID := campaignIDFromPath(r)
 	if campaignID == "" {
-		wire.WriteError(w, http.StatusBadRequest, "campaign id required")
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
 		return
 	}
 
 	if err := s.DeleteCampaign(campaignID); err != nil {
-		switch {
-		case errors.Is(err, ErrCampaignNotFound):
-			wire.WriteError(w, http.StatusNotFound, "campaign not found")
-		default:
-			wire.WriteError(w, http.StatusInternalServerError, "failed to delete campaign")
-		}
+		writeServiceError(w, err, "failed to delete campaign")
 		return
 	}
 
@@ -320,54 +497,80 @@ func (s *Service) handleDeleteCampaign(w http.ResponseWriter, r *http.Request) {
 func (s *Service) handleGetCampaignLocations(w http.ResponseWriter, r *http.Request) {
 	campaignID := campaignIDFromPath(r)
 	if campaignID == "" {
-		wire.WriteError(w, http.StatusBadRequest, "campaign id required")
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
 		return
 	}
 
-	locations, err := s.GetCampaignLocations(campaignID)
+	response, err := s.loadCampaignLocations(campaignID)
 	if err != nil {
-		switch {
-		case errors.Is(err, ErrCampaignNotFound):
-			wire.WriteError(w, http.StatusNotFound, "campaign not found")
-		default:
-			wire.WriteError(w, http.StatusInternalServerError, "failed to get campaign locations")
-		}
+		writeServiceError(w, err, "failed to get campaign locations")
 		return
 	}
 
-	wire.WriteData(w, http.StatusOK, CampaignLocationsResponse{Locations: locations})
+	w.Header().Set("ETag", revisionETag(response.Revision))
+	wire.WriteData(w, http.StatusOK, response)
+}
+
+// loadCampaignLocations reads the revision before the list, so a write
+// landing in between leaves the tag stale and a later If-Match fails safe
+// rather than passing against data the client never saw.
+func (s *Service) loadCampaignLocations(campaignID string) (CampaignLocationsResponse, error) {
+	campaign, err := s.GetCampaign(campaignID)
+	if err != nil {
+		return CampaignLocationsResponse{}, err
+	}
+
+	locations, err := s.GetCampaignLocations(campaignID)
+	if err != nil {
+		return CampaignLocationsResponse{}, err
+	}
+
+	return CampaignLocationsResponse{
+		Locations: locations,
+		Revision:  campaign.LocationsRevision,
+	}, nil
 }
 
 func (s *Service) handleUpdateCampaignLocations(w http.ResponseWriter, r *http.Request) {
 	campaignID := campaignIDFromPath(r)
 	if campaignID == "" {
-		wire.WriteError(w, http.StatusBadRequest, "campaign id required")
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
 		return
 	}
 
 	var req CampaignLocationsRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		wire.WriteError(w, http.StatusBadRequest, "invalid request body")
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "invalid request body")
 		return
 	}
 
-	if err := s.SetCampaignLocations(campaignID, req.Locations); err != nil {
-		switch {
-		case errors.Is(err, ErrCampaignNotFound):
-			wire.WriteError(w, http.StatusNotFound, "campaign not found")
-		case errors.Is(err, ErrEmptyLocation):
-			wire.WriteError(w, http.StatusBadRequest, err.Error())
-		default:
-			wire.WriteError(w, http.StatusInternalServerError, "failed to update campaign locations")
-		}
+	campaign, err := s.GetCampaign(campaignID)
+	if err != nil {
+		writeServiceError(w, err, "failed to load campaign")
 		return
 	}
 
-	updated, err := s.GetCampaignLocations(campaignID)
+	conditional, ok := checkRevision(r, req.Revision, campaign.LocationsRevision)
+	if !ok {
+		writeServiceError(w, ErrRevisionConflict, "")
+		return
+	}
+
+	var revision int64
+	if conditional {
+		revision = campaign.LocationsRevision
+	}
+	if err := s.SetCampaignLocationsAt(campaignID, req.Locations, revision); err != nil {
+		writeServiceError(w, err, "failed to update campaign locations")
+		return
+	}
+
+	updated, err := s.loadCampaignLocations(campaignID)
 	if err != nil {
-		wire.WriteError(w, http.StatusInternalServerError, "failed to load updated campaign locations")
+		writeError(w, http.StatusInternalServerError, CodeInternalError, "failed to load updated campaign locations")
 		return
 	}
 
-	wire.WriteData(w, http.StatusOK, CampaignLocationsResponse{Locations: updated})
+	w.Header().Set("ETag", revisionETag(updated.Revision))
+	wire.WriteData(w, http.StatusOK, updated)
 }
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"cosign/pkg/cosignapi"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+const (
+	CodeNotFound               = cosignapi.CodeNotFound
+	CodeSignatureNotFound      = cosignapi.CodeSignatureNotFound
+	CodeEmptyCampaignName      = cosignapi.CodeEmptyCampaignName
+	CodeInvalidThemeColor      = cosignapi.CodeInvalidThemeColor
+	CodeInvalidThemeLogoURL    = cosignapi.CodeInvalidThemeLogoURL
+	CodeInvalidRedirectURL     = cosignapi.CodeInvalidRedirectURL
+	CodeRedirectNotAllowed     = cosignapi.CodeRedirectNotAllowed
+	CodeInvalidGoal            = cosignapi.CodeInvalidGoal
+	CodeInvalidLanguage        = cosignapi.CodeInvalidLanguage
+	CodeInvalidPagination      = cosignapi.CodeInvalidPagination
+	CodeInvalidID              = cosignapi.CodeInvalidID
+	CodeLocationNotFound       = cosignapi.CodeLocationNotFound
+	CodeDuplicateLocation      = cosignapi.CodeDuplicateLocation
+	CodeDuplicateLocationAlias = cosignapi.CodeDuplicateLocationAlias
+	CodeUnknownLocationParent  = cosignapi.CodeUnknownLocationParent
+	CodeLocationCycle          = cosignapi.CodeLocationCycle
+	CodeInvalidCountryCode     = cosignapi.CodeInvalidCountryCode
+	CodeInvalidSubdivisionCode = cosignapi.CodeInvalidSubdivisionCode
+	CodeInvalidLocationLevel   = cosignapi.CodeInvalidLocationLevel
+	CodeInvalidCoordinates     = cosignapi.CodeInvalidCoordinates
+	CodeInvalidGazetteer       = cosignapi.CodeInvalidGazetteer
+	CodeMergeIntoSelf          = cosignapi.CodeMergeIntoSelf
+	CodeRevisionConflict       = cosignapi.CodeRevisionConflict
+	CodeWebhookNotFound        = cosignapi.CodeWebhookNotFound
+	CodeWebhookDeliveryMissing = cosignapi.CodeWebhookDeliveryMissing
+	CodeInvalidWebhookURL      = cosignapi.CodeInvalidWebhookURL
+	CodeInvalidWebhookEvent    = cosignapi.CodeInvalidWebhookEvent
+	CodeEmptyWebhookEvents     = cosignapi.CodeEmptyWebhookEvents
+	CodeImportBatchTooLarge    = cosignapi.CodeImportBatchTooLarge
+	CodeInvalidStatsDays       = cosignapi.CodeInvalidStatsDays
+	CodeInvalidVisibility      = cosignapi.CodeInvalidVisibility
+	CodeInvalidBulkAction      = cosignapi.CodeInvalidBulkAction
+	CodeBulkTargetRequired     = cosignapi.CodeBulkTargetRequired
+	CodeTooManyBulkIDs         = cosignapi.CodeTooManyBulkIDs
+	CodeBulkOperationNotFound  = cosignapi.CodeBulkOperationNotFound
+	CodeBulkUndoExpired        = cosignapi.CodeBulkUndoExpired
+	CodeUnavailable            = cosignapi.CodeUnavailable
+)
+
+type (
+	APIError   = cosignapi.APIError
+	FieldError = cosignapi.FieldError
+)
+
+type errorResponse struct {
+	Error APIError `json:"error"`
+}
+
+// errorSpec is how the API answers a service error; its code comes from
+// cosignapi, which the client shares.
+type errorSpec struct {
+	err    error
+	status int
+	field  string
+}
+
+func (spec errorSpec) code() string {
+	return cosignapi.ErrorCode(spec.err)
+}
+
+var errorSpecs = []errorSpec{
+	{ErrCampaignNotFound, http.StatusNotFound, ""},
+	{ErrSignatureNotFound, http.StatusNotFound, ""},
+	{ErrInvalidEmail, http.StatusBadRequest, "email"},
+	{ErrDuplicateEmail, http.StatusConflict, "email"},
+	{ErrLocationNotInOptions, http.StatusBadRequest, "location"},
+	{ErrEmptyName, http.StatusBadRequest, "name"},
+	{ErrEmptyEmail, http.StatusBadRequest, "email"},
+	{ErrEmptyLocation, http.StatusBadRequest, "location"},
+	{ErrEmptyCampaignName, http.StatusBadRequest, "name"},
+	{ErrInvalidThemeColor, http.StatusBadRequest, "theme"},
+	{ErrInvalidThemeLogoURL, http.StatusBadRequest, "theme.logo_url"},
+	{ErrInvalidRedirectURL, http.StatusBadRequest, ""},
+	{ErrRedirectNotAllowed, http.StatusBadRequest, ""},
+	{ErrInvalidGoal, http.StatusBadRequest, "goal"},
+	{ErrInvalidLanguage, http.StatusBadRequest, "language"},
+
+	{ErrLocationNotFound, http.StatusNotFound, ""},
+	{ErrDuplicateLocation, http.StatusBadRequest, "value"},
+	{ErrDuplicateLocationAlias, http.StatusBadRequest, "aliases"},
+	{ErrUnknownLocationParent, http.StatusBadRequest, "parent"},
+	{ErrLocationCycle, http.StatusBadRequest, "parent"},
+	{ErrInvalidCountryCode, http.StatusBadRequest, "country_code"},
+	{ErrInvalidSubdivisionCode, http.StatusBadRequest, "subdivision_code"},
+	{ErrInvalidLocationLevel, http.StatusBadRequest, "location_level"},
+	{ErrInvalidCoordinates, http.StatusBadRequest, "latitude"},
+	{ErrInvalidGazetteer, http.StatusBadRequest, ""},
+	{ErrMergeIntoSelf, http.StatusBadRequest, "into_id"},
+	{ErrRevisionConflict, http.StatusPreconditionFailed, ""},
+
+	{ErrWebhookNotFound, http.StatusNotFound, ""},
+	{ErrWebhookDeliveryNotFound, http.StatusNotFound, ""},
+	{ErrInvalidWebhookURL, http.StatusBadRequest, "url"},
+	{ErrInvalidWebhookEvent, http.StatusBadRequest, "events"},
+	{ErrEmptyWebhookEvents, http.StatusBadRequest, "events"},
+
+	{ErrImportBatchTooLarge, http.StatusBadRequest, "signatures"},
+	{ErrInvalidStatsDays, http.StatusBadRequest, "days"},
+	{ErrInvalidVisibility, http.StatusBadRequest, "visibility"},
+
+	{ErrInvalidBulkAction, http.StatusBadRequest, "action"},
+	{ErrBulkTargetRequired, http.StatusBadRequest, "ids"},
+	{ErrTooManyBulkIDs, http.StatusBadRequest, "ids"},
+	{ErrBulkOperationNotFound, http.StatusNotFound, ""},
+	{ErrBulkUndoExpired, http.StatusConflict, ""},
+
+	{ErrTooManyStreams, http.StatusTooManyRequests, ""},
+}
+
+func lookupError(err error) (errorSpec, bool) {
+	for _, spec := range errorSpecs {
+		if errors.Is(err, spec.err) {
+			return spec, true
+		}
+	}
+	return errorSpec{}, false
+}
+
+// ErrorCode maps service errors to stable machine-readable codes.
+func ErrorCode(err error) string {
+	if spec, ok := lookupError(err); ok {
+		return spec.code()
+	}
+	return CodeInternalError
+}
+
+// ErrorStatus maps service errors to the HTTP status the API answers with.
+func ErrorStatus(err error) int {
+	if spec, ok := lookupError(err); ok {
+		return spec.status
+	}
+	return http.StatusInternalServerError
+}
+
+// fieldError attributes an error to a request field more precisely than
+// its default, e.g. which of two redirect urls was rejected.
+type fieldError struct {
+	field string
+	err   error
+}
+
+func (e fieldError) Error() string { return e.err.Error() }
+func (e fieldError) Unwrap() error { return e.err }
+
+func withField(field string, err error) error {
+	return fieldError{field: field, err: err}
+}
+
+func errorField(err error, spec errorSpec) string {
+	var attributed fieldError
+	if errors.As(err, &attributed) {
+		return attributed.field
+	}
+	return spec.field
+}
+
+func writeAPIError(w http.ResponseWriter, status int, apiErr APIError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(errorResponse{Error: apiErr})
+}
+
+// writeError writes an error that has no service error behind it, such as
+// a malformed body or path.
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	writeAPIError(w, status, APIError{Code: code, Message: message})
+}
+
+// writeServiceError answers with the status and code registered for err,
+// or a 500 carrying fallback for anything unregistered, which keeps
+// database details out of responses.
+func writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	spec, ok := lookupError(err)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, CodeInternalError, fallback)
+		return
+	}
+
+	apiErr := APIError{Code: spec.code(), Message: err.Error()}
+	if field := errorField(err, spec); field != "" {
+		apiErr.Fields = []FieldError{{Field: field, Code: spec.code(), Message: err.Error()}}
+	}
+	writeAPIError(w, spec.status, apiErr)
+}
@@ -0,0 +1,81 @@
+package service_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"cosign/internal/service"
+	"cosign/internal/testutil"
+	"cosign/pkg/cosignapi"
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+func decodeAPIError(t *testing.T, raw []byte) service.APIError {
+	t.Helper()
+
+	var envelope localizedErrorEnvelope
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		t.Fatalf("decode error envelope: %v", err)
+	}
+	return envelope.Error
+}
+
+func TestErrorEnvelopeCarriesCodeAndField(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Codes")
+
+	badColor := wire.TestPut[service.Campaign](
+		handler,
+		"/admin/campaigns/"+campaign.ID,
+		`{"theme":{"primary_color":"#123abc","background_color":"blue"}}`,
+		authHeader(),
+	)
+	badColor.ExpectStatus(t, http.StatusBadRequest)
+	apiErr := decodeAPIError(t, badColor.Raw)
+	if apiErr.Code != service.CodeInvalidThemeColor {
+		t.Fatalf("expected code %q, got %q", service.CodeInvalidThemeColor, apiErr.Code)
+	}
+	if len(apiErr.Fields) != 1 || apiErr.Fields[0].Field != "theme.background_color" {
+		t.Fatalf("expected background color field, got %+v", apiErr.Fields)
+	}
+
+	path := "/campaigns/" + campaign.ID + "/signatures"
+	body := `{"name":"Alex","email":"alex@example.com","location":"Boston"}`
+	wire.TestPost[service.Signature](handler, path, body).ExpectStatus(t, http.StatusCreated)
+
+	duplicate := wire.TestPost[service.Signature](handler, path, body)
+	duplicate.ExpectStatus(t, http.StatusConflict)
+	apiErr = decodeAPIError(t, duplicate.Raw)
+	if apiErr.Code != service.CodeDuplicateEmail {
+		t.Fatalf("expected code %q, got %q", service.CodeDuplicateEmail, apiErr.Code)
+	}
+	if len(apiErr.Fields) != 1 || apiErr.Fields[0].Field != "email" {
+		t.Fatalf("expected email field, got %+v", apiErr.Fields)
+	}
+
+	missing := wire.TestGet[service.Campaign](handler, "/admin/campaigns/cmp-missing", authHeader())
+	missing.ExpectStatus(t, http.StatusNotFound)
+	apiErr = decodeAPIError(t, missing.Raw)
+	if apiErr.Code != service.CodeCampaignNotFound || len(apiErr.Fields) != 0 {
+		t.Fatalf("unexpected not found error: %+v", apiErr)
+	}
+}
+
+func TestErrorForCodeRoundTrips(t *testing.T) {
+	for _, err := range []error{
+		service.ErrDuplicateEmail,
+		service.ErrLocationNotInOptions,
+		service.ErrRevisionConflict,
+		service.ErrWebhookNotFound,
+	} {
+		if got := cosignapi.ErrorForCode(service.ErrorCode(err)); !errors.Is(got, err) {
+			t.Fatalf("expected %v back from its code, got %v", err, got)
+		}
+	}
+	if cosignapi.ErrorForCode(service.CodeInternalError) != nil {
+		t.Fatalf("expected no error for internal_error")
+	}
+}
@@ -0,0 +1,287 @@
+package service
+
+import (
+	"cosign/pkg/cosignapi"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+const maxGazetteerBytes = 8 << 20
+
+type (
+	LocationCoordinate        = cosignapi.LocationCoordinate
+	ImportCoordinatesRequest  = cosignapi.ImportCoordinatesRequest
+	ImportCoordinatesResponse = cosignapi.ImportCoordinatesResponse
+	GeoJSONFeatureCollection  = cosignapi.GeoJSONFeatureCollection
+	GeoJSONFeature            = cosignapi.GeoJSONFeature
+	GeoJSONPoint              = cosignapi.GeoJSONPoint
+	SignatureMapLocation      = cosignapi.SignatureMapLocation
+	UnmappedLocations         = cosignapi.UnmappedLocations
+	UnmappedLocation          = cosignapi.UnmappedLocation
+)
+
+// LocationSignatureCount is one row of signatures grouped by location.
+// LocationID is zero for custom text that names no preset.
+type LocationSignatureCount struct {
+	Value      string
+	LocationID int64
+	Latitude   *float64
+	Longitude  *float64
+	Count      int
+}
+
+func validateCoordinates(latitude, longitude *float64) error {
+	if latitude == nil && longitude == nil {
+		return nil
+	}
+	if latitude == nil || longitude == nil {
+		return ErrInvalidCoordinates
+	}
+	if math.IsNaN(*latitude) || *latitude < -90 || *latitude > 90 {
+		return ErrInvalidCoordinates
+	}
+	if math.IsNaN(*longitude) || *longitude < -180 || *longitude > 180 {
+		return ErrInvalidCoordinates
+	}
+	return nil
+}
+
+// ParseGazetteerCSV reads a CSV with a header row naming value (or name),
+// latitude (or lat) and longitude (or lon, lng) columns. Other columns are
+// ignored so exports from common gazetteers can be loaded as-is.
+func ParseGazetteerCSV(r io.Reader) ([]LocationCoordinate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, ErrInvalidGazetteer
+	}
+
+	columns := map[string]int{"value": -1, "latitude": -1, "longitude": -1}
+	for idx, name := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) {
+		case "value", "name", "location":
+			columns["value"] = idx
+		case "latitude", "lat":
+			columns["latitude"] = idx
+		case "longitude", "lon", "lng", "long":
+			columns["longitude"] = idx
+		}
+	}
+	for _, idx := range columns {
+		if idx < 0 {
+			return nil, ErrInvalidGazetteer
+		}
+	}
+
+	var coordinates []LocationCoordinate
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidGazetteer
+		}
+
+		field := func(name string) string {
+			if idx := columns[name]; idx < len(record) {
+				return strings.TrimSpace(record[idx])
+			}
+			return ""
+		}
+		if field("value") == "" {
+			continue
+		}
+
+		latitude, latErr := strconv.ParseFloat(field("latitude"), 64)
+		longitude, lonErr := strconv.ParseFloat(field("longitude"), 64)
+		if latErr != nil || lonErr != nil {
+			return nil, ErrInvalidCoordinates
+		}
+
+		coordinates = append(coordinates, LocationCoordinate{
+			Value:     field("value"),
+			Latitude:  latitude,
+			Longitude: longitude,
+		})
+	}
+
+	return coordinates, nil
+}
+
+// ImportLocationCoordinates sets coordinates on presets matched by value or
+// alias, ignoring case and spacing, and reports rows that matched nothing.
+func (s *Service) ImportLocationCoordinates(campaignID string, req ImportCoordinatesRequest) (*ImportCoordinatesResponse, error) {
+	options, err := s.GetCampaignLocations(campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ImportCoordinatesResponse{Unmatched: []string{}}
+	updates := make(map[int64]LocationOption)
+	for _, coordinate := range req.Coordinates {
+		if err := validateCoordinates(&coordinate.Latitude, &coordinate.Longitude); err != nil {
+			return nil, err
+		}
+
+		value := canonicalLocation(strings.TrimSpace(coordinate.Value), options)
+		idx := -1
+		for i := range options {
+			if options[i].Value == value {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			response.Unmatched = append(response.Unmatched, coordinate.Value)
+			continue
+		}
+
+		updates[options[idx].ID] = LocationOption{
+			ID:        options[idx].ID,
+			Latitude:  &coordinate.Latitude,
+			Longitude: &coordinate.Longitude,
+		}
+	}
+
+	changed := make([]LocationOption, 0, len(updates))
+	for _, opt := range updates {
+		changed = append(changed, opt)
+	}
+
+	if err := s.store.SetLocationCoordinates(campaignID, changed); err != nil {
+		if errors.Is(err, ErrCampaignNotFound) {
+			return nil, err
+		}
+		return nil, DatabaseError{Err: err}
+	}
+
+	response.Updated = len(changed)
+	return response, nil
+}
+
+// GetSignatureMap returns signature counts as GeoJSON points, one per preset
+// with coordinates, labelled in lang.
+func (s *Service) GetSignatureMap(campaignID, lang string) (*GeoJSONFeatureCollection, error) {
+	options, err := s.GetCampaignLocations(campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.store.CountSignaturesByMappedLocation(campaignID)
+	if err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+
+	labels := make(map[int64]string, len(options))
+	for _, opt := range LocalizeLocations(options, lang) {
+		labels[opt.ID] = opt.Label
+	}
+
+	collection := &GeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: []GeoJSONFeature{},
+		Unmapped: UnmappedLocations{Locations: []UnmappedLocation{}},
+	}
+	for _, count := range counts {
+		if count.LocationID == 0 || count.Latitude == nil || count.Longitude == nil {
+			collection.Unmapped.Total += count.Count
+			collection.Unmapped.Locations = append(collection.Unmapped.Locations, UnmappedLocation{
+				Value:  count.Value,
+				Count:  count.Count,
+				Custom: count.LocationID == 0,
+			})
+			continue
+		}
+
+		collection.Features = append(collection.Features, GeoJSONFeature{
+			Type: "Feature",
+			Geometry: GeoJSONPoint{
+				Type:        "Point",
+				Coordinates: [2]float64{*count.Longitude, *count.Latitude},
+			},
+			Properties: SignatureMapLocation{
+				ID:    count.LocationID,
+				Value: count.Value,
+				Label: labels[count.LocationID],
+				Count: count.Count,
+			},
+		})
+	}
+
+	return collection, nil
+}
+
+func (s *Service) handleImportLocationCoordinates(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
+		return
+	}
+
+	var req ImportCoordinatesRequest
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/csv" {
+		coordinates, err := ParseGazetteerCSV(http.MaxBytesReader(w, r.Body, maxGazetteerBytes))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, CodeInvalidGazetteer, err.Error())
+			return
+		}
+		req.Coordinates = coordinates
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "invalid request body")
+		return
+	}
+
+	result, err := s.ImportLocationCoordinates(campaignID, req)
+	if err != nil {
+		writeServiceError(w, err, "failed to import coordinates")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, result)
+}
+
+func (s *Service) handleGetSignatureMap(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writePublicError(w, r, http.StatusBadRequest, CodeCampaignIDRequired)
+		return
+	}
+
+	campaign, err := s.GetCampaign(campaignID)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrCampaignNotFound):
+			writePublicError(w, r, http.StatusNotFound, CodeCampaignNotFound)
+		default:
+			writePublicError(w, r, http.StatusInternalServerError, CodeInternalError)
+		}
+		return
+	}
+
+	lang := NegotiateLanguage(r, campaign.Languages())
+	collection, err := s.GetSignatureMap(campaignID, lang)
+	if err != nil {
+		writePublicError(w, r, http.StatusInternalServerError, CodeInternalError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	w.Header().Set("Content-Language", lang)
+	w.Header().Add("Vary", "Accept-Language")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(collection)
+}
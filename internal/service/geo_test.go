@@ -0,0 +1,137 @@
+package service_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cosign/internal/service"
+	"cosign/internal/testutil"
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+const gazetteerCSV = "name,country,lat,lng\n" +
+	"new york city,US,40.7128,-74.0060\n" +
+	"Boston,US,42.3601,-71.0589\n" +
+	"Springfield,US,39.7817,-89.6501\n"
+
+func TestGazetteerImportSetsCoordinates(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Cities")
+
+	put := wire.TestPut[service.CampaignLocationsResponse](
+		handler,
+		"/admin/campaigns/"+campaign.ID+"/locations",
+		`{"locations":[{"value":"New York City"},{"value":"Boston"},{"value":"Chicago","latitude":41.8781,"longitude":-87.6298}]}`,
+		authHeader(),
+	)
+	put.ExpectStatus(t, http.StatusOK)
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/campaigns/"+campaign.ID+"/locations/coordinates", strings.NewReader(gazetteerCSV))
+	req.Header.Set("Content-Type", "text/csv")
+	req.Header.Set(authHeader().Key, authHeader().Value)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var envelope struct {
+		Data service.ImportCoordinatesResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("decode import response: %v", err)
+	}
+	if envelope.Data.Updated != 2 || len(envelope.Data.Unmatched) != 1 || envelope.Data.Unmatched[0] != "Springfield" {
+		t.Fatalf("unexpected import result: %+v", envelope.Data)
+	}
+
+	locations := wire.TestGet[service.CampaignLocationsResponse](handler, "/admin/campaigns/"+campaign.ID+"/locations", authHeader())
+	locations.ExpectStatus(t, http.StatusOK)
+	nyc := locations.Data.Locations[0]
+	if nyc.Latitude == nil || *nyc.Latitude != 40.7128 || *nyc.Longitude != -74.0060 {
+		t.Fatalf("unexpected coordinates: %+v", nyc)
+	}
+
+	half := wire.TestPut[service.CampaignLocationsResponse](
+		handler,
+		"/admin/campaigns/"+campaign.ID+"/locations",
+		`{"locations":[{"value":"Boston","latitude":42.36}]}`,
+		authHeader(),
+	)
+	half.ExpectStatus(t, http.StatusBadRequest)
+
+	outOfRange := wire.TestPost[service.ImportCoordinatesResponse](
+		handler,
+		"/admin/campaigns/"+campaign.ID+"/locations/coordinates",
+		`{"coordinates":[{"value":"Boston","latitude":142,"longitude":0}]}`,
+		authHeader(),
+	)
+	outOfRange.ExpectStatus(t, http.StatusBadRequest)
+
+	missingColumns := httptest.NewRequest(http.MethodPost, "/admin/campaigns/"+campaign.ID+"/locations/coordinates", strings.NewReader("name,lat\nBoston,1\n"))
+	missingColumns.Header.Set("Content-Type", "text/csv")
+	missingColumns.Header.Set(authHeader().Key, authHeader().Value)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, missingColumns)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected missing columns to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestSignatureMapReturnsGeoJSON(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Cities")
+
+	translate := wire.TestPut[service.Campaign](handler, "/admin/campaigns/"+campaign.ID, `{"translations":{"fr":{"name":"Villes"}}}`, authHeader())
+	translate.ExpectStatus(t, http.StatusOK)
+
+	put := wire.TestPut[service.CampaignLocationsResponse](
+		handler,
+		"/admin/campaigns/"+campaign.ID+"/locations",
+		`{"locations":[{"value":"Boston","latitude":42.3601,"longitude":-71.0589,"labels":{"fr":"Boston (MA)"}},{"value":"Chicago"}]}`,
+		authHeader(),
+	)
+	put.ExpectStatus(t, http.StatusOK)
+	signLocations(t, svc, campaign.ID, "Boston", "boston", "Chicago", "Atlantis")
+
+	req := httptest.NewRequest(http.MethodGet, "/campaigns/"+campaign.ID+"/locations/geojson", nil)
+	req.Header.Set("Accept-Language", "fr")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/geo+json" {
+		t.Fatalf("unexpected content type %q", got)
+	}
+
+	var collection service.GeoJSONFeatureCollection
+	if err := json.Unmarshal(rec.Body.Bytes(), &collection); err != nil {
+		t.Fatalf("decode geojson: %v", err)
+	}
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 1 {
+		t.Fatalf("unexpected collection: %+v", collection)
+	}
+
+	feature := collection.Features[0]
+	if feature.Geometry.Coordinates != [2]float64{-71.0589, 42.3601} {
+		t.Fatalf("expected longitude first, got %v", feature.Geometry.Coordinates)
+	}
+	if feature.Properties.Count != 2 || feature.Properties.Label != "Boston (MA)" {
+		t.Fatalf("unexpected feature properties: %+v", feature.Properties)
+	}
+
+	if collection.Unmapped.Total != 2 || len(collection.Unmapped.Locations) != 2 {
+		t.Fatalf("unexpected unmapped totals: %+v", collection.Unmapped)
+	}
+	for _, unmapped := range collection.Unmapped.Locations {
+		if unmapped.Custom != (unmapped.Value == "Atlantis") {
+			t.Fatalf("unexpected unmapped entry: %+v", unmapped)
+		}
+	}
+}
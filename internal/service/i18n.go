@@ -0,0 +1,272 @@
+package service
+
+import (
+	"cosign/pkg/cosignapi"
+	"embed"
+	"encoding/json"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const DefaultLanguage = cosignapi.DefaultLanguage
+
+// SupportedLanguages are the languages with a built-in message catalog.
+var SupportedLanguages = []string{"en", "fr", "es"}
+
+const (
+	CodeInvalidRequest       = cosignapi.CodeInvalidRequest
+	CodeCampaignIDRequired   = cosignapi.CodeCampaignIDRequired
+	CodeCampaignNotFound     = cosignapi.CodeCampaignNotFound
+	CodeEmptyName            = cosignapi.CodeEmptyName
+	CodeEmptyEmail           = cosignapi.CodeEmptyEmail
+	CodeEmptyLocation        = cosignapi.CodeEmptyLocation
+	CodeInvalidEmail         = cosignapi.CodeInvalidEmail
+	CodeDuplicateEmail       = cosignapi.CodeDuplicateEmail
+	CodeLocationNotInOptions = cosignapi.CodeLocationNotInOptions
+	CodeRateLimited          = cosignapi.CodeRateLimited
+	CodeTooManyStreams       = cosignapi.CodeTooManyStreams
+	CodeInternalError        = cosignapi.CodeInternalError
+
+	CodeIdempotencyKeyReused   = cosignapi.CodeIdempotencyKeyReused
+	CodeIdempotencyKeyInFlight = cosignapi.CodeIdempotencyKeyInFlight
+)
+
+var languageTagRegex = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+var catalog = mustLoadCatalog()
+
+func mustLoadCatalog() map[string]map[string]string {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+
+	messages := make(map[string]map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := localeFiles.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+
+		var table map[string]string
+		if err := json.Unmarshal(data, &table); err != nil {
+			panic("locales/" + entry.Name() + ": " + err.Error())
+		}
+		messages[strings.TrimSuffix(entry.Name(), ".json")] = table
+	}
+	return messages
+}
+
+// Message looks up a catalog message, falling back to English and then to
+// the key itself.
+func Message(lang, key string) string {
+	if msg, ok := catalog[lang][key]; ok {
+		return msg
+	}
+	if msg, ok := catalog[DefaultLanguage][key]; ok {
+		return msg
+	}
+	return key
+}
+
+// Messages returns the full catalog for lang with English filling any gaps.
+func Messages(lang string) map[string]string {
+	messages := make(map[string]string, len(catalog[DefaultLanguage]))
+	for key, msg := range catalog[DefaultLanguage] {
+		messages[key] = msg
+	}
+	for key, msg := range catalog[lang] {
+		messages[key] = msg
+	}
+	return messages
+}
+
+// writePublicError writes the standard error envelope with a machine code
+// and a message in the language negotiated for the request.
+func writePublicError(w http.ResponseWriter, r *http.Request, statusCode int, code string) {
+	lang := NegotiateLanguage(r, SupportedLanguages)
+	message := Message(lang, code)
+
+	apiErr := APIError{Code: code, Message: message}
+	for _, spec := range errorSpecs {
+		if spec.code() == code && spec.field != "" {
+			apiErr.Fields = []FieldError{{Field: spec.field, Code: code, Message: message}}
+			break
+		}
+	}
+
+	w.Header().Set("Content-Language", lang)
+	w.Header().Add("Vary", "Accept-Language")
+	writeAPIError(w, statusCode, apiErr)
+}
+
+// NegotiateLanguage picks the best entry of available for the request using
+// the `lang` query parameter, then Accept-Language. It falls back to the
+// first available language.
+func NegotiateLanguage(r *http.Request, available []string) string {
+	if len(available) == 0 {
+		return DefaultLanguage
+	}
+
+	if lang := strings.TrimSpace(r.URL.Query().Get("lang")); lang != "" {
+		if match := matchLanguage(lang, available); match != "" {
+			return match
+		}
+	}
+
+	for _, tag := range parseAcceptLanguage(r.Header.Get("Accept-Language")) {
+		if tag == "*" {
+			break
+		}
+		if match := matchLanguage(tag, available); match != "" {
+			return match
+		}
+	}
+
+	return available[0]
+}
+
+func matchLanguage(tag string, available []string) string {
+	tag = strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+	for _, lang := range available {
+		if lang == tag {
+			return lang
+		}
+	}
+
+	primary, _, _ := strings.Cut(tag, "-")
+	for _, lang := range available {
+		if candidate, _, _ := strings.Cut(lang, "-"); candidate == primary {
+			return lang
+		}
+	}
+	return ""
+}
+
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	ordered := make([]string, 0, len(tags))
+	for _, t := range tags {
+		ordered = append(ordered, t.tag)
+	}
+	return ordered
+}
+
+// LocalizeCampaign returns a copy of the campaign rendered in lang, with the
+// translation table dropped.
+func LocalizeCampaign(campaign *Campaign, lang string) *Campaign {
+	localized := *campaign
+	localized.Translations = nil
+
+	if translation, ok := campaign.Translations[lang]; ok {
+		if translation.Name != "" {
+			localized.Name = translation.Name
+		}
+		if translation.Letter != "" {
+			localized.Letter = translation.Letter
+		}
+		localized.Language = lang
+	}
+	return &localized
+}
+
+// LocalizeLocations fills Label with the translation for lang (or the value
+// itself) and drops the label tables.
+func LocalizeLocations(options []LocationOption, lang string) []LocationOption {
+	localized := make([]LocationOption, 0, len(options))
+	for _, opt := range options {
+		label := opt.Labels[lang]
+		if label == "" {
+			label = opt.Value
+		}
+		opt.Label = label
+		opt.Labels = nil
+		localized = append(localized, opt)
+	}
+	return localized
+}
+
+func normalizeLanguageTag(raw string) (string, error) {
+	tag := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(raw), "_", "-"))
+	if tag == "" {
+		return DefaultLanguage, nil
+	}
+	if !languageTagRegex.MatchString(tag) {
+		return "", ErrInvalidLanguage
+	}
+	return tag, nil
+}
+
+func normalizeCampaignTranslations(translations map[string]CampaignTranslation) (map[string]CampaignTranslation, error) {
+	normalized := make(map[string]CampaignTranslation, len(translations))
+	for rawLang, translation := range translations {
+		lang, err := normalizeLanguageTag(rawLang)
+		if err != nil || strings.TrimSpace(rawLang) == "" {
+			return nil, ErrInvalidLanguage
+		}
+
+		translation.Name = strings.TrimSpace(translation.Name)
+		translation.Letter = strings.TrimSpace(translation.Letter)
+		if translation.Name == "" && translation.Letter == "" {
+			continue
+		}
+		normalized[lang] = translation
+	}
+	return normalized, nil
+}
+
+func normalizeLocationLabels(labels map[string]string) (map[string]string, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+
+	normalized := make(map[string]string, len(labels))
+	for rawLang, label := range labels {
+		lang, err := normalizeLanguageTag(rawLang)
+		if err != nil || strings.TrimSpace(rawLang) == "" {
+			return nil, ErrInvalidLanguage
+		}
+
+		label = strings.TrimSpace(label)
+		if label == "" {
+			continue
+		}
+		normalized[lang] = label
+	}
+	return normalized, nil
+}
@@ -0,0 +1,151 @@
+package service_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cosign/internal/service"
+	"cosign/internal/testutil"
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+type localizedErrorEnvelope struct {
+	Error service.APIError `json:"error"`
+}
+
+func translateCampaign(t *testing.T, handler http.Handler, campaignID string) {
+	t.Helper()
+
+	update := wire.TestPut[service.Campaign](
+		handler,
+		"/admin/campaigns/"+campaignID,
+		`{"letter":"Please act.","translations":{"fr":{"name":"Sauvez le parc","letter":"Agissez."},"es":{"name":"Salvar el parque"}}}`,
+		authHeader(),
+	)
+	update.ExpectStatus(t, http.StatusOK)
+	if len(update.Data.Translations) != 2 || update.Data.Language != "en" {
+		t.Fatalf("unexpected translations: %+v", update.Data)
+	}
+
+	locs := wire.TestPut[service.CampaignLocationsResponse](
+		handler,
+		"/admin/campaigns/"+campaignID+"/locations",
+		`{"locations":[{"value":"Geneva","display_order":1,"labels":{"fr":"Genève"}}]}`,
+		authHeader(),
+	)
+	locs.ExpectStatus(t, http.StatusOK)
+}
+
+func TestPublicCampaignNegotiatesLanguage(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Save the Park")
+	translateCampaign(t, handler, campaign.ID)
+
+	french := wire.TestGet[service.Campaign](
+		handler,
+		"/campaigns/"+campaign.ID,
+		wire.TestHeader{Key: "Accept-Language", Value: "de-CH, fr-CA;q=0.8, en;q=0.5"},
+	)
+	french.ExpectStatus(t, http.StatusOK)
+	if french.Data.Name != "Sauvez le parc" || french.Data.Letter != "Agissez." || french.Data.Language != "fr" {
+		t.Fatalf("expected french campaign, got %+v", french.Data)
+	}
+	if french.Data.Translations != nil {
+		t.Fatalf("expected translations to be omitted publicly, got %+v", french.Data.Translations)
+	}
+	if got := french.Headers.Get("Content-Language"); got != "fr" {
+		t.Fatalf("expected content-language fr, got %q", got)
+	}
+
+	// a partial translation falls back to the base letter
+	spanish := wire.TestGet[service.Campaign](handler, "/campaigns/"+campaign.ID+"?lang=es")
+	spanish.ExpectStatus(t, http.StatusOK)
+	if spanish.Data.Name != "Salvar el parque" || spanish.Data.Letter != "Please act." {
+		t.Fatalf("expected spanish name with base letter, got %+v", spanish.Data)
+	}
+
+	fallback := wire.TestGet[service.Campaign](
+		handler,
+		"/campaigns/"+campaign.ID,
+		wire.TestHeader{Key: "Accept-Language", Value: "ja"},
+	)
+	fallback.ExpectStatus(t, http.StatusOK)
+	if fallback.Data.Name != "Save the Park" || fallback.Data.Language != "en" {
+		t.Fatalf("expected base language fallback, got %+v", fallback.Data)
+	}
+
+	locations := wire.TestGet[service.CampaignLocationsResponse](handler, "/campaigns/"+campaign.ID+"/locations?lang=fr")
+	locations.ExpectStatus(t, http.StatusOK)
+	if len(locations.Data.Locations) != 1 {
+		t.Fatalf("expected one location, got %+v", locations.Data.Locations)
+	}
+	if loc := locations.Data.Locations[0]; loc.Value != "Geneva" || loc.Label != "Genève" || loc.Labels != nil {
+		t.Fatalf("expected localized label with stable value, got %+v", loc)
+	}
+}
+
+func TestPublicErrorsAreCodedAndLocalized(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Petition")
+
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/campaigns/"+campaign.ID+"/signatures",
+		strings.NewReader(`{"name":"Alice","email":"not-an-email","location":"Lyon"}`),
+	)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "fr-FR,fr;q=0.9")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Language"); got != "fr" {
+		t.Fatalf("expected content-language fr, got %q", got)
+	}
+
+	var envelope localizedErrorEnvelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if envelope.Error.Code != service.CodeInvalidEmail {
+		t.Fatalf("expected invalid_email code, got %+v", envelope.Error)
+	}
+	if envelope.Error.Message != service.Message("fr", service.CodeInvalidEmail) || envelope.Error.Message == service.Message("en", service.CodeInvalidEmail) {
+		t.Fatalf("expected french message, got %q", envelope.Error.Message)
+	}
+
+	missing := wire.TestGet[service.Campaign](handler, "/campaigns/missing?lang=es")
+	missing.ExpectStatus(t, http.StatusNotFound)
+	if missing.Error == nil || missing.Error.Message != service.Message("es", service.CodeCampaignNotFound) {
+		t.Fatalf("expected spanish not found message, got %#v", missing.Error)
+	}
+}
+
+func TestCampaignTranslationsRejectInvalidLanguage(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Petition")
+
+	badTranslation := wire.TestPut[service.Campaign](
+		handler,
+		"/admin/campaigns/"+campaign.ID,
+		`{"translations":{"not a tag":{"name":"x"}}}`,
+		authHeader(),
+	)
+	badTranslation.ExpectStatus(t, http.StatusBadRequest)
+
+	badLanguage := wire.TestPut[service.Campaign](
+		handler,
+		"/admin/campaigns/"+campaign.ID,
+		`{"language":"english!"}`,
+		authHeader(),
+	)
+	badLanguage.ExpectStatus(t, http.StatusBadRequest)
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultIdempotencyTTL   = 24 * time.Hour
+	maxIdempotencyKeyLength = 255
+	maxIdempotentBodyBytes  = 1 << 20
+)
+
+// IdempotencyRecord is a claimed Idempotency-Key, scoped to the method and
+// path it was sent to. Status stays zero until the first request finishes
+// and its response is stored.
+type IdempotencyRecord struct {
+	Key         string
+	Method      string
+	Path        string
+	Fingerprint string
+	Status      int
+	Headers     http.Header
+	Body        []byte
+	CreatedAt   int64
+	ExpiresAt   int64
+}
+
+// WithIdempotency replays the stored response when a request repeats an
+// Idempotency-Key on the same route, and rejects a key reused there for a
+// different request. Requests without the header pass straight through.
+// Server errors and panics are not stored, so the client can retry them
+// under the same key.
+func (s *Service) WithIdempotency(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimSpace(r.Header.Get("Idempotency-Key"))
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			writePublicError(w, r, http.StatusBadRequest, CodeInvalidRequest)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodyBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writePublicError(w, r, http.StatusRequestEntityTooLarge, CodeInvalidRequest)
+				return
+			}
+			writePublicError(w, r, http.StatusBadRequest, CodeInvalidRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := requestFingerprint(r, body)
+
+		now := s.clock()
+		method, path := r.Method, r.URL.Path
+		claimed, err := s.store.ClaimIdempotencyKey(
+			key, method, path, fingerprint, now.Unix(), now.Add(s.idempotencyTTL).Unix(),
+		)
+		if err != nil {
+			writePublicError(w, r, http.StatusInternalServerError, CodeInternalError)
+			return
+		}
+		if !claimed {
+			s.replayIdempotent(w, r, key, fingerprint)
+			return
+		}
+
+		recorder := &responseRecorder{header: make(http.Header)}
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				_ = s.store.DeleteIdempotencyKey(key, method, path)
+				panic(recovered)
+			}
+		}()
+		next(recorder, r)
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+
+		if recorder.status >= http.StatusInternalServerError {
+			_ = s.store.DeleteIdempotencyKey(key, method, path)
+		} else {
+			_ = s.store.CompleteIdempotencyKey(IdempotencyRecord{
+				Key:     key,
+				Method:  method,
+				Path:    path,
+				Status:  recorder.status,
+				Headers: recorder.header,
+				Body:    recorder.body.Bytes(),
+			})
+		}
+
+		writeRecordedResponse(w, recorder.status, recorder.header, recorder.body.Bytes())
+	}
+}
+
+func (s *Service) replayIdempotent(w http.ResponseWriter, r *http.Request, key, fingerprint string) {
+	record, err := s.store.GetIdempotencyKey(key, r.Method, r.URL.Path)
+	if err != nil {
+		// the first attempt failed and released the key since the claim
+		if errors.Is(err, ErrIdempotencyKeyNotFound) {
+			writePublicError(w, r, http.StatusConflict, CodeIdempotencyKeyInFlight)
+			return
+		}
+		writePublicError(w, r, http.StatusInternalServerError, CodeInternalError)
+		return
+	}
+
+	switch {
+	case record.Fingerprint != fingerprint:
+		writePublicError(w, r, http.StatusUnprocessableEntity, CodeIdempotencyKeyReused)
+	case record.Status == 0:
+		writePublicError(w, r, http.StatusConflict, CodeIdempotencyKeyInFlight)
+	default:
+		w.Header().Set("Idempotent-Replayed", "true")
+		writeRecordedResponse(w, record.Status, record.Headers, record.Body)
+	}
+}
+
+// requestFingerprint ties a key to the exact request it was first sent
+// with: method, full request URI, media type, and body.
+func requestFingerprint(r *http.Request, body []byte) string {
+	target := r.RequestURI
+	if target == "" {
+		target = r.URL.RequestURI()
+	}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	hash := sha256.New()
+	for _, part := range []string{r.Method, target, mediaType} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func writeRecordedResponse(w http.ResponseWriter, status int, header http.Header, body []byte) {
+	for name, values := range header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// responseRecorder buffers a handler's response so it can be stored before
+// it is sent.
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(p)
+}
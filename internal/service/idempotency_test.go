@@ -0,0 +1,114 @@
+package service_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cosign/internal/service"
+	"cosign/internal/testutil"
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+func idempotencyHeader(key string) wire.TestHeader {
+	return wire.TestHeader{Key: "Idempotency-Key", Value: key}
+}
+
+func TestSignatureRetryReplaysOriginalResponse(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	svc := testutil.SetupServiceWith(t, func(opts *service.Options) {
+		opts.Clock = func() time.Time { return now }
+		opts.IdempotencyTTL = time.Hour
+	})
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Retries")
+	path := "/campaigns/" + campaign.ID + "/signatures"
+	body := `{"name":"Alex","email":"alex@example.com","location":"Boston"}`
+
+	first := wire.TestPost[service.Signature](handler, path, body, idempotencyHeader("sig-1"))
+	first.ExpectStatus(t, http.StatusCreated)
+
+	retry := wire.TestPost[service.Signature](handler, path, body, idempotencyHeader("sig-1"))
+	retry.ExpectStatus(t, http.StatusCreated)
+	if retry.Data.ID != first.Data.ID {
+		t.Fatalf("expected replayed signature %d, got %d", first.Data.ID, retry.Data.ID)
+	}
+	if retry.Headers.Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected replay header on retry")
+	}
+
+	reused := wire.TestPost[service.Signature](
+		handler, path, `{"name":"Sam","email":"sam@example.com","location":"Boston"}`, idempotencyHeader("sig-1"),
+	)
+	reused.ExpectStatus(t, http.StatusUnprocessableEntity)
+
+	listed := wire.TestGet[service.Signatures](handler, "/admin/campaigns/"+campaign.ID+"/signatures", authHeader())
+	if listed.Data.Total != 1 {
+		t.Fatalf("expected one signature, got %d", listed.Data.Total)
+	}
+
+	// once the key expires the request runs again
+	now = now.Add(2 * time.Hour)
+	expired := wire.TestPost[service.Signature](handler, path, body, idempotencyHeader("sig-1"))
+	expired.ExpectStatus(t, http.StatusConflict)
+}
+
+func TestCampaignCreateRetryDoesNotDuplicate(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+
+	first := wire.TestPost[service.Campaign](handler, "/admin/campaigns", `{"name":"Once"}`, authHeader(), idempotencyHeader("cmp-1"))
+	first.ExpectStatus(t, http.StatusCreated)
+	retry := wire.TestPost[service.Campaign](handler, "/admin/campaigns", `{"name":"Once"}`, authHeader(), idempotencyHeader("cmp-1"))
+	retry.ExpectStatus(t, http.StatusCreated)
+	if retry.Data.ID != first.Data.ID {
+		t.Fatalf("expected replayed campaign %q, got %q", first.Data.ID, retry.Data.ID)
+	}
+
+	// the same key on another route is a separate key
+	other := wire.TestPost[service.Signature](
+		handler,
+		"/campaigns/"+first.Data.ID+"/signatures",
+		`{"name":"Once","email":"once@example.com","location":"Boston"}`,
+		idempotencyHeader("cmp-1"),
+	)
+	other.ExpectStatus(t, http.StatusCreated)
+
+	listed := wire.TestGet[service.Campaigns](handler, "/admin/campaigns", authHeader())
+	if listed.Data.Total != 1 {
+		t.Fatalf("expected one campaign, got %d", listed.Data.Total)
+	}
+}
+
+func TestIdempotencyKeyIsReleasedWhenTheHandlerPanics(t *testing.T) {
+	svc := testutil.SetupService(t)
+	panicking := svc.WithIdempotency(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	succeeding := svc.WithIdempotency(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	send := func(handler http.HandlerFunc) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/campaigns", strings.NewReader(`{"name":"Once"}`))
+		req.Header.Set("Idempotency-Key", "cmp-panic")
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("expected the panic to propagate")
+			}
+		}()
+		send(panicking)
+	}()
+
+	if rec := send(succeeding); rec.Code != http.StatusCreated {
+		t.Fatalf("expected the retry to run after the panic, got %d: %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,697 @@
+package service
+
+import (
+	"cosign/pkg/cosignapi"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+var (
+	countryCodeRegex     = regexp.MustCompile(`^[A-Z]{2}$`)
+	subdivisionCodeRegex = regexp.MustCompile(`^([A-Z]{2})-[A-Z0-9]{1,3}$`)
+)
+
+type (
+	UpdateLocationRequest = cosignapi.UpdateLocationRequest
+	MoveLocationRequest   = cosignapi.MoveLocationRequest
+	MergeLocationRequest  = cosignapi.MergeLocationRequest
+	MergeLocationResponse = cosignapi.MergeLocationResponse
+	LocationStat          = cosignapi.LocationStat
+	CampaignLocationStats = cosignapi.CampaignLocationStats
+)
+
+// normalizeLocations flattens nested Children into Parent references,
+// validates the tree, and orders it so every parent precedes its children.
+func normalizeLocations(options []LocationOption) ([]LocationOption, error) {
+	var flat []LocationOption
+	var flatten func(options []LocationOption, parent string) error
+	flatten = func(options []LocationOption, parent string) error {
+		for idx, loc := range options {
+			value := strings.TrimSpace(loc.Value)
+			if value == "" {
+				return ErrEmptyLocation
+			}
+
+			if parent != "" {
+				loc.Parent = parent
+			}
+			loc.Value = value
+			loc.Parent = strings.TrimSpace(loc.Parent)
+			loc.CountryCode = strings.ToUpper(strings.TrimSpace(loc.CountryCode))
+			loc.SubdivisionCode = strings.ToUpper(strings.TrimSpace(loc.SubdivisionCode))
+			if loc.DisplayOrder <= 0 {
+				loc.DisplayOrder = idx + 1
+			}
+
+			labels, err := normalizeLocationLabels(loc.Labels)
+			if err != nil {
+				return err
+			}
+			loc.Labels = labels
+			loc.Aliases = normalizeLocationAliases(value, loc.Aliases)
+			if err := validateCoordinates(loc.Latitude, loc.Longitude); err != nil {
+				return err
+			}
+
+			children := loc.Children
+			loc.Children = nil
+			loc.ID = 0
+			loc.ParentID = 0
+			loc.Label = ""
+			flat = append(flat, loc)
+
+			if err := flatten(children, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := flatten(options, ""); err != nil {
+		return nil, err
+	}
+
+	byValue := make(map[string]LocationOption, len(flat))
+	for _, loc := range flat {
+		if _, exists := byValue[loc.Value]; exists {
+			return nil, ErrDuplicateLocation
+		}
+		byValue[loc.Value] = loc
+	}
+
+	// aliases must point at exactly one location
+	owners := make(map[string]string, len(flat))
+	for _, loc := range flat {
+		owners[locationKey(loc.Value)] = loc.Value
+	}
+	for _, loc := range flat {
+		for _, alias := range loc.Aliases {
+			key := locationKey(alias)
+			if owner, taken := owners[key]; taken && owner != loc.Value {
+				return nil, ErrDuplicateLocationAlias
+			}
+			owners[key] = loc.Value
+		}
+	}
+
+	for _, loc := range flat {
+		if loc.Parent == "" {
+			continue
+		}
+		if loc.Parent == loc.Value {
+			return nil, ErrLocationCycle
+		}
+		if _, ok := byValue[loc.Parent]; !ok {
+			return nil, ErrUnknownLocationParent
+		}
+	}
+
+	ordered := make([]LocationOption, 0, len(flat))
+	placed := make(map[string]bool, len(flat))
+	for len(ordered) < len(flat) {
+		progressed := false
+		for _, loc := range flat {
+			if placed[loc.Value] || (loc.Parent != "" && !placed[loc.Parent]) {
+				continue
+			}
+			placed[loc.Value] = true
+			ordered = append(ordered, loc)
+			progressed = true
+		}
+		if !progressed {
+			return nil, ErrLocationCycle
+		}
+	}
+
+	for _, loc := range ordered {
+		if err := validateLocationCodes(loc, byValue); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+// validateLocationCodes checks ISO 3166 formats and that a subdivision
+// belongs to the nearest country set on the option or its ancestors.
+// normalizeLocationAliases trims aliases and drops blanks, repeats, and
+// ones that only restate the value.
+func normalizeLocationAliases(value string, aliases []string) []string {
+	seen := map[string]bool{locationKey(value): true}
+	var normalized []string
+	for _, alias := range aliases {
+		alias = strings.TrimSpace(alias)
+		key := locationKey(alias)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, alias)
+	}
+	return normalized
+}
+
+// locationKey folds case and runs of whitespace so "New  York" and
+// "new york" compare equal.
+func locationKey(value string) string {
+	return strings.Join(strings.Fields(strings.ToLower(value)), " ")
+}
+
+func validateLocationCodes(loc LocationOption, byValue map[string]LocationOption) error {
+	if loc.CountryCode != "" && !countryCodeRegex.MatchString(loc.CountryCode) {
+		return ErrInvalidCountryCode
+	}
+	if loc.SubdivisionCode == "" {
+		return nil
+	}
+
+	match := subdivisionCodeRegex.FindStringSubmatch(loc.SubdivisionCode)
+	if match == nil {
+		return ErrInvalidSubdivisionCode
+	}
+
+	country := loc.CountryCode
+	for parent := loc.Parent; country == "" && parent != ""; parent = byValue[parent].Parent {
+		country = byValue[parent].CountryCode
+	}
+	if country != "" && country != match[1] {
+		return ErrInvalidSubdivisionCode
+	}
+	return nil
+}
+
+// BuildLocationTree nests a flat option list under each option's parent,
+// ordering siblings by display order.
+func BuildLocationTree(options []LocationOption) []LocationOption {
+	children := make(map[string][]LocationOption, len(options))
+	for _, opt := range options {
+		children[opt.Parent] = append(children[opt.Parent], opt)
+	}
+
+	var build func(parent string) []LocationOption
+	build = func(parent string) []LocationOption {
+		nodes := children[parent]
+		slices.SortStableFunc(nodes, func(a, b LocationOption) int { return a.DisplayOrder - b.DisplayOrder })
+		for idx := range nodes {
+			nodes[idx].Children = build(nodes[idx].Value)
+		}
+		return nodes
+	}
+	return build("")
+}
+
+// flattenLocationTree lists a tree depth-first, so each parent is directly
+// followed by its children.
+func flattenLocationTree(nodes []LocationOption) []LocationOption {
+	flat := make([]LocationOption, 0, len(nodes))
+	for _, node := range nodes {
+		children := node.Children
+		node.Children = nil
+		flat = append(flat, node)
+		flat = append(flat, flattenLocationTree(children)...)
+	}
+	return flat
+}
+
+// SelectableLocations returns the options a signer may pick: leaves when
+// level is 0, otherwise options at that depth (1 being the top) plus any
+// shallower leaves.
+func SelectableLocations(options []LocationOption, level int) []LocationOption {
+	hasChildren := make(map[string]bool, len(options))
+	parents := make(map[string]string, len(options))
+	for _, opt := range options {
+		parents[opt.Value] = opt.Parent
+		if opt.Parent != "" {
+			hasChildren[opt.Parent] = true
+		}
+	}
+
+	selectable := make([]LocationOption, 0, len(options))
+	for _, opt := range options {
+		leaf := !hasChildren[opt.Value]
+		if level <= 0 {
+			if leaf {
+				selectable = append(selectable, opt)
+			}
+			continue
+		}
+
+		depth := 1
+		for parent := opt.Parent; parent != "" && depth <= len(options); parent = parents[parent] {
+			depth++
+		}
+		if depth == level || (leaf && depth < level) {
+			selectable = append(selectable, opt)
+		}
+	}
+	return selectable
+}
+
+func (s *Service) GetCampaignLocation(campaignID string, id int64) (*LocationOption, error) {
+	location, err := s.store.GetCampaignLocation(campaignID, id)
+	if err != nil {
+		if errors.Is(err, ErrLocationNotFound) {
+			return nil, err
+		}
+		return nil, DatabaseError{Err: err}
+	}
+	return location, nil
+}
+
+// CreateCampaignLocation appends a single option under the parent named by
+// ParentID (or Parent) without touching the rest of the list.
+func (s *Service) CreateCampaignLocation(campaignID string, option LocationOption) (*LocationOption, error) {
+	current, err := s.GetCampaignLocations(campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	option.Children = nil
+	if option.ParentID > 0 {
+		parent := findLocation(current, option.ParentID)
+		if parent == nil {
+			return nil, ErrUnknownLocationParent
+		}
+		option.Parent = parent.Value
+	}
+
+	option.DisplayOrder = 1
+	for _, loc := range current {
+		if loc.Parent == strings.TrimSpace(option.Parent) && loc.DisplayOrder >= option.DisplayOrder {
+			option.DisplayOrder = loc.DisplayOrder + 1
+		}
+	}
+
+	normalized, err := normalizeLocations(append(current, option))
+	if err != nil {
+		return nil, err
+	}
+	created := normalized[slices.IndexFunc(normalized, func(loc LocationOption) bool {
+		return loc.Value == strings.TrimSpace(option.Value)
+	})]
+	if created.Parent != "" {
+		created.ParentID = current[slices.IndexFunc(current, func(loc LocationOption) bool {
+			return loc.Value == created.Parent
+		})].ID
+	}
+
+	id, err := s.store.InsertCampaignLocation(campaignID, created)
+	if err != nil {
+		if errors.Is(err, ErrCampaignNotFound) {
+			return nil, err
+		}
+		return nil, DatabaseError{Err: err}
+	}
+
+	return s.GetCampaignLocation(campaignID, id)
+}
+
+func (s *Service) UpdateCampaignLocation(campaignID string, id int64, req UpdateLocationRequest) (*LocationOption, error) {
+	current, err := s.GetCampaignLocations(campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	target := findLocation(current, id)
+	if target == nil {
+		return nil, ErrLocationNotFound
+	}
+
+	previous := target.Value
+	if req.Value != nil {
+		target.Value = *req.Value
+	}
+	if req.CountryCode != nil {
+		target.CountryCode = *req.CountryCode
+	}
+	if req.SubdivisionCode != nil {
+		target.SubdivisionCode = *req.SubdivisionCode
+	}
+	if req.Labels != nil {
+		target.Labels = req.Labels
+	}
+	if req.Aliases != nil {
+		target.Aliases = req.Aliases
+	}
+	if req.ClearCoordinates {
+		target.Latitude = nil
+		target.Longitude = nil
+	}
+	if req.Latitude != nil || req.Longitude != nil {
+		target.Latitude = req.Latitude
+		target.Longitude = req.Longitude
+	}
+
+	// children reference their parent by value during validation
+	value := strings.TrimSpace(target.Value)
+	for idx := range current {
+		if current[idx].Parent == previous {
+			current[idx].Parent = value
+		}
+	}
+
+	normalized, err := normalizeLocations(current)
+	if err != nil {
+		return nil, err
+	}
+	updated := normalized[slices.IndexFunc(normalized, func(loc LocationOption) bool { return loc.Value == value })]
+	updated.ID = target.ID
+	updated.ParentID = target.ParentID
+
+	if err := s.store.UpdateCampaignLocation(campaignID, updated); err != nil {
+		if errors.Is(err, ErrLocationNotFound) {
+			return nil, err
+		}
+		return nil, DatabaseError{Err: err}
+	}
+
+	return s.GetCampaignLocation(campaignID, id)
+}
+
+// DeleteCampaignLocation removes the option and everything nested under it.
+func (s *Service) DeleteCampaignLocation(campaignID string, id int64) error {
+	err := s.store.DeleteCampaignLocation(campaignID, id)
+	if err != nil {
+		if errors.Is(err, ErrLocationNotFound) {
+			return err
+		}
+		return DatabaseError{Err: err}
+	}
+	return nil
+}
+
+func (s *Service) MoveCampaignLocation(campaignID string, id int64, req MoveLocationRequest) (*LocationOption, error) {
+	current, err := s.GetCampaignLocations(campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	target := findLocation(current, id)
+	if target == nil {
+		return nil, ErrLocationNotFound
+	}
+
+	parentValue := ""
+	if req.ParentID > 0 {
+		parent := findLocation(current, req.ParentID)
+		if parent == nil {
+			return nil, ErrUnknownLocationParent
+		}
+		parentValue = parent.Value
+	}
+	target.Parent = parentValue
+	target.ParentID = req.ParentID
+
+	// re-validating catches moves under a descendant and country mismatches
+	if _, err := normalizeLocations(current); err != nil {
+		return nil, err
+	}
+
+	var siblings []LocationOption
+	for _, loc := range current {
+		if loc.ParentID == req.ParentID && loc.ID != id {
+			siblings = append(siblings, loc)
+		}
+	}
+	slices.SortStableFunc(siblings, func(a, b LocationOption) int { return a.DisplayOrder - b.DisplayOrder })
+
+	position := len(siblings)
+	if req.Position > 0 && req.Position <= len(siblings) {
+		position = req.Position - 1
+	}
+	siblings = slices.Insert(siblings, position, *target)
+
+	siblingIDs := make([]int64, 0, len(siblings))
+	for _, loc := range siblings {
+		siblingIDs = append(siblingIDs, loc.ID)
+	}
+
+	if err := s.store.MoveCampaignLocation(campaignID, id, req.ParentID, siblingIDs); err != nil {
+		if errors.Is(err, ErrLocationNotFound) {
+			return nil, err
+		}
+		return nil, DatabaseError{Err: err}
+	}
+
+	return s.GetCampaignLocation(campaignID, id)
+}
+
+// MergeCampaignLocation folds one location into another, rewriting the
+// signatures that chose it and re-homing its children.
+func (s *Service) MergeCampaignLocation(campaignID string, id int64, req MergeLocationRequest) (*MergeLocationResponse, error) {
+	if id == req.IntoID {
+		return nil, ErrMergeIntoSelf
+	}
+
+	current, err := s.GetCampaignLocations(campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	source := findLocation(current, id)
+	if source == nil {
+		return nil, ErrLocationNotFound
+	}
+	target := findLocation(current, req.IntoID)
+	if target == nil {
+		return nil, ErrLocationNotFound
+	}
+
+	// validate the tree as it will look after the merge
+	merged := make([]LocationOption, 0, len(current))
+	for _, loc := range current {
+		if loc.ID == id {
+			continue
+		}
+		if loc.ParentID == id {
+			loc.Parent = target.Value
+			loc.ParentID = target.ID
+		}
+		merged = append(merged, loc)
+	}
+	if _, err := normalizeLocations(merged); err != nil {
+		return nil, err
+	}
+
+	rewritten, err := s.store.MergeCampaignLocation(campaignID, id, req.IntoID)
+	if err != nil {
+		if errors.Is(err, ErrLocationNotFound) {
+			return nil, err
+		}
+		return nil, DatabaseError{Err: err}
+	}
+
+	location, err := s.GetCampaignLocation(campaignID, req.IntoID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MergeLocationResponse{
+		Location:  location,
+		Rewritten: rewritten,
+	}, nil
+}
+
+func findLocation(options []LocationOption, id int64) *LocationOption {
+	for idx := range options {
+		if options[idx].ID == id {
+			return &options[idx]
+		}
+	}
+	return nil
+}
+
+func (s *Service) GetCampaignLocationStats(campaignID string) (*CampaignLocationStats, error) {
+	options, err := s.GetCampaignLocations(campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.store.CountSignaturesByLocation(campaignID)
+	if err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+
+	stats := &CampaignLocationStats{}
+	for value, count := range counts {
+		stats.Total += count
+		if !slices.ContainsFunc(options, func(opt LocationOption) bool { return opt.Value == value }) {
+			stats.Other += count
+		}
+	}
+
+	var rollup func(nodes []LocationOption) []LocationStat
+	rollup = func(nodes []LocationOption) []LocationStat {
+		result := make([]LocationStat, 0, len(nodes))
+		for _, node := range nodes {
+			stat := LocationStat{
+				Value:           node.Value,
+				CountryCode:     node.CountryCode,
+				SubdivisionCode: node.SubdivisionCode,
+				Count:           counts[node.Value],
+				Children:        rollup(node.Children),
+			}
+			stat.Total = stat.Count
+			for _, child := range stat.Children {
+				stat.Total += child.Total
+			}
+			result = append(result, stat)
+		}
+		return result
+	}
+	stats.Locations = rollup(BuildLocationTree(options))
+
+	return stats, nil
+}
+
+func (s *Service) handleGetCampaignLocationStats(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
+		return
+	}
+
+	stats, err := s.GetCampaignLocationStats(campaignID)
+	if err != nil {
+		writeServiceError(w, err, "failed to get location stats")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, stats)
+}
+
+func (s *Service) handleCreateCampaignLocation(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
+		return
+	}
+
+	var req LocationOption
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "invalid request body")
+		return
+	}
+
+	location, err := s.CreateCampaignLocation(campaignID, req)
+	if err != nil {
+		writeServiceError(w, err, "failed to create location")
+		return
+	}
+
+	wire.WriteData(w, http.StatusCreated, location)
+}
+
+func (s *Service) handleGetCampaignLocation(w http.ResponseWriter, r *http.Request) {
+	campaignID, locationID, ok := locationPathParams(w, r)
+	if !ok {
+		return
+	}
+
+	location, err := s.GetCampaignLocation(campaignID, locationID)
+	if err != nil {
+		writeServiceError(w, err, "failed to get location")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, location)
+}
+
+func (s *Service) handleUpdateCampaignLocation(w http.ResponseWriter, r *http.Request) {
+	campaignID, locationID, ok := locationPathParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateLocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "invalid request body")
+		return
+	}
+
+	location, err := s.UpdateCampaignLocation(campaignID, locationID, req)
+	if err != nil {
+		writeServiceError(w, err, "failed to update location")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, location)
+}
+
+func (s *Service) handleDeleteCampaignLocation(w http.ResponseWriter, r *http.Request) {
+	campaignID, locationID, ok := locationPathParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.DeleteCampaignLocation(campaignID, locationID); err != nil {
+		writeServiceError(w, err, "failed to delete location")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Service) handleMoveCampaignLocation(w http.ResponseWriter, r *http.Request) {
+	campaignID, locationID, ok := locationPathParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req MoveLocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "invalid request body")
+		return
+	}
+
+	location, err := s.MoveCampaignLocation(campaignID, locationID, req)
+	if err != nil {
+		writeServiceError(w, err, "failed to move location")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, location)
+}
+
+func (s *Service) handleMergeCampaignLocation(w http.ResponseWriter, r *http.Request) {
+	campaignID, locationID, ok := locationPathParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req MergeLocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "invalid request body")
+		return
+	}
+
+	result, err := s.MergeCampaignLocation(campaignID, locationID, req)
+	if err != nil {
+		writeServiceError(w, err, "failed to merge location")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, result)
+}
+
+func locationPathParams(w http.ResponseWriter, r *http.Request) (string, int64, bool) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
+		return "", 0, false
+	}
+
+	locationID, err := strconv.ParseInt(r.PathValue("location_id"), 10, 64)
+	if err != nil || locationID <= 0 {
+		writeError(w, http.StatusBadRequest, CodeInvalidID, "invalid location id")
+		return "", 0, false
+	}
+
+	return campaignID, locationID, true
+}
@@ -0,0 +1,388 @@
+package service_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cosign/internal/service"
+	"cosign/internal/testutil"
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+const nestedLocationsBody = `{"locations":[
+	{"value":"United States","country_code":"us","children":[
+		{"value":"New York","subdivision_code":"US-NY","children":[
+			{"value":"Brooklyn"},
+			{"value":"Queens"}
+		]},
+		{"value":"Vermont","subdivision_code":"US-VT"}
+	]},
+	{"value":"Canada","country_code":"CA","children":[
+		{"value":"Ontario","subdivision_code":"CA-ON"}
+	]}
+]}`
+
+func setNestedLocations(t *testing.T, handler http.Handler, campaignID string) {
+	t.Helper()
+
+	result := wire.TestPut[service.CampaignLocationsResponse](
+		handler,
+		"/admin/campaigns/"+campaignID+"/locations",
+		nestedLocationsBody,
+		authHeader(),
+	)
+	result.ExpectStatus(t, http.StatusOK)
+	if len(result.Data.Locations) != 7 {
+		t.Fatalf("expected seven flat locations, got %d", len(result.Data.Locations))
+	}
+}
+
+func TestHierarchicalLocationsRoundTrip(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "National")
+	setNestedLocations(t, handler, campaign.ID)
+
+	admin := wire.TestGet[service.CampaignLocationsResponse](handler, "/admin/campaigns/"+campaign.ID+"/locations", authHeader())
+	admin.ExpectStatus(t, http.StatusOK)
+	byValue := make(map[string]service.LocationOption)
+	for _, loc := range admin.Data.Locations {
+		byValue[loc.Value] = loc
+	}
+	if got := byValue["United States"]; got.CountryCode != "US" || got.Parent != "" {
+		t.Fatalf("unexpected country option: %+v", got)
+	}
+	if got := byValue["Brooklyn"]; got.Parent != "New York" || got.ParentID != byValue["New York"].ID {
+		t.Fatalf("expected brooklyn under new york, got %+v", got)
+	}
+
+	public := wire.TestGet[service.CampaignLocationsResponse](handler, "/campaigns/"+campaign.ID+"/locations")
+	public.ExpectStatus(t, http.StatusOK)
+	if len(public.Data.Locations) != 2 || public.Data.Locations[0].Value != "United States" {
+		t.Fatalf("expected two top-level groups, got %+v", public.Data.Locations)
+	}
+	states := public.Data.Locations[0].Children
+	if len(states) != 2 || states[0].Value != "New York" || len(states[0].Children) != 2 {
+		t.Fatalf("expected nested states and cities, got %+v", states)
+	}
+}
+
+func TestHierarchicalLocationsRejectInvalidTrees(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "National")
+
+	for name, body := range map[string]string{
+		"unknown parent":         `{"locations":[{"value":"Brooklyn","parent":"New York"}]}`,
+		"cycle":                  `{"locations":[{"value":"A","parent":"B"},{"value":"B","parent":"A"}]}`,
+		"duplicate":              `{"locations":[{"value":"A"},{"value":"B","children":[{"value":"A"}]}]}`,
+		"bad country":            `{"locations":[{"value":"A","country_code":"USA"}]}`,
+		"mismatched subdivision": `{"locations":[{"value":"A","country_code":"CA","children":[{"value":"B","subdivision_code":"US-NY"}]}]}`,
+	} {
+		result := wire.TestPut[service.CampaignLocationsResponse](
+			handler,
+			"/admin/campaigns/"+campaign.ID+"/locations",
+			body,
+			authHeader(),
+		)
+		if result.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", name, result.Code)
+		}
+	}
+}
+
+func TestSignatureLocationMustBeSelectableLevel(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "National")
+	setNestedLocations(t, handler, campaign.ID)
+
+	strict := wire.TestPut[service.Campaign](handler, "/admin/campaigns/"+campaign.ID, `{"allow_custom_text":false}`, authHeader())
+	strict.ExpectStatus(t, http.StatusOK)
+
+	sign := func(email, location string) int {
+		body := `{"name":"Alex","email":"` + email + `","location":"` + location + `"}`
+		return wire.TestPost[service.Signature](handler, "/campaigns/"+campaign.ID+"/signatures", body).Code
+	}
+
+	if status := sign("a@example.com", "New York"); status != http.StatusBadRequest {
+		t.Fatalf("expected branch selection to be rejected, got %d", status)
+	}
+	if status := sign("b@example.com", "Brooklyn"); status != http.StatusCreated {
+		t.Fatalf("expected leaf selection to be accepted, got %d", status)
+	}
+	if status := sign("c@example.com", "Vermont"); status != http.StatusCreated {
+		t.Fatalf("expected shallow leaf to be accepted, got %d", status)
+	}
+
+	level := wire.TestPut[service.Campaign](handler, "/admin/campaigns/"+campaign.ID, `{"location_level":2}`, authHeader())
+	level.ExpectStatus(t, http.StatusOK)
+	if level.Data.LocationLevel != 2 {
+		t.Fatalf("expected location level 2, got %d", level.Data.LocationLevel)
+	}
+
+	if status := sign("d@example.com", "New York"); status != http.StatusCreated {
+		t.Fatalf("expected state selection at level 2, got %d", status)
+	}
+	if status := sign("e@example.com", "Queens"); status != http.StatusBadRequest {
+		t.Fatalf("expected city selection to be rejected at level 2, got %d", status)
+	}
+}
+
+func TestLocationStatsRollUp(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "National")
+	setNestedLocations(t, handler, campaign.ID)
+
+	for email, location := range map[string]string{
+		"a@example.com": "Brooklyn",
+		"b@example.com": "Brooklyn",
+		"c@example.com": "Queens",
+		"d@example.com": "Vermont",
+		"e@example.com": "Ontario",
+		"f@example.com": "Somewhere Else",
+	} {
+		if _, err := svc.CreateSignature(campaign.ID, "Alex", email, location); err != nil {
+			t.Fatalf("create signature: %v", err)
+		}
+	}
+
+	stats := wire.TestGet[service.CampaignLocationStats](handler, "/admin/campaigns/"+campaign.ID+"/locations/stats", authHeader())
+	stats.ExpectStatus(t, http.StatusOK)
+	if stats.Data.Total != 6 || stats.Data.Other != 1 {
+		t.Fatalf("unexpected totals: %+v", stats.Data)
+	}
+
+	us := stats.Data.Locations[0]
+	if us.Value != "United States" || us.Count != 0 || us.Total != 4 {
+		t.Fatalf("unexpected country rollup: %+v", us)
+	}
+	if ny := us.Children[0]; ny.Value != "New York" || ny.Total != 3 || ny.Children[0].Count != 2 {
+		t.Fatalf("unexpected state rollup: %+v", ny)
+	}
+	if ca := stats.Data.Locations[1]; ca.Total != 1 {
+		t.Fatalf("unexpected canada rollup: %+v", ca)
+	}
+}
+
+func locationIDs(t *testing.T, handler http.Handler, campaignID string) map[string]int64 {
+	t.Helper()
+
+	result := wire.TestGet[service.CampaignLocationsResponse](handler, "/admin/campaigns/"+campaignID+"/locations", authHeader())
+	result.ExpectStatus(t, http.StatusOK)
+
+	ids := make(map[string]int64, len(result.Data.Locations))
+	for _, loc := range result.Data.Locations {
+		ids[loc.Value] = loc.ID
+	}
+	return ids
+}
+
+func patchLocation(t *testing.T, handler http.Handler, path, body string) (int, service.LocationOption) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPatch, path, strings.NewReader(body))
+	req.Header.Set(authHeader().Key, authHeader().Value)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var envelope struct {
+		Data service.LocationOption `json:"data"`
+	}
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+			t.Fatalf("decode patch response: %v", err)
+		}
+	}
+	return rec.Code, envelope.Data
+}
+
+func TestLocationGranularCRUDKeepsIDs(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "National")
+	setNestedLocations(t, handler, campaign.ID)
+	before := locationIDs(t, handler, campaign.ID)
+	base := "/admin/campaigns/" + campaign.ID + "/locations/"
+
+	created := wire.TestPost[service.LocationOption](
+		handler,
+		"/admin/campaigns/"+campaign.ID+"/locations",
+		fmt.Sprintf(`{"value":"Manhattan","parent_id":%d,"labels":{"fr":"Manhattan (NY)"}}`, before["New York"]),
+		authHeader(),
+	)
+	created.ExpectStatus(t, http.StatusCreated)
+	if created.Data.ID == 0 || created.Data.Parent != "New York" || created.Data.DisplayOrder != 3 {
+		t.Fatalf("unexpected created location: %+v", created.Data)
+	}
+
+	code, renamed := patchLocation(t, handler, base+fmt.Sprint(before["New York"]), `{"value":"New York State"}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+	if renamed.ID != before["New York"] || renamed.Value != "New York State" || renamed.SubdivisionCode != "US-NY" {
+		t.Fatalf("unexpected renamed location: %+v", renamed)
+	}
+
+	child := wire.TestGet[service.LocationOption](handler, base+fmt.Sprint(before["Brooklyn"]), authHeader())
+	child.ExpectStatus(t, http.StatusOK)
+	if child.Data.Parent != "New York State" {
+		t.Fatalf("expected child to follow renamed parent, got %+v", child.Data)
+	}
+
+	if code, _ := patchLocation(t, handler, base+fmt.Sprint(before["Queens"]), `{"value":"Brooklyn"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected duplicate rename to fail with 400, got %d", code)
+	}
+
+	deleted := wire.TestDelete[struct{}](handler, base+fmt.Sprint(before["New York"]), authHeader())
+	deleted.ExpectStatus(t, http.StatusNoContent)
+
+	after := locationIDs(t, handler, campaign.ID)
+	if _, ok := after["Brooklyn"]; ok {
+		t.Fatalf("expected descendants to be deleted with their parent")
+	}
+	if after["Vermont"] != before["Vermont"] || after["Canada"] != before["Canada"] {
+		t.Fatalf("expected untouched locations to keep their ids")
+	}
+
+	missing := wire.TestDelete[struct{}](handler, base+fmt.Sprint(before["New York"]), authHeader())
+	missing.ExpectStatus(t, http.StatusNotFound)
+}
+
+func TestLocationMoveReordersAndReparents(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "National")
+	setNestedLocations(t, handler, campaign.ID)
+	ids := locationIDs(t, handler, campaign.ID)
+	base := "/admin/campaigns/" + campaign.ID + "/locations/"
+
+	first := wire.TestPost[service.LocationOption](
+		handler,
+		base+fmt.Sprint(ids["Vermont"])+"/move",
+		fmt.Sprintf(`{"parent_id":%d,"position":1}`, ids["United States"]),
+		authHeader(),
+	)
+	first.ExpectStatus(t, http.StatusOK)
+
+	public := wire.TestGet[service.CampaignLocationsResponse](handler, "/campaigns/"+campaign.ID+"/locations")
+	public.ExpectStatus(t, http.StatusOK)
+	if states := public.Data.Locations[0].Children; states[0].Value != "Vermont" || states[1].Value != "New York" {
+		t.Fatalf("expected vermont first, got %+v", states)
+	}
+
+	wrongCountry := wire.TestPost[service.LocationOption](
+		handler,
+		base+fmt.Sprint(ids["Vermont"])+"/move",
+		fmt.Sprintf(`{"parent_id":%d}`, ids["Canada"]),
+		authHeader(),
+	)
+	wrongCountry.ExpectStatus(t, http.StatusBadRequest)
+
+	underDescendant := wire.TestPost[service.LocationOption](
+		handler,
+		base+fmt.Sprint(ids["United States"])+"/move",
+		fmt.Sprintf(`{"parent_id":%d}`, ids["Brooklyn"]),
+		authHeader(),
+	)
+	underDescendant.ExpectStatus(t, http.StatusBadRequest)
+
+	toTop := wire.TestPost[service.LocationOption](handler, base+fmt.Sprint(ids["Queens"])+"/move", `{"parent_id":0}`, authHeader())
+	toTop.ExpectStatus(t, http.StatusOK)
+	if toTop.Data.Parent != "" || toTop.Data.DisplayOrder != 3 {
+		t.Fatalf("expected queens at the end of the top level, got %+v", toTop.Data)
+	}
+}
+
+func TestLocationReplacePreservesIDs(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Flat")
+
+	path := "/admin/campaigns/" + campaign.ID + "/locations"
+	first := wire.TestPut[service.CampaignLocationsResponse](handler, path, `{"locations":[{"value":"A"},{"value":"B"}]}`, authHeader())
+	first.ExpectStatus(t, http.StatusOK)
+	before := locationIDs(t, handler, campaign.ID)
+
+	second := wire.TestPut[service.CampaignLocationsResponse](handler, path, `{"locations":[{"value":"C"},{"value":"B"}]}`, authHeader())
+	second.ExpectStatus(t, http.StatusOK)
+	after := locationIDs(t, handler, campaign.ID)
+
+	if after["B"] != before["B"] {
+		t.Fatalf("expected B to keep id %d, got %d", before["B"], after["B"])
+	}
+	if _, ok := after["A"]; ok || after["C"] == 0 {
+		t.Fatalf("unexpected locations after replace: %+v", after)
+	}
+	if second.Data.Locations[0].Value != "C" {
+		t.Fatalf("expected replace order to be kept, got %+v", second.Data.Locations)
+	}
+}
+
+func TestSignatureLocationMatchesAliases(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "City")
+	path := "/admin/campaigns/" + campaign.ID + "/locations"
+
+	conflict := wire.TestPut[service.CampaignLocationsResponse](handler, path, `{"locations":[{"value":"New York City","aliases":["Boston"]},{"value":"Boston"}]}`, authHeader())
+	conflict.ExpectStatus(t, http.StatusBadRequest)
+
+	put := wire.TestPut[service.CampaignLocationsResponse](
+		handler,
+		path,
+		`{"locations":[{"value":"New York City","aliases":[" nyc ","New York, NY","new york city","NYC"]},{"value":"Boston"}]}`,
+		authHeader(),
+	)
+	put.ExpectStatus(t, http.StatusOK)
+	if got := put.Data.Locations[0].Aliases; len(got) != 2 || got[0] != "nyc" || got[1] != "New York, NY" {
+		t.Fatalf("expected trimmed, de-duplicated aliases, got %q", got)
+	}
+
+	cases := map[string]string{
+		"NYC":                "New York City",
+		"new york,  ny":      "New York City",
+		"  new   YORK city ": "New York City",
+		"boston":             "Boston",
+		"Atlantis":           "Atlantis",
+	}
+	idx := 0
+	for raw, want := range cases {
+		idx++
+		signature, err := svc.CreateSignature(campaign.ID, "Alex", fmt.Sprintf("alias%d@example.com", idx), raw)
+		if err != nil {
+			t.Fatalf("create signature %q: %v", raw, err)
+		}
+		if signature.Location != want || signature.LocationRaw != strings.TrimSpace(raw) {
+			t.Fatalf("expected %q to be stored as %q, got %+v", raw, want, signature)
+		}
+
+		stored, err := svc.GetSignature(campaign.ID, signature.ID)
+		if err != nil {
+			t.Fatalf("get signature: %v", err)
+		}
+		if stored.LocationRaw != signature.LocationRaw {
+			t.Fatalf("expected raw location to be stored, got %+v", stored)
+		}
+	}
+
+	strict := wire.TestPut[service.Campaign](handler, "/admin/campaigns/"+campaign.ID, `{"allow_custom_text":false}`, authHeader())
+	strict.ExpectStatus(t, http.StatusOK)
+	if _, err := svc.CreateSignature(campaign.ID, "Alex", "strict@example.com", "nyc"); err != nil {
+		t.Fatalf("expected alias to satisfy preset validation: %v", err)
+	}
+
+	ids := locationIDs(t, handler, campaign.ID)
+	code, updated := patchLocation(t, handler, fmt.Sprintf("%s/%d", path, ids["Boston"]), `{"aliases":["Beantown"]}`)
+	if code != http.StatusOK || len(updated.Aliases) != 1 || updated.Aliases[0] != "Beantown" {
+		t.Fatalf("unexpected alias update: %d %+v", code, updated)
+	}
+	if code, _ := patchLocation(t, handler, fmt.Sprintf("%s/%d", path, ids["Boston"]), `{"aliases":["NYC"]}`); code != http.StatusBadRequest {
+		t.Fatalf("expected alias owned by another location to be rejected, got %d", code)
+	}
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"cosign/pkg/cosignapi"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+type (
+	LocationSuggestion          = cosignapi.LocationSuggestion
+	LocationSuggestionsResponse = cosignapi.LocationSuggestionsResponse
+	LocationMapping             = cosignapi.LocationMapping
+	NormalizeLocationsRequest   = cosignapi.NormalizeLocationsRequest
+	NormalizeLocationsResponse  = cosignapi.NormalizeLocationsResponse
+)
+
+// SuggestLocationNormalizations lists every signed location that is not a
+// preset, most common first, paired with the closest selectable preset by
+// case-folded edit distance against values, aliases and labels.
+func (s *Service) SuggestLocationNormalizations(campaignID string) (*LocationSuggestionsResponse, error) {
+	campaign, err := s.GetCampaign(campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := s.GetCampaignLocations(campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.store.CountSignaturesByLocation(campaignID)
+	if err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+
+	presets := make(map[string]bool, len(options))
+	for _, opt := range options {
+		presets[opt.Value] = true
+	}
+	candidates := SelectableLocations(options, campaign.LocationLevel)
+
+	suggestions := []LocationSuggestion{}
+	for value, count := range counts {
+		if presets[value] || strings.TrimSpace(value) == "" {
+			continue
+		}
+
+		suggestion := LocationSuggestion{Value: value, Count: count}
+		if match, distance, ok := closestLocation(value, candidates); ok {
+			suggestion.LocationID = match.ID
+			suggestion.Suggestion = match.Value
+			suggestion.Distance = distance
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	slices.SortFunc(suggestions, func(a, b LocationSuggestion) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Value, b.Value)
+	})
+
+	return &LocationSuggestionsResponse{Suggestions: suggestions}, nil
+}
+
+// NormalizeLocations rewrites signatures carrying each mapped free-text
+// value to the chosen preset, in a single transaction. The preset must be
+// one a signer could pick at the campaign's location level.
+func (s *Service) NormalizeLocations(campaignID string, req NormalizeLocationsRequest) (*NormalizeLocationsResponse, error) {
+	campaign, err := s.GetCampaign(campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := s.GetCampaignLocations(campaignID)
+	if err != nil {
+		return nil, err
+	}
+	selectable := SelectableLocations(options, campaign.LocationLevel)
+
+	mappings := make(map[string]string, len(req.Mappings))
+	for _, mapping := range req.Mappings {
+		if mapping.Value == "" {
+			return nil, ErrEmptyLocation
+		}
+		target := findLocation(selectable, mapping.LocationID)
+		if target == nil {
+			if findLocation(options, mapping.LocationID) == nil {
+				return nil, ErrLocationNotFound
+			}
+			return nil, ErrLocationNotInOptions
+		}
+		mappings[mapping.Value] = target.Value
+	}
+
+	rewritten, err := s.store.RemapSignatureLocations(campaignID, mappings)
+	if err != nil {
+		if errors.Is(err, ErrCampaignNotFound) {
+			return nil, err
+		}
+		return nil, DatabaseError{Err: err}
+	}
+
+	return &NormalizeLocationsResponse{Rewritten: rewritten}, nil
+}
+
+// closestLocation accepts a match when at most a quarter of the folded
+// preset (minimum one character) has to change.
+func closestLocation(value string, candidates []LocationOption) (LocationOption, int, bool) {
+	folded := foldLocation(value)
+
+	var best LocationOption
+	bestDistance := -1
+	for _, candidate := range candidates {
+		names := append([]string{candidate.Value}, candidate.Aliases...)
+		for _, label := range candidate.Labels {
+			names = append(names, label)
+		}
+
+		for _, name := range names {
+			target := foldLocation(name)
+			distance := editDistance(folded, target)
+			if distance > max(1, len([]rune(target))/4) {
+				continue
+			}
+			if bestDistance < 0 || distance < bestDistance {
+				best = candidate
+				bestDistance = distance
+			}
+		}
+	}
+
+	return best, bestDistance, bestDistance >= 0
+}
+
+// foldLocation lowercases and drops punctuation so "new york." and
+// "New  York" compare equal.
+func foldLocation(value string) string {
+	fields := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// editDistance is the Levenshtein distance between two strings, by rune.
+func editDistance(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(br)]
+}
+
+func (s *Service) handleGetLocationSuggestions(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
+		return
+	}
+
+	suggestions, err := s.SuggestLocationNormalizations(campaignID)
+	if err != nil {
+		writeServiceError(w, err, "failed to suggest locations")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, suggestions)
+}
+
+func (s *Service) handleNormalizeLocations(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
+		return
+	}
+
+	var req NormalizeLocationsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "invalid request body")
+		return
+	}
+
+	result, err := s.NormalizeLocations(campaignID, req)
+	if err != nil {
+		writeServiceError(w, err, "failed to normalize locations")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, result)
+}
@@ -0,0 +1,186 @@
+package service_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"cosign/internal/service"
+	"cosign/internal/testutil"
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+func signLocations(t *testing.T, svc *service.Service, campaignID string, locations ...string) {
+	t.Helper()
+
+	for idx, location := range locations {
+		email := fmt.Sprintf("signer%d@example.com", idx)
+		if _, err := svc.CreateSignature(campaignID, "Alex", email, location); err != nil {
+			t.Fatalf("create signature: %v", err)
+		}
+	}
+}
+
+func locationCounts(t *testing.T, handler http.Handler, campaignID string) map[string]int {
+	t.Helper()
+
+	result := wire.TestGet[service.Signatures](handler, "/admin/campaigns/"+campaignID+"/signatures?limit=100", authHeader())
+	result.ExpectStatus(t, http.StatusOK)
+
+	counts := make(map[string]int)
+	for _, sig := range result.Data.Signatures {
+		counts[sig.Location]++
+	}
+	return counts
+}
+
+func TestLocationRenameRewritesSignatures(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "City")
+
+	put := wire.TestPut[service.CampaignLocationsResponse](handler, "/admin/campaigns/"+campaign.ID+"/locations", `{"locations":[{"value":"NYC"},{"value":"Boston"}]}`, authHeader())
+	put.ExpectStatus(t, http.StatusOK)
+	signLocations(t, svc, campaign.ID, "NYC", "NYC", "Boston")
+
+	ids := locationIDs(t, handler, campaign.ID)
+	code, _ := patchLocation(t, handler, fmt.Sprintf("/admin/campaigns/%s/locations/%d", campaign.ID, ids["NYC"]), `{"value":"New York City"}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+
+	counts := locationCounts(t, handler, campaign.ID)
+	if counts["New York City"] != 2 || counts["NYC"] != 0 || counts["Boston"] != 1 {
+		t.Fatalf("unexpected signature locations after rename: %+v", counts)
+	}
+}
+
+func TestLocationMergeRewritesSignaturesAndChildren(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "National")
+	setNestedLocations(t, handler, campaign.ID)
+	ids := locationIDs(t, handler, campaign.ID)
+	signLocations(t, svc, campaign.ID, "Queens", "Brooklyn", "New York")
+
+	base := "/admin/campaigns/" + campaign.ID + "/locations/"
+	self := wire.TestPost[service.MergeLocationResponse](handler, base+fmt.Sprint(ids["Queens"])+"/merge", fmt.Sprintf(`{"into_id":%d}`, ids["Queens"]), authHeader())
+	self.ExpectStatus(t, http.StatusBadRequest)
+
+	intoChild := wire.TestPost[service.MergeLocationResponse](handler, base+fmt.Sprint(ids["New York"])+"/merge", fmt.Sprintf(`{"into_id":%d}`, ids["Brooklyn"]), authHeader())
+	intoChild.ExpectStatus(t, http.StatusBadRequest)
+
+	merged := wire.TestPost[service.MergeLocationResponse](handler, base+fmt.Sprint(ids["Queens"])+"/merge", fmt.Sprintf(`{"into_id":%d}`, ids["Brooklyn"]), authHeader())
+	merged.ExpectStatus(t, http.StatusOK)
+	if merged.Data.Rewritten != 1 || merged.Data.Location.ID != ids["Brooklyn"] {
+		t.Fatalf("unexpected merge result: %+v", merged.Data)
+	}
+
+	counts := locationCounts(t, handler, campaign.ID)
+	if counts["Brooklyn"] != 2 || counts["Queens"] != 0 {
+		t.Fatalf("unexpected signature locations after merge: %+v", counts)
+	}
+
+	// merging a branch re-homes its children under the target
+	state := wire.TestPost[service.MergeLocationResponse](handler, base+fmt.Sprint(ids["New York"])+"/merge", fmt.Sprintf(`{"into_id":%d}`, ids["Vermont"]), authHeader())
+	state.ExpectStatus(t, http.StatusOK)
+
+	brooklyn := wire.TestGet[service.LocationOption](handler, base+fmt.Sprint(ids["Brooklyn"]), authHeader())
+	brooklyn.ExpectStatus(t, http.StatusOK)
+	if brooklyn.Data.ParentID != ids["Vermont"] {
+		t.Fatalf("expected brooklyn under vermont, got %+v", brooklyn.Data)
+	}
+	if counts := locationCounts(t, handler, campaign.ID); counts["Vermont"] != 1 {
+		t.Fatalf("expected state signature to move to vermont, got %+v", counts)
+	}
+}
+
+func TestLocationNormalizationSuggestsAndApplies(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "City")
+
+	put := wire.TestPut[service.CampaignLocationsResponse](
+		handler,
+		"/admin/campaigns/"+campaign.ID+"/locations",
+		`{"locations":[{"value":"New York City"},{"value":"Boston"},{"value":"Montréal","labels":{"en":"Montreal"}}]}`,
+		authHeader(),
+	)
+	put.ExpectStatus(t, http.StatusOK)
+	signLocations(t, svc, campaign.ID, "new york city.", "New York  City.", "new york city.", "Bostn", "montreal", "Atlantis", "Boston")
+
+	result := wire.TestGet[service.LocationSuggestionsResponse](handler, "/admin/campaigns/"+campaign.ID+"/locations/suggestions", authHeader())
+	result.ExpectStatus(t, http.StatusOK)
+
+	suggestions := make(map[string]service.LocationSuggestion)
+	for _, suggestion := range result.Data.Suggestions {
+		suggestions[suggestion.Value] = suggestion
+	}
+	if len(suggestions) != 5 || result.Data.Suggestions[0].Value != "new york city." || result.Data.Suggestions[0].Count != 2 {
+		t.Fatalf("unexpected suggestions: %+v", result.Data.Suggestions)
+	}
+	if got := suggestions["New York  City."]; got.Suggestion != "New York City" || got.Distance != 0 {
+		t.Fatalf("expected folded match, got %+v", got)
+	}
+	if got := suggestions["Bostn"]; got.Suggestion != "Boston" || got.Distance != 1 {
+		t.Fatalf("expected edit distance match, got %+v", got)
+	}
+	if got := suggestions["montreal"]; got.Suggestion != "Montréal" {
+		t.Fatalf("expected label match, got %+v", got)
+	}
+	if got := suggestions["Atlantis"]; got.LocationID != 0 {
+		t.Fatalf("expected no match for unrelated text, got %+v", got)
+	}
+
+	body := fmt.Sprintf(
+		`{"mappings":[{"value":"new york city.","location_id":%d},{"value":"Bostn","location_id":%d}]}`,
+		suggestions["new york city."].LocationID,
+		suggestions["Bostn"].LocationID,
+	)
+	applied := wire.TestPost[service.NormalizeLocationsResponse](handler, "/admin/campaigns/"+campaign.ID+"/locations/normalize", body, authHeader())
+	applied.ExpectStatus(t, http.StatusOK)
+	if applied.Data.Rewritten != 3 {
+		t.Fatalf("expected three rewritten signatures, got %d", applied.Data.Rewritten)
+	}
+
+	counts := locationCounts(t, handler, campaign.ID)
+	if counts["New York City"] != 2 || counts["Boston"] != 2 || counts["new york city."] != 0 {
+		t.Fatalf("unexpected signature locations after normalize: %+v", counts)
+	}
+
+	unknown := wire.TestPost[service.NormalizeLocationsResponse](handler, "/admin/campaigns/"+campaign.ID+"/locations/normalize", `{"mappings":[{"value":"Atlantis","location_id":999}]}`, authHeader())
+	unknown.ExpectStatus(t, http.StatusNotFound)
+}
+
+func TestLocationNormalizationRejectsUnselectableTargets(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "National")
+	setNestedLocations(t, handler, campaign.ID)
+	signLocations(t, svc, campaign.ID, "NYC")
+
+	locations := wire.TestGet[service.CampaignLocationsResponse](handler, "/admin/campaigns/"+campaign.ID+"/locations", authHeader())
+	locations.ExpectStatus(t, http.StatusOK)
+	ids := make(map[string]int64)
+	for _, loc := range locations.Data.Locations {
+		ids[loc.Value] = loc.ID
+	}
+
+	normalize := func(locationID int64) wire.TestResult[service.NormalizeLocationsResponse] {
+		body := fmt.Sprintf(`{"mappings":[{"value":"NYC","location_id":%d}]}`, locationID)
+		return wire.TestPost[service.NormalizeLocationsResponse](
+			handler, "/admin/campaigns/"+campaign.ID+"/locations/normalize", body, authHeader(),
+		)
+	}
+
+	branch := normalize(ids["New York"])
+	branch.ExpectStatus(t, http.StatusBadRequest)
+	if code := decodeAPIError(t, branch.Raw).Code; code != service.CodeLocationNotInOptions {
+		t.Fatalf("expected %s for a branch target, got %s", service.CodeLocationNotInOptions, code)
+	}
+	if counts := locationCounts(t, handler, campaign.ID); counts["NYC"] != 1 {
+		t.Fatalf("expected the signature to keep its location, got %+v", counts)
+	}
+
+	normalize(ids["Brooklyn"]).ExpectStatus(t, http.StatusOK)
+}
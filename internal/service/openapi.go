@@ -0,0 +1,821 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/cors"
+)
+
+// apiOperation documents one route. Request and response bodies are given
+// as Go values, and their types are turned into the document's schemas, so
+// the field lists cannot drift from the structs.
+type apiOperation struct {
+	method  string
+	path    string
+	id      string
+	tag     string
+	summary string
+	auth    bool
+	params  []string
+
+	request         any
+	requestExample  string
+	formRequest     bool
+	csvRequest      bool
+	status          int
+	response        any
+	responseExample string
+	mediaType       string
+	etag            bool
+	errors          []int
+
+	// plainErrors marks routes whose errors carry only a message, as the
+	// keys and cors packages write them.
+	plainErrors bool
+}
+
+var apiParameters = map[string]map[string]any{
+	"limit": {
+		"name": "limit", "in": "query",
+		"description": "page size (default 100)",
+		"schema":      map[string]any{"type": "integer", "minimum": 1},
+	},
+	"offset": {
+		"name": "offset", "in": "query",
+		"description": "number of items to skip",
+		"schema":      map[string]any{"type": "integer", "minimum": 0},
+	},
+	"lang": {
+		"name": "lang", "in": "query",
+		"description": "language tag, preferred over Accept-Language",
+		"schema":      map[string]any{"type": "string"},
+	},
+	"accept_language": {
+		"name": "Accept-Language", "in": "header",
+		"schema": map[string]any{"type": "string"},
+	},
+	"if_match": {
+		"name": "If-Match", "in": "header",
+		"description": "revision ETag the write is based on; a stale revision fails with 412",
+		"schema":      map[string]any{"type": "string"},
+	},
+	"idempotency_key": {
+		"name": "Idempotency-Key", "in": "header",
+		"description": "replays the stored response when a request is retried",
+		"schema":      map[string]any{"type": "string", "maxLength": maxIdempotencyKeyLength},
+	},
+	"last_event_id": {
+		"name": "Last-Event-ID", "in": "header",
+		"description": "resume the stream after this event",
+		"schema":      map[string]any{"type": "string"},
+	},
+	"last_event_id_query": {
+		"name": "last_event_id", "in": "query",
+		"description": "resume the stream after this event, for clients that cannot set headers",
+		"schema":      map[string]any{"type": "string"},
+	},
+	"label": {
+		"name": "label", "in": "query",
+		"description": "badge label (default \"signatures\")",
+		"schema":      map[string]any{"type": "string", "maxLength": badgeMaxLabelLen},
+	},
+	"webhook_campaign_id": {
+		"name": "campaign_id", "in": "query",
+		"description": "only webhooks scoped to this campaign",
+		"schema":      map[string]any{"type": "string"},
+	},
+	"stats_days": {
+		"name": "days", "in": "query",
+		"description": "number of days in the daily series, ending today (default 30)",
+		"schema":      map[string]any{"type": "integer", "minimum": 1, "maximum": MaxStatsDays},
+	},
+	"signature_query": {
+		"name": "q", "in": "query",
+		"description": "only signatures whose name or email contains this text, ignoring case",
+		"schema":      map[string]any{"type": "string"},
+	},
+	"signature_location": {
+		"name": "location", "in": "query",
+		"description": "only signatures with exactly this location",
+		"schema":      map[string]any{"type": "string"},
+	},
+	"signature_visibility": {
+		"name": "visibility", "in": "query",
+		"description": "only visible or only hidden signatures (default both)",
+		"schema":      map[string]any{"type": "string", "enum": []string{VisibilityVisible, VisibilityHidden}},
+	},
+	"signature_ids": {
+		"name": "ids", "in": "query",
+		"description": "only these signatures, as comma separated ids",
+		"schema":      map[string]any{"type": "string"},
+	},
+	"delivery_status": {
+		"name": "status", "in": "query",
+		"schema": map[string]any{"type": "string", "enum": []string{DeliveryStatusPending, DeliveryStatusSucceeded, DeliveryStatusDead}},
+	},
+}
+
+var integerPathParams = []string{"location_id", "signature_id", "delivery_id"}
+
+var apiOperations = []apiOperation{
+	{
+		method: http.MethodGet, path: "/health", id: "getHealth", tag: "meta",
+		summary: "Check service and database health",
+		status:  http.StatusOK, response: HealthResponse{},
+		responseExample: `{"status":"healthy"}`,
+		errors:          []int{http.StatusServiceUnavailable},
+	},
+	{
+		method: http.MethodGet, path: "/openapi.json", id: "getOpenAPI", tag: "meta",
+		summary: "This document",
+		status:  http.StatusOK, mediaType: "application/json",
+	},
+
+	// public campaign routes
+	{
+		method: http.MethodGet, path: "/campaigns/{campaign_id}", id: "getPublicCampaign", tag: "public",
+		summary: "Get a campaign in the best matching language",
+		params:  []string{"lang", "accept_language"},
+		status:  http.StatusOK, response: Campaign{},
+		responseExample: `{"id":"cmp-1","name":"Save the park","letter":"Dear council,","language":"en","goal":500,"allow_custom_text":true,"location_level":0,"theme":{"primary_color":"#1f6feb","background_color":"","logo_url":""},"success_url":"","error_url":"","created_at":1700000000,"revision":3,"locations_revision":1}`,
+		errors:          []int{http.StatusNotFound},
+	},
+	{method: http.MethodOptions, path: "/campaigns/{campaign_id}", id: "preflightPublicCampaign", tag: "public"},
+	{
+		method: http.MethodGet, path: "/campaigns/{campaign_id}/locations", id: "getPublicCampaignLocations", tag: "public",
+		summary: "List a campaign's location options with localized labels",
+		params:  []string{"lang", "accept_language"},
+		status:  http.StatusOK, response: CampaignLocationsResponse{},
+		responseExample: `{"locations":[{"id":1,"value":"New York","label":"Nueva York","display_order":0,"country_code":"US","children":[{"id":2,"value":"Brooklyn","display_order":0,"parent":"New York","parent_id":1}]}]}`,
+		errors:          []int{http.StatusNotFound},
+	},
+	{method: http.MethodOptions, path: "/campaigns/{campaign_id}/locations", id: "preflightPublicCampaignLocations", tag: "public"},
+	{
+		method: http.MethodGet, path: "/campaigns/{campaign_id}/locations/geojson", id: "getSignatureMap", tag: "public",
+		summary: "Signature counts per mapped location as GeoJSON",
+		params:  []string{"lang", "accept_language"},
+		status:  http.StatusOK, response: GeoJSONFeatureCollection{}, mediaType: "application/geo+json",
+		responseExample: `{"type":"FeatureCollection","features":[{"type":"Feature","geometry":{"type":"Point","coordinates":[-73.94,40.65]},"properties":{"id":2,"value":"Brooklyn","count":12}}],"unmapped":{"total":1,"locations":[{"value":"Atlantis","count":1,"custom":true}]}}`,
+		errors:          []int{http.StatusNotFound},
+	},
+	{method: http.MethodOptions, path: "/campaigns/{campaign_id}/locations/geojson", id: "preflightSignatureMap", tag: "public"},
+	{
+		method: http.MethodGet, path: "/campaigns/{campaign_id}/signatures", id: "listPublicSignatures", tag: "public",
+		summary: "List a campaign's signatures, newest first, optionally filtered",
+		params:  []string{"limit", "offset", "signature_query", "signature_location", "signature_visibility", "signature_ids"},
+		status:  http.StatusOK, response: Signatures{},
+		responseExample: `{"signatures":[{"id":7,"name":"Alex","email":"alex@example.com","location":"Brooklyn","created_at":1700000000}],"total":1,"limit":100,"offset":0}`,
+		errors:          []int{http.StatusBadRequest},
+	},
+	{method: http.MethodOptions, path: "/campaigns/{campaign_id}/signatures", id: "preflightSignatures", tag: "public"},
+	{
+		method: http.MethodPost, path: "/campaigns/{campaign_id}/signatures", id: "createSignature", tag: "public",
+		summary: "Sign a campaign; form posts redirect to the campaign's success or error URL when set",
+		params:  []string{"idempotency_key", "lang", "accept_language"},
+		request: CreateSignatureRequest{}, formRequest: true,
+		requestExample: `{"name":"Alex","email":"alex@example.com","location":"Brooklyn"}`,
+		status:         http.StatusCreated, response: Signature{},
+		responseExample: `{"id":7,"name":"Alex","email":"alex@example.com","location":"Brooklyn","location_raw":"brooklyn","created_at":1700000000}`,
+		errors: []int{
+			http.StatusBadRequest, http.StatusNotFound, http.StatusConflict,
+			http.StatusRequestEntityTooLarge, http.StatusUnprocessableEntity, http.StatusTooManyRequests,
+		},
+	},
+	{
+		method: http.MethodGet, path: "/campaigns/{campaign_id}/events", id: "streamCampaignEvents", tag: "public",
+		summary: "Server-sent events with the signature count and new signatures",
+		params:  []string{"last_event_id", "last_event_id_query"},
+		status:  http.StatusOK, mediaType: "text/event-stream",
+		errors: []int{http.StatusNotFound, http.StatusTooManyRequests},
+	},
+	{method: http.MethodOptions, path: "/campaigns/{campaign_id}/events", id: "preflightCampaignEvents", tag: "public"},
+	{
+		method: http.MethodGet, path: "/campaigns/{campaign_id}/badge.svg", id: "getBadge", tag: "public",
+		summary: "SVG badge with the signature count",
+		params:  []string{"label"},
+		status:  http.StatusOK, mediaType: "image/svg+xml", etag: true,
+		errors: []int{http.StatusNotModified, http.StatusNotFound},
+	},
+	{
+		method: http.MethodGet, path: "/campaigns/{campaign_id}/card.png", id: "getCard", tag: "public",
+		summary: "Social card image with the campaign name and progress",
+		status:  http.StatusOK, mediaType: "image/png", etag: true,
+		errors: []int{http.StatusNotModified, http.StatusNotFound},
+	},
+
+	// admin campaign routes
+	{
+		method: http.MethodGet, path: "/admin/campaigns", id: "listCampaigns", tag: "admin", auth: true,
+		summary: "List campaigns",
+		params:  []string{"limit", "offset"},
+		status:  http.StatusOK, response: Campaigns{},
+		responseExample: `{"campaigns":[{"id":"cmp-1","name":"Save the park","letter":"","language":"en","goal":0,"allow_custom_text":false,"location_level":0,"theme":{"primary_color":"","background_color":"","logo_url":""},"success_url":"","error_url":"","created_at":1700000000,"revision":1,"locations_revision":1}],"total":1,"limit":100,"offset":0}`,
+		errors:          []int{http.StatusBadRequest},
+	},
+	{
+		method: http.MethodGet, path: "/admin/campaigns/trash", id: "listDeletedCampaigns", tag: "admin", auth: true,
+		summary: "List campaigns in the trash, most recently deleted first",
+		params:  []string{"limit", "offset"},
+		status:  http.StatusOK, response: Campaigns{},
+		responseExample: `{"campaigns":[{"id":"cmp-1","name":"Save the park","letter":"","language":"en","goal":0,"allow_custom_text":false,"location_level":0,"theme":{"primary_color":"","background_color":"","logo_url":""},"success_url":"","error_url":"","created_at":1700000000,"deleted_at":1700086400,"purge_at":1702678400,"revision":1,"locations_revision":1}],"total":1,"limit":100,"offset":0}`,
+		errors:          []int{http.StatusBadRequest},
+	},
+	{
+		method: http.MethodPost, path: "/admin/campaigns", id: "createCampaign", tag: "admin", auth: true,
+		summary: "Create a campaign",
+		params:  []string{"idempotency_key"},
+		request: CreateCampaignRequest{}, requestExample: `{"name":"Save the park"}`,
+		status: http.StatusCreated, response: Campaign{},
+		errors: []int{http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity},
+	},
+	{
+		method: http.MethodGet, path: "/admin/campaigns/{campaign_id}", id: "getCampaign", tag: "admin", auth: true,
+		summary: "Get a campaign with all translations",
+		status:  http.StatusOK, response: Campaign{}, etag: true,
+		errors: []int{http.StatusNotFound},
+	},
+	{
+		method: http.MethodPut, path: "/admin/campaigns/{campaign_id}", id: "updateCampaign", tag: "admin", auth: true,
+		summary: "Update a campaign; omitted fields keep their values",
+		params:  []string{"if_match"},
+		request: UpdateCampaignRequest{},
+		requestExample: `{"name":"Save the park","goal":500,"theme":{"primary_color":"#1f6feb","background_color":"#ffffff","logo_url":""},` +
+			`"translations":{"fr":{"name":"Sauvez le parc"}},"revision":3}`,
+		status: http.StatusOK, response: Campaign{}, etag: true,
+		errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusPreconditionFailed},
+	},
+	{
+		method: http.MethodDelete, path: "/admin/campaigns/{campaign_id}", id: "deleteCampaign", tag: "admin", auth: true,
+		summary: "Move a campaign and its signatures to the trash",
+		status:  http.StatusNoContent,
+		errors:  []int{http.StatusNotFound},
+	},
+	{
+		method: http.MethodPost, path: "/admin/campaigns/{campaign_id}/restore", id: "restoreCampaign", tag: "admin", auth: true,
+		summary: "Restore a campaign from the trash",
+		status:  http.StatusOK, response: Campaign{},
+		errors: []int{http.StatusNotFound},
+	},
+	{
+		method: http.MethodGet, path: "/admin/campaigns/{campaign_id}/locations", id: "getCampaignLocations", tag: "admin", auth: true,
+		summary: "Get a campaign's location tree",
+		status:  http.StatusOK, response: CampaignLocationsResponse{}, etag: true,
+		errors: []int{http.StatusNotFound},
+	},
+	{
+		method: http.MethodPut, path: "/admin/campaigns/{campaign_id}/locations", id: "replaceCampaignLocations", tag: "admin", auth: true,
+		summary: "Replace a campaign's location tree",
+		params:  []string{"if_match"},
+		request: CampaignLocationsRequest{},
+		requestExample: `{"locations":[{"value":"New York","display_order":0,"country_code":"US","aliases":["NYC"],` +
+			`"children":[{"value":"Brooklyn","display_order":0,"latitude":40.65,"longitude":-73.94}]}],"revision":2}`,
+		status: http.StatusOK, response: CampaignLocationsResponse{}, etag: true,
+		errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusPreconditionFailed},
+	},
+	{
+		method: http.MethodPost, path: "/admin/campaigns/{campaign_id}/locations", id: "createCampaignLocation", tag: "admin", auth: true,
+		summary:        "Add a location option",
+		request:        LocationOption{},
+		requestExample: `{"value":"Queens","parent":"New York","display_order":0,"labels":{"es":"Queens"}}`,
+		status:         http.StatusCreated, response: LocationOption{},
+		errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: http.MethodGet, path: "/admin/campaigns/{campaign_id}/locations/stats", id: "getCampaignLocationStats", tag: "admin", auth: true,
+		summary: "Signature counts rolled up the location tree",
+		status:  http.StatusOK, response: CampaignLocationStats{},
+		responseExample: `{"locations":[{"value":"New York","country_code":"US","count":3,"total":15,"children":[{"value":"Brooklyn","count":12,"total":12}]}],"other":1,"total":16}`,
+		errors:          []int{http.StatusNotFound},
+	},
+	{
+		method: http.MethodGet, path: "/admin/campaigns/{campaign_id}/locations/suggestions", id: "getLocationSuggestions", tag: "admin", auth: true,
+		summary: "Suggest presets for free-text signature locations",
+		status:  http.StatusOK, response: LocationSuggestionsResponse{},
+		responseExample: `{"suggestions":[{"value":"brooklin","count":2,"location_id":2,"suggestion":"Brooklyn","distance":1}]}`,
+		errors:          []int{http.StatusNotFound},
+	},
+	{
+		method: http.MethodPost, path: "/admin/campaigns/{campaign_id}/locations/normalize", id: "normalizeLocations", tag: "admin", auth: true,
+		summary: "Rewrite free-text signature locations to presets",
+		request: NormalizeLocationsRequest{}, requestExample: `{"mappings":[{"value":"brooklin","location_id":2}]}`,
+		status: http.StatusOK, response: NormalizeLocationsResponse{}, responseExample: `{"rewritten":2}`,
+		errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: http.MethodPost, path: "/admin/campaigns/{campaign_id}/locations/coordinates", id: "importLocationCoordinates", tag: "admin", auth: true,
+		summary: "Set coordinates by location value, from JSON or a gazetteer CSV",
+		request: ImportCoordinatesRequest{}, csvRequest: true,
+		requestExample: `{"coordinates":[{"value":"Brooklyn","latitude":40.65,"longitude":-73.94}]}`,
+		status:         http.StatusOK, response: ImportCoordinatesResponse{},
+		responseExample: `{"updated":1,"unmatched":["Atlantis"]}`,
+		errors:          []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: http.MethodGet, path: "/admin/campaigns/{campaign_id}/locations/{location_id}", id: "getCampaignLocation", tag: "admin", auth: true,
+		summary: "Get a location option",
+		status:  http.StatusOK, response: LocationOption{},
+		errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: http.MethodPatch, path: "/admin/campaigns/{campaign_id}/locations/{location_id}", id: "updateCampaignLocation", tag: "admin", auth: true,
+		summary:        "Update a location option; renaming rewrites its signatures",
+		request:        UpdateLocationRequest{},
+		requestExample: `{"value":"Brooklyn, NY","aliases":["BK"],"latitude":40.65,"longitude":-73.94}`,
+		status:         http.StatusOK, response: LocationOption{},
+		errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: http.MethodDelete, path: "/admin/campaigns/{campaign_id}/locations/{location_id}", id: "deleteCampaignLocation", tag: "admin", auth: true,
+		summary: "Delete a location option and its children",
+		status:  http.StatusNoContent,
+		errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: http.MethodPost, path: "/admin/campaigns/{campaign_id}/locations/{location_id}/move", id: "moveCampaignLocation", tag: "admin", auth: true,
+		summary: "Move a location under a new parent or position",
+		request: MoveLocationRequest{}, requestExample: `{"parent_id":1,"position":2}`,
+		status: http.StatusOK, response: LocationOption{},
+		errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: http.MethodPost, path: "/admin/campaigns/{campaign_id}/locations/{location_id}/merge", id: "mergeCampaignLocation", tag: "admin", auth: true,
+		summary: "Merge a location into another, rewriting its signatures",
+		request: MergeLocationRequest{}, requestExample: `{"into_id":2}`,
+		status: http.StatusOK, response: MergeLocationResponse{},
+		responseExample: `{"location":{"id":2,"value":"Brooklyn","display_order":0,"aliases":["Brooklin"]},"rewritten":4}`,
+		errors:          []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: http.MethodGet, path: "/admin/campaigns/{campaign_id}/signatures", id: "listSignatures", tag: "admin", auth: true,
+		summary: "List a campaign's signatures, newest first, optionally filtered",
+		params:  []string{"limit", "offset", "signature_query", "signature_location", "signature_visibility", "signature_ids"},
+		status:  http.StatusOK, response: Signatures{},
+		errors: []int{http.StatusBadRequest},
+	},
+	{
+		method: http.MethodPost, path: "/admin/campaigns/{campaign_id}/signatures/import", id: "importSignatures", tag: "admin", auth: true,
+		summary:        "Add up to 500 signatures, each checked on its own; a dry run stores nothing and answers 200",
+		params:         []string{"idempotency_key"},
+		request:        ImportSignaturesRequest{},
+		requestExample: `{"signatures":[{"name":"Ada","email":"ada@example.org","location":"brooklyn"},{"name":"Bob","email":"bob","location":"Queens"}],"dry_run":true}`,
+		status:         http.StatusCreated, response: ImportSignaturesResponse{},
+		responseExample: `{"results":[{"index":0,"location":"Brooklyn"},{"index":1,"error":{"code":"invalid_email","message":"invalid email address"}}],"accepted":1,"rejected":1}`,
+		errors:          []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity},
+	},
+	{
+		method: http.MethodGet, path: "/admin/campaigns/{campaign_id}/signatures/stats", id: "getSignatureStats", tag: "admin", auth: true,
+		summary: "Signature totals, daily counts in UTC and top locations",
+		params:  []string{"stats_days"},
+		status:  http.StatusOK, response: SignatureStats{},
+		responseExample: `{"total":120,"last_24h":4,"last_7d":31,"days":[{"date":"2026-10-17","count":6},{"date":"2026-10-18","count":2}],"top_locations":[{"location":"Brooklyn","count":64},{"location":"Queens","count":40}]}`,
+		errors:          []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: http.MethodPost, path: "/admin/campaigns/{campaign_id}/signatures/bulk", id: "bulkSignatures", tag: "admin", auth: true,
+		summary:        "Delete, hide or unhide listed or matching signatures in one transaction, undoable for a while",
+		request:        BulkSignaturesRequest{},
+		requestExample: `{"action":"hide","filter":{"query":"example.org","visibility":"visible"}}`,
+		status:         http.StatusOK, response: BulkSignaturesResponse{},
+		responseExample: `{"operation_id":"3f9a2c1d7e4b8a60","action":"hide","affected":12,"undo_until":1700000600}`,
+		errors:          []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: http.MethodGet, path: "/admin/campaigns/{campaign_id}/signatures/bulk/{operation_id}", id: "getSignatureBulkOperation", tag: "admin", auth: true,
+		summary: "Get a bulk action that has not been undone, to see what undoing it would put back",
+		status:  http.StatusOK, response: SignatureBulkOperation{},
+		responseExample: `{"id":"3f9a2c1d7e4b8a60","campaign_id":"parks","action":"hide","created_at":1700000000,"expires_at":1700000600}`,
+		errors:          []int{http.StatusNotFound},
+	},
+	{
+		method: http.MethodPost, path: "/admin/campaigns/{campaign_id}/signatures/bulk/{operation_id}/undo", id: "undoBulkSignatures", tag: "admin", auth: true,
+		summary: "Undo a bulk action before its undo window closes",
+		status:  http.StatusOK, response: BulkUndoResponse{},
+		responseExample: `{"operation_id":"3f9a2c1d7e4b8a60","action":"hide","restored":12}`,
+		errors:          []int{http.StatusNotFound, http.StatusConflict},
+	},
+	{
+		method: http.MethodGet, path: "/admin/campaigns/{campaign_id}/signatures/trash", id: "listDeletedSignatures", tag: "admin", auth: true,
+		summary: "List a campaign's signatures in the trash, most recently deleted first",
+		params:  []string{"limit", "offset"},
+		status:  http.StatusOK, response: Signatures{},
+		responseExample: `{"signatures":[{"id":7,"name":"Ada","email":"ada@example.org","location":"Brooklyn","created_at":1700000000,"deleted_at":1700086400,"purge_at":1702678400}],"total":1,"limit":100,"offset":0}`,
+		errors:          []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: http.MethodDelete, path: "/admin/campaigns/{campaign_id}/signatures/{signature_id}", id: "deleteSignature", tag: "admin", auth: true,
+		summary: "Move a signature to the trash",
+		status:  http.StatusNoContent,
+		errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: http.MethodPost, path: "/admin/campaigns/{campaign_id}/signatures/{signature_id}/restore", id: "restoreSignature", tag: "admin", auth: true,
+		summary: "Restore a signature from the trash unless its email has signed again",
+		status:  http.StatusOK, response: Signature{},
+		errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+	},
+
+	// webhooks
+	{
+		method: http.MethodGet, path: "/admin/webhooks", id: "listWebhooks", tag: "webhooks", auth: true,
+		summary: "List webhooks",
+		params:  []string{"webhook_campaign_id"},
+		status:  http.StatusOK, response: Webhooks{},
+		responseExample: `{"webhooks":[{"id":"whk-1","campaign_id":"cmp-1","url":"https://example.org/hook","events":["signature.created"],"created_at":1700000000}]}`,
+	},
+	{
+		method: http.MethodPost, path: "/admin/webhooks", id: "createWebhook", tag: "webhooks", auth: true,
+		summary:        "Create a webhook; the secret is only returned here",
+		request:        CreateWebhookRequest{},
+		requestExample: `{"campaign_id":"cmp-1","url":"https://example.org/hook","events":["signature.created","milestone.reached"]}`,
+		status:         http.StatusCreated, response: Webhook{},
+		responseExample: `{"id":"whk-1","campaign_id":"cmp-1","url":"https://example.org/hook","secret":"whsec-abc","events":["signature.created","milestone.reached"],"created_at":1700000000}`,
+		errors:          []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: http.MethodGet, path: "/admin/webhooks/{webhook_id}", id: "getWebhook", tag: "webhooks", auth: true,
+		summary: "Get a webhook",
+		status:  http.StatusOK, response: Webhook{},
+		errors: []int{http.StatusNotFound},
+	},
+	{
+		method: http.MethodDelete, path: "/admin/webhooks/{webhook_id}", id: "deleteWebhook", tag: "webhooks", auth: true,
+		summary: "Delete a webhook",
+		status:  http.StatusNoContent,
+		errors:  []int{http.StatusNotFound},
+	},
+	{
+		method: http.MethodGet, path: "/admin/webhooks/{webhook_id}/deliveries", id: "listWebhookDeliveries", tag: "webhooks", auth: true,
+		summary: "List a webhook's deliveries",
+		params:  []string{"limit", "offset", "delivery_status"},
+		status:  http.StatusOK, response: WebhookDeliveries{},
+		responseExample: `{"deliveries":[{"id":3,"webhook_id":"whk-1","event":"signature.created","payload":{"event":"signature.created","campaign_id":"cmp-1","created_at":1700000000,"data":{}},` +
+			`"status":"dead","attempts":8,"next_attempt_at":0,"last_status_code":500,"last_error":"server returned 500","created_at":1700000000,"updated_at":1700003600}],"total":1,"limit":100,"offset":0}`,
+		errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+	{
+		method: http.MethodPost, path: "/admin/webhooks/{webhook_id}/deliveries/{delivery_id}/replay", id: "replayWebhookDelivery", tag: "webhooks", auth: true,
+		summary: "Queue a delivery to be sent again",
+		status:  http.StatusAccepted, response: WebhookDelivery{},
+		errors: []int{http.StatusBadRequest, http.StatusNotFound},
+	},
+
+	// settings, served by the keys and cors packages apart from the key list
+	{
+		method: http.MethodGet, path: "/settings/keys", id: "listAPIKeys", tag: "settings", auth: true,
+		summary: "List API keys by id; their secrets are never returned",
+		status:  http.StatusOK, response: APIKeys{},
+		responseExample: `{"keys":[{"id":"3f2a9c0d1e4b5a6c","created_at":1700000000,"last_used_at":1700003600}]}`,
+	},
+	{
+		method: http.MethodPost, path: "/settings/keys", id: "createAPIKey", tag: "settings", auth: true, plainErrors: true,
+		summary: "Create an API key; the token is only returned here",
+		status:  http.StatusCreated, response: "",
+	},
+	{
+		method: http.MethodDelete, path: "/settings/keys/{id}", id: "deleteAPIKey", tag: "settings", auth: true, plainErrors: true,
+		summary: "Revoke an API key",
+		status:  http.StatusNoContent,
+	},
+	{
+		method: http.MethodGet, path: "/settings/cors", id: "getCORSOrigins", tag: "settings", auth: true, plainErrors: true,
+		summary: "List origins allowed to call the public routes from a browser",
+		status:  http.StatusOK, response: []cors.AllowedOrigin{},
+		responseExample: `[{"url":"https://example.org"}]`,
+	},
+	{
+		method: http.MethodPut, path: "/settings/cors", id: "setCORSOrigins", tag: "settings", auth: true, plainErrors: true,
+		summary: "Replace the allowed origins",
+		request: []cors.AllowedOrigin{}, requestExample: `[{"url":"https://example.org"}]`,
+		status: http.StatusNoContent,
+		errors: []int{http.StatusBadRequest},
+	},
+}
+
+func (s *Service) buildOpenAPIRouter(mux *routeMux) {
+	mux.HandleFunc("GET /openapi.json", s.handleOpenAPI)
+}
+
+func (s *Service) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	// RequestURI keeps the API prefix that StripPrefix removed from the path
+	serverURL := strings.TrimSuffix(strings.SplitN(r.RequestURI, "?", 2)[0], "/openapi.json")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(openAPIDocument(serverURL))
+}
+
+// openAPIDocument builds the OpenAPI 3.1 document for apiOperations.
+func openAPIDocument(serverURL string) map[string]any {
+	schemas := newSchemaBuilder()
+	for _, op := range apiOperations {
+		if op.request != nil {
+			schemas.markRequest(reflect.TypeOf(op.request))
+		}
+	}
+
+	paths := map[string]map[string]any{}
+	for _, op := range apiOperations {
+		if paths[op.path] == nil {
+			paths[op.path] = map[string]any{}
+		}
+		paths[op.path][strings.ToLower(op.method)] = op.document(schemas)
+	}
+
+	schemas.components["ErrorResponse"] = schemas.structSchema(reflect.TypeOf(errorResponse{}))
+	schemas.components["PlainErrorResponse"] = map[string]any{
+		"type":     "object",
+		"required": []string{"error"},
+		"properties": map[string]any{
+			"error": map[string]any{
+				"type":       "object",
+				"required":   []string{"message"},
+				"properties": map[string]any{"message": map[string]any{"type": "string"}},
+			},
+		},
+	}
+
+	document := map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "Cosign API",
+			"version":     "v1",
+			"description": "Successful responses wrap their payload as {\"data\": ...}; errors as {\"error\": {\"code\", \"message\", \"fields\"}}.",
+		},
+		"tags": []map[string]string{
+			{"name": "meta"},
+			{"name": "public", "description": "Unauthenticated routes for campaign pages and embeds"},
+			{"name": "admin", "description": "Campaign, location, and signature management"},
+			{"name": "webhooks"},
+			{"name": "settings", "description": "API keys and CORS allowlist"},
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas":    schemas.components,
+			"parameters": apiParameters,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+	if serverURL != "" {
+		document["servers"] = []map[string]string{{"url": serverURL}}
+	}
+	return document
+}
+
+func (op apiOperation) document(schemas *schemaBuilder) map[string]any {
+	operation := map[string]any{
+		"operationId": op.id,
+		"tags":        []string{op.tag},
+	}
+	if op.summary != "" {
+		operation["summary"] = op.summary
+	}
+	if op.auth {
+		operation["security"] = []map[string][]string{{"bearerAuth": {}}}
+	}
+
+	var parameters []map[string]any
+	for _, name := range pathParams(op.path) {
+		schema := map[string]any{"type": "string"}
+		if slices.Contains(integerPathParams, name) {
+			schema = map[string]any{"type": "integer", "format": "int64"}
+		}
+		parameters = append(parameters, map[string]any{
+			"name": name, "in": "path", "required": true, "schema": schema,
+		})
+	}
+	for _, name := range op.params {
+		parameters = append(parameters, map[string]any{"$ref": "#/components/parameters/" + name})
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	if op.request != nil {
+		schema := schemas.schemaFor(reflect.TypeOf(op.request))
+		content := map[string]any{
+			"application/json": mediaTypeObject(schema, op.requestExample),
+		}
+		if op.formRequest {
+			content["application/x-www-form-urlencoded"] = map[string]any{"schema": schema}
+		}
+		if op.csvRequest {
+			content["text/csv"] = map[string]any{
+				"schema": map[string]any{"type": "string", "description": "gazetteer CSV with value, latitude, and longitude columns"},
+			}
+		}
+		operation["requestBody"] = map[string]any{"required": true, "content": content}
+	}
+
+	responses := map[string]any{}
+	if op.method == http.MethodOptions {
+		responses["204"] = map[string]any{"description": "CORS preflight for an allowed origin"}
+		responses["403"] = map[string]any{"description": "origin not allowed"}
+		operation["summary"] = "CORS preflight"
+		operation["responses"] = responses
+		return operation
+	}
+
+	success := map[string]any{"description": http.StatusText(op.status)}
+	switch {
+	case op.response != nil && op.mediaType == "":
+		data := map[string]any{
+			"type":       "object",
+			"required":   []string{"data"},
+			"properties": map[string]any{"data": schemas.schemaFor(reflect.TypeOf(op.response))},
+		}
+		example := ""
+		if op.responseExample != "" {
+			example = `{"data":` + op.responseExample + `}`
+		}
+		success["content"] = map[string]any{"application/json": mediaTypeObject(data, example)}
+	case op.response != nil:
+		success["content"] = map[string]any{
+			op.mediaType: mediaTypeObject(schemas.schemaFor(reflect.TypeOf(op.response)), op.responseExample),
+		}
+	case op.mediaType != "":
+		success["content"] = map[string]any{op.mediaType: map[string]any{}}
+	}
+	if op.etag {
+		success["headers"] = map[string]any{
+			"ETag": map[string]any{"schema": map[string]any{"type": "string"}},
+		}
+	}
+	responses[fmt.Sprint(op.status)] = success
+
+	errorSchema := "#/components/schemas/ErrorResponse"
+	if op.plainErrors {
+		errorSchema = "#/components/schemas/PlainErrorResponse"
+	}
+	errorContent := map[string]any{
+		"application/json": map[string]any{"schema": map[string]any{"$ref": errorSchema}},
+	}
+	for _, status := range op.errors {
+		response := map[string]any{"description": http.StatusText(status)}
+		if status != http.StatusNotModified {
+			response["content"] = errorContent
+		}
+		responses[fmt.Sprint(status)] = response
+	}
+	if op.auth {
+		// the auth middleware's errors carry no code
+		responses["401"] = map[string]any{
+			"description": http.StatusText(http.StatusUnauthorized),
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": map[string]any{"$ref": "#/components/schemas/PlainErrorResponse"}},
+			},
+		}
+	}
+	responses["default"] = map[string]any{"description": "unexpected error", "content": errorContent}
+	operation["responses"] = responses
+
+	return operation
+}
+
+func mediaTypeObject(schema map[string]any, example string) map[string]any {
+	object := map[string]any{"schema": schema}
+	if example != "" {
+		object["example"] = json.RawMessage(example)
+	}
+	return object
+}
+
+func pathParams(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			names = append(names, strings.Trim(segment, "{}"))
+		}
+	}
+	return names
+}
+
+// schemaBuilder turns Go types into JSON schemas, collecting named structs
+// as components. Fields of request types are all optional, since handlers
+// treat a missing field as unchanged or zero; response fields without
+// omitempty are always present.
+type schemaBuilder struct {
+	components map[string]any
+	requests   map[reflect.Type]bool
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		components: map[string]any{},
+		requests:   map[reflect.Type]bool{},
+	}
+}
+
+func (b *schemaBuilder) markRequest(t reflect.Type) {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		b.markRequest(t.Elem())
+	case reflect.Struct:
+		if b.requests[t] {
+			return
+		}
+		b.requests[t] = true
+		for _, field := range reflect.VisibleFields(t) {
+			if field.IsExported() {
+				b.markRequest(field.Type)
+			}
+		}
+	}
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+func (b *schemaBuilder) schemaFor(t reflect.Type) map[string]any {
+	if t == rawMessageType {
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.schemaFor(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": b.schemaFor(t.Elem())}
+	case reflect.Array:
+		return map[string]any{
+			"type": "array", "items": b.schemaFor(t.Elem()),
+			"minItems": t.Len(), "maxItems": t.Len(),
+		}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schemaFor(t.Elem())}
+	case reflect.Struct:
+		name := t.Name()
+		if name == "" {
+			return b.structSchema(t)
+		}
+		if _, ok := b.components[name]; !ok {
+			// reserve the name first so recursive types terminate
+			b.components[name] = map[string]any{}
+			b.components[name] = b.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	default:
+		return map[string]any{}
+	}
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		omitEmpty := slices.Contains(strings.Split(options, ","), "omitempty")
+
+		schema := b.schemaFor(field.Type)
+		if field.Type.Kind() == reflect.Pointer && !omitEmpty {
+			schema = nullable(schema)
+		}
+		properties[name] = schema
+		if !omitEmpty && !b.requests[t] {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func nullable(schema map[string]any) map[string]any {
+	if kind, ok := schema["type"].(string); ok {
+		copied := map[string]any{}
+		for key, value := range schema {
+			copied[key] = value
+		}
+		copied["type"] = []string{kind, "null"}
+		return copied
+	}
+	return map[string]any{"oneOf": []any{schema, map[string]any{"type": "null"}}}
+}
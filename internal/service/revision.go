@@ -0,0 +1,48 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxUpdateAttempts bounds how often an unconditional partial update is
+// re-applied to a freshly loaded campaign after losing a race.
+const maxUpdateAttempts = 3
+
+func revisionETag(revision int64) string {
+	return `"` + strconv.FormatInt(revision, 10) + `"`
+}
+
+// checkRevision reports whether a write may proceed against the current
+// revision, and whether the client asked for a conditional write at all.
+// If-Match wins over a revision in the body; the body field exists for
+// clients that cannot set headers. Weak tags never match, as If-Match uses
+// strong comparison.
+func checkRevision(
+	r *http.Request,
+	bodyRevision *int64,
+	current int64,
+) (
+	conditional bool,
+	ok bool,
+) {
+	if header := strings.TrimSpace(r.Header.Get("If-Match")); header != "" {
+		if header == "*" {
+			return false, true
+		}
+		want := revisionETag(current)
+		for tag := range strings.SplitSeq(header, ",") {
+			if strings.TrimSpace(tag) == want {
+				return true, true
+			}
+		}
+		return true, false
+	}
+
+	if bodyRevision != nil {
+		return true, *bodyRevision == current
+	}
+
+	return false, true
+}
@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"net/http"
+	"testing"
+
+	"cosign/internal/service"
+	"cosign/internal/testutil"
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+func ifMatchHeader(tag string) wire.TestHeader {
+	return wire.TestHeader{Key: "If-Match", Value: tag}
+}
+
+func TestCampaignUpdateHonorsIfMatch(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Revisions")
+	path := "/admin/campaigns/" + campaign.ID
+
+	loaded := wire.TestGet[service.Campaign](handler, path, authHeader())
+	loaded.ExpectStatus(t, http.StatusOK)
+	etag := loaded.Headers.Get("ETag")
+	if etag != `"1"` {
+		t.Fatalf("expected initial etag \"1\", got %q", etag)
+	}
+
+	first := wire.TestPut[service.Campaign](handler, path, `{"name":"First"}`, authHeader(), ifMatchHeader(etag))
+	first.ExpectStatus(t, http.StatusOK)
+	if first.Data.Revision != 2 || first.Headers.Get("ETag") != `"2"` {
+		t.Fatalf("expected revision 2 after update, got %d (%q)", first.Data.Revision, first.Headers.Get("ETag"))
+	}
+
+	stale := wire.TestPut[service.Campaign](handler, path, `{"name":"Second"}`, authHeader(), ifMatchHeader(etag))
+	stale.ExpectStatus(t, http.StatusPreconditionFailed)
+
+	weak := wire.TestPut[service.Campaign](handler, path, `{"name":"Second"}`, authHeader(), ifMatchHeader(`W/"2"`))
+	weak.ExpectStatus(t, http.StatusPreconditionFailed)
+
+	staleBody := wire.TestPut[service.Campaign](handler, path, `{"name":"Second","revision":1}`, authHeader())
+	staleBody.ExpectStatus(t, http.StatusPreconditionFailed)
+
+	current := wire.TestGet[service.Campaign](handler, path, authHeader())
+	if current.Data.Name != "First" || current.Data.Revision != 2 {
+		t.Fatalf("expected rejected writes to leave the campaign alone, got %+v", current.Data)
+	}
+
+	unconditional := wire.TestPut[service.Campaign](handler, path, `{"name":"Third"}`, authHeader())
+	unconditional.ExpectStatus(t, http.StatusOK)
+	if unconditional.Data.Revision != 3 {
+		t.Fatalf("expected unconditional update to advance revision, got %d", unconditional.Data.Revision)
+	}
+}
+
+func TestLocationSetRevisionGuardsReplace(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Revisions")
+	path := "/admin/campaigns/" + campaign.ID + "/locations"
+
+	loaded := wire.TestGet[service.CampaignLocationsResponse](handler, path, authHeader())
+	loaded.ExpectStatus(t, http.StatusOK)
+	etag := loaded.Headers.Get("ETag")
+	if etag != `"1"` || loaded.Data.Revision != 1 {
+		t.Fatalf("expected location set revision 1, got %q / %d", etag, loaded.Data.Revision)
+	}
+
+	replaced := wire.TestPut[service.CampaignLocationsResponse](
+		handler, path, `{"locations":[{"value":"North"}]}`, authHeader(), ifMatchHeader(etag),
+	)
+	replaced.ExpectStatus(t, http.StatusOK)
+	if replaced.Headers.Get("ETag") != `"2"` {
+		t.Fatalf("expected etag \"2\" after replace, got %q", replaced.Headers.Get("ETag"))
+	}
+
+	// a granular write also advances the set revision
+	created := wire.TestPost[service.LocationOption](handler, path, `{"value":"South"}`, authHeader())
+	created.ExpectStatus(t, http.StatusCreated)
+
+	stale := wire.TestPut[service.CampaignLocationsResponse](
+		handler, path, `{"locations":[{"value":"East"}],"revision":2}`, authHeader(),
+	)
+	stale.ExpectStatus(t, http.StatusPreconditionFailed)
+
+	current := wire.TestGet[service.CampaignLocationsResponse](handler, path, authHeader())
+	if len(current.Data.Locations) != 2 || current.Data.Revision != 3 {
+		t.Fatalf("expected north and south at revision 3, got %+v", current.Data)
+	}
+
+	// editing campaign fields leaves the location set revision alone
+	wire.TestPut[service.Campaign](handler, "/admin/campaigns/"+campaign.ID, `{"goal":10}`, authHeader()).
+		ExpectStatus(t, http.StatusOK)
+
+	fresh := wire.TestPut[service.CampaignLocationsResponse](
+		handler, path, `{"locations":[{"value":"East"}]}`, authHeader(), ifMatchHeader(`"3"`),
+	)
+	fresh.ExpectStatus(t, http.StatusOK)
+}
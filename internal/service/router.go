@@ -1,58 +1,108 @@
 package service
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+)
 
 type Middleware struct {
-	auth      func(http.HandlerFunc) http.HandlerFunc
-	cors      func(http.HandlerFunc) http.HandlerFunc
-	rateLimit func(http.HandlerFunc) http.HandlerFunc
+	auth       func(http.HandlerFunc) http.HandlerFunc
+	cors       func(http.HandlerFunc) http.HandlerFunc
+	rateLimit  func(http.HandlerFunc) http.HandlerFunc
+	idempotent func(http.HandlerFunc) http.HandlerFunc
+}
+
+// Route is a method and full path pattern served by BuildRouter, relative
+// to the API prefix.
+type Route struct {
+	Method string
+	Path   string
+}
+
+// routeMux is a ServeMux that records the full pattern of each route
+// registered on it or on the subrouters mounted beneath it.
+type routeMux struct {
+	*http.ServeMux
+	prefix string
+	routes *[]Route
+}
+
+func newRouteMux() *routeMux {
+	return &routeMux{ServeMux: http.NewServeMux(), routes: &[]Route{}}
+}
+
+func (m *routeMux) subrouter(prefix string) *routeMux {
+	return &routeMux{ServeMux: http.NewServeMux(), prefix: m.prefix + prefix, routes: m.routes}
+}
+
+func (m *routeMux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	method, path, _ := strings.Cut(pattern, " ")
+	path = strings.TrimSuffix(m.prefix+path, "/{$}")
+	*m.routes = append(*m.routes, Route{Method: method, Path: path})
+	m.ServeMux.HandleFunc(pattern, handler)
 }
 
 func (s *Service) BuildRouter() http.Handler {
-	mux := http.NewServeMux()
+	mux, _ := s.buildRouter()
+	return mux
+}
+
+// Routes lists the routes BuildRouter registers, except the settings
+// routes owned by the keys and cors packages.
+func (s *Service) Routes() []Route {
+	_, routes := s.buildRouter()
+	return routes
+}
+
+func (s *Service) buildRouter() (http.Handler, []Route) {
+	mux := newRouteMux()
 	mw := Middleware{
-		auth:      s.keys.WithAuth,
-		cors:      s.cors.WithCORS,
-		rateLimit: s.withRateLimit,
+		auth:       s.withAuth,
+		cors:       s.cors.WithCORS,
+		rateLimit:  s.WithRateLimit,
+		idempotent: s.WithIdempotency,
 	}
 
 	s.buildHealthRouter(mux)
+	s.buildOpenAPIRouter(mux)
 	s.buildPublicRouter(mux, mw)
 	s.buildAdminRouter(mux, mw)
 	s.buildSettingsRouter(mux, mw)
 
-	return mux
+	return mux, *mux.routes
 }
 
-func (s *Service) buildHealthRouter(mux *http.ServeMux) {
+func (s *Service) buildHealthRouter(mux *routeMux) {
 	mux.HandleFunc("GET /health", s.handleHealth)
 }
 
-func (s *Service) buildPublicRouter(mux *http.ServeMux, mw Middleware) {
-	campaignsMux := http.NewServeMux()
+func (s *Service) buildPublicRouter(mux *routeMux, mw Middleware) {
+	campaignsMux := mux.subrouter("/campaigns")
 	s.buildPublicCampaignRouter(campaignsMux, mw)
 	s.buildPublicSignatureRouter(campaignsMux, mw)
+	s.buildPublicBadgeRouter(campaignsMux, mw)
 
 	mountSubrouter(mux, "/campaigns", campaignsMux)
 }
 
-func (s *Service) buildAdminRouter(mux *http.ServeMux, mw Middleware) {
-	adminMux := http.NewServeMux()
+func (s *Service) buildAdminRouter(mux *routeMux, mw Middleware) {
+	adminMux := mux.subrouter("/admin")
 	s.buildAdminCampaignsRouter(adminMux, mw)
+	s.buildAdminWebhooksRouter(adminMux, mw)
 	securedAdmin := http.HandlerFunc(mw.auth(adminMux.ServeHTTP))
 
 	mountSubrouter(mux, "/admin", securedAdmin)
 }
 
-func (s *Service) buildAdminCampaignsRouter(mux *http.ServeMux, mw Middleware) {
-	campaignsMux := http.NewServeMux()
+func (s *Service) buildAdminCampaignsRouter(mux *routeMux, mw Middleware) {
+	campaignsMux := mux.subrouter("/campaigns")
 	s.buildAdminCampaignRouter(campaignsMux, mw)
 	s.buildAdminSignatureRouter(campaignsMux, mw)
 
 	mountSubrouter(mux, "/campaigns", campaignsMux)
 }
 
-func mountSubrouter(parent *http.ServeMux, prefix string, child http.Handler) {
+func mountSubrouter(parent *routeMux, prefix string, child http.Handler) {
 	stripped := http.StripPrefix(prefix, child)
 	parent.Handle(prefix+"/", stripped)
 	parent.Handle(prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
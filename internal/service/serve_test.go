@@ -0,0 +1,51 @@
+package service_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"cosign/internal/httpserver"
+	"cosign/internal/service"
+	"cosign/internal/testutil"
+)
+
+func TestServeEndsEventStreamsOnShutdown(t *testing.T) {
+	svc := testutil.SetupService(t)
+	campaign := createCampaign(t, svc.BuildRouter(), "Live")
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- svc.Serve(ctx, ln, "/api/v1", httpserver.Options{ShutdownTimeout: 10 * time.Second})
+	}()
+
+	res, events := openEventStream(t, context.Background(), "http://"+ln.Addr().String()+"/api/v1", campaign.ID, "")
+	defer res.Body.Close()
+	nextEvent(t, events, service.StreamEventCount)
+
+	started := time.Now()
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected open streams not to hold the drain until its deadline")
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Fatalf("expected prompt shutdown, took %s", elapsed)
+	}
+
+	for range events {
+	}
+}
@@ -1,6 +1,9 @@
 package service
 
 import (
+	"context"
+	"cosign/internal/httpserver"
+	"cosign/pkg/cosignapi"
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
@@ -18,16 +21,48 @@ import (
 	"golang.org/x/time/rate"
 )
 
+// The API's wire types, codes and errors are defined in cosignapi, which
+// the Go client shares; the service uses them under these names.
 var (
-	ErrCampaignNotFound     = errors.New("campaign not found")
-	ErrSignatureNotFound    = errors.New("signature not found")
-	ErrInvalidEmail         = errors.New("invalid email address")
-	ErrDuplicateEmail       = errors.New("email already signed")
-	ErrLocationNotInOptions = errors.New("location must be from preset options")
-	ErrEmptyName            = errors.New("name cannot be empty")
-	ErrEmptyEmail           = errors.New("email cannot be empty")
-	ErrEmptyLocation        = errors.New("location cannot be empty")
-	ErrEmptyCampaignName    = errors.New("campaign name cannot be empty")
+	ErrCampaignNotFound     = cosignapi.ErrCampaignNotFound
+	ErrSignatureNotFound    = cosignapi.ErrSignatureNotFound
+	ErrInvalidEmail         = cosignapi.ErrInvalidEmail
+	ErrDuplicateEmail       = cosignapi.ErrDuplicateEmail
+	ErrLocationNotInOptions = cosignapi.ErrLocationNotInOptions
+	ErrEmptyName            = cosignapi.ErrEmptyName
+	ErrEmptyEmail           = cosignapi.ErrEmptyEmail
+	ErrEmptyLocation        = cosignapi.ErrEmptyLocation
+	ErrEmptyCampaignName    = cosignapi.ErrEmptyCampaignName
+	ErrInvalidThemeColor    = cosignapi.ErrInvalidThemeColor
+	ErrInvalidThemeLogoURL  = cosignapi.ErrInvalidThemeLogoURL
+	ErrInvalidRedirectURL   = cosignapi.ErrInvalidRedirectURL
+	ErrRedirectNotAllowed   = cosignapi.ErrRedirectNotAllowed
+	ErrInvalidGoal          = cosignapi.ErrInvalidGoal
+	ErrInvalidLanguage      = cosignapi.ErrInvalidLanguage
+
+	ErrLocationNotFound       = cosignapi.ErrLocationNotFound
+	ErrDuplicateLocation      = cosignapi.ErrDuplicateLocation
+	ErrDuplicateLocationAlias = cosignapi.ErrDuplicateLocationAlias
+	ErrUnknownLocationParent  = cosignapi.ErrUnknownLocationParent
+	ErrLocationCycle          = cosignapi.ErrLocationCycle
+	ErrInvalidCountryCode     = cosignapi.ErrInvalidCountryCode
+	ErrInvalidSubdivisionCode = cosignapi.ErrInvalidSubdivisionCode
+	ErrInvalidLocationLevel   = cosignapi.ErrInvalidLocationLevel
+	ErrInvalidCoordinates     = cosignapi.ErrInvalidCoordinates
+	ErrInvalidGazetteer       = cosignapi.ErrInvalidGazetteer
+	ErrMergeIntoSelf          = cosignapi.ErrMergeIntoSelf
+
+	ErrRevisionConflict = cosignapi.ErrRevisionConflict
+
+	ErrWebhookNotFound         = cosignapi.ErrWebhookNotFound
+	ErrWebhookDeliveryNotFound = cosignapi.ErrWebhookDeliveryNotFound
+	ErrInvalidWebhookURL       = cosignapi.ErrInvalidWebhookURL
+	ErrInvalidWebhookEvent     = cosignapi.ErrInvalidWebhookEvent
+	ErrEmptyWebhookEvents      = cosignapi.ErrEmptyWebhookEvents
+
+	ErrTooManyStreams = cosignapi.ErrTooManyStreams
+
+	ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
 )
 
 type DatabaseError struct{ Err error }
@@ -35,77 +70,113 @@ type DatabaseError struct{ Err error }
 func (e DatabaseError) Error() string { return fmt.Sprintf("database error: %v", e.Err) }
 func (e DatabaseError) Unwrap() error { return e.Err }
 
-type Campaign struct {
-	ID              string `json:"id"`
-	Name            string `json:"name"`
-	AllowCustomText bool   `json:"allow_custom_text"`
-	CreatedAt       int64  `json:"created_at"`
-}
-
-type LocationOption struct {
-	ID           int64  `json:"id,omitempty"`
-	Value        string `json:"value"`
-	DisplayOrder int    `json:"display_order"`
-}
-
-type Campaigns struct {
-	Campaigns []*Campaign `json:"campaigns"`
-	Total     int         `json:"total"`
-	Limit     int         `json:"limit"`
-	Offset    int         `json:"offset"`
-}
-
-type Signature struct {
-	ID        int64  `json:"id"`
-	Name      string `json:"name"`
-	Email     string `json:"email"`
-	Location  string `json:"location"`
-	CreatedAt int64  `json:"created_at"`
-}
-
-type Signatures struct {
-	Signatures []*Signature `json:"signatures"`
-	Total      int          `json:"total"`
-	Limit      int          `json:"limit"`
-	Offset     int          `json:"offset"`
-}
-
-type HealthResponse struct {
-	Status string `json:"status"`
-}
+type (
+	Campaign            = cosignapi.Campaign
+	CampaignTranslation = cosignapi.CampaignTranslation
+	CampaignTheme       = cosignapi.CampaignTheme
+	LocationOption      = cosignapi.LocationOption
+	Campaigns           = cosignapi.Campaigns
+	Signature           = cosignapi.Signature
+	Signatures          = cosignapi.Signatures
+	HealthResponse      = cosignapi.HealthResponse
+)
 
 type Store interface {
 	InsertCampaign(id, name string, allowCustomText bool, createdAt int64) error
 	GetCampaign(id string) (*Campaign, error)
 	ListCampaigns(limit, offset int) ([]*Campaign, error)
 	CountCampaigns() (int, error)
-	UpdateCampaign(id, name string, allowCustomText bool) error
-	DeleteCampaign(id string) error
+	UpdateCampaign(campaign Campaign) error
+	DeleteCampaign(id string, deletedAt int64) error
+	RestoreCampaign(id string) error
+	ListDeletedCampaigns(limit, offset int) ([]*Campaign, error)
+	CountDeletedCampaigns() (int, error)
 	GetCampaignLocations(campaignID string) ([]*LocationOption, error)
-	ReplaceCampaignLocations(campaignID string, options []LocationOption) error
-
-	InsertSignature(campaignID, name, email, location string, createdAt int64) (int64, error)
+	GetCampaignLocation(campaignID string, id int64) (*LocationOption, error)
+	ReplaceCampaignLocations(campaignID string, options []LocationOption, revision int64) error
+	InsertCampaignLocation(campaignID string, option LocationOption) (int64, error)
+	UpdateCampaignLocation(campaignID string, option LocationOption) error
+	DeleteCampaignLocation(campaignID string, id int64) error
+	MoveCampaignLocation(campaignID string, id, parentID int64, siblingIDs []int64) error
+	MergeCampaignLocation(campaignID string, sourceID, targetID int64) (int, error)
+	SetLocationCoordinates(campaignID string, options []LocationOption) error
+	RemapSignatureLocations(campaignID string, mappings map[string]string) (int, error)
+
+	InsertSignature(campaignID, name, email, location, locationRaw string, createdAt int64) (int64, error)
 	GetSignature(campaignID string, id int64) (*Signature, error)
-	ListSignatures(campaignID string, limit, offset int) ([]*Signature, error)
-	CountSignatures(campaignID string) (int, error)
-	DeleteSignature(campaignID string, id int64) error
+	ListSignatures(campaignID string, filter SignatureFilter, limit, offset int) ([]*Signature, error)
+	CountSignatures(campaignID string, filter SignatureFilter) (int, error)
+	CountSignaturesSince(campaignID string, since int64) (int, error)
+	CountSignaturesByDay(campaignID string, since int64) (map[string]int, error)
+	CountSignaturesByLocation(campaignID string) (map[string]int, error)
+	CountSignaturesByMappedLocation(campaignID string) ([]LocationSignatureCount, error)
+	DeleteSignature(campaignID string, id int64, deletedAt int64) error
+	RestoreSignature(campaignID string, id int64) error
+	ListDeletedSignatures(campaignID string, limit, offset int) ([]*Signature, error)
+	CountDeletedSignatures(campaignID string) (int, error)
 	SignatureEmailExists(campaignID, email string) (bool, error)
+	ApplySignatureBulkOperation(op SignatureBulkOperation, filter SignatureFilter) ([]*Signature, error)
+	GetSignatureBulkOperation(campaignID, id string) (*SignatureBulkOperation, error)
+	UndoSignatureBulkOperation(campaignID, id string, now int64) (*SignatureBulkOperation, []*Signature, error)
+	PurgeDeleted(before int64) (campaigns, signatures int, err error)
+
+	InsertWebhook(webhook Webhook) error
+	GetWebhook(id string) (*Webhook, error)
+	ListWebhooks(campaignID string) ([]*Webhook, error)
+	ListCampaignEventWebhooks(campaignID string) ([]*Webhook, error)
+	DeleteWebhook(id string) error
+	InsertWebhookDelivery(webhookID, event, payload string, createdAt int64) (int64, error)
+	RecordMilestone(campaignID string, milestone int, reachedAt int64) (bool, error)
+	GetWebhookDelivery(webhookID string, id int64) (*WebhookDelivery, error)
+	ListWebhookDeliveries(webhookID, status string, limit, offset int) ([]*WebhookDelivery, error)
+	CountWebhookDeliveries(webhookID, status string) (int, error)
+	ListDueWebhookDeliveries(now int64, limit int) ([]*WebhookDelivery, error)
+	UpdateWebhookDelivery(delivery WebhookDelivery) error
+
+	ClaimIdempotencyKey(key, method, path, fingerprint string, createdAt, expiresAt int64) (bool, error)
+	GetIdempotencyKey(key, method, path string) (*IdempotencyRecord, error)
+	CompleteIdempotencyKey(record IdempotencyRecord) error
+	DeleteIdempotencyKey(key, method, path string) error
+
+	ListAPIKeys() ([]*APIKey, error)
 }
 
 type Options struct {
-	Store       Store
-	KeysOptions *keys.Options
-	CORSOptions *cors.Options
-	Clock       func() time.Time
-	HealthCheck func() error
+	Store         Store
+	KeysOptions   *keys.Options
+	CORSOptions   *cors.Options
+	Clock         func() time.Time
+	HealthCheck   func() error
+	WebhookClient *http.Client
+	Stream        StreamOptions
+
+	// IdempotencyTTL is how long a stored Idempotency-Key response is
+	// replayed; zero means 24 hours.
+	IdempotencyTTL time.Duration
+
+	// BulkUndoWindow is how long a bulk signature action can be undone;
+	// zero means 10 minutes.
+	BulkUndoWindow time.Duration
+
+	// TrashRetention is how long deleted campaigns and signatures stay in
+	// the trash before they are purged; zero means 30 days.
+	TrashRetention time.Duration
 }
 
 type Service struct {
-	store       Store
-	keys        *keys.Service
-	cors        *cors.Service
-	clock       func() time.Time
-	healthCheck func() error
+	store         Store
+	keys          *keys.Service
+	cors          *cors.Service
+	clock         func() time.Time
+	healthCheck   func() error
+	webhookClient *http.Client
+	broadcaster   *broadcaster
+	stream        StreamOptions
+	badges        *badgeCache
+
+	idempotencyTTL time.Duration
+	bulkUndoWindow time.Duration
+	trashRetention time.Duration
 
 	rateLimiters   map[string]*rate.Limiter
 	rateLimitersMu sync.Mutex
@@ -142,33 +213,100 @@ func New(opts Options) (*Service, error) {
 		healthCheck = func() error { return nil }
 	}
 
+	webhookClient := opts.WebhookClient
+	if webhookClient == nil {
+		webhookClient = &http.Client{Timeout: webhookTimeout}
+	}
+
+	idempotencyTTL := opts.IdempotencyTTL
+	if idempotencyTTL <= 0 {
+		idempotencyTTL = defaultIdempotencyTTL
+	}
+
+	bulkUndoWindow := opts.BulkUndoWindow
+	if bulkUndoWindow <= 0 {
+		bulkUndoWindow = defaultBulkUndoWindow
+	}
+
+	trashRetention := opts.TrashRetention
+	if trashRetention <= 0 {
+		trashRetention = defaultTrashRetention
+	}
+
 	return &Service{
-		store:        opts.Store,
-		keys:         keysSvc,
-		cors:         corsSvc,
-		clock:        clock,
-		healthCheck:  healthCheck,
-		rateLimiters: make(map[string]*rate.Limiter),
+		store:         opts.Store,
+		keys:          keysSvc,
+		cors:          corsSvc,
+		clock:         clock,
+		healthCheck:   healthCheck,
+		webhookClient: webhookClient,
+		broadcaster:   newBroadcaster(clock),
+		stream:        streamOptionsWithDefaults(opts.Stream),
+		badges:        newBadgeCache(),
+
+		idempotencyTTL: idempotencyTTL,
+		bulkUndoWindow: bulkUndoWindow,
+		trashRetention: trashRetention,
+		rateLimiters:   make(map[string]*rate.Limiter),
 	}, nil
 }
 
+// Mount attaches an additional handler to the root mux alongside the API,
+// e.g. the hosted public campaign pages.
+type Mount struct {
+	Pattern string
+	Handler http.Handler
+}
+
+// Serve runs the API and mounts on ln until ctx ends, then drains
+// in-flight requests, ends event streams, cancels in-flight webhook sends,
+// and stops the trash sweeper after its current batch.
 func (s *Service) Serve(
-	addr string,
+	ctx context.Context,
+	ln net.Listener,
 	apiPrefix string,
+	opts httpserver.Options,
+	mounts ...Mount,
 ) error {
+	var worker sync.WaitGroup
+	workers, stopWorkers := context.WithCancel(context.Background())
+	worker.Go(func() { s.runWebhookWorker(workers) })
+	worker.Go(func() { s.runTrashSweeper(workers.Done()) })
+	defer func() {
+		stopWorkers()
+		worker.Wait()
+	}()
+
 	apiHandler := http.StripPrefix(apiPrefix, s.BuildRouter())
 	rootMux := http.NewServeMux()
 	rootMux.Handle(apiPrefix+"/", apiHandler)
 	rootMux.Handle(apiPrefix, apiHandler)
-	return http.ListenAndServe(addr, rootMux)
+	for _, mount := range mounts {
+		rootMux.Handle(mount.Pattern, mount.Handler)
+	}
+	return httpserver.Serve(ctx, ln, rootMux, opts, s.broadcaster.close)
 }
 
-func (s *Service) withRateLimit(next http.HandlerFunc) http.HandlerFunc {
+// AllowedOrigins returns the current CORS allowlist.
+func (s *Service) AllowedOrigins() ([]string, error) {
+	origins, err := s.cors.GetOrigins()
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, len(origins))
+	for _, origin := range origins {
+		urls = append(urls, origin.URL)
+	}
+	return urls, nil
+}
+
+func (s *Service) WithRateLimit(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ip := clientIP(r)
 		limiter := s.rateLimiterFor(ip)
 		if !limiter.Allow() {
-			wire.WriteError(w, http.StatusTooManyRequests, "rate limit exceeded")
+			writePublicError(w, r, http.StatusTooManyRequests, CodeRateLimited)
 			return
 		}
 
@@ -209,7 +347,7 @@ func clientIP(r *http.Request) string {
 
 func (s *Service) handleHealth(w http.ResponseWriter, r *http.Request) {
 	if err := s.healthCheck(); err != nil {
-		wire.WriteError(w, http.StatusServiceUnavailable, "database unhealthy")
+		writeError(w, http.StatusServiceUnavailable, CodeUnavailable, "database unhealthy")
 		return
 	}
 
@@ -228,6 +366,18 @@ func signatureIDFromPath(r *http.Request) (int64, error) {
 	return strconv.ParseInt(v, 10, 64)
 }
 
+func webhookIDFromPath(r *http.Request) string {
+	return strings.TrimSpace(r.PathValue("webhook_id"))
+}
+
+func deliveryIDFromPath(r *http.Request) (int64, error) {
+	v := strings.TrimSpace(r.PathValue("delivery_id"))
+	if v == "" {
+		return 0, fmt.Errorf("missing delivery id")
+	}
+	return strconv.ParseInt(v, 10, 64)
+}
+
 func randomID(bytes int) (string, error) {
 	b := make([]byte, bytes)
 	if _, err := rand.Read(b); err != nil {
@@ -3,6 +3,7 @@ package service_test
 import (
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"testing"
 
@@ -67,6 +68,42 @@ func TestCampaignLifecycle(t *testing.T) {
 	deleteResult.ExpectStatus(t, http.StatusNoContent)
 }
 
+func TestCampaignLetterAndThemeUpdate(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Themed")
+
+	update := wire.TestPut[service.Campaign](
+		handler,
+		"/admin/campaigns/"+campaign.ID,
+		`{"letter":"Dear council,\n\nPlease act.","theme":{"primary_color":"#123abc","logo_url":"https://example.org/logo.png"}}`,
+		authHeader(),
+	)
+	update.ExpectStatus(t, http.StatusOK)
+	if update.Data.Name != "Themed" || update.Data.Letter != "Dear council,\n\nPlease act." {
+		t.Fatalf("unexpected campaign update response: %+v", update.Data)
+	}
+	if update.Data.Theme.PrimaryColor != "#123abc" || update.Data.Theme.LogoURL != "https://example.org/logo.png" {
+		t.Fatalf("unexpected campaign theme: %+v", update.Data.Theme)
+	}
+
+	badColor := wire.TestPut[service.Campaign](
+		handler,
+		"/admin/campaigns/"+campaign.ID,
+		`{"theme":{"primary_color":"red; background: url(x)"}}`,
+		authHeader(),
+	)
+	badColor.ExpectStatus(t, http.StatusBadRequest)
+
+	badLogo := wire.TestPut[service.Campaign](
+		handler,
+		"/admin/campaigns/"+campaign.ID,
+		`{"theme":{"logo_url":"javascript:alert(1)"}}`,
+		authHeader(),
+	)
+	badLogo.ExpectStatus(t, http.StatusBadRequest)
+}
+
 func TestSignaturePublicCORSValidation(t *testing.T) {
 	svc := testutil.SetupService(t)
 	handler := svc.BuildRouter()
@@ -154,6 +191,18 @@ func TestSettingsCorsAndKeysRoutes(t *testing.T) {
 		t.Fatalf("unexpected key token format: %q", createKey.Data)
 	}
 
+	listKeys := wire.TestGet[service.APIKeys](handler, "/settings/keys", authHeader())
+	listKeys.ExpectStatus(t, http.StatusOK)
+	if !slices.ContainsFunc(listKeys.Data.Keys, func(key *service.APIKey) bool { return key.ID == parts[0] }) {
+		t.Fatalf("expected new key %q in list, got %#v", parts[0], listKeys.Data.Keys)
+	}
+
 	deleteKey := wire.TestDelete[struct{}](handler, "/settings/keys/"+parts[0], authHeader())
 	deleteKey.ExpectStatus(t, http.StatusNoContent)
+
+	listKeys = wire.TestGet[service.APIKeys](handler, "/settings/keys", authHeader())
+	listKeys.ExpectStatus(t, http.StatusOK)
+	if len(listKeys.Data.Keys) != 1 || listKeys.Data.Keys[0].ID != "default" {
+		t.Fatalf("expected only the bootstrap key after revoking, got %#v", listKeys.Data.Keys)
+	}
 }
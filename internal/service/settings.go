@@ -1,8 +1,40 @@
 package service
 
-import "net/http"
+import (
+	"cosign/pkg/cosignapi"
+	"net/http"
 
-func (s *Service) buildSettingsRouter(mux *http.ServeMux, mw Middleware) {
-	s.keys.Router(mux, "/settings", mw.auth)
-	s.cors.Router(mux, "/settings", mw.auth)
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+type (
+	APIKey  = cosignapi.APIKey
+	APIKeys = cosignapi.APIKeys
+)
+
+func (s *Service) buildSettingsRouter(mux *routeMux, mw Middleware) {
+	mux.HandleFunc("GET /settings/keys", mw.auth(s.handleListAPIKeys))
+	s.keys.Router(mux.ServeMux, "/settings", mw.auth)
+	s.cors.Router(mux.ServeMux, "/settings", mw.auth)
+}
+
+func (s *Service) ListAPIKeys() (*APIKeys, error) {
+	keys, err := s.store.ListAPIKeys()
+	if err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+	if keys == nil {
+		keys = []*APIKey{}
+	}
+	return &APIKeys{Keys: keys}, nil
+}
+
+func (s *Service) handleListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := s.ListAPIKeys()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternalError, "failed to list api keys")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, keys)
 }
@@ -1,10 +1,14 @@
 package service
 
 import (
+	"cosign/pkg/cosignapi"
 	"encoding/json"
 	"errors"
+	"mime"
 	"net/http"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"git.sr.ht/~jakintosh/command-go/pkg/wire"
@@ -12,32 +16,61 @@ import (
 
 var signatureEmailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
 
-type CreateSignatureRequest struct {
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Location string `json:"location"`
-}
+const maxSignatureFormBytes = 1 << 20
 
-func (s *Service) CreateSignature(campaignID, name, email, location string) (*Signature, error) {
-	name = strings.TrimSpace(name)
-	email = strings.TrimSpace(email)
-	location = strings.TrimSpace(location)
+const (
+	VisibilityVisible = cosignapi.VisibilityVisible
+	VisibilityHidden  = cosignapi.VisibilityHidden
+)
+
+var ErrInvalidVisibility = cosignapi.ErrInvalidVisibility
 
-	if name == "" {
-		return nil, ErrEmptyName
+type (
+	SignatureFilter        = cosignapi.SignatureFilter
+	CreateSignatureRequest = cosignapi.CreateSignatureRequest
+)
+
+// visibleSignatures is what the public sees and what counts are made of.
+var visibleSignatures = SignatureFilter{Visibility: VisibilityVisible}
+
+func checkSignatureFilter(filter SignatureFilter) (SignatureFilter, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	filter.Location = strings.TrimSpace(filter.Location)
+	filter.Visibility = strings.ToLower(strings.TrimSpace(filter.Visibility))
+	if filter.Visibility != "" && filter.Visibility != VisibilityVisible && filter.Visibility != VisibilityHidden {
+		return filter, ErrInvalidVisibility
 	}
-	if email == "" {
-		return nil, ErrEmptyEmail
+	return filter, nil
+}
+
+// signatureFilterFromQuery reads a filter from the q, location, visibility
+// and comma separated ids parameters; only malformed ids fail here.
+func signatureFilterFromQuery(query url.Values) (SignatureFilter, error) {
+	filter := SignatureFilter{
+		Query:      query.Get("q"),
+		Location:   query.Get("location"),
+		Visibility: query.Get("visibility"),
 	}
-	if location == "" {
-		return nil, ErrEmptyLocation
+	if raw := strings.TrimSpace(query.Get("ids")); raw != "" {
+		filter.IDs = []int64{}
+		for _, part := range strings.Split(raw, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			if err != nil {
+				return filter, err
+			}
+			filter.IDs = append(filter.IDs, id)
+		}
 	}
+	return filter, nil
+}
 
-	if !signatureEmailRegex.MatchString(email) {
-		return nil, ErrInvalidEmail
+func (s *Service) CreateSignature(campaignID, name, email, location string) (*Signature, error) {
+	req, err := checkSignature(CreateSignatureRequest{Name: name, Email: email, Location: location})
+	if err != nil {
+		return nil, err
 	}
 
-	exists, err := s.store.SignatureEmailExists(campaignID, email)
+	exists, err := s.store.SignatureEmailExists(campaignID, req.Email)
 	if err != nil {
 		return nil, DatabaseError{Err: err}
 	}
@@ -45,23 +78,61 @@ func (s *Service) CreateSignature(campaignID, name, email, location string) (*Si
 		return nil, ErrDuplicateEmail
 	}
 
-	if err := s.validateSignatureLocation(campaignID, location); err != nil {
+	location, err = s.resolveSignatureLocation(campaignID, req.Location)
+	if err != nil {
 		return nil, err
 	}
 
+	return s.insertSignature(campaignID, req, location)
+}
+
+// checkSignature trims a submission and validates everything but its
+// location and whether its email already signed.
+func checkSignature(req CreateSignatureRequest) (CreateSignatureRequest, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Email = strings.TrimSpace(req.Email)
+	req.Location = strings.TrimSpace(req.Location)
+
+	if req.Name == "" {
+		return req, ErrEmptyName
+	}
+	if req.Email == "" {
+		return req, ErrEmptyEmail
+	}
+	if req.Location == "" {
+		return req, ErrEmptyLocation
+	}
+
+	if !signatureEmailRegex.MatchString(req.Email) {
+		return req, ErrInvalidEmail
+	}
+	return req, nil
+}
+
+// insertSignature stores a checked submission under its resolved location,
+// keeping what was typed as the raw location.
+func (s *Service) insertSignature(campaignID string, req CreateSignatureRequest, location string) (*Signature, error) {
 	createdAt := s.clock().Unix()
-	id, err := s.store.InsertSignature(campaignID, name, email, location, createdAt)
+	id, err := s.store.InsertSignature(campaignID, req.Name, req.Email, location, req.Location, createdAt)
 	if err != nil {
 		return nil, DatabaseError{Err: err}
 	}
 
-	return &Signature{
-		ID:        id,
-		Name:      name,
-		Email:     email,
-		Location:  location,
-		CreatedAt: createdAt,
-	}, nil
+	signature := &Signature{
+		ID:          id,
+		Name:        req.Name,
+		Email:       req.Email,
+		Location:    location,
+		LocationRaw: req.Location,
+		CreatedAt:   createdAt,
+	}
+
+	s.badges.invalidate(campaignID)
+	s.publishSignatureCreated(campaignID, signature)
+	s.enqueueWebhookEvent(campaignID, EventSignatureCreated, signature)
+	s.enqueueMilestoneEvent(campaignID)
+
+	return signature, nil
 }
 
 func (s *Service) GetSignature(campaignID string, id int64) (*Signature, error) {
@@ -75,7 +146,7 @@ func (s *Service) GetSignature(campaignID string, id int64) (*Signature, error)
 	return signature, nil
 }
 
-func (s *Service) ListSignatures(campaignID string, limit, offset int) (*Signatures, error) {
+func (s *Service) ListSignatures(campaignID string, filter SignatureFilter, limit, offset int) (*Signatures, error) {
 	if limit <= 0 {
 		limit = 100
 	}
@@ -83,12 +154,17 @@ func (s *Service) ListSignatures(campaignID string, limit, offset int) (*Signatu
 		offset = 0
 	}
 
-	list, err := s.store.ListSignatures(campaignID, limit, offset)
+	filter, err := checkSignatureFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.store.ListSignatures(campaignID, filter, limit, offset)
 	if err != nil {
 		return nil, DatabaseError{Err: err}
 	}
 
-	total, err := s.store.CountSignatures(campaignID)
+	total, err := s.store.CountSignatures(campaignID, filter)
 	if err != nil {
 		return nil, DatabaseError{Err: err}
 	}
@@ -102,7 +178,12 @@ func (s *Service) ListSignatures(campaignID string, limit, offset int) (*Signatu
 }
 
 func (s *Service) DeleteSignature(campaignID string, id int64) error {
-	err := s.store.DeleteSignature(campaignID, id)
+	signature, err := s.GetSignature(campaignID, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.DeleteSignature(campaignID, id, s.clock().Unix())
 	if err != nil {
 		if errors.Is(err, ErrSignatureNotFound) {
 			return err
@@ -110,98 +191,250 @@ func (s *Service) DeleteSignature(campaignID string, id int64) error {
 		return DatabaseError{Err: err}
 	}
 
+	s.badges.invalidate(campaignID)
+	s.publishCount(campaignID)
+	s.enqueueWebhookEvent(campaignID, EventSignatureDeleted, signature)
+
 	return nil
 }
 
-func (s *Service) validateSignatureLocation(campaignID, location string) error {
+// resolveSignatureLocation maps submitted text onto the preset it names,
+// by value or alias, ignoring case and spacing. Text that matches no preset
+// is kept as-is when custom text is allowed.
+func (s *Service) resolveSignatureLocation(campaignID, location string) (string, error) {
 	campaign, err := s.GetCampaign(campaignID)
 	if err != nil {
 		if errors.Is(err, ErrCampaignNotFound) {
-			return ErrCampaignNotFound
+			return "", ErrCampaignNotFound
 		}
-		return err
-	}
-
-	if campaign.AllowCustomText {
-		return nil
+		return "", err
 	}
 
 	options, err := s.GetCampaignLocations(campaignID)
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	return resolveLocation(campaign, options, location)
+}
+
+func resolveLocation(campaign *Campaign, options []LocationOption, location string) (string, error) {
+	location = canonicalLocation(location, options)
+	if campaign.AllowCustomText || len(options) == 0 {
+		return location, nil
 	}
 
-	if len(options) == 0 {
-		return nil
+	for _, opt := range SelectableLocations(options, campaign.LocationLevel) {
+		if opt.Value == location {
+			return location, nil
+		}
 	}
 
+	return "", ErrLocationNotInOptions
+}
+
+func canonicalLocation(location string, options []LocationOption) string {
 	for _, opt := range options {
 		if opt.Value == location {
-			return nil
+			return opt.Value
 		}
 	}
 
-	return ErrLocationNotInOptions
+	key := locationKey(location)
+	for _, opt := range options {
+		if locationKey(opt.Value) == key {
+			return opt.Value
+		}
+	}
+	for _, opt := range options {
+		for _, alias := range opt.Aliases {
+			if locationKey(alias) == key {
+				return opt.Value
+			}
+		}
+	}
+
+	return location
 }
 
-func (s *Service) buildPublicSignatureRouter(mux *http.ServeMux, mw Middleware) {
-	mux.HandleFunc("GET /{campaign_id}/signatures", mw.cors(s.handleListSignatures))
+func (s *Service) buildPublicSignatureRouter(mux *routeMux, mw Middleware) {
+	mux.HandleFunc("GET /{campaign_id}/signatures", mw.cors(s.handlePublicListSignatures))
 	mux.HandleFunc("OPTIONS /{campaign_id}/signatures", mw.cors(s.handleCreateSignature))
-	mux.HandleFunc("POST /{campaign_id}/signatures", mw.cors(mw.rateLimit(s.handleCreateSignature)))
+	mux.HandleFunc("POST /{campaign_id}/signatures", mw.cors(mw.rateLimit(mw.idempotent(s.handleCreateSignature))))
+	mux.HandleFunc("GET /{campaign_id}/events", mw.cors(s.handleCampaignEvents))
+	mux.HandleFunc("OPTIONS /{campaign_id}/events", mw.cors(s.handleCampaignEvents))
 }
 
-func (s *Service) buildAdminSignatureRouter(mux *http.ServeMux, _ Middleware) {
+func (s *Service) buildAdminSignatureRouter(mux *routeMux, mw Middleware) {
 	mux.HandleFunc("GET /{campaign_id}/signatures", s.handleListSignatures)
+	mux.HandleFunc("POST /{campaign_id}/signatures/import", mw.idempotent(s.handleImportSignatures))
+	mux.HandleFunc("GET /{campaign_id}/signatures/stats", s.handleGetSignatureStats)
+	mux.HandleFunc("POST /{campaign_id}/signatures/bulk", s.handleBulkSignatures)
+	mux.HandleFunc("GET /{campaign_id}/signatures/bulk/{operation_id}", s.handleGetSignatureBulkOperation)
+	mux.HandleFunc("POST /{campaign_id}/signatures/bulk/{operation_id}/undo", s.handleUndoBulkSignatures)
+	mux.HandleFunc("GET /{campaign_id}/signatures/trash", s.handleListDeletedSignatures)
 	mux.HandleFunc("DELETE /{campaign_id}/signatures/{signature_id}", s.handleDeleteSignature)
+	mux.HandleFunc("POST /{campaign_id}/signatures/{signature_id}/restore", s.handleRestoreSignature)
 }
 
 func (s *Service) handleCreateSignature(w http.ResponseWriter, r *http.Request) {
 	campaignID := campaignIDFromPath(r)
 	if campaignID == "" {
-		wire.WriteError(w, http.StatusBadRequest, "campaign id required")
+		writePublicError(w, r, http.StatusBadRequest, CodeCampaignIDRequired)
+		return
+	}
+
+	if isFormRequest(r) {
+		s.handleCreateSignatureForm(w, r, campaignID)
 		return
 	}
 
 	var req CreateSignatureRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		wire.WriteError(w, http.StatusBadRequest, "invalid request body")
+		writePublicError(w, r, http.StatusBadRequest, CodeInvalidRequest)
 		return
 	}
 
 	signature, err := s.CreateSignature(campaignID, req.Name, req.Email, req.Location)
 	if err != nil {
-		switch {
-		case errors.Is(err, ErrEmptyName), errors.Is(err, ErrEmptyEmail), errors.Is(err, ErrEmptyLocation), errors.Is(err, ErrInvalidEmail), errors.Is(err, ErrLocationNotInOptions):
-			wire.WriteError(w, http.StatusBadRequest, err.Error())
-		case errors.Is(err, ErrCampaignNotFound):
-			wire.WriteError(w, http.StatusNotFound, "campaign not found")
-		case errors.Is(err, ErrDuplicateEmail):
-			wire.WriteError(w, http.StatusConflict, err.Error())
-		default:
-			wire.WriteError(w, http.StatusInternalServerError, "failed to create signature")
-		}
+		writePublicError(w, r, ErrorStatus(err), ErrorCode(err))
+		return
+	}
+
+	wire.WriteData(w, http.StatusCreated, signature)
+}
+
+// handleCreateSignatureForm accepts plain HTML form posts and redirects to the
+// campaign's success or error URL. Without configured redirects it answers
+// like the JSON endpoint.
+func (s *Service) handleCreateSignatureForm(w http.ResponseWriter, r *http.Request, campaignID string) {
+	campaign, err := s.GetCampaign(campaignID)
+	if err != nil {
+		writePublicError(w, r, ErrorStatus(err), ErrorCode(err))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxSignatureFormBytes)
+	if err := parseSignatureForm(r); err != nil {
+		s.redirectSignatureError(w, r, campaign, http.StatusBadRequest, CodeInvalidRequest)
+		return
+	}
+
+	signature, err := s.CreateSignature(
+		campaignID,
+		r.PostFormValue("name"),
+		r.PostFormValue("email"),
+		r.PostFormValue("location"),
+	)
+	if err != nil {
+		s.redirectSignatureError(w, r, campaign, ErrorStatus(err), ErrorCode(err))
+		return
+	}
+
+	if target := s.allowedRedirect(campaign.SuccessURL); target != "" {
+		http.Redirect(w, r, target, http.StatusSeeOther)
 		return
 	}
 
 	wire.WriteData(w, http.StatusCreated, signature)
 }
 
+func (s *Service) redirectSignatureError(
+	w http.ResponseWriter,
+	r *http.Request,
+	campaign *Campaign,
+	status int,
+	code string,
+) {
+	target := s.allowedRedirect(campaign.ErrorURL)
+	if target == "" {
+		writePublicError(w, r, status, code)
+		return
+	}
+
+	parsed, err := url.Parse(target)
+	if err != nil {
+		writePublicError(w, r, status, code)
+		return
+	}
+	query := parsed.Query()
+	query.Set("error", code)
+	parsed.RawQuery = query.Encode()
+
+	http.Redirect(w, r, parsed.String(), http.StatusSeeOther)
+}
+
+// allowedRedirect re-checks the target on every use since the CORS allowlist
+// may have changed after the campaign was saved.
+func (s *Service) allowedRedirect(target string) string {
+	if target == "" || s.validateRedirectURL(target) != nil {
+		return ""
+	}
+	return target
+}
+
+func isFormRequest(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data"
+}
+
+func parseSignatureForm(r *http.Request) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		return r.ParseMultipartForm(maxSignatureFormBytes)
+	}
+	return r.ParseForm()
+}
+
+// handlePublicListSignatures lists only visible signatures, whatever the
+// query asks for.
+func (s *Service) handlePublicListSignatures(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writePublicError(w, r, http.StatusBadRequest, CodeCampaignIDRequired)
+		return
+	}
+
+	limit, offset, malformed := wire.ParsePagination(r)
+	if malformed != nil {
+		writePublicError(w, r, http.StatusBadRequest, CodeInvalidRequest)
+		return
+	}
+
+	signatures, err := s.ListSignatures(campaignID, visibleSignatures, limit, offset)
+	if err != nil {
+		writePublicError(w, r, http.StatusInternalServerError, CodeInternalError)
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, signatures)
+}
+
 func (s *Service) handleListSignatures(w http.ResponseWriter, r *http.Request) {
 	campaignID := campaignIDFromPath(r)
 	if campaignID == "" {
-		wire.WriteError(w, http.StatusBadRequest, "campaign id required")
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
 		return
 	}
 
 	limit, offset, malformed := wire.ParsePagination(r)
 	if malformed != nil {
-		wire.WriteError(w, http.StatusBadRequest, malformed.Error())
+		writeError(w, http.StatusBadRequest, CodeInvalidPagination, malformed.Error())
 		return
 	}
 
-	signatures, err := s.ListSignatures(campaignID, limit, offset)
+	filter, err := signatureFilterFromQuery(r.URL.Query())
 	if err != nil {
-		wire.WriteError(w, http.StatusInternalServerError, "failed to list signatures")
+		writeError(w, http.StatusBadRequest, CodeInvalidID, "ids must be comma separated signature ids")
+		return
+	}
+
+	signatures, err := s.ListSignatures(campaignID, filter, limit, offset)
+	if err != nil {
+		writeServiceError(w, err, "failed to list signatures")
 		return
 	}
 
@@ -211,23 +444,18 @@ func (s *Service) handleListSignatures(w http.ResponseWriter, r *http.Request) {
 func (s *Service) handleDeleteSignature(w http.ResponseWriter, r *http.Request) {
 	campaignID := campaignIDFromPath(r)
 	if campaignID == "" {
-		wire.WriteError(w, http.StatusBadRequest, "campaign id required")
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
 		return
 	}
 
 	signatureID, err := signatureIDFromPath(r)
 	if err != nil {
-		wire.WriteError(w, http.StatusBadRequest, "invalid signature id")
+		writeError(w, http.StatusBadRequest, CodeInvalidID, "invalid signature id")
 		return
 	}
 
 	if err := s.DeleteSignature(campaignID, signatureID); err != nil {
-		switch {
-		case errors.Is(err, ErrSignatureNotFound):
-			wire.WriteError(w, http.StatusNotFound, "signature not found")
-		default:
-			wire.WriteError(w, http.StatusInternalServerError, "failed to delete signature")
-		}
+		writeServiceError(w, err, "failed to delete signature")
 		return
 	}
 
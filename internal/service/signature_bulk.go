@@ -0,0 +1,197 @@
+package service
+
+import (
+	"cosign/pkg/cosignapi"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+const (
+	BulkActionDelete = cosignapi.BulkActionDelete
+	BulkActionHide   = cosignapi.BulkActionHide
+	BulkActionUnhide = cosignapi.BulkActionUnhide
+
+	MaxBulkIDs = cosignapi.MaxBulkIDs
+
+	defaultBulkUndoWindow = 10 * time.Minute
+)
+
+var (
+	ErrInvalidBulkAction     = cosignapi.ErrInvalidBulkAction
+	ErrBulkTargetRequired    = cosignapi.ErrBulkTargetRequired
+	ErrTooManyBulkIDs        = cosignapi.ErrTooManyBulkIDs
+	ErrBulkOperationNotFound = cosignapi.ErrBulkOperationNotFound
+	ErrBulkUndoExpired       = cosignapi.ErrBulkUndoExpired
+)
+
+type (
+	BulkSignaturesRequest  = cosignapi.BulkSignaturesRequest
+	BulkSignaturesResponse = cosignapi.BulkSignaturesResponse
+	BulkUndoResponse       = cosignapi.BulkUndoResponse
+	SignatureBulkOperation = cosignapi.SignatureBulkOperation
+)
+
+func (s *Service) BulkSignatures(campaignID string, req BulkSignaturesRequest) (*BulkSignaturesResponse, error) {
+	action := strings.ToLower(strings.TrimSpace(req.Action))
+	switch action {
+	case BulkActionDelete, BulkActionHide, BulkActionUnhide:
+	default:
+		return nil, ErrInvalidBulkAction
+	}
+
+	if (len(req.IDs) > 0) == (req.Filter != nil) {
+		return nil, ErrBulkTargetRequired
+	}
+	if len(req.IDs) > MaxBulkIDs {
+		return nil, ErrTooManyBulkIDs
+	}
+
+	filter := SignatureFilter{IDs: req.IDs}
+	if req.Filter != nil {
+		var err error
+		if filter, err = checkSignatureFilter(*req.Filter); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := s.GetCampaign(campaignID); err != nil {
+		return nil, err
+	}
+
+	id, err := randomID(8)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.clock()
+	op := SignatureBulkOperation{
+		ID:         id,
+		CampaignID: campaignID,
+		Action:     action,
+		CreatedAt:  now.Unix(),
+		ExpiresAt:  now.Add(s.bulkUndoWindow).Unix(),
+	}
+	affected, err := s.store.ApplySignatureBulkOperation(op, filter)
+	if err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+
+	response := &BulkSignaturesResponse{Action: action, Affected: len(affected)}
+	if len(affected) == 0 {
+		return response, nil
+	}
+	response.OperationID = op.ID
+	response.UndoUntil = op.ExpiresAt
+
+	s.badges.invalidate(campaignID)
+	s.publishCount(campaignID)
+	switch action {
+	case BulkActionDelete:
+		for _, signature := range affected {
+			s.enqueueWebhookEvent(campaignID, EventSignatureDeleted, signature)
+		}
+	case BulkActionUnhide:
+		s.enqueueMilestoneEvent(campaignID)
+	}
+
+	return response, nil
+}
+
+// GetSignatureBulkOperation returns a bulk operation that has not been
+// undone, expired or not, so callers can check what undoing it would do.
+func (s *Service) GetSignatureBulkOperation(campaignID, operationID string) (*SignatureBulkOperation, error) {
+	op, err := s.store.GetSignatureBulkOperation(campaignID, operationID)
+	if err != nil {
+		if errors.Is(err, ErrBulkOperationNotFound) {
+			return nil, err
+		}
+		return nil, DatabaseError{Err: err}
+	}
+	return op, nil
+}
+
+func (s *Service) UndoBulkSignatures(campaignID, operationID string) (*BulkUndoResponse, error) {
+	op, restored, err := s.store.UndoSignatureBulkOperation(campaignID, operationID, s.clock().Unix())
+	if err != nil {
+		if errors.Is(err, ErrBulkOperationNotFound) || errors.Is(err, ErrBulkUndoExpired) {
+			return nil, err
+		}
+		return nil, DatabaseError{Err: err}
+	}
+
+	s.badges.invalidate(campaignID)
+	s.publishCount(campaignID)
+	if op.Action == BulkActionDelete {
+		for _, signature := range restored {
+			s.enqueueWebhookEvent(campaignID, EventSignatureCreated, signature)
+		}
+	}
+	if op.Action != BulkActionUnhide {
+		s.enqueueMilestoneEvent(campaignID)
+	}
+
+	return &BulkUndoResponse{
+		OperationID: op.ID,
+		Action:      op.Action,
+		Restored:    len(restored),
+	}, nil
+}
+
+func (s *Service) handleBulkSignatures(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
+		return
+	}
+
+	var req BulkSignaturesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "invalid request body")
+		return
+	}
+
+	response, err := s.BulkSignatures(campaignID, req)
+	if err != nil {
+		writeServiceError(w, err, "failed to apply bulk action")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, response)
+}
+
+func (s *Service) handleGetSignatureBulkOperation(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
+		return
+	}
+
+	op, err := s.GetSignatureBulkOperation(campaignID, r.PathValue("operation_id"))
+	if err != nil {
+		writeServiceError(w, err, "failed to get bulk operation")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, op)
+}
+
+func (s *Service) handleUndoBulkSignatures(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
+		return
+	}
+
+	response, err := s.UndoBulkSignatures(campaignID, r.PathValue("operation_id"))
+	if err != nil {
+		writeServiceError(w, err, "failed to undo bulk action")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, response)
+}
@@ -0,0 +1,152 @@
+package service_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"cosign/internal/service"
+	"cosign/internal/testutil"
+	"cosign/pkg/cosignapi"
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+func signBulkCampaign(t *testing.T, handler http.Handler, campaignID string, emails ...string) map[string]int64 {
+	t.Helper()
+	ids := make(map[string]int64, len(emails))
+	for _, email := range emails {
+		body := fmt.Sprintf(`{"name":"Signer","email":%q,"location":"Brooklyn"}`, email)
+		result := wire.TestPost[service.Signature](handler, "/campaigns/"+campaignID+"/signatures", body, originHeader("http://test-origin"))
+		result.ExpectStatus(t, http.StatusCreated)
+		ids[email] = result.Data.ID
+	}
+	return ids
+}
+
+func TestListSignaturesFiltersByQueryVisibilityAndIDs(t *testing.T) {
+	handler := testutil.SetupService(t).BuildRouter()
+	campaign := createCampaign(t, handler, "Filters")
+	ids := signBulkCampaign(t, handler, campaign.ID, "ada@example.org", "bob@example.com", "cy@example.org")
+	admin := "/admin/campaigns/" + campaign.ID + "/signatures"
+
+	wire.TestPost[service.BulkSignaturesResponse](handler, admin+"/bulk", fmt.Sprintf(`{"action":"hide","ids":[%d]}`, ids["cy@example.org"]), authHeader()).
+		ExpectStatus(t, http.StatusOK)
+
+	cases := map[string]int{
+		"":                                  3,
+		"?q=EXAMPLE.ORG":                    2,
+		"?q=%25":                            0,
+		"?visibility=hidden":                1,
+		"?q=example.org&visibility=visible": 1,
+		fmt.Sprintf("?ids=%d,%d", ids["ada@example.org"], ids["bob@example.com"]): 2,
+	}
+	for query, want := range cases {
+		list := wire.TestGet[service.Signatures](handler, admin+query, authHeader())
+		list.ExpectStatus(t, http.StatusOK)
+		if list.Data.Total != want || len(list.Data.Signatures) != want {
+			t.Fatalf("%q: expected %d signatures, got %+v", query, want, list.Data)
+		}
+	}
+
+	wire.TestGet[service.Signatures](handler, admin+"?visibility=secret", authHeader()).ExpectStatus(t, http.StatusBadRequest)
+	wire.TestGet[service.Signatures](handler, admin+"?ids=1,x", authHeader()).ExpectStatus(t, http.StatusBadRequest)
+
+	public := wire.TestGet[service.Signatures](handler, "/campaigns/"+campaign.ID+"/signatures?visibility=hidden")
+	public.ExpectStatus(t, http.StatusOK)
+	if public.Data.Total != 2 {
+		t.Fatalf("expected the public list to leave out hidden signatures, got %+v", public.Data)
+	}
+}
+
+func TestBulkSignaturesHideAndDeleteAreUndoable(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	svc := testutil.SetupServiceWith(t, func(opts *service.Options) {
+		opts.Clock = func() time.Time { return now }
+		opts.BulkUndoWindow = 5 * time.Minute
+	})
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Bulk")
+	ids := signBulkCampaign(t, handler, campaign.ID, "ada@example.org", "bob@example.org", "cy@example.com")
+	admin := "/admin/campaigns/" + campaign.ID + "/signatures"
+
+	hide := wire.TestPost[service.BulkSignaturesResponse](handler, admin+"/bulk", `{"action":"hide","filter":{"query":"example.org"}}`, authHeader())
+	hide.ExpectStatus(t, http.StatusOK)
+	if hide.Data.Affected != 2 || hide.Data.OperationID == "" || hide.Data.UndoUntil != now.Add(5*time.Minute).Unix() {
+		t.Fatalf("expected two hidden signatures with an undo window, got %+v", hide.Data)
+	}
+	if stats, _ := svc.GetSignatureStats(campaign.ID, 1); stats.Total != 1 {
+		t.Fatalf("expected hidden signatures left out of the total, got %d", stats.Total)
+	}
+
+	again := wire.TestPost[service.BulkSignaturesResponse](handler, admin+"/bulk", `{"action":"hide","filter":{"query":"example.org"}}`, authHeader())
+	if again.Data.Affected != 0 || again.Data.OperationID != "" {
+		t.Fatalf("expected hiding hidden signatures to change nothing, got %+v", again.Data)
+	}
+
+	op := wire.TestGet[service.SignatureBulkOperation](handler, admin+"/bulk/"+hide.Data.OperationID, authHeader())
+	op.ExpectStatus(t, http.StatusOK)
+	if op.Data.Action != service.BulkActionHide || op.Data.ExpiresAt != hide.Data.UndoUntil {
+		t.Fatalf("expected the hide operation, got %+v", op.Data)
+	}
+
+	undo := wire.TestPost[service.BulkUndoResponse](handler, admin+"/bulk/"+hide.Data.OperationID+"/undo", "", authHeader())
+	undo.ExpectStatus(t, http.StatusOK)
+	if undo.Data.Restored != 2 || undo.Data.Action != service.BulkActionHide {
+		t.Fatalf("expected two signatures shown again, got %+v", undo.Data)
+	}
+	wire.TestPost[service.BulkUndoResponse](handler, admin+"/bulk/"+hide.Data.OperationID+"/undo", "", authHeader()).
+		ExpectStatus(t, http.StatusNotFound)
+	wire.TestGet[service.SignatureBulkOperation](handler, admin+"/bulk/"+hide.Data.OperationID, authHeader()).
+		ExpectStatus(t, http.StatusNotFound)
+
+	body := fmt.Sprintf(`{"action":"delete","ids":[%d,%d]}`, ids["ada@example.org"], ids["cy@example.com"])
+	deleted := wire.TestPost[service.BulkSignaturesResponse](handler, admin+"/bulk", body, authHeader())
+	deleted.ExpectStatus(t, http.StatusOK)
+	if deleted.Data.Affected != 2 {
+		t.Fatalf("expected two deleted signatures, got %+v", deleted.Data)
+	}
+	signBulkCampaign(t, handler, campaign.ID, "cy@example.com")
+
+	restored := wire.TestPost[service.BulkUndoResponse](handler, admin+"/bulk/"+deleted.Data.OperationID+"/undo", "", authHeader())
+	restored.ExpectStatus(t, http.StatusOK)
+	if restored.Data.Restored != 1 {
+		t.Fatalf("expected only the signature not signed again to come back, got %+v", restored.Data)
+	}
+	if _, err := svc.GetSignature(campaign.ID, ids["ada@example.org"]); err != nil {
+		t.Fatalf("expected the deleted signature back under its id: %v", err)
+	}
+
+	expiring := wire.TestPost[service.BulkSignaturesResponse](handler, admin+"/bulk", `{"action":"delete","filter":{}}`, authHeader())
+	if expiring.Data.Affected != 3 {
+		t.Fatalf("expected an empty filter to match every signature, got %+v", expiring.Data)
+	}
+	now = now.Add(5 * time.Minute)
+	wire.TestPost[service.BulkUndoResponse](handler, admin+"/bulk/"+expiring.Data.OperationID+"/undo", "", authHeader()).
+		ExpectStatus(t, http.StatusConflict)
+}
+
+func TestBulkSignaturesRejectsBadRequests(t *testing.T) {
+	handler := testutil.SetupService(t).BuildRouter()
+	campaign := createCampaign(t, handler, "Bulk Errors")
+	admin := "/admin/campaigns/" + campaign.ID + "/signatures/bulk"
+
+	cases := map[string]string{
+		`{"action":"archive","ids":[1]}`:                                                service.CodeInvalidBulkAction,
+		`{"action":"delete"}`:                                                           service.CodeBulkTargetRequired,
+		`{"action":"delete","ids":[1],"filter":{}}`:                                     service.CodeBulkTargetRequired,
+		`{"action":"hide","filter":{"visibility":"secret"}}`:                            service.CodeInvalidVisibility,
+		`{"action":"delete","ids":[` + strings.Repeat("2,", service.MaxBulkIDs) + `1]}`: service.CodeTooManyBulkIDs,
+	}
+	for body, code := range cases {
+		result := wire.TestPost[service.BulkSignaturesResponse](handler, admin, body, authHeader())
+		result.ExpectStatus(t, http.StatusBadRequest)
+		if result.Error == nil || cosignapi.ErrorForCode(code).Error() != result.Error.Message {
+			t.Fatalf("expected %s, got %#v", code, result.Error)
+		}
+	}
+
+	wire.TestPost[service.BulkSignaturesResponse](handler, "/admin/campaigns/missing/signatures/bulk", `{"action":"delete","filter":{}}`, authHeader()).
+		ExpectStatus(t, http.StatusNotFound)
+}
@@ -0,0 +1,137 @@
+package service_test
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"cosign/internal/service"
+	"cosign/internal/testutil"
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+func postSignatureForm(handler http.Handler, campaignID string, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/campaigns/"+campaignID+"/signatures", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Origin", "http://test-origin")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func setRedirects(t *testing.T, handler http.Handler, campaignID string) {
+	t.Helper()
+
+	result := wire.TestPut[service.Campaign](
+		handler,
+		"/admin/campaigns/"+campaignID,
+		`{"success_url":"http://test-origin/thanks","error_url":"http://test-origin/oops?ref=letter"}`,
+		authHeader(),
+	)
+	result.ExpectStatus(t, http.StatusOK)
+}
+
+func TestSignatureFormRedirectsToSuccessURL(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Forms")
+	setRedirects(t, handler, campaign.ID)
+
+	rec := postSignatureForm(handler, campaign.ID, url.Values{
+		"name":     {"Alice"},
+		"email":    {"alice@example.com"},
+		"location": {"NYC"},
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Location"); got != "http://test-origin/thanks" {
+		t.Fatalf("unexpected success redirect: %q", got)
+	}
+
+	list, err := svc.ListSignatures(campaign.ID, service.SignatureFilter{}, 10, 0)
+	if err != nil || list.Total != 1 {
+		t.Fatalf("expected one signature, got %+v (%v)", list, err)
+	}
+}
+
+func TestSignatureFormRedirectsErrorCode(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Forms")
+	setRedirects(t, handler, campaign.ID)
+
+	rec := postSignatureForm(handler, campaign.ID, url.Values{
+		"name":     {"Alice"},
+		"email":    {"nope"},
+		"location": {"NYC"},
+	})
+	if rec.Code != http.StatusSeeOther {
+		t.Fatalf("expected 303, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Location"); got != "http://test-origin/oops?error=invalid_email&ref=letter" {
+		t.Fatalf("unexpected error redirect: %q", got)
+	}
+}
+
+func TestSignatureMultipartWithoutRedirectsReturnsJSON(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Multipart")
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	writer.WriteField("name", "Bob")
+	writer.WriteField("email", "bob@example.com")
+	writer.WriteField("location", "Boston")
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/campaigns/"+campaign.ID+"/signatures", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `"email":"bob@example.com"`) {
+		t.Fatalf("expected signature json, got %s", rec.Body.String())
+	}
+}
+
+func TestCampaignRedirectsMustMatchCORSAllowlist(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Open Redirect")
+
+	result := wire.TestPut[service.Campaign](
+		handler,
+		"/admin/campaigns/"+campaign.ID,
+		`{"success_url":"https://evil.example/phish"}`,
+		authHeader(),
+	)
+	result.ExpectStatus(t, http.StatusBadRequest)
+}
+
+func TestCampaignUpdatesKeepRedirectsOfDroppedOrigins(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Dropped Origin")
+	setRedirects(t, handler, campaign.ID)
+
+	wire.TestPut[struct{}](handler, "/settings/cors", `[{"url":"http://app.example"}]`, authHeader()).
+		ExpectStatus(t, http.StatusNoContent)
+
+	renamed := wire.TestPut[service.Campaign](handler, "/admin/campaigns/"+campaign.ID, `{"name":"Renamed"}`, authHeader())
+	renamed.ExpectStatus(t, http.StatusOK)
+	if renamed.Data.Name != "Renamed" || renamed.Data.SuccessURL != "http://test-origin/thanks" {
+		t.Fatalf("expected the rename kept with the stored redirect, got %+v", renamed.Data)
+	}
+
+	wire.TestPut[service.Campaign](handler, "/admin/campaigns/"+campaign.ID, `{"success_url":"http://test-origin/welcome"}`, authHeader()).
+		ExpectStatus(t, http.StatusBadRequest)
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"cosign/pkg/cosignapi"
+	"encoding/json"
+	"net/http"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+const MaxImportBatch = cosignapi.MaxImportBatch
+
+var ErrImportBatchTooLarge = cosignapi.ErrImportBatchTooLarge
+
+type (
+	ImportSignaturesRequest  = cosignapi.ImportSignaturesRequest
+	ImportSignatureResult    = cosignapi.ImportSignatureResult
+	ImportSignaturesResponse = cosignapi.ImportSignaturesResponse
+)
+
+// ImportSignatures checks and stores each row in turn. A rejected row does
+// not stop the rest; an email repeated within the batch is rejected as a
+// duplicate after its first use.
+func (s *Service) ImportSignatures(campaignID string, req ImportSignaturesRequest) (*ImportSignaturesResponse, error) {
+	if len(req.Signatures) > MaxImportBatch {
+		return nil, ErrImportBatchTooLarge
+	}
+
+	campaign, err := s.GetCampaign(campaignID)
+	if err != nil {
+		return nil, err
+	}
+	options, err := s.GetCampaignLocations(campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ImportSignaturesResponse{Results: make([]ImportSignatureResult, 0, len(req.Signatures))}
+	seen := make(map[string]bool, len(req.Signatures))
+	for idx, row := range req.Signatures {
+		result, err := s.importSignature(campaign, options, row, req.DryRun, seen)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Index = idx
+		if result.Error != nil {
+			response.Rejected++
+		} else {
+			response.Accepted++
+		}
+		response.Results = append(response.Results, result)
+	}
+	return response, nil
+}
+
+// importSignature returns an error only for failures that should end the
+// whole import; a row's own problems go in the result.
+func (s *Service) importSignature(
+	campaign *Campaign,
+	options []LocationOption,
+	row CreateSignatureRequest,
+	dryRun bool,
+	seen map[string]bool,
+) (ImportSignatureResult, error) {
+	reject := func(err error) (ImportSignatureResult, error) {
+		return ImportSignatureResult{Error: &APIError{Code: ErrorCode(err), Message: err.Error()}}, nil
+	}
+
+	row, err := checkSignature(row)
+	if err != nil {
+		return reject(err)
+	}
+
+	if seen[row.Email] {
+		return reject(ErrDuplicateEmail)
+	}
+	exists, err := s.store.SignatureEmailExists(campaign.ID, row.Email)
+	if err != nil {
+		return ImportSignatureResult{}, DatabaseError{Err: err}
+	}
+	if exists {
+		return reject(ErrDuplicateEmail)
+	}
+
+	location, err := resolveLocation(campaign, options, row.Location)
+	if err != nil {
+		return reject(err)
+	}
+	seen[row.Email] = true
+
+	result := ImportSignatureResult{Location: location}
+	if dryRun {
+		return result, nil
+	}
+
+	signature, err := s.insertSignature(campaign.ID, row, location)
+	if err != nil {
+		return ImportSignatureResult{}, err
+	}
+	result.ID = signature.ID
+	return result, nil
+}
+
+func (s *Service) handleImportSignatures(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
+		return
+	}
+
+	var req ImportSignaturesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "invalid request body")
+		return
+	}
+
+	response, err := s.ImportSignatures(campaignID, req)
+	if err != nil {
+		writeServiceError(w, err, "failed to import signatures")
+		return
+	}
+
+	status := http.StatusCreated
+	if req.DryRun {
+		status = http.StatusOK
+	}
+	wire.WriteData(w, status, response)
+}
@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"cosign/internal/service"
+	"cosign/internal/testutil"
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+func TestImportSignaturesDryRunAndCommit(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Import")
+
+	wire.TestPut[service.Campaign](handler, "/admin/campaigns/"+campaign.ID, `{"allow_custom_text":false}`, authHeader()).
+		ExpectStatus(t, http.StatusOK)
+	wire.TestPut[service.CampaignLocationsResponse](
+		handler,
+		"/admin/campaigns/"+campaign.ID+"/locations",
+		`{"locations":[{"value":"Brooklyn","aliases":["BK"]},{"value":"Queens"}]}`,
+		authHeader(),
+	).ExpectStatus(t, http.StatusOK)
+
+	rows := `[
+		{"name":"Ada","email":"ada@example.com","location":"bk"},
+		{"name":"Bob","email":"bob","location":"Queens"},
+		{"name":"Cy","email":"cy@example.com","location":"Berlin"},
+		{"name":"Ada again","email":"ada@example.com","location":"Queens"},
+		{"name":"","email":"dee@example.com","location":"Queens"}
+	]`
+	path := "/admin/campaigns/" + campaign.ID + "/signatures/import"
+
+	preview := wire.TestPost[service.ImportSignaturesResponse](handler, path, `{"dry_run":true,"signatures":`+rows+`}`, authHeader())
+	preview.ExpectStatus(t, http.StatusOK)
+	if preview.Data.Accepted != 1 || preview.Data.Rejected != 4 {
+		t.Fatalf("expected 1 accepted and 4 rejected, got %+v", preview.Data)
+	}
+
+	wantCodes := []string{"", service.CodeInvalidEmail, service.CodeLocationNotInOptions, service.CodeDuplicateEmail, service.CodeEmptyName}
+	for idx, result := range preview.Data.Results {
+		code := ""
+		if result.Error != nil {
+			code = result.Error.Code
+		}
+		if result.Index != idx || code != wantCodes[idx] {
+			t.Fatalf("row %d: expected code %q, got %+v", idx, wantCodes[idx], result)
+		}
+	}
+	if preview.Data.Results[0].Location != "Brooklyn" || preview.Data.Results[0].ID != 0 {
+		t.Fatalf("expected a resolved location and no id on a dry run, got %+v", preview.Data.Results[0])
+	}
+
+	list := wire.TestGet[service.Signatures](handler, "/admin/campaigns/"+campaign.ID+"/signatures", authHeader())
+	if list.Data.Total != 0 {
+		t.Fatalf("expected a dry run to store nothing, got %d signatures", list.Data.Total)
+	}
+
+	commit := wire.TestPost[service.ImportSignaturesResponse](handler, path, `{"signatures":`+rows+`}`, authHeader())
+	commit.ExpectStatus(t, http.StatusCreated)
+	if commit.Data.Accepted != 1 || commit.Data.Results[0].ID == 0 {
+		t.Fatalf("expected the valid row stored, got %+v", commit.Data)
+	}
+
+	list = wire.TestGet[service.Signatures](handler, "/admin/campaigns/"+campaign.ID+"/signatures", authHeader())
+	if list.Data.Total != 1 || list.Data.Signatures[0].Location != "Brooklyn" || list.Data.Signatures[0].LocationRaw != "bk" {
+		t.Fatalf("expected one stored signature in Brooklyn, got %+v", list.Data)
+	}
+
+	again := wire.TestPost[service.ImportSignaturesResponse](handler, path, `{"dry_run":true,"signatures":`+rows+`}`, authHeader())
+	if again.Data.Results[0].Error == nil || again.Data.Results[0].Error.Code != service.CodeDuplicateEmail {
+		t.Fatalf("expected an already stored email to be a duplicate, got %+v", again.Data.Results[0])
+	}
+}
+
+func TestImportSignaturesLimitsBatchSize(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Import")
+
+	rows := make([]string, service.MaxImportBatch+1)
+	for idx := range rows {
+		rows[idx] = fmt.Sprintf(`{"name":"Signer","email":"s%d@example.com","location":"Here"}`, idx)
+	}
+
+	tooMany := wire.TestPost[service.ImportSignaturesResponse](
+		handler,
+		"/admin/campaigns/"+campaign.ID+"/signatures/import",
+		`{"signatures":[`+strings.Join(rows, ",")+`]}`,
+		authHeader(),
+	)
+	tooMany.ExpectStatus(t, http.StatusBadRequest)
+	if tooMany.Error == nil || !strings.Contains(tooMany.Error.Message, "at most") {
+		t.Fatalf("expected batch size error, got %#v", tooMany.Error)
+	}
+
+	missing := wire.TestPost[service.ImportSignaturesResponse](
+		handler,
+		"/admin/campaigns/missing/signatures/import",
+		`{"signatures":[]}`,
+		authHeader(),
+	)
+	missing.ExpectStatus(t, http.StatusNotFound)
+}
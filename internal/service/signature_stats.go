@@ -0,0 +1,103 @@
+package service
+
+import (
+	"cmp"
+	"cosign/pkg/cosignapi"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+const (
+	DefaultStatsDays = 30
+	MaxStatsDays     = cosignapi.MaxStatsDays
+
+	topLocationsLimit = 10
+)
+
+var ErrInvalidStatsDays = cosignapi.ErrInvalidStatsDays
+
+type (
+	DailySignatureCount = cosignapi.DailySignatureCount
+	LocationCount       = cosignapi.LocationCount
+	SignatureStats      = cosignapi.SignatureStats
+)
+
+func (s *Service) GetSignatureStats(campaignID string, days int) (*SignatureStats, error) {
+	if days < 1 || days > MaxStatsDays {
+		return nil, ErrInvalidStatsDays
+	}
+	if _, err := s.GetCampaign(campaignID); err != nil {
+		return nil, err
+	}
+
+	now := s.clock().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	first := today.AddDate(0, 0, 1-days)
+
+	stats := &SignatureStats{Days: make([]DailySignatureCount, 0, days)}
+	var err error
+	if stats.Total, err = s.store.CountSignatures(campaignID, visibleSignatures); err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+	if stats.Last24h, err = s.store.CountSignaturesSince(campaignID, now.Add(-24*time.Hour).Unix()); err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+	if stats.Last7d, err = s.store.CountSignaturesSince(campaignID, now.AddDate(0, 0, -7).Unix()); err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+
+	daily, err := s.store.CountSignaturesByDay(campaignID, first.Unix())
+	if err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+	for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		stats.Days = append(stats.Days, DailySignatureCount{Date: date, Count: daily[date]})
+	}
+
+	locations, err := s.store.CountSignaturesByLocation(campaignID)
+	if err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+	stats.TopLocations = make([]LocationCount, 0, len(locations))
+	for location, count := range locations {
+		stats.TopLocations = append(stats.TopLocations, LocationCount{Location: location, Count: count})
+	}
+	slices.SortFunc(stats.TopLocations, func(a, b LocationCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Location, b.Location))
+	})
+	stats.TopLocations = stats.TopLocations[:min(len(stats.TopLocations), topLocationsLimit)]
+
+	return stats, nil
+}
+
+func (s *Service) handleGetSignatureStats(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
+		return
+	}
+
+	days := DefaultStatsDays
+	if raw := strings.TrimSpace(r.URL.Query().Get("days")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			writeServiceError(w, ErrInvalidStatsDays, "failed to get signature stats")
+			return
+		}
+		days = parsed
+	}
+
+	stats, err := s.GetSignatureStats(campaignID, days)
+	if err != nil {
+		writeServiceError(w, err, "failed to get signature stats")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, stats)
+}
@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"cosign/internal/service"
+	"cosign/internal/testutil"
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+func TestSignatureStatsCountsByWindowDayAndLocation(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	svc := testutil.SetupServiceWith(t, func(opts *service.Options) {
+		opts.Clock = func() time.Time { return now }
+	})
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Stats")
+
+	signAt := func(at time.Time, email, location string) {
+		t.Helper()
+		now = at
+		body := fmt.Sprintf(`{"name":"Signer","email":%q,"location":%q}`, email, location)
+		wire.TestPost[service.Signature](handler, "/campaigns/"+campaign.ID+"/signatures", body, originHeader("http://test-origin")).
+			ExpectStatus(t, http.StatusCreated)
+	}
+	end := now
+	signAt(end.AddDate(0, 0, -40), "old@example.com", "Queens")
+	signAt(end.AddDate(0, 0, -5), "week@example.com", "Brooklyn")
+	signAt(end.Add(-30*time.Hour), "yesterday@example.com", "Brooklyn")
+	signAt(end.Add(-2*time.Hour), "today1@example.com", "Bronx")
+	signAt(end.Add(-time.Hour), "today2@example.com", "Brooklyn")
+	now = end
+
+	stats := wire.TestGet[service.SignatureStats](handler, "/admin/campaigns/"+campaign.ID+"/signatures/stats?days=7", authHeader())
+	stats.ExpectStatus(t, http.StatusOK)
+	if stats.Data.Total != 5 || stats.Data.Last24h != 2 || stats.Data.Last7d != 4 {
+		t.Fatalf("expected totals 5/2/4, got %+v", stats.Data)
+	}
+
+	days := stats.Data.Days
+	if len(days) != 7 || days[0].Date != "2026-10-12" || days[6].Date != "2026-10-18" {
+		t.Fatalf("expected the 7 days ending today, got %+v", days)
+	}
+	want := []int{0, 1, 0, 0, 0, 1, 2}
+	for idx, day := range days {
+		if day.Count != want[idx] {
+			t.Fatalf("day %s: expected %d, got %d", day.Date, want[idx], day.Count)
+		}
+	}
+
+	top := stats.Data.TopLocations
+	if len(top) != 3 || top[0] != (service.LocationCount{Location: "Brooklyn", Count: 3}) || top[1].Location != "Bronx" {
+		t.Fatalf("expected locations by count then name, got %+v", top)
+	}
+
+	defaults := wire.TestGet[service.SignatureStats](handler, "/admin/campaigns/"+campaign.ID+"/signatures/stats", authHeader())
+	if len(defaults.Data.Days) != service.DefaultStatsDays {
+		t.Fatalf("expected %d days by default, got %d", service.DefaultStatsDays, len(defaults.Data.Days))
+	}
+
+	for _, days := range []string{"0", "366", "week"} {
+		bad := wire.TestGet[service.SignatureStats](handler, "/admin/campaigns/"+campaign.ID+"/signatures/stats?days="+days, authHeader())
+		bad.ExpectStatus(t, http.StatusBadRequest)
+		if bad.Error == nil || !strings.Contains(bad.Error.Message, "between 1 and") {
+			t.Fatalf("days=%s: expected a days error, got %#v", days, bad.Error)
+		}
+	}
+
+	wire.TestGet[service.SignatureStats](handler, "/admin/campaigns/missing/signatures/stats", authHeader()).
+		ExpectStatus(t, http.StatusNotFound)
+}
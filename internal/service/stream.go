@@ -0,0 +1,395 @@
+package service
+
+import (
+	"cosign/pkg/cosignapi"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	StreamEventCount     = cosignapi.StreamEventCount
+	StreamEventSignature = cosignapi.StreamEventSignature
+)
+
+const (
+	defaultStreamHeartbeat      = 15 * time.Second
+	defaultStreamMaxLifetime    = 30 * time.Minute
+	defaultStreamMaxPerClient   = 4
+	defaultStreamMaxPerCampaign = 1000
+	streamHistorySize           = 100
+	streamSubscriberBuffer      = 16
+	streamRetryMillis           = 5000
+
+	// streamHistoryIdle is how long a campaign's history outlives its last
+	// subscriber, so clients that lost their connection can still resume.
+	streamHistoryIdle = time.Minute
+
+	// streamWriteTimeout replaces the server's write timeout for event
+	// streams, which outlive any single response deadline; each write gets
+	// this long to reach a slow client.
+	streamWriteTimeout = 10 * time.Second
+)
+
+type StreamOptions struct {
+	Heartbeat      time.Duration
+	MaxLifetime    time.Duration
+	MaxPerClient   int
+	MaxPerCampaign int
+}
+
+type (
+	StreamCount     = cosignapi.StreamCount
+	PublicSignature = cosignapi.PublicSignature
+)
+
+type PublicSignatures struct {
+	Signatures []PublicSignature `json:"signatures"`
+	Total      int               `json:"total"`
+}
+
+type streamEvent struct {
+	ID   int64
+	Type string
+	Data any
+}
+
+type streamSubscriber chan streamEvent
+
+// broadcaster fans campaign events out to connected SSE streams. It keeps a
+// short per-campaign history so reconnecting clients can resume from their
+// Last-Event-ID, but only for campaigns with subscribers or that lost their
+// last one within streamHistoryIdle.
+type broadcaster struct {
+	mu          sync.Mutex
+	clock       func() time.Time
+	lastID      int64
+	subscribers map[string]map[streamSubscriber]struct{}
+	history     map[string][]streamEvent
+	idleSince   map[string]time.Time
+	perClient   map[string]int
+
+	// closed ends every stream when the server shuts down.
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newBroadcaster(clock func() time.Time) *broadcaster {
+	return &broadcaster{
+		clock:       clock,
+		subscribers: make(map[string]map[streamSubscriber]struct{}),
+		history:     make(map[string][]streamEvent),
+		idleSince:   make(map[string]time.Time),
+		perClient:   make(map[string]int),
+		closed:      make(chan struct{}),
+	}
+}
+
+func (b *broadcaster) close() {
+	b.closeOnce.Do(func() { close(b.closed) })
+}
+
+func (b *broadcaster) publish(campaignID, eventType string, data any) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.expireIdleHistories()
+	if _, idle := b.idleSince[campaignID]; !idle && len(b.subscribers[campaignID]) == 0 {
+		return
+	}
+
+	b.lastID++
+	event := streamEvent{ID: b.lastID, Type: eventType, Data: data}
+
+	history := append(b.history[campaignID], event)
+	if len(history) > streamHistorySize {
+		history = history[len(history)-streamHistorySize:]
+	}
+	b.history[campaignID] = history
+
+	for sub := range b.subscribers[campaignID] {
+		select {
+		case sub <- event:
+		default:
+			// slow consumers miss events; the next count event resyncs them
+		}
+	}
+}
+
+// drop forgets the campaign's history. Campaigns are dropped when they
+// are trashed; nothing publishes for a trashed campaign, so there is no
+// history left to drop when the trash is purged.
+func (b *broadcaster) drop(campaignID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.history, campaignID)
+	delete(b.idleSince, campaignID)
+}
+
+// expireIdleHistories must be called with mu held.
+func (b *broadcaster) expireIdleHistories() {
+	now := b.clock()
+	for campaignID, since := range b.idleSince {
+		if now.Sub(since) >= streamHistoryIdle {
+			delete(b.history, campaignID)
+			delete(b.idleSince, campaignID)
+		}
+	}
+}
+
+func (b *broadcaster) hasSubscribers(campaignID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers[campaignID]) > 0
+}
+
+func (b *broadcaster) subscribe(
+	campaignID string,
+	client string,
+	lastEventID int64,
+	opts StreamOptions,
+) (
+	streamSubscriber,
+	[]streamEvent,
+	int64,
+	error,
+) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.perClient[client] >= opts.MaxPerClient {
+		return nil, nil, 0, ErrTooManyStreams
+	}
+	if len(b.subscribers[campaignID]) >= opts.MaxPerCampaign {
+		return nil, nil, 0, ErrTooManyStreams
+	}
+
+	b.expireIdleHistories()
+	delete(b.idleSince, campaignID)
+
+	sub := make(streamSubscriber, streamSubscriberBuffer)
+	if b.subscribers[campaignID] == nil {
+		b.subscribers[campaignID] = make(map[streamSubscriber]struct{})
+	}
+	b.subscribers[campaignID][sub] = struct{}{}
+	b.perClient[client]++
+
+	var missed []streamEvent
+	if lastEventID >= 0 {
+		for _, event := range b.history[campaignID] {
+			if event.ID > lastEventID {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	return sub, missed, b.lastID, nil
+}
+
+func (b *broadcaster) unsubscribe(campaignID, client string, sub streamSubscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subscribers[campaignID], sub)
+	if len(b.subscribers[campaignID]) == 0 {
+		delete(b.subscribers, campaignID)
+		b.idleSince[campaignID] = b.clock()
+	}
+
+	b.perClient[client]--
+	if b.perClient[client] <= 0 {
+		delete(b.perClient, client)
+	}
+}
+
+func (s *Service) publishSignatureCreated(campaignID string, signature *Signature) {
+	s.broadcaster.publish(campaignID, StreamEventSignature, newPublicSignature(signature))
+	s.publishCount(campaignID)
+}
+
+func (s *Service) publishCount(campaignID string) {
+	if !s.broadcaster.hasSubscribers(campaignID) {
+		return
+	}
+
+	count, err := s.store.CountSignatures(campaignID, visibleSignatures)
+	if err != nil {
+		log.Printf("stream: count signatures: %v", err)
+		return
+	}
+
+	s.broadcaster.publish(campaignID, StreamEventCount, StreamCount{Count: count})
+}
+
+func newPublicSignature(signature *Signature) PublicSignature {
+	return PublicSignature{
+		Name:      publicSignerName(signature.Name),
+		Location:  signature.Location,
+		CreatedAt: signature.CreatedAt,
+	}
+}
+
+// ListPublicSignatures returns the most recent signatures in their public
+// form, newest first.
+func (s *Service) ListPublicSignatures(campaignID string, limit int) (*PublicSignatures, error) {
+	list, err := s.ListSignatures(campaignID, visibleSignatures, limit, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	public := make([]PublicSignature, 0, len(list.Signatures))
+	for _, signature := range list.Signatures {
+		public = append(public, newPublicSignature(signature))
+	}
+	return &PublicSignatures{Signatures: public, Total: list.Total}, nil
+}
+
+// publicSignerName reduces a signer name to the first name and last initial,
+// e.g. "Alice Smith" becomes "Alice S.".
+func publicSignerName(name string) string {
+	parts := strings.Fields(name)
+	if len(parts) < 2 {
+		return strings.Join(parts, "")
+	}
+
+	initial, _ := utf8.DecodeRuneInString(parts[len(parts)-1])
+	return parts[0] + " " + string(initial) + "."
+}
+
+func streamOptionsWithDefaults(opts StreamOptions) StreamOptions {
+	if opts.Heartbeat <= 0 {
+		opts.Heartbeat = defaultStreamHeartbeat
+	}
+	if opts.MaxLifetime <= 0 {
+		opts.MaxLifetime = defaultStreamMaxLifetime
+	}
+	if opts.MaxPerClient <= 0 {
+		opts.MaxPerClient = defaultStreamMaxPerClient
+	}
+	if opts.MaxPerCampaign <= 0 {
+		opts.MaxPerCampaign = defaultStreamMaxPerCampaign
+	}
+	return opts
+}
+
+// lastEventIDFromRequest returns -1 when the client is not resuming.
+func lastEventIDFromRequest(r *http.Request) int64 {
+	raw := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if raw == "" {
+		raw = strings.TrimSpace(r.URL.Query().Get("last_event_id"))
+	}
+
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 0 {
+		return -1
+	}
+	return id
+}
+
+func writeStreamEvent(w http.ResponseWriter, event streamEvent) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+func (s *Service) handleCampaignEvents(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writePublicError(w, r, http.StatusBadRequest, CodeCampaignIDRequired)
+		return
+	}
+
+	if _, err := s.GetCampaign(campaignID); err != nil {
+		switch {
+		case errors.Is(err, ErrCampaignNotFound):
+			writePublicError(w, r, http.StatusNotFound, CodeCampaignNotFound)
+		default:
+			writePublicError(w, r, http.StatusInternalServerError, CodeInternalError)
+		}
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writePublicError(w, r, http.StatusInternalServerError, CodeInternalError)
+		return
+	}
+
+	client := clientIP(r)
+	sub, missed, lastID, err := s.broadcaster.subscribe(campaignID, client, lastEventIDFromRequest(r), s.stream)
+	if err != nil {
+		writePublicError(w, r, http.StatusTooManyRequests, CodeTooManyStreams)
+		return
+	}
+	defer s.broadcaster.unsubscribe(campaignID, client, sub)
+
+	count, err := s.store.CountSignatures(campaignID, visibleSignatures)
+	if err != nil {
+		writePublicError(w, r, http.StatusInternalServerError, CodeInternalError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	controller := http.NewResponseController(w)
+	extendDeadline := func() {
+		_ = controller.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	}
+
+	extendDeadline()
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", streamRetryMillis); err != nil {
+		return
+	}
+	for _, event := range missed {
+		if err := writeStreamEvent(w, event); err != nil {
+			return
+		}
+	}
+	if err := writeStreamEvent(w, streamEvent{ID: lastID, Type: StreamEventCount, Data: StreamCount{Count: count}}); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(s.stream.Heartbeat)
+	defer heartbeat.Stop()
+	lifetime := time.NewTimer(s.stream.MaxLifetime)
+	defer lifetime.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-lifetime.C:
+			return
+		case <-s.broadcaster.closed:
+			return
+		case <-heartbeat.C:
+			extendDeadline()
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case event := <-sub:
+			extendDeadline()
+			if err := writeStreamEvent(w, event); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,239 @@
+package service_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"cosign/internal/service"
+	"cosign/internal/testutil"
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+type sseEvent struct {
+	ID   int64
+	Type string
+	Data string
+}
+
+func openEventStream(
+	t *testing.T,
+	ctx context.Context,
+	baseURL string,
+	campaignID string,
+	lastEventID string,
+) (*http.Response, <-chan sseEvent) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/campaigns/"+campaignID+"/events", nil)
+	if err != nil {
+		t.Fatalf("build stream request: %v", err)
+	}
+	req.Header.Set("Origin", "http://test-origin")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("open stream: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		t.Fatalf("expected stream status 200, got %d", res.StatusCode)
+	}
+
+	events := make(chan sseEvent, 16)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(res.Body)
+		var event sseEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if event.Type != "" {
+					events <- event
+				}
+				event = sseEvent{}
+			case strings.HasPrefix(line, "id: "):
+				event.ID, _ = strconv.ParseInt(strings.TrimPrefix(line, "id: "), 10, 64)
+			case strings.HasPrefix(line, "event: "):
+				event.Type = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				event.Data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+
+	return res, events
+}
+
+func nextEvent(t *testing.T, events <-chan sseEvent, eventType string) sseEvent {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatalf("stream closed waiting for %q event", eventType)
+			}
+			if event.Type == eventType {
+				return event
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for %q event", eventType)
+		}
+	}
+}
+
+func TestCampaignEventStreamPushesCountsAndSignatures(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	campaign := createCampaign(t, handler, "Live")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	res, events := openEventStream(t, ctx, server.URL, campaign.ID, "")
+	defer res.Body.Close()
+
+	if got := res.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("unexpected content type: %q", got)
+	}
+	if got := res.Header.Get("Access-Control-Allow-Origin"); got != "http://test-origin" {
+		t.Fatalf("expected cors header on stream, got %q", got)
+	}
+
+	initial := nextEvent(t, events, service.StreamEventCount)
+	if initial.Data != `{"count":0}` {
+		t.Fatalf("unexpected initial count: %s", initial.Data)
+	}
+
+	sign := wire.TestPost[service.Signature](
+		handler,
+		"/campaigns/"+campaign.ID+"/signatures",
+		`{"name":"Alice Smith","email":"alice@example.com","location":"NYC"}`,
+	)
+	sign.ExpectStatus(t, http.StatusCreated)
+
+	signed := nextEvent(t, events, service.StreamEventSignature)
+	if strings.Contains(signed.Data, "alice@example.com") {
+		t.Fatalf("signature event leaked email: %s", signed.Data)
+	}
+	var public service.PublicSignature
+	if err := json.Unmarshal([]byte(signed.Data), &public); err != nil {
+		t.Fatalf("decode signature event: %v", err)
+	}
+	if public.Name != "Alice S." || public.Location != "NYC" {
+		t.Fatalf("unexpected signature event: %+v", public)
+	}
+
+	count := nextEvent(t, events, service.StreamEventCount)
+	if count.Data != `{"count":1}` {
+		t.Fatalf("unexpected count after signing: %s", count.Data)
+	}
+}
+
+func TestCampaignEventStreamResumesFromLastEventID(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	campaign := createCampaign(t, handler, "Resume")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	res, events := openEventStream(t, ctx, server.URL, campaign.ID, "")
+	initial := nextEvent(t, events, service.StreamEventCount)
+	cancel()
+	res.Body.Close()
+
+	sign := wire.TestPost[service.Signature](
+		handler,
+		"/campaigns/"+campaign.ID+"/signatures",
+		`{"name":"Bob","email":"bob@example.com","location":"Boston"}`,
+	)
+	sign.ExpectStatus(t, http.StatusCreated)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	res, events = openEventStream(t, ctx, server.URL, campaign.ID, strconv.FormatInt(initial.ID, 10))
+	defer res.Body.Close()
+
+	missed := nextEvent(t, events, service.StreamEventSignature)
+	if missed.ID <= initial.ID || !strings.Contains(missed.Data, `"name":"Bob"`) {
+		t.Fatalf("expected missed signature event after %d, got %+v", initial.ID, missed)
+	}
+
+	count := nextEvent(t, events, service.StreamEventCount)
+	if count.Data != `{"count":1}` {
+		t.Fatalf("unexpected resumed count: %s", count.Data)
+	}
+}
+
+func TestCampaignEventStreamKeepsHistoryOnlyWhileWatched(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	campaign := createCampaign(t, handler, "Unwatched")
+	signBulkCampaign(t, handler, campaign.ID, "ada@example.org")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	res, events := openEventStream(t, ctx, server.URL, campaign.ID, "0")
+	defer res.Body.Close()
+	if first := <-events; first.Type != service.StreamEventCount || first.Data != `{"count":1}` {
+		t.Fatalf("expected no history for a campaign nobody watched, got %+v", first)
+	}
+
+	signBulkCampaign(t, handler, campaign.ID, "bob@example.org")
+	signed := nextEvent(t, events, service.StreamEventSignature)
+
+	wire.TestDelete[struct{}](handler, "/admin/campaigns/"+campaign.ID, authHeader()).ExpectStatus(t, http.StatusNoContent)
+	wire.TestPost[service.Campaign](handler, "/admin/campaigns/"+campaign.ID+"/restore", "", authHeader()).ExpectStatus(t, http.StatusOK)
+
+	res, events = openEventStream(t, ctx, server.URL, campaign.ID, strconv.FormatInt(signed.ID-1, 10))
+	defer res.Body.Close()
+	if first := <-events; first.Type != service.StreamEventCount || first.Data != `{"count":2}` {
+		t.Fatalf("expected the history dropped with the trashed campaign, got %+v", first)
+	}
+}
+
+func TestCampaignEventStreamLimitsConnectionsPerClient(t *testing.T) {
+	svc := testutil.SetupServiceWith(t, func(opts *service.Options) {
+		opts.Stream = service.StreamOptions{MaxPerClient: 1}
+	})
+	handler := svc.BuildRouter()
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	campaign := createCampaign(t, handler, "Limited")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	res, events := openEventStream(t, ctx, server.URL, campaign.ID, "")
+	defer res.Body.Close()
+	nextEvent(t, events, service.StreamEventCount)
+
+	second, err := http.Get(server.URL + "/campaigns/" + campaign.ID + "/events")
+	if err != nil {
+		t.Fatalf("open second stream: %v", err)
+	}
+	defer second.Body.Close()
+	if second.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected second stream to be rejected, got %d", second.StatusCode)
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+type inProcessKey struct{}
+
+// inProcessRemoteAddr is the RemoteAddr seen by handlers for requests made
+// through Transport.
+const inProcessRemoteAddr = "in-process"
+
+func isInProcess(ctx context.Context) bool {
+	trusted, _ := ctx.Value(inProcessKey{}).(bool)
+	return trusted
+}
+
+// withAuth is keys.WithAuth, except that requests made through Transport
+// are already trusted and skip the API key check.
+func (s *Service) withAuth(next http.HandlerFunc) http.HandlerFunc {
+	authed := s.keys.WithAuth(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isInProcess(r.Context()) {
+			next(w, r)
+			return
+		}
+		authed(w, r)
+	}
+}
+
+// Transport serves requests with the API router directly, without a
+// network hop or an API key. URLs are routed relative to the API prefix,
+// so clients should use a base URL with no path, e.g. "http://cosign".
+// It is meant for embedding trusted callers like the dashboard in the
+// same process.
+func (s *Service) Transport() http.RoundTripper {
+	return &inProcessTransport{handler: s.BuildRouter()}
+}
+
+type inProcessTransport struct {
+	handler http.Handler
+}
+
+func (t *inProcessTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := context.WithValue(req.Context(), inProcessKey{}, true)
+	r := req.Clone(ctx)
+	r.RequestURI = r.URL.RequestURI()
+	r.RemoteAddr = inProcessRemoteAddr
+	if r.Host == "" {
+		r.Host = r.URL.Host
+	}
+	if r.Body == nil {
+		r.Body = http.NoBody
+	}
+
+	pr, pw := io.Pipe()
+	w := &pipeResponseWriter{
+		header: http.Header{},
+		body:   pw,
+		ready:  make(chan struct{}),
+	}
+
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				pw.CloseWithError(fmt.Errorf("handler panic: %v", v))
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			pw.Close()
+		}()
+		t.handler.ServeHTTP(w, r)
+	}()
+
+	select {
+	case <-w.ready:
+	case <-ctx.Done():
+		pr.CloseWithError(ctx.Err())
+		return nil, ctx.Err()
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", w.status, http.StatusText(w.status)),
+		StatusCode:    w.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        w.sent,
+		Body:          pr,
+		ContentLength: -1,
+		Request:       req,
+	}, nil
+}
+
+// pipeResponseWriter streams a handler's response body to the caller as it
+// is written, so event streams work through Transport.
+type pipeResponseWriter struct {
+	header http.Header
+	body   *io.PipeWriter
+
+	once   sync.Once
+	ready  chan struct{}
+	status int
+	sent   http.Header
+}
+
+func (w *pipeResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *pipeResponseWriter) WriteHeader(status int) {
+	w.once.Do(func() {
+		w.status = status
+		w.sent = w.header.Clone()
+		close(w.ready)
+	})
+}
+
+func (w *pipeResponseWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(p)
+}
+
+// Flush is a no-op: writes reach the caller as soon as they are read.
+func (w *pipeResponseWriter) Flush() {}
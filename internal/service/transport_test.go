@@ -0,0 +1,64 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"cosign/internal/testutil"
+	"cosign/pkg/cosignclient"
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+func TestTransportTrustsInProcessCallers(t *testing.T) {
+	svc := testutil.SetupService(t)
+	client := cosignclient.New(cosignclient.Options{
+		BaseURL:    "http://cosign",
+		HTTPClient: &http.Client{Transport: svc.Transport()},
+	})
+	ctx := context.Background()
+
+	campaign, err := client.CreateCampaign(ctx, "In Process")
+	if err != nil {
+		t.Fatalf("create campaign: %v", err)
+	}
+
+	got, err := client.GetCampaign(ctx, campaign.ID)
+	if err != nil {
+		t.Fatalf("get campaign: %v", err)
+	}
+	if got.Name != "In Process" {
+		t.Fatalf("expected campaign name %q, got %q", "In Process", got.Name)
+	}
+
+	if _, err := client.GetCampaign(ctx, "missing"); !errors.Is(err, cosignclient.ErrCampaignNotFound) {
+		t.Fatalf("expected campaign not found, got %v", err)
+	}
+
+	// the trust comes from the transport, not from the router
+	result := wire.TestGet[any](svc.BuildRouter(), "/admin/campaigns/"+campaign.ID)
+	result.ExpectStatus(t, http.StatusUnauthorized)
+}
+
+func TestTransportStreamsEvents(t *testing.T) {
+	svc := testutil.SetupService(t)
+	campaign := createCampaign(t, svc.BuildRouter(), "Live")
+	client := cosignclient.New(cosignclient.Options{
+		BaseURL:    "http://cosign",
+		HTTPClient: &http.Client{Transport: svc.Transport()},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for event, err := range client.Events(ctx, campaign.ID, "") {
+		if err != nil {
+			t.Fatalf("stream events: %v", err)
+		}
+		if event.Type != cosignclient.StreamEventCount {
+			t.Fatalf("expected initial %q event, got %q", cosignclient.StreamEventCount, event.Type)
+		}
+		break
+	}
+}
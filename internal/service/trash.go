@@ -0,0 +1,233 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+const (
+	defaultTrashRetention = 30 * 24 * time.Hour
+	trashSweepInterval    = time.Hour
+)
+
+// TrashPurge counts what one sweep removed from the trash for good.
+type TrashPurge struct {
+	Campaigns  int `json:"campaigns"`
+	Signatures int `json:"signatures"`
+}
+
+func (s *Service) purgeAt(deletedAt int64) int64 {
+	return time.Unix(deletedAt, 0).Add(s.trashRetention).Unix()
+}
+
+func (s *Service) ListDeletedCampaigns(limit, offset int) (*Campaigns, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	campaigns, err := s.store.ListDeletedCampaigns(limit, offset)
+	if err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+	for _, campaign := range campaigns {
+		campaign.PurgeAt = s.purgeAt(campaign.DeletedAt)
+	}
+
+	total, err := s.store.CountDeletedCampaigns()
+	if err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+
+	return &Campaigns{
+		Campaigns: campaigns,
+		Total:     total,
+		Limit:     limit,
+		Offset:    offset,
+	}, nil
+}
+
+// RestoreCampaign takes the campaign out of the trash, along with every
+// signature that was not deleted on its own.
+func (s *Service) RestoreCampaign(id string) (*Campaign, error) {
+	if err := s.store.RestoreCampaign(id); err != nil {
+		if errors.Is(err, ErrCampaignNotFound) {
+			return nil, err
+		}
+		return nil, DatabaseError{Err: err}
+	}
+
+	s.badges.invalidate(id)
+	s.enqueueMilestoneEvent(id)
+
+	return s.GetCampaign(id)
+}
+
+func (s *Service) ListDeletedSignatures(campaignID string, limit, offset int) (*Signatures, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	if _, err := s.GetCampaign(campaignID); err != nil {
+		return nil, err
+	}
+
+	list, err := s.store.ListDeletedSignatures(campaignID, limit, offset)
+	if err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+	for _, signature := range list {
+		signature.PurgeAt = s.purgeAt(signature.DeletedAt)
+	}
+
+	total, err := s.store.CountDeletedSignatures(campaignID)
+	if err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+
+	return &Signatures{
+		Signatures: list,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+	}, nil
+}
+
+// RestoreSignature takes the signature out of the trash, unless its email
+// has signed the campaign again since.
+func (s *Service) RestoreSignature(campaignID string, id int64) (*Signature, error) {
+	if _, err := s.GetCampaign(campaignID); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.RestoreSignature(campaignID, id); err != nil {
+		if errors.Is(err, ErrSignatureNotFound) || errors.Is(err, ErrDuplicateEmail) {
+			return nil, err
+		}
+		return nil, DatabaseError{Err: err}
+	}
+
+	signature, err := s.GetSignature(campaignID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.badges.invalidate(campaignID)
+	s.publishCount(campaignID)
+	s.enqueueWebhookEvent(campaignID, EventSignatureCreated, signature)
+	s.enqueueMilestoneEvent(campaignID)
+
+	return signature, nil
+}
+
+// PurgeTrash permanently removes whatever has been in the trash longer
+// than the retention. The trash sweeper calls it on an interval.
+func (s *Service) PurgeTrash() (*TrashPurge, error) {
+	before := s.clock().Add(-s.trashRetention).Unix()
+	campaigns, signatures, err := s.store.PurgeDeleted(before)
+	if err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+	return &TrashPurge{Campaigns: campaigns, Signatures: signatures}, nil
+}
+
+func (s *Service) runTrashSweeper(stop <-chan struct{}) {
+	ticker := time.NewTicker(trashSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if _, err := s.PurgeTrash(); err != nil {
+				log.Printf("trash: purge: %v", err)
+			}
+		}
+	}
+}
+
+func (s *Service) handleListDeletedCampaigns(w http.ResponseWriter, r *http.Request) {
+	limit, offset, malformed := wire.ParsePagination(r)
+	if malformed != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidPagination, malformed.Error())
+		return
+	}
+
+	campaigns, err := s.ListDeletedCampaigns(limit, offset)
+	if err != nil {
+		writeServiceError(w, err, "failed to list deleted campaigns")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, campaigns)
+}
+
+func (s *Service) handleRestoreCampaign(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
+		return
+	}
+
+	campaign, err := s.RestoreCampaign(campaignID)
+	if err != nil {
+		writeServiceError(w, err, "failed to restore campaign")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, campaign)
+}
+
+func (s *Service) handleListDeletedSignatures(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
+		return
+	}
+
+	limit, offset, malformed := wire.ParsePagination(r)
+	if malformed != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidPagination, malformed.Error())
+		return
+	}
+
+	signatures, err := s.ListDeletedSignatures(campaignID, limit, offset)
+	if err != nil {
+		writeServiceError(w, err, "failed to list deleted signatures")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, signatures)
+}
+
+func (s *Service) handleRestoreSignature(w http.ResponseWriter, r *http.Request) {
+	campaignID := campaignIDFromPath(r)
+	if campaignID == "" {
+		writeError(w, http.StatusBadRequest, CodeCampaignIDRequired, "campaign id required")
+		return
+	}
+
+	signatureID, err := signatureIDFromPath(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidID, "invalid signature id")
+		return
+	}
+
+	signature, err := s.RestoreSignature(campaignID, signatureID)
+	if err != nil {
+		writeServiceError(w, err, "failed to restore signature")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, signature)
+}
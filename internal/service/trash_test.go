@@ -0,0 +1,130 @@
+package service_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"cosign/internal/service"
+	"cosign/internal/testutil"
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+func TestDeletedSignaturesMoveToTrashAndRestore(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	svc := testutil.SetupServiceWith(t, func(opts *service.Options) {
+		opts.Clock = func() time.Time { return now }
+		opts.TrashRetention = 48 * time.Hour
+	})
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Trash")
+	ids := signBulkCampaign(t, handler, campaign.ID, "ada@example.org", "bob@example.org")
+	admin := "/admin/campaigns/" + campaign.ID + "/signatures"
+	ada := fmt.Sprintf("/%d", ids["ada@example.org"])
+
+	wire.TestDelete[struct{}](handler, admin+ada, authHeader()).ExpectStatus(t, http.StatusNoContent)
+	wire.TestDelete[struct{}](handler, admin+ada, authHeader()).ExpectStatus(t, http.StatusNotFound)
+
+	list := wire.TestGet[service.Signatures](handler, admin, authHeader())
+	if list.Data.Total != 1 || list.Data.Signatures[0].Email != "bob@example.org" {
+		t.Fatalf("expected the deleted signature left out, got %+v", list.Data)
+	}
+
+	trash := wire.TestGet[service.Signatures](handler, admin+"/trash", authHeader())
+	trash.ExpectStatus(t, http.StatusOK)
+	if trash.Data.Total != 1 {
+		t.Fatalf("expected one signature in the trash, got %+v", trash.Data)
+	}
+	if got := trash.Data.Signatures[0]; got.DeletedAt != now.Unix() || got.PurgeAt != now.Add(48*time.Hour).Unix() {
+		t.Fatalf("expected deletion and purge times, got %+v", got)
+	}
+
+	restored := wire.TestPost[service.Signature](handler, admin+ada+"/restore", "", authHeader())
+	restored.ExpectStatus(t, http.StatusOK)
+	if restored.Data.Email != "ada@example.org" || restored.Data.DeletedAt != 0 {
+		t.Fatalf("expected the restored signature, got %+v", restored.Data)
+	}
+	wire.TestPost[service.Signature](handler, admin+ada+"/restore", "", authHeader()).ExpectStatus(t, http.StatusNotFound)
+
+	wire.TestDelete[struct{}](handler, admin+ada, authHeader()).ExpectStatus(t, http.StatusNoContent)
+	again := signBulkCampaign(t, handler, campaign.ID, "ada@example.org")
+	if again["ada@example.org"] == ids["ada@example.org"] {
+		t.Fatalf("expected signing again to start a new signature")
+	}
+	if trash := wire.TestGet[service.Signatures](handler, admin+"/trash", authHeader()); trash.Data.Total != 1 {
+		t.Fatalf("expected the trashed signature kept after signing again, got %+v", trash.Data)
+	}
+	conflict := wire.TestPost[service.Signature](handler, admin+ada+"/restore", "", authHeader())
+	conflict.ExpectStatus(t, http.StatusConflict)
+	if apiErr := decodeAPIError(t, conflict.Raw); apiErr.Code != service.CodeDuplicateEmail {
+		t.Fatalf("expected a duplicate email conflict, got %+v", apiErr)
+	}
+}
+
+func TestDeletedCampaignsMoveToTrashAndRestore(t *testing.T) {
+	handler := testutil.SetupService(t).BuildRouter()
+	campaign := createCampaign(t, handler, "Trash")
+	signBulkCampaign(t, handler, campaign.ID, "ada@example.org", "bob@example.org")
+
+	wire.TestDelete[struct{}](handler, "/admin/campaigns/"+campaign.ID, authHeader()).ExpectStatus(t, http.StatusNoContent)
+
+	wire.TestGet[service.Campaign](handler, "/admin/campaigns/"+campaign.ID, authHeader()).ExpectStatus(t, http.StatusNotFound)
+	wire.TestGet[service.Campaign](handler, "/campaigns/"+campaign.ID).ExpectStatus(t, http.StatusNotFound)
+	if list := wire.TestGet[service.Campaigns](handler, "/admin/campaigns", authHeader()); list.Data.Total != 0 {
+		t.Fatalf("expected the deleted campaign left out, got %+v", list.Data)
+	}
+
+	trash := wire.TestGet[service.Campaigns](handler, "/admin/campaigns/trash", authHeader())
+	trash.ExpectStatus(t, http.StatusOK)
+	if trash.Data.Total != 1 || trash.Data.Campaigns[0].ID != campaign.ID || trash.Data.Campaigns[0].PurgeAt == 0 {
+		t.Fatalf("expected the campaign in the trash, got %+v", trash.Data)
+	}
+
+	restored := wire.TestPost[service.Campaign](handler, "/admin/campaigns/"+campaign.ID+"/restore", "", authHeader())
+	restored.ExpectStatus(t, http.StatusOK)
+	if restored.Data.DeletedAt != 0 {
+		t.Fatalf("expected the restored campaign out of the trash, got %+v", restored.Data)
+	}
+	wire.TestPost[service.Campaign](handler, "/admin/campaigns/"+campaign.ID+"/restore", "", authHeader()).ExpectStatus(t, http.StatusNotFound)
+
+	list := wire.TestGet[service.Signatures](handler, "/admin/campaigns/"+campaign.ID+"/signatures", authHeader())
+	if list.Data.Total != 2 {
+		t.Fatalf("expected the signatures back with the campaign, got %+v", list.Data)
+	}
+}
+
+func TestPurgeTrashRemovesExpiredItems(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	svc := testutil.SetupServiceWith(t, func(opts *service.Options) {
+		opts.Clock = func() time.Time { return now }
+		opts.TrashRetention = 24 * time.Hour
+	})
+	handler := svc.BuildRouter()
+	kept := createCampaign(t, handler, "Kept")
+	ids := signBulkCampaign(t, handler, kept.ID, "ada@example.org", "bob@example.org")
+	trashed := createCampaign(t, handler, "Trashed")
+	signBulkCampaign(t, handler, trashed.ID, "cy@example.org")
+
+	wire.TestDelete[struct{}](handler, fmt.Sprintf("/admin/campaigns/%s/signatures/%d", kept.ID, ids["ada@example.org"]), authHeader()).
+		ExpectStatus(t, http.StatusNoContent)
+	wire.TestDelete[struct{}](handler, "/admin/campaigns/"+trashed.ID, authHeader()).ExpectStatus(t, http.StatusNoContent)
+
+	now = now.Add(23 * time.Hour)
+	if purged, err := svc.PurgeTrash(); err != nil || purged.Campaigns != 0 || purged.Signatures != 0 {
+		t.Fatalf("expected nothing purged within the retention, got %+v, %v", purged, err)
+	}
+
+	now = now.Add(time.Hour)
+	purged, err := svc.PurgeTrash()
+	if err != nil || purged.Campaigns != 1 || purged.Signatures != 1 {
+		t.Fatalf("expected one campaign and one signature purged, got %+v, %v", purged, err)
+	}
+
+	wire.TestPost[service.Campaign](handler, "/admin/campaigns/"+trashed.ID+"/restore", "", authHeader()).ExpectStatus(t, http.StatusNotFound)
+	wire.TestPost[service.Signature](handler, fmt.Sprintf("/admin/campaigns/%s/signatures/%d/restore", kept.ID, ids["ada@example.org"]), "", authHeader()).
+		ExpectStatus(t, http.StatusNotFound)
+	if list := wire.TestGet[service.Signatures](handler, "/admin/campaigns/"+kept.ID+"/signatures", authHeader()); list.Data.Total != 1 {
+		t.Fatalf("expected the kept signature untouched, got %+v", list.Data)
+	}
+}
@@ -0,0 +1,556 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"cosign/pkg/cosignapi"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+const (
+	EventSignatureCreated = cosignapi.EventSignatureCreated
+	EventSignatureDeleted = cosignapi.EventSignatureDeleted
+	EventCampaignUpdated  = cosignapi.EventCampaignUpdated
+	EventMilestoneReached = cosignapi.EventMilestoneReached
+)
+
+const (
+	DeliveryStatusPending   = cosignapi.DeliveryStatusPending
+	DeliveryStatusSucceeded = cosignapi.DeliveryStatusSucceeded
+	DeliveryStatusDead      = cosignapi.DeliveryStatusDead
+)
+
+const (
+	webhookTimeout      = 10 * time.Second
+	webhookPollInterval = 2 * time.Second
+	webhookBatchSize    = 50
+	webhookMaxAttempts  = 8
+	webhookRetryBase    = 30 * time.Second
+	webhookRetryMax     = time.Hour
+)
+
+var webhookEvents = []string{
+	EventSignatureCreated,
+	EventSignatureDeleted,
+	EventCampaignUpdated,
+	EventMilestoneReached,
+}
+
+var signatureMilestones = []int{10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000, 25000, 50000, 100000}
+
+type (
+	Webhook              = cosignapi.Webhook
+	Webhooks             = cosignapi.Webhooks
+	WebhookDelivery      = cosignapi.WebhookDelivery
+	WebhookDeliveries    = cosignapi.WebhookDeliveries
+	WebhookEvent         = cosignapi.WebhookEvent
+	CreateWebhookRequest = cosignapi.CreateWebhookRequest
+)
+
+type MilestoneReached struct {
+	Milestone int `json:"milestone"`
+	Count     int `json:"count"`
+}
+
+// SignWebhookPayload returns the value sent in the X-Cosign-Signature header:
+// a hex HMAC-SHA256 over "{timestamp}.{body}" keyed with the webhook secret.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Service) buildAdminWebhooksRouter(mux *routeMux, _ Middleware) {
+	webhooksMux := mux.subrouter("/webhooks")
+	webhooksMux.HandleFunc("GET /{$}", s.handleListWebhooks)
+	webhooksMux.HandleFunc("POST /{$}", s.handleCreateWebhook)
+	webhooksMux.HandleFunc("GET /{webhook_id}", s.handleGetWebhook)
+	webhooksMux.HandleFunc("DELETE /{webhook_id}", s.handleDeleteWebhook)
+	webhooksMux.HandleFunc("GET /{webhook_id}/deliveries", s.handleListWebhookDeliveries)
+	webhooksMux.HandleFunc("POST /{webhook_id}/deliveries/{delivery_id}/replay", s.handleReplayWebhookDelivery)
+
+	mountSubrouter(mux, "/webhooks", webhooksMux)
+}
+
+func (s *Service) CreateWebhook(req CreateWebhookRequest) (*Webhook, error) {
+	campaignID := strings.TrimSpace(req.CampaignID)
+	target := strings.TrimSpace(req.URL)
+
+	parsed, err := url.Parse(target)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, ErrInvalidWebhookURL
+	}
+
+	events := make([]string, 0, len(req.Events))
+	for _, event := range req.Events {
+		event = strings.TrimSpace(event)
+		if !slices.Contains(webhookEvents, event) {
+			return nil, ErrInvalidWebhookEvent
+		}
+		if !slices.Contains(events, event) {
+			events = append(events, event)
+		}
+	}
+	if len(events) == 0 {
+		return nil, ErrEmptyWebhookEvents
+	}
+
+	if campaignID != "" {
+		if _, err := s.GetCampaign(campaignID); err != nil {
+			return nil, err
+		}
+	}
+
+	id, err := randomID(8)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := strings.TrimSpace(req.Secret)
+	if secret == "" {
+		secret, err = randomID(32)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	webhook := Webhook{
+		ID:         id,
+		CampaignID: campaignID,
+		URL:        target,
+		Secret:     secret,
+		Events:     events,
+		CreatedAt:  s.clock().Unix(),
+	}
+	if err := s.store.InsertWebhook(webhook); err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+
+	return &webhook, nil
+}
+
+func (s *Service) GetWebhook(id string) (*Webhook, error) {
+	webhook, err := s.store.GetWebhook(id)
+	if err != nil {
+		if errors.Is(err, ErrWebhookNotFound) {
+			return nil, err
+		}
+		return nil, DatabaseError{Err: err}
+	}
+	return webhook, nil
+}
+
+func (s *Service) ListWebhooks(campaignID string) (*Webhooks, error) {
+	webhooks, err := s.store.ListWebhooks(campaignID)
+	if err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+	if webhooks == nil {
+		webhooks = []*Webhook{}
+	}
+	return &Webhooks{Webhooks: webhooks}, nil
+}
+
+func (s *Service) DeleteWebhook(id string) error {
+	err := s.store.DeleteWebhook(id)
+	if err != nil {
+		if errors.Is(err, ErrWebhookNotFound) {
+			return err
+		}
+		return DatabaseError{Err: err}
+	}
+	return nil
+}
+
+func (s *Service) ListWebhookDeliveries(webhookID, status string, limit, offset int) (*WebhookDeliveries, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	if _, err := s.GetWebhook(webhookID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.store.ListWebhookDeliveries(webhookID, status, limit, offset)
+	if err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+
+	total, err := s.store.CountWebhookDeliveries(webhookID, status)
+	if err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+
+	return &WebhookDeliveries{
+		Deliveries: deliveries,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+	}, nil
+}
+
+func (s *Service) ReplayWebhookDelivery(webhookID string, id int64) (*WebhookDelivery, error) {
+	delivery, err := s.store.GetWebhookDelivery(webhookID, id)
+	if err != nil {
+		if errors.Is(err, ErrWebhookDeliveryNotFound) {
+			return nil, err
+		}
+		return nil, DatabaseError{Err: err}
+	}
+
+	now := s.clock().Unix()
+	delivery.Status = DeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = now
+	delivery.UpdatedAt = now
+	if err := s.store.UpdateWebhookDelivery(*delivery); err != nil {
+		return nil, DatabaseError{Err: err}
+	}
+
+	return delivery, nil
+}
+
+// DeliverWebhooks sends every pending delivery that is due and returns how
+// many were attempted. The background worker calls it on an interval. It
+// stops between deliveries once ctx ends, and a send that ctx cancels is
+// left pending without counting as an attempt.
+func (s *Service) DeliverWebhooks(ctx context.Context) (int, error) {
+	attempted := 0
+	for {
+		due, err := s.store.ListDueWebhookDeliveries(s.clock().Unix(), webhookBatchSize)
+		if err != nil {
+			return attempted, DatabaseError{Err: err}
+		}
+
+		for _, delivery := range due {
+			if err := ctx.Err(); err != nil {
+				return attempted, err
+			}
+			if err := s.attemptWebhookDelivery(ctx, delivery); err != nil {
+				return attempted, err
+			}
+			attempted++
+		}
+
+		if len(due) < webhookBatchSize {
+			return attempted, nil
+		}
+	}
+}
+
+func (s *Service) runWebhookWorker(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.DeliverWebhooks(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("webhooks: deliver: %v", err)
+			}
+		}
+	}
+}
+
+func (s *Service) attemptWebhookDelivery(ctx context.Context, delivery *WebhookDelivery) error {
+	now := s.clock()
+	delivery.Attempts++
+	delivery.UpdatedAt = now.Unix()
+
+	webhook, err := s.store.GetWebhook(delivery.WebhookID)
+	if err != nil {
+		if !errors.Is(err, ErrWebhookNotFound) {
+			return DatabaseError{Err: err}
+		}
+		delivery.Status = DeliveryStatusDead
+		delivery.LastError = err.Error()
+		return s.saveWebhookDelivery(delivery)
+	}
+
+	statusCode, sendErr := s.sendWebhook(ctx, webhook, delivery, now.Unix())
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	delivery.LastStatusCode = statusCode
+
+	switch {
+	case sendErr == nil:
+		delivery.Status = DeliveryStatusSucceeded
+		delivery.LastError = ""
+	case delivery.Attempts >= webhookMaxAttempts:
+		delivery.Status = DeliveryStatusDead
+		delivery.LastError = sendErr.Error()
+	default:
+		delivery.Status = DeliveryStatusPending
+		delivery.LastError = sendErr.Error()
+		delivery.NextAttemptAt = now.Add(webhookBackoff(delivery.Attempts)).Unix()
+	}
+
+	return s.saveWebhookDelivery(delivery)
+}
+
+func (s *Service) saveWebhookDelivery(delivery *WebhookDelivery) error {
+	if err := s.store.UpdateWebhookDelivery(*delivery); err != nil {
+		return DatabaseError{Err: err}
+	}
+	return nil
+}
+
+func (s *Service) sendWebhook(
+	ctx context.Context,
+	webhook *Webhook,
+	delivery *WebhookDelivery,
+	timestamp int64,
+) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cosign-webhooks")
+	req.Header.Set("X-Cosign-Event", delivery.Event)
+	req.Header.Set("X-Cosign-Delivery", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Cosign-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Cosign-Signature", SignWebhookPayload(webhook.Secret, timestamp, delivery.Payload))
+
+	res, err := s.webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("receiver returned %s", res.Status)
+	}
+
+	return res.StatusCode, nil
+}
+
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookRetryBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= webhookRetryMax {
+			return webhookRetryMax
+		}
+	}
+	return delay
+}
+
+func (s *Service) eventWebhooks(campaignID, event string) []*Webhook {
+	webhooks, err := s.store.ListCampaignEventWebhooks(campaignID)
+	if err != nil {
+		log.Printf("webhooks: list subscribers for %s: %v", event, err)
+		return nil
+	}
+
+	subscribed := make([]*Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		if slices.Contains(webhook.Events, event) {
+			subscribed = append(subscribed, webhook)
+		}
+	}
+	return subscribed
+}
+
+// enqueueWebhookEvent records one delivery per subscribed webhook. Failures
+// are logged rather than returned so that webhooks never block the write that
+// triggered them.
+func (s *Service) enqueueWebhookEvent(campaignID, event string, data any) {
+	webhooks := s.eventWebhooks(campaignID, event)
+	if len(webhooks) == 0 {
+		return
+	}
+
+	now := s.clock().Unix()
+	payload, err := json.Marshal(WebhookEvent{
+		Event:      event,
+		CampaignID: campaignID,
+		CreatedAt:  now,
+		Data:       data,
+	})
+	if err != nil {
+		log.Printf("webhooks: encode %s payload: %v", event, err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		if _, err := s.store.InsertWebhookDelivery(webhook.ID, event, string(payload), now); err != nil {
+			log.Printf("webhooks: enqueue %s for %s: %v", event, webhook.ID, err)
+		}
+	}
+}
+
+// enqueueMilestoneEvent announces every milestone the visible count has
+// reached, once per campaign and milestone, so a count that jumps past a
+// milestone still announces it and losing and regaining signatures around
+// one does not announce it again. Every write that can raise the visible
+// count calls it.
+func (s *Service) enqueueMilestoneEvent(campaignID string) {
+	if len(s.eventWebhooks(campaignID, EventMilestoneReached)) == 0 {
+		return
+	}
+
+	count, err := s.store.CountSignatures(campaignID, visibleSignatures)
+	if err != nil {
+		log.Printf("webhooks: count signatures for milestone: %v", err)
+		return
+	}
+
+	now := s.clock().Unix()
+	for _, milestone := range signatureMilestones {
+		if milestone > count {
+			break
+		}
+
+		first, err := s.store.RecordMilestone(campaignID, milestone, now)
+		if err != nil {
+			log.Printf("webhooks: record milestone: %v", err)
+			return
+		}
+		if first {
+			s.enqueueWebhookEvent(campaignID, EventMilestoneReached, MilestoneReached{
+				Milestone: milestone,
+				Count:     count,
+			})
+		}
+	}
+}
+
+func redactWebhookSecret(webhook *Webhook) *Webhook {
+	redacted := *webhook
+	redacted.Secret = ""
+	return &redacted
+}
+
+func (s *Service) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidRequest, "invalid request body")
+		return
+	}
+
+	webhook, err := s.CreateWebhook(req)
+	if err != nil {
+		writeServiceError(w, err, "failed to create webhook")
+		return
+	}
+
+	wire.WriteData(w, http.StatusCreated, webhook)
+}
+
+func (s *Service) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	campaignID := strings.TrimSpace(r.URL.Query().Get("campaign_id"))
+
+	webhooks, err := s.ListWebhooks(campaignID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternalError, "failed to list webhooks")
+		return
+	}
+
+	for idx, webhook := range webhooks.Webhooks {
+		webhooks.Webhooks[idx] = redactWebhookSecret(webhook)
+	}
+
+	wire.WriteData(w, http.StatusOK, webhooks)
+}
+
+func (s *Service) handleGetWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID := webhookIDFromPath(r)
+	if webhookID == "" {
+		writeError(w, http.StatusBadRequest, CodeInvalidID, "webhook id required")
+		return
+	}
+
+	webhook, err := s.GetWebhook(webhookID)
+	if err != nil {
+		writeServiceError(w, err, "failed to load webhook")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, redactWebhookSecret(webhook))
+}
+
+func (s *Service) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID := webhookIDFromPath(r)
+	if webhookID == "" {
+		writeError(w, http.StatusBadRequest, CodeInvalidID, "webhook id required")
+		return
+	}
+
+	if err := s.DeleteWebhook(webhookID); err != nil {
+		writeServiceError(w, err, "failed to delete webhook")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Service) handleListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhookID := webhookIDFromPath(r)
+	if webhookID == "" {
+		writeError(w, http.StatusBadRequest, CodeInvalidID, "webhook id required")
+		return
+	}
+
+	limit, offset, malformed := wire.ParsePagination(r)
+	if malformed != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidPagination, malformed.Error())
+		return
+	}
+
+	status := strings.TrimSpace(r.URL.Query().Get("status"))
+
+	deliveries, err := s.ListWebhookDeliveries(webhookID, status, limit, offset)
+	if err != nil {
+		writeServiceError(w, err, "failed to list webhook deliveries")
+		return
+	}
+
+	wire.WriteData(w, http.StatusOK, deliveries)
+}
+
+func (s *Service) handleReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	webhookID := webhookIDFromPath(r)
+	if webhookID == "" {
+		writeError(w, http.StatusBadRequest, CodeInvalidID, "webhook id required")
+		return
+	}
+
+	deliveryID, err := deliveryIDFromPath(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidID, "invalid delivery id")
+		return
+	}
+
+	delivery, err := s.ReplayWebhookDelivery(webhookID, deliveryID)
+	if err != nil {
+		writeServiceError(w, err, "failed to replay webhook delivery")
+		return
+	}
+
+	wire.WriteData(w, http.StatusAccepted, delivery)
+}
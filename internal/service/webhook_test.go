@@ -0,0 +1,290 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"cosign/internal/service"
+	"cosign/internal/testutil"
+	"git.sr.ht/~jakintosh/command-go/pkg/wire"
+)
+
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rcv *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	rcv.requests = append(rcv.requests, r)
+	rcv.bodies = append(rcv.bodies, body)
+	w.WriteHeader(rcv.status)
+}
+
+func (rcv *webhookReceiver) setStatus(status int) {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	rcv.status = status
+}
+
+func (rcv *webhookReceiver) count() int {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	return len(rcv.requests)
+}
+
+func createWebhook(t *testing.T, handler http.Handler, campaignID, url string, events ...string) service.Webhook {
+	t.Helper()
+
+	eventsJSON, _ := json.Marshal(events)
+	body := fmt.Sprintf(`{"campaign_id":%q,"url":%q,"events":%s}`, campaignID, url, eventsJSON)
+	result := wire.TestPost[service.Webhook](handler, "/admin/webhooks", body, authHeader())
+	result.ExpectStatus(t, http.StatusCreated)
+	if result.Data.Secret == "" {
+		t.Fatalf("expected webhook secret in create response")
+	}
+
+	return result.Data
+}
+
+func TestWebhookDeliversSignedPayload(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Hooks")
+
+	receiver := &webhookReceiver{status: http.StatusOK}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	webhook := createWebhook(t, handler, campaign.ID, server.URL, service.EventSignatureCreated)
+
+	sign := wire.TestPost[service.Signature](
+		handler,
+		"/campaigns/"+campaign.ID+"/signatures",
+		`{"name":"Alice","email":"alice@example.com","location":"NYC"}`,
+	)
+	sign.ExpectStatus(t, http.StatusCreated)
+
+	attempted, err := svc.DeliverWebhooks(context.Background())
+	if err != nil {
+		t.Fatalf("deliver webhooks: %v", err)
+	}
+	if attempted != 1 || receiver.count() != 1 {
+		t.Fatalf("expected one delivery, attempted %d, received %d", attempted, receiver.count())
+	}
+
+	req := receiver.requests[0]
+	body := receiver.bodies[0]
+	if got := req.Header.Get("X-Cosign-Event"); got != service.EventSignatureCreated {
+		t.Fatalf("unexpected event header: %q", got)
+	}
+
+	timestamp, err := strconv.ParseInt(req.Header.Get("X-Cosign-Timestamp"), 10, 64)
+	if err != nil {
+		t.Fatalf("parse timestamp header: %v", err)
+	}
+	expected := service.SignWebhookPayload(webhook.Secret, timestamp, body)
+	if got := req.Header.Get("X-Cosign-Signature"); got != expected {
+		t.Fatalf("unexpected signature header: got %q want %q", got, expected)
+	}
+
+	var event struct {
+		Event string            `json:"event"`
+		Data  service.Signature `json:"data"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if event.Event != service.EventSignatureCreated || event.Data.Email != "alice@example.com" {
+		t.Fatalf("unexpected payload: %s", body)
+	}
+
+	list := wire.TestGet[service.WebhookDeliveries](handler, "/admin/webhooks/"+webhook.ID+"/deliveries", authHeader())
+	list.ExpectStatus(t, http.StatusOK)
+	if len(list.Data.Deliveries) != 1 || list.Data.Deliveries[0].Status != service.DeliveryStatusSucceeded {
+		t.Fatalf("expected one succeeded delivery, got %+v", list.Data.Deliveries)
+	}
+}
+
+func TestWebhookRetriesDeadLettersAndReplays(t *testing.T) {
+	now := time.Unix(1736802000, 0)
+	svc := testutil.SetupServiceWith(t, func(opts *service.Options) {
+		opts.Clock = func() time.Time { return now }
+	})
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Retries")
+
+	receiver := &webhookReceiver{status: http.StatusInternalServerError}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	webhook := createWebhook(t, handler, "", server.URL, service.EventCampaignUpdated)
+
+	update := wire.TestPut[service.Campaign](handler, "/admin/campaigns/"+campaign.ID, `{"name":"Retries 2"}`, authHeader())
+	update.ExpectStatus(t, http.StatusOK)
+
+	for attempt := 1; attempt <= 20; attempt++ {
+		if _, err := svc.DeliverWebhooks(context.Background()); err != nil {
+			t.Fatalf("deliver webhooks: %v", err)
+		}
+
+		if attempted, _ := svc.DeliverWebhooks(context.Background()); attempted != 0 {
+			t.Fatalf("expected backoff to delay retry after attempt %d", attempt)
+		}
+		now = now.Add(2 * time.Hour)
+	}
+
+	dead := wire.TestGet[service.WebhookDeliveries](
+		handler,
+		"/admin/webhooks/"+webhook.ID+"/deliveries?status=dead",
+		authHeader(),
+	)
+	dead.ExpectStatus(t, http.StatusOK)
+	if dead.Data.Total != 1 {
+		t.Fatalf("expected one dead delivery, got %+v", dead.Data)
+	}
+
+	delivery := dead.Data.Deliveries[0]
+	if receiver.count() != delivery.Attempts || delivery.LastStatusCode != http.StatusInternalServerError {
+		t.Fatalf("unexpected dead delivery: %+v (received %d)", delivery, receiver.count())
+	}
+
+	receiver.setStatus(http.StatusNoContent)
+	replay := wire.TestPost[service.WebhookDelivery](
+		handler,
+		fmt.Sprintf("/admin/webhooks/%s/deliveries/%d/replay", webhook.ID, delivery.ID),
+		"",
+		authHeader(),
+	)
+	replay.ExpectStatus(t, http.StatusAccepted)
+	if replay.Data.Status != service.DeliveryStatusPending {
+		t.Fatalf("expected replayed delivery to be pending, got %q", replay.Data.Status)
+	}
+
+	if _, err := svc.DeliverWebhooks(context.Background()); err != nil {
+		t.Fatalf("deliver replayed webhook: %v", err)
+	}
+
+	succeeded := wire.TestGet[service.WebhookDeliveries](
+		handler,
+		"/admin/webhooks/"+webhook.ID+"/deliveries?status=succeeded",
+		authHeader(),
+	)
+	succeeded.ExpectStatus(t, http.StatusOK)
+	if succeeded.Data.Total != 1 {
+		t.Fatalf("expected replayed delivery to succeed, got %+v", succeeded.Data)
+	}
+}
+
+func TestWebhookDeliveryStopsWhenCanceled(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+	campaign := createCampaign(t, handler, "Shutdown")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		cancel()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	webhook := createWebhook(t, handler, campaign.ID, server.URL, service.EventSignatureCreated)
+	signBulkCampaign(t, handler, campaign.ID, "a@example.com", "b@example.com")
+
+	attempted, err := svc.DeliverWebhooks(ctx)
+	if !errors.Is(err, context.Canceled) || attempted != 0 {
+		t.Fatalf("expected delivery to stop on cancel, attempted %d, got %v", attempted, err)
+	}
+
+	list := wire.TestGet[service.WebhookDeliveries](handler, "/admin/webhooks/"+webhook.ID+"/deliveries", authHeader())
+	list.ExpectStatus(t, http.StatusOK)
+	for _, delivery := range list.Data.Deliveries {
+		if delivery.Status != service.DeliveryStatusPending || delivery.Attempts != 0 {
+			t.Fatalf("expected canceled deliveries to stay pending, got %+v", delivery)
+		}
+	}
+}
+
+func TestMilestoneFiresOncePerCampaign(t *testing.T) {
+	handler := testutil.SetupService(t).BuildRouter()
+	campaign := createCampaign(t, handler, "Milestones")
+	webhook := createWebhook(t, handler, campaign.ID, "http://hooks.example", service.EventMilestoneReached)
+
+	emails := make([]string, 10)
+	for i := range emails {
+		emails[i] = fmt.Sprintf("signer%d@example.org", i)
+	}
+	ids := signBulkCampaign(t, handler, campaign.ID, emails...)
+
+	wire.TestDelete[struct{}](handler, fmt.Sprintf("/admin/campaigns/%s/signatures/%d", campaign.ID, ids[emails[0]]), authHeader()).
+		ExpectStatus(t, http.StatusNoContent)
+	signBulkCampaign(t, handler, campaign.ID, "late@example.org")
+
+	list := wire.TestGet[service.WebhookDeliveries](handler, "/admin/webhooks/"+webhook.ID+"/deliveries", authHeader())
+	list.ExpectStatus(t, http.StatusOK)
+	if list.Data.Total != 1 || list.Data.Deliveries[0].Event != service.EventMilestoneReached {
+		t.Fatalf("expected the milestone announced once, got %+v", list.Data)
+	}
+}
+
+func TestMilestoneFiresWhenTheCountJumpsPastIt(t *testing.T) {
+	handler := testutil.SetupService(t).BuildRouter()
+	campaign := createCampaign(t, handler, "Jumps")
+	webhook := createWebhook(t, handler, campaign.ID, "http://hooks.example", service.EventMilestoneReached)
+	admin := "/admin/campaigns/" + campaign.ID + "/signatures"
+
+	emails := make([]string, 12)
+	for i := range emails {
+		emails[i] = fmt.Sprintf("signer%d@example.org", i)
+	}
+	ids := signBulkCampaign(t, handler, campaign.ID, emails[:9]...)
+	hidden := fmt.Sprintf("[%d,%d,%d]", ids[emails[0]], ids[emails[1]], ids[emails[2]])
+	wire.TestPost[service.BulkSignaturesResponse](handler, admin+"/bulk", `{"action":"hide","ids":`+hidden+`}`, authHeader()).
+		ExpectStatus(t, http.StatusOK)
+	signBulkCampaign(t, handler, campaign.ID, emails[9:]...)
+	wire.TestPost[service.BulkSignaturesResponse](handler, admin+"/bulk", `{"action":"unhide","ids":`+hidden+`}`, authHeader()).
+		ExpectStatus(t, http.StatusOK)
+
+	list := wire.TestGet[service.WebhookDeliveries](handler, "/admin/webhooks/"+webhook.ID+"/deliveries", authHeader())
+	list.ExpectStatus(t, http.StatusOK)
+	if list.Data.Total != 1 {
+		t.Fatalf("expected the skipped milestone announced once, got %+v", list.Data)
+	}
+	var event struct {
+		Data service.MilestoneReached `json:"data"`
+	}
+	if err := json.Unmarshal(list.Data.Deliveries[0].Payload, &event); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if event.Data.Milestone != 10 || event.Data.Count != 12 {
+		t.Fatalf("expected milestone 10 reached at 12, got %+v", event.Data)
+	}
+}
+
+func TestWebhookRejectsUnknownEvent(t *testing.T) {
+	svc := testutil.SetupService(t)
+	handler := svc.BuildRouter()
+
+	result := wire.TestPost[service.Webhook](
+		handler,
+		"/admin/webhooks",
+		`{"url":"https://hooks.example","events":["signature.updated"]}`,
+		authHeader(),
+	)
+	result.ExpectStatus(t, http.StatusBadRequest)
+}
@@ -13,6 +13,11 @@ const BootstrapToken = "default.0123456789abcdef0123456789abcdef0123456789abcdef
 
 func SetupService(t *testing.T) *service.Service {
 	t.Helper()
+	return SetupServiceWith(t, nil)
+}
+
+func SetupServiceWith(t *testing.T, configure func(*service.Options)) *service.Service {
+	t.Helper()
 
 	db, err := database.Open(database.Options{Path: ":memory:", WAL: false})
 	if err != nil {
@@ -24,7 +29,7 @@ func SetupService(t *testing.T) *service.Service {
 		}
 	})
 
-	svc, err := service.New(service.Options{
+	opts := service.Options{
 		Store: db,
 		KeysOptions: &keys.Options{
 			Store:          db.KeysStore,
@@ -35,7 +40,12 @@ func SetupService(t *testing.T) *service.Service {
 			InitialOrigins: []string{"http://test-origin"},
 		},
 		HealthCheck: db.HealthCheck,
-	})
+	}
+	if configure != nil {
+		configure(&opts)
+	}
+
+	svc, err := service.New(opts)
 	if err != nil {
 		t.Fatalf("create test service: %v", err)
 	}
@@ -0,0 +1,8 @@
+// Package cosignapi holds the cosign API's wire types, error codes and
+// errors. The service and the Go client both use it, so the client stays in
+// step with the API without importing the server.
+package cosignapi
+
+type HealthResponse struct {
+	Status string `json:"status"`
+}
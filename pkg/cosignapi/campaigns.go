@@ -0,0 +1,91 @@
+package cosignapi
+
+import (
+	"slices"
+	"sort"
+)
+
+const DefaultLanguage = "en"
+
+type Campaign struct {
+	ID              string        `json:"id"`
+	Name            string        `json:"name"`
+	Letter          string        `json:"letter"`
+	Language        string        `json:"language"`
+	Goal            int           `json:"goal"`
+	AllowCustomText bool          `json:"allow_custom_text"`
+	LocationLevel   int           `json:"location_level"`
+	Theme           CampaignTheme `json:"theme"`
+	SuccessURL      string        `json:"success_url"`
+	ErrorURL        string        `json:"error_url"`
+	CreatedAt       int64         `json:"created_at"`
+
+	// DeletedAt is set while the campaign is in the trash, and PurgeAt is
+	// when the trash sweeper removes it for good.
+	DeletedAt int64 `json:"deleted_at,omitempty"`
+	PurgeAt   int64 `json:"purge_at,omitempty"`
+
+	// Revision and LocationsRevision count writes to the campaign and its
+	// location set; admin responses expose them as ETags.
+	Revision          int64 `json:"revision"`
+	LocationsRevision int64 `json:"locations_revision"`
+
+	Translations map[string]CampaignTranslation `json:"translations,omitempty"`
+}
+
+type Campaigns struct {
+	Campaigns []*Campaign `json:"campaigns"`
+	Total     int         `json:"total"`
+	Limit     int         `json:"limit"`
+	Offset    int         `json:"offset"`
+}
+
+type CampaignTheme struct {
+	PrimaryColor    string `json:"primary_color"`
+	BackgroundColor string `json:"background_color"`
+	LogoURL         string `json:"logo_url"`
+}
+
+type CampaignTranslation struct {
+	Name   string `json:"name,omitempty"`
+	Letter string `json:"letter,omitempty"`
+}
+
+type CreateCampaignRequest struct {
+	Name string `json:"name"`
+}
+
+type UpdateCampaignRequest struct {
+	Name            string         `json:"name"`
+	Letter          *string        `json:"letter,omitempty"`
+	Goal            *int           `json:"goal,omitempty"`
+	Language        *string        `json:"language,omitempty"`
+	AllowCustomText *bool          `json:"allow_custom_text"`
+	LocationLevel   *int           `json:"location_level,omitempty"`
+	Theme           *CampaignTheme `json:"theme,omitempty"`
+	SuccessURL      *string        `json:"success_url,omitempty"`
+	ErrorURL        *string        `json:"error_url,omitempty"`
+
+	// Translations replaces all translations when set; an empty map clears them.
+	Translations map[string]CampaignTranslation `json:"translations,omitempty"`
+
+	// Revision stands in for If-Match when the client cannot set headers.
+	Revision *int64 `json:"revision,omitempty"`
+}
+
+// Languages lists the campaign's base language followed by its translations.
+func (c *Campaign) Languages() []string {
+	languages := []string{c.Language}
+	if c.Language == "" {
+		languages[0] = DefaultLanguage
+	}
+
+	extra := make([]string, 0, len(c.Translations))
+	for lang := range c.Translations {
+		if !slices.Contains(languages, lang) {
+			extra = append(extra, lang)
+		}
+	}
+	sort.Strings(extra)
+	return append(languages, extra...)
+}
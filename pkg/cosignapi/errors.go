@@ -0,0 +1,207 @@
+package cosignapi
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Codes the API reports errors under. Codes are stable, so clients may
+// switch on them; the public ones have messages in the service's catalog.
+const (
+	CodeInvalidRequest       = "invalid_request"
+	CodeCampaignIDRequired   = "campaign_id_required"
+	CodeCampaignNotFound     = "campaign_not_found"
+	CodeEmptyName            = "empty_name"
+	CodeEmptyEmail           = "empty_email"
+	CodeEmptyLocation        = "empty_location"
+	CodeInvalidEmail         = "invalid_email"
+	CodeDuplicateEmail       = "duplicate_email"
+	CodeLocationNotInOptions = "location_not_in_options"
+	CodeRateLimited          = "rate_limited"
+	CodeTooManyStreams       = "too_many_streams"
+	CodeInternalError        = "internal_error"
+
+	CodeIdempotencyKeyReused   = "idempotency_key_reused"
+	CodeIdempotencyKeyInFlight = "idempotency_key_in_flight"
+)
+
+const (
+	CodeNotFound               = "not_found"
+	CodeSignatureNotFound      = "signature_not_found"
+	CodeEmptyCampaignName      = "empty_campaign_name"
+	CodeInvalidThemeColor      = "invalid_theme_color"
+	CodeInvalidThemeLogoURL    = "invalid_theme_logo_url"
+	CodeInvalidRedirectURL     = "invalid_redirect_url"
+	CodeRedirectNotAllowed     = "redirect_not_allowed"
+	CodeInvalidGoal            = "invalid_goal"
+	CodeInvalidLanguage        = "invalid_language"
+	CodeInvalidPagination      = "invalid_pagination"
+	CodeInvalidID              = "invalid_id"
+	CodeLocationNotFound       = "location_not_found"
+	CodeDuplicateLocation      = "duplicate_location"
+	CodeDuplicateLocationAlias = "duplicate_location_alias"
+	CodeUnknownLocationParent  = "unknown_location_parent"
+	CodeLocationCycle          = "location_cycle"
+	CodeInvalidCountryCode     = "invalid_country_code"
+	CodeInvalidSubdivisionCode = "invalid_subdivision_code"
+	CodeInvalidLocationLevel   = "invalid_location_level"
+	CodeInvalidCoordinates     = "invalid_coordinates"
+	CodeInvalidGazetteer       = "invalid_gazetteer"
+	CodeMergeIntoSelf          = "merge_into_self"
+	CodeRevisionConflict       = "revision_conflict"
+	CodeWebhookNotFound        = "webhook_not_found"
+	CodeWebhookDeliveryMissing = "webhook_delivery_not_found"
+	CodeInvalidWebhookURL      = "invalid_webhook_url"
+	CodeInvalidWebhookEvent    = "invalid_webhook_event"
+	CodeEmptyWebhookEvents     = "empty_webhook_events"
+	CodeImportBatchTooLarge    = "import_batch_too_large"
+	CodeInvalidStatsDays       = "invalid_stats_days"
+	CodeInvalidVisibility      = "invalid_visibility"
+	CodeInvalidBulkAction      = "invalid_bulk_action"
+	CodeBulkTargetRequired     = "bulk_target_required"
+	CodeTooManyBulkIDs         = "too_many_bulk_ids"
+	CodeBulkOperationNotFound  = "bulk_operation_not_found"
+	CodeBulkUndoExpired        = "bulk_undo_expired"
+	CodeUnavailable            = "unavailable"
+)
+
+var (
+	ErrCampaignNotFound     = errors.New("campaign not found")
+	ErrSignatureNotFound    = errors.New("signature not found")
+	ErrInvalidEmail         = errors.New("invalid email address")
+	ErrDuplicateEmail       = errors.New("email already signed")
+	ErrLocationNotInOptions = errors.New("location must be from preset options")
+	ErrEmptyName            = errors.New("name cannot be empty")
+	ErrEmptyEmail           = errors.New("email cannot be empty")
+	ErrEmptyLocation        = errors.New("location cannot be empty")
+	ErrEmptyCampaignName    = errors.New("campaign name cannot be empty")
+	ErrInvalidThemeColor    = errors.New("theme colors must be hex values like #1a2b3c")
+	ErrInvalidThemeLogoURL  = errors.New("theme logo url must be an absolute http or https url")
+	ErrInvalidRedirectURL   = errors.New("redirect url must be an absolute http or https url")
+	ErrRedirectNotAllowed   = errors.New("redirect url origin must be in the cors allowlist")
+	ErrInvalidGoal          = errors.New("goal cannot be negative")
+	ErrInvalidLanguage      = errors.New("language must be a tag like en or pt-br")
+
+	ErrLocationNotFound       = errors.New("location not found")
+	ErrDuplicateLocation      = errors.New("location values must be unique")
+	ErrDuplicateLocationAlias = errors.New("location aliases must be unique and differ from other location values")
+	ErrUnknownLocationParent  = errors.New("location parent must be another location in the list")
+	ErrLocationCycle          = errors.New("location parents cannot form a cycle")
+	ErrInvalidCountryCode     = errors.New("country code must be an ISO 3166-1 alpha-2 code like US")
+	ErrInvalidSubdivisionCode = errors.New("subdivision code must be an ISO 3166-2 code like US-NY within its country")
+	ErrInvalidLocationLevel   = errors.New("location level cannot be negative")
+	ErrInvalidCoordinates     = errors.New("coordinates need both latitude (-90 to 90) and longitude (-180 to 180)")
+	ErrInvalidGazetteer       = errors.New("gazetteer csv needs value, latitude and longitude columns")
+	ErrMergeIntoSelf          = errors.New("location cannot be merged into itself")
+
+	ErrRevisionConflict = errors.New("revision conflict: modified since it was loaded, reload and retry")
+
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrInvalidWebhookURL       = errors.New("webhook url must be an absolute http or https url")
+	ErrInvalidWebhookEvent     = errors.New("unknown webhook event")
+	ErrEmptyWebhookEvents      = errors.New("webhook events cannot be empty")
+
+	ErrImportBatchTooLarge = fmt.Errorf("an import batch can hold at most %d signatures", MaxImportBatch)
+	ErrInvalidStatsDays    = fmt.Errorf("days must be between 1 and %d", MaxStatsDays)
+	ErrInvalidVisibility   = errors.New("visibility must be visible or hidden")
+
+	ErrInvalidBulkAction     = errors.New("action must be delete, hide or unhide")
+	ErrBulkTargetRequired    = errors.New("bulk actions need either ids or a filter, not both")
+	ErrTooManyBulkIDs        = fmt.Errorf("a bulk action can list at most %d ids", MaxBulkIDs)
+	ErrBulkOperationNotFound = errors.New("bulk operation not found")
+	ErrBulkUndoExpired       = errors.New("bulk operation can no longer be undone")
+
+	ErrTooManyStreams = errors.New("too many event streams")
+)
+
+// APIError is the error half of the response envelope. Validation failures
+// also name the offending request fields.
+type APIError struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError points a validation failure at a request field, named by its
+// JSON path, e.g. "theme.primary_color".
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// errorCodes pairs each error with the code the API reports it under.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrCampaignNotFound, CodeCampaignNotFound},
+	{ErrSignatureNotFound, CodeSignatureNotFound},
+	{ErrInvalidEmail, CodeInvalidEmail},
+	{ErrDuplicateEmail, CodeDuplicateEmail},
+	{ErrLocationNotInOptions, CodeLocationNotInOptions},
+	{ErrEmptyName, CodeEmptyName},
+	{ErrEmptyEmail, CodeEmptyEmail},
+	{ErrEmptyLocation, CodeEmptyLocation},
+	{ErrEmptyCampaignName, CodeEmptyCampaignName},
+	{ErrInvalidThemeColor, CodeInvalidThemeColor},
+	{ErrInvalidThemeLogoURL, CodeInvalidThemeLogoURL},
+	{ErrInvalidRedirectURL, CodeInvalidRedirectURL},
+	{ErrRedirectNotAllowed, CodeRedirectNotAllowed},
+	{ErrInvalidGoal, CodeInvalidGoal},
+	{ErrInvalidLanguage, CodeInvalidLanguage},
+
+	{ErrLocationNotFound, CodeLocationNotFound},
+	{ErrDuplicateLocation, CodeDuplicateLocation},
+	{ErrDuplicateLocationAlias, CodeDuplicateLocationAlias},
+	{ErrUnknownLocationParent, CodeUnknownLocationParent},
+	{ErrLocationCycle, CodeLocationCycle},
+	{ErrInvalidCountryCode, CodeInvalidCountryCode},
+	{ErrInvalidSubdivisionCode, CodeInvalidSubdivisionCode},
+	{ErrInvalidLocationLevel, CodeInvalidLocationLevel},
+	{ErrInvalidCoordinates, CodeInvalidCoordinates},
+	{ErrInvalidGazetteer, CodeInvalidGazetteer},
+	{ErrMergeIntoSelf, CodeMergeIntoSelf},
+	{ErrRevisionConflict, CodeRevisionConflict},
+
+	{ErrWebhookNotFound, CodeWebhookNotFound},
+	{ErrWebhookDeliveryNotFound, CodeWebhookDeliveryMissing},
+	{ErrInvalidWebhookURL, CodeInvalidWebhookURL},
+	{ErrInvalidWebhookEvent, CodeInvalidWebhookEvent},
+	{ErrEmptyWebhookEvents, CodeEmptyWebhookEvents},
+
+	{ErrImportBatchTooLarge, CodeImportBatchTooLarge},
+	{ErrInvalidStatsDays, CodeInvalidStatsDays},
+	{ErrInvalidVisibility, CodeInvalidVisibility},
+
+	{ErrInvalidBulkAction, CodeInvalidBulkAction},
+	{ErrBulkTargetRequired, CodeBulkTargetRequired},
+	{ErrTooManyBulkIDs, CodeTooManyBulkIDs},
+	{ErrBulkOperationNotFound, CodeBulkOperationNotFound},
+	{ErrBulkUndoExpired, CodeBulkUndoExpired},
+
+	{ErrTooManyStreams, CodeTooManyStreams},
+}
+
+// ErrorCode returns the code the API reports err under, or "" for errors
+// without one.
+func ErrorCode(err error) string {
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.err) {
+			return entry.code
+		}
+	}
+	return ""
+}
+
+// ErrorForCode returns the error a code stands for, or nil for codes
+// without one, so clients can turn codes back into errors.Is checks.
+func ErrorForCode(code string) error {
+	for _, entry := range errorCodes {
+		if entry.code == code {
+			return entry.err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package cosignapi
+
+type LocationOption struct {
+	ID              int64             `json:"id,omitempty"`
+	Value           string            `json:"value"`
+	Label           string            `json:"label,omitempty"`
+	DisplayOrder    int               `json:"display_order"`
+	Parent          string            `json:"parent,omitempty"`
+	ParentID        int64             `json:"parent_id,omitempty"`
+	CountryCode     string            `json:"country_code,omitempty"`
+	SubdivisionCode string            `json:"subdivision_code,omitempty"`
+	Latitude        *float64          `json:"latitude,omitempty"`
+	Longitude       *float64          `json:"longitude,omitempty"`
+	Labels          map[string]string `json:"labels,omitempty"`
+	Aliases         []string          `json:"aliases,omitempty"`
+	Children        []LocationOption  `json:"children,omitempty"`
+}
+
+type CampaignLocationsRequest struct {
+	Locations []LocationOption `json:"locations"`
+	Revision  *int64           `json:"revision,omitempty"`
+}
+
+type CampaignLocationsResponse struct {
+	Locations []LocationOption `json:"locations"`
+	Revision  int64            `json:"revision,omitempty"`
+}
+
+type UpdateLocationRequest struct {
+	Value           *string `json:"value,omitempty"`
+	CountryCode     *string `json:"country_code,omitempty"`
+	SubdivisionCode *string `json:"subdivision_code,omitempty"`
+
+	// Labels replaces all labels when set; an empty map clears them.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Aliases replaces all aliases when set; an empty list clears them.
+	Aliases []string `json:"aliases"`
+
+	Latitude         *float64 `json:"latitude,omitempty"`
+	Longitude        *float64 `json:"longitude,omitempty"`
+	ClearCoordinates bool     `json:"clear_coordinates,omitempty"`
+}
+
+type MoveLocationRequest struct {
+	ParentID int64 `json:"parent_id"`
+	// Position is 1-based among the new siblings; 0 moves to the end.
+	Position int `json:"position"`
+}
+
+type MergeLocationRequest struct {
+	IntoID int64 `json:"into_id"`
+}
+
+type MergeLocationResponse struct {
+	Location  *LocationOption `json:"location"`
+	Rewritten int             `json:"rewritten"`
+}
+
+type LocationStat struct {
+	Value           string         `json:"value"`
+	CountryCode     string         `json:"country_code,omitempty"`
+	SubdivisionCode string         `json:"subdivision_code,omitempty"`
+	Count           int            `json:"count"`
+	Total           int            `json:"total"`
+	Children        []LocationStat `json:"children,omitempty"`
+}
+
+type CampaignLocationStats struct {
+	Locations []LocationStat `json:"locations"`
+	Other     int            `json:"other"`
+	Total     int            `json:"total"`
+}
+
+type LocationSuggestion struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+	// LocationID and Suggestion are empty when no preset is close enough.
+	LocationID int64  `json:"location_id,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+	Distance   int    `json:"distance"`
+}
+
+type LocationSuggestionsResponse struct {
+	Suggestions []LocationSuggestion `json:"suggestions"`
+}
+
+type LocationMapping struct {
+	Value      string `json:"value"`
+	LocationID int64  `json:"location_id"`
+}
+
+type NormalizeLocationsRequest struct {
+	Mappings []LocationMapping `json:"mappings"`
+}
+
+type NormalizeLocationsResponse struct {
+	Rewritten int `json:"rewritten"`
+}
+
+type LocationCoordinate struct {
+	Value     string  `json:"value"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type ImportCoordinatesRequest struct {
+	Coordinates []LocationCoordinate `json:"coordinates"`
+}
+
+type ImportCoordinatesResponse struct {
+	Updated   int      `json:"updated"`
+	Unmatched []string `json:"unmatched"`
+}
+
+type GeoJSONFeatureCollection struct {
+	Type     string            `json:"type"`
+	Features []GeoJSONFeature  `json:"features"`
+	Unmapped UnmappedLocations `json:"unmapped"`
+}
+
+type GeoJSONFeature struct {
+	Type       string               `json:"type"`
+	Geometry   GeoJSONPoint         `json:"geometry"`
+	Properties SignatureMapLocation `json:"properties"`
+}
+
+type GeoJSONPoint struct {
+	Type string `json:"type"`
+	// Coordinates are longitude then latitude, as GeoJSON requires.
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type SignatureMapLocation struct {
+	ID    int64  `json:"id"`
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int    `json:"count"`
+}
+
+// UnmappedLocations counts signatures that cannot be placed on a map:
+// custom text, and presets without coordinates.
+type UnmappedLocations struct {
+	Total     int                `json:"total"`
+	Locations []UnmappedLocation `json:"locations"`
+}
+
+type UnmappedLocation struct {
+	Value  string `json:"value"`
+	Count  int    `json:"count"`
+	Custom bool   `json:"custom"`
+}
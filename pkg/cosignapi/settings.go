@@ -0,0 +1,13 @@
+package cosignapi
+
+// APIKey describes an issued key by its id, the part of its token before
+// the dot; the secret is never stored.
+type APIKey struct {
+	ID         string `json:"id"`
+	CreatedAt  int64  `json:"created_at"`
+	LastUsedAt int64  `json:"last_used_at,omitempty"`
+}
+
+type APIKeys struct {
+	Keys []*APIKey `json:"keys"`
+}
@@ -0,0 +1,157 @@
+package cosignapi
+
+const (
+	VisibilityVisible = "visible"
+	VisibilityHidden  = "hidden"
+)
+
+const (
+	BulkActionDelete = "delete"
+	BulkActionHide   = "hide"
+	BulkActionUnhide = "unhide"
+)
+
+const (
+	// MaxBulkIDs is the most signature ids one bulk request may list;
+	// larger selections are sent as a filter.
+	MaxBulkIDs = 1000
+	// MaxImportBatch is the most signatures one import request may carry;
+	// larger files are sent in batches.
+	MaxImportBatch = 500
+	MaxStatsDays   = 365
+)
+
+const (
+	StreamEventCount     = "count"
+	StreamEventSignature = "signature"
+)
+
+type Signature struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Location string `json:"location"`
+	// LocationRaw is the text as submitted, before matching it to a preset.
+	LocationRaw string `json:"location_raw,omitempty"`
+	// Hidden signatures are kept but left out of public lists and counts.
+	Hidden    bool  `json:"hidden,omitempty"`
+	CreatedAt int64 `json:"created_at"`
+	// DeletedAt and PurgeAt are set while the signature is in the trash.
+	DeletedAt int64 `json:"deleted_at,omitempty"`
+	PurgeAt   int64 `json:"purge_at,omitempty"`
+}
+
+type Signatures struct {
+	Signatures []*Signature `json:"signatures"`
+	Total      int          `json:"total"`
+	Limit      int          `json:"limit"`
+	Offset     int          `json:"offset"`
+}
+
+// SignatureFilter narrows a campaign's signatures. Query matches part of
+// the name or email, ignoring case; Location matches exactly; IDs, when not
+// nil, limits the match to those signatures. Empty fields match everything.
+type SignatureFilter struct {
+	Query      string  `json:"query,omitempty"`
+	Location   string  `json:"location,omitempty"`
+	Visibility string  `json:"visibility,omitempty"`
+	IDs        []int64 `json:"ids,omitempty"`
+}
+
+// BulkSignaturesRequest applies one action to the listed signatures or to
+// every signature matching Filter. Hide skips signatures already hidden and
+// unhide those already visible.
+type BulkSignaturesRequest struct {
+	Action string           `json:"action"`
+	IDs    []int64          `json:"ids,omitempty"`
+	Filter *SignatureFilter `json:"filter,omitempty"`
+}
+
+// BulkSignaturesResponse names the operation to undo until UndoUntil. When
+// nothing matched there is nothing to undo and no operation.
+type BulkSignaturesResponse struct {
+	OperationID string `json:"operation_id,omitempty"`
+	Action      string `json:"action"`
+	Affected    int    `json:"affected"`
+	UndoUntil   int64  `json:"undo_until,omitempty"`
+}
+
+// BulkUndoResponse counts the signatures put back. A deleted signature
+// whose email has signed again since is left out.
+type BulkUndoResponse struct {
+	OperationID string `json:"operation_id"`
+	Action      string `json:"action"`
+	Restored    int    `json:"restored"`
+}
+
+// SignatureBulkOperation is what the store keeps of a bulk action, along
+// with the affected signatures as they were, until it expires.
+type SignatureBulkOperation struct {
+	ID         string `json:"id"`
+	CampaignID string `json:"campaign_id"`
+	Action     string `json:"action"`
+	CreatedAt  int64  `json:"created_at"`
+	ExpiresAt  int64  `json:"expires_at"`
+}
+
+type CreateSignatureRequest struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Location string `json:"location"`
+}
+
+// ImportSignaturesRequest adds many signatures at once, each checked as if
+// submitted on its own. With DryRun nothing is stored, so a file can be
+// previewed before it is committed.
+type ImportSignaturesRequest struct {
+	Signatures []CreateSignatureRequest `json:"signatures"`
+	DryRun     bool                     `json:"dry_run,omitempty"`
+}
+
+type ImportSignaturesResponse struct {
+	Results  []ImportSignatureResult `json:"results"`
+	Accepted int                     `json:"accepted"`
+	Rejected int                     `json:"rejected"`
+}
+
+// ImportSignatureResult reports one row: the location it resolved to and,
+// unless a dry run, its new ID; or why it was rejected.
+type ImportSignatureResult struct {
+	Index    int       `json:"index"`
+	ID       int64     `json:"id,omitempty"`
+	Location string    `json:"location,omitempty"`
+	Error    *APIError `json:"error,omitempty"`
+}
+
+// SignatureStats sums a campaign's signatures overall, over the last day
+// and week, per UTC day for the last Days days, oldest first, and for its
+// most signed locations.
+type SignatureStats struct {
+	Total        int                   `json:"total"`
+	Last24h      int                   `json:"last_24h"`
+	Last7d       int                   `json:"last_7d"`
+	Days         []DailySignatureCount `json:"days"`
+	TopLocations []LocationCount       `json:"top_locations"`
+}
+
+type DailySignatureCount struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+type LocationCount struct {
+	Location string `json:"location"`
+	Count    int    `json:"count"`
+}
+
+type StreamCount struct {
+	Count int `json:"count"`
+}
+
+// PublicSignature is a signature as shown to the public: no email and only
+// the signer's first name and last initial.
+type PublicSignature struct {
+	Name      string `json:"name"`
+	Location  string `json:"location"`
+	CreatedAt int64  `json:"created_at"`
+}
@@ -0,0 +1,64 @@
+package cosignapi
+
+import "encoding/json"
+
+const (
+	EventSignatureCreated = "signature.created"
+	EventSignatureDeleted = "signature.deleted"
+	EventCampaignUpdated  = "campaign.updated"
+	EventMilestoneReached = "milestone.reached"
+)
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusDead      = "dead"
+)
+
+type Webhook struct {
+	ID         string   `json:"id"`
+	CampaignID string   `json:"campaign_id,omitempty"`
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret,omitempty"`
+	Events     []string `json:"events"`
+	CreatedAt  int64    `json:"created_at"`
+}
+
+type Webhooks struct {
+	Webhooks []*Webhook `json:"webhooks"`
+}
+
+type CreateWebhookRequest struct {
+	CampaignID string   `json:"campaign_id"`
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	Events     []string `json:"events"`
+}
+
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	WebhookID      string          `json:"webhook_id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  int64           `json:"next_attempt_at"`
+	LastStatusCode int             `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	CreatedAt      int64           `json:"created_at"`
+	UpdatedAt      int64           `json:"updated_at"`
+}
+
+type WebhookDeliveries struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+	Total      int                `json:"total"`
+	Limit      int                `json:"limit"`
+	Offset     int                `json:"offset"`
+}
+
+type WebhookEvent struct {
+	Event      string `json:"event"`
+	CampaignID string `json:"campaign_id"`
+	CreatedAt  int64  `json:"created_at"`
+	Data       any    `json:"data"`
+}
@@ -0,0 +1,167 @@
+package cosignclient
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+)
+
+func (c *Client) Health(ctx context.Context) (*HealthResponse, error) {
+	response := &HealthResponse{}
+	if err := c.do(ctx, newRequest(http.MethodGet, "/health"), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// OpenAPI returns the API's OpenAPI document.
+func (c *Client) OpenAPI(ctx context.Context) ([]byte, error) {
+	data, _, err := c.send(ctx, newRequest(http.MethodGet, "/openapi.json"))
+	return data, err
+}
+
+// ListCampaigns returns one page of campaigns; a zero limit uses the
+// server's default page size.
+func (c *Client) ListCampaigns(ctx context.Context, limit, offset int) (*Campaigns, error) {
+	req := newRequest(http.MethodGet, "/admin/campaigns").withQuery(pageQuery(limit, offset))
+	response := &Campaigns{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// AllCampaigns pages through every campaign, pageSize at a time.
+func (c *Client) AllCampaigns(ctx context.Context, pageSize int) iter.Seq2[*Campaign, error] {
+	return paginate(func(offset int) ([]*Campaign, int, error) {
+		page, err := c.ListCampaigns(ctx, pageSize, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		return page.Campaigns, page.Total, nil
+	})
+}
+
+// CreateCampaign creates a campaign under a generated Idempotency-Key, so a
+// retried create cannot make two.
+func (c *Client) CreateCampaign(ctx context.Context, name string) (*Campaign, error) {
+	req, err := newRequest(http.MethodPost, "/admin/campaigns").withJSON(CreateCampaignRequest{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	response := &Campaign{}
+	if err := c.do(ctx, req.withIdempotencyKey(), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) GetCampaign(ctx context.Context, campaignID string) (*Campaign, error) {
+	response := &Campaign{}
+	if err := c.do(ctx, newRequest(http.MethodGet, "/admin/campaigns/"+pathEscape(campaignID)), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// UpdateCampaign applies update; set update.Revision to fail with
+// ErrRevisionConflict if the campaign changed since it was read.
+func (c *Client) UpdateCampaign(ctx context.Context, campaignID string, update UpdateCampaignRequest) (*Campaign, error) {
+	req, err := newRequest(http.MethodPut, "/admin/campaigns/"+pathEscape(campaignID)).withJSON(update)
+	if err != nil {
+		return nil, err
+	}
+	response := &Campaign{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// DeleteCampaign moves the campaign to the trash, where RestoreCampaign can
+// bring it back until the server purges it.
+func (c *Client) DeleteCampaign(ctx context.Context, campaignID string) error {
+	return c.do(ctx, newRequest(http.MethodDelete, "/admin/campaigns/"+pathEscape(campaignID)), nil)
+}
+
+// ListDeletedCampaigns returns one page of the campaigns in the trash, most
+// recently deleted first.
+func (c *Client) ListDeletedCampaigns(ctx context.Context, limit, offset int) (*Campaigns, error) {
+	req := newRequest(http.MethodGet, "/admin/campaigns/trash").withQuery(pageQuery(limit, offset))
+	response := &Campaigns{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) RestoreCampaign(ctx context.Context, campaignID string) (*Campaign, error) {
+	response := &Campaign{}
+	if err := c.do(ctx, newRequest(http.MethodPost, "/admin/campaigns/"+pathEscape(campaignID, "restore")), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetPublicCampaign returns the campaign as signers see it, translated into
+// lang when it has that translation; an empty lang uses the default.
+func (c *Client) GetPublicCampaign(ctx context.Context, campaignID string, lang string) (*Campaign, error) {
+	req := newRequest(http.MethodGet, "/campaigns/"+pathEscape(campaignID)).withQuery(langQuery(lang))
+	response := &Campaign{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Badge returns the campaign's SVG signature count badge; an empty label
+// uses the default.
+func (c *Client) Badge(ctx context.Context, campaignID string, label string) ([]byte, error) {
+	query := url.Values{}
+	if label != "" {
+		query.Set("label", label)
+	}
+	req := newRequest(http.MethodGet, "/campaigns/"+pathEscape(campaignID, "badge.svg")).withQuery(query)
+	data, _, err := c.send(ctx, req)
+	return data, err
+}
+
+// Card returns the campaign's PNG social card.
+func (c *Client) Card(ctx context.Context, campaignID string) ([]byte, error) {
+	data, _, err := c.send(ctx, newRequest(http.MethodGet, "/campaigns/"+pathEscape(campaignID, "card.png")))
+	return data, err
+}
+
+func langQuery(lang string) url.Values {
+	query := url.Values{}
+	if lang != "" {
+		query.Set("lang", lang)
+	}
+	return query
+}
+
+// paginate yields the items of successive pages until total is reached or
+// a page comes back empty. An error is yielded once and ends the sequence.
+func paginate[T any](fetch func(offset int) ([]T, int, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		offset := 0
+		for {
+			items, total, err := fetch(offset)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			offset += len(items)
+			if len(items) == 0 || offset >= total {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,363 @@
+// Package cosignclient is a typed Go client for the cosign API.
+//
+// Every method takes a context, decodes the response envelope into the
+// cosignapi types, and returns *Error for API failures so callers can branch
+// on status, code, and field details. Reads are retried on transient
+// failures; creates that the API deduplicates are sent with a generated
+// Idempotency-Key so their retries are safe too. Other writes are sent
+// once unless the caller opts in with WithRetries.
+package cosignclient
+
+import (
+	"bytes"
+	"context"
+	"cosign/pkg/cosignapi"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout      = 10 * time.Second
+	defaultMaxRetries   = 2
+	defaultRetryBackoff = 250 * time.Millisecond
+	maxRetryAfter       = 30 * time.Second
+)
+
+var defaultHTTPClient = &http.Client{Timeout: defaultTimeout}
+
+type Options struct {
+	// BaseURL includes the API prefix, e.g. http://localhost:8080/api/v1.
+	BaseURL string
+	APIKey  string
+
+	// HTTPClient defaults to one with a 10 second timeout. Event streams
+	// use a copy without the timeout.
+	HTTPClient *http.Client
+
+	// MaxRetries is how often a failed request is retried; zero means 2
+	// and a negative value disables retries.
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry, doubling after
+	// each; zero means 250ms.
+	RetryBackoff time.Duration
+}
+
+type Client struct {
+	baseURL      string
+	apiKey       string
+	httpClient   *http.Client
+	maxRetries   int
+	retryBackoff time.Duration
+}
+
+func New(opts Options) *Client {
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = defaultHTTPClient
+	}
+
+	maxRetries := opts.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = defaultMaxRetries
+	}
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	retryBackoff := opts.RetryBackoff
+	if retryBackoff <= 0 {
+		retryBackoff = defaultRetryBackoff
+	}
+
+	return &Client{
+		baseURL:      strings.TrimRight(opts.BaseURL, "/"),
+		apiKey:       opts.APIKey,
+		httpClient:   httpClient,
+		maxRetries:   maxRetries,
+		retryBackoff: retryBackoff,
+	}
+}
+
+// BaseURL returns the API root the client sends requests to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// APIKeyID returns the id of the client's API key, the part of its token
+// before the dot, or "" without one.
+func (c *Client) APIKeyID() string {
+	id, _, _ := strings.Cut(c.apiKey, ".")
+	return id
+}
+
+// Error is an error response from the API. It unwraps to the cosignapi
+// error registered for its code, so errors.Is(err, ErrDuplicateEmail) works
+// across the wire.
+type Error struct {
+	Status int
+	APIError
+
+	retryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return cosignapi.ErrorForCode(e.Code)
+}
+
+// Field returns the message for the named request field, if the error
+// points at it.
+func (e *Error) Field(name string) (string, bool) {
+	for _, field := range e.Fields {
+		if field.Field == name {
+			return field.Message, true
+		}
+	}
+	return "", false
+}
+
+// AsError returns the API error in err's chain, if any.
+func AsError(err error) (*Error, bool) {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// request describes one API call. Bodies are kept as bytes so a retry can
+// send them again.
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	body        []byte
+	contentType string
+	header      http.Header
+	idempotent  bool
+}
+
+func newRequest(method string, path string) request {
+	return request{method: method, path: path, header: http.Header{}}
+}
+
+func (r request) withQuery(query url.Values) request {
+	r.query = query
+	return r
+}
+
+func (r request) withJSON(body any) (request, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return r, fmt.Errorf("encode request: %w", err)
+	}
+	r.body = data
+	r.contentType = "application/json"
+	return r, nil
+}
+
+// withIdempotencyKey marks a create the API deduplicates, so it may be
+// retried under a key generated once for the call.
+func (r request) withIdempotencyKey() request {
+	key := make([]byte, 16)
+	_, _ = rand.Read(key)
+	r.header.Set("Idempotency-Key", hex.EncodeToString(key))
+	r.idempotent = true
+	return r
+}
+
+type retriesKey struct{}
+
+// WithRetries returns a context under which calls retry transient failures
+// even when replaying them is not safe, for callers that can tolerate a
+// repeated update or delete answering not found or with a revision conflict.
+func WithRetries(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retriesKey{}, true)
+}
+
+func retryable(ctx context.Context, req request) bool {
+	if req.idempotent || req.method == http.MethodGet || req.method == http.MethodHead {
+		return true
+	}
+	optedIn, _ := ctx.Value(retriesKey{}).(bool)
+	return optedIn
+}
+
+type envelope struct {
+	Data  json.RawMessage `json:"data"`
+	Error *APIError       `json:"error"`
+}
+
+// do sends req and decodes the data envelope into response, which may be
+// nil for routes without a body.
+func (c *Client) do(ctx context.Context, req request, response any) error {
+	data, _, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	if response == nil || len(data) == 0 {
+		return nil
+	}
+
+	var decoded envelope
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if len(decoded.Data) == 0 || string(decoded.Data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(decoded.Data, response); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// doRaw is do for routes that answer with a bare JSON document.
+func (c *Client) doRaw(ctx context.Context, req request, response any) error {
+	data, _, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, response); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// send performs req with retries and returns the raw body of a successful
+// response.
+func (c *Client) send(ctx context.Context, req request) ([]byte, http.Header, error) {
+	retryable := retryable(ctx, req)
+	backoff := c.retryBackoff
+
+	for attempt := 0; ; attempt++ {
+		data, header, err := c.sendOnce(ctx, req)
+		if err == nil {
+			return data, header, nil
+		}
+		if !retryable || attempt >= c.maxRetries || !isTransient(ctx, err) {
+			return nil, nil, err
+		}
+
+		wait := backoff
+		if apiErr, ok := AsError(err); ok && apiErr.retryAfter > wait {
+			wait = apiErr.retryAfter
+		}
+		backoff *= 2
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) sendOnce(ctx context.Context, req request) ([]byte, http.Header, error) {
+	res, err := c.open(ctx, c.httpClient, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		return nil, nil, responseError(res, data)
+	}
+	return data, res.Header, nil
+}
+
+func (c *Client) open(ctx context.Context, httpClient *http.Client, req request) (*http.Response, error) {
+	target := c.baseURL + "/" + strings.TrimLeft(req.path, "/")
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	var body io.Reader
+	if req.body != nil {
+		body = bytes.NewReader(req.body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	if req.contentType != "" {
+		httpReq.Header.Set("Content-Type", req.contentType)
+	}
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	return httpClient.Do(httpReq)
+}
+
+func responseError(res *http.Response, data []byte) *Error {
+	apiErr := &Error{Status: res.StatusCode}
+	if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.retryAfter = min(time.Duration(seconds)*time.Second, maxRetryAfter)
+	}
+
+	var decoded envelope
+	if err := json.Unmarshal(data, &decoded); err == nil && decoded.Error != nil && decoded.Error.Message != "" {
+		apiErr.APIError = *decoded.Error
+		return apiErr
+	}
+	apiErr.Message = fmt.Sprintf("%s %s: server returned %s", res.Request.Method, res.Request.URL, res.Status)
+	return apiErr
+}
+
+func isTransient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	apiErr, ok := AsError(err)
+	if !ok {
+		// the request never got an answer
+		return true
+	}
+	switch apiErr.Status {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func pathEscape(segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+	return strings.Join(escaped, "/")
+}
+
+func pageQuery(limit, offset int) url.Values {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	return query
+}
@@ -0,0 +1,249 @@
+package cosignclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cosign/internal/testutil"
+	"cosign/pkg/cosignclient"
+)
+
+func setupClient(t *testing.T) *cosignclient.Client {
+	t.Helper()
+
+	svc := testutil.SetupService(t)
+	server := httptest.NewServer(svc.BuildRouter())
+	t.Cleanup(server.Close)
+
+	return cosignclient.New(cosignclient.Options{
+		BaseURL: server.URL,
+		APIKey:  testutil.BootstrapToken,
+	})
+}
+
+func TestClientCampaignLifecycle(t *testing.T) {
+	client := setupClient(t)
+	ctx := context.Background()
+
+	campaign, err := client.CreateCampaign(ctx, "Save the park")
+	if err != nil {
+		t.Fatalf("create campaign: %v", err)
+	}
+
+	goal := 50
+	updated, err := client.UpdateCampaign(ctx, campaign.ID, cosignclient.UpdateCampaignRequest{
+		Name:     "Save the park",
+		Goal:     &goal,
+		Revision: &campaign.Revision,
+	})
+	if err != nil {
+		t.Fatalf("update campaign: %v", err)
+	}
+	if updated.Goal != 50 {
+		t.Fatalf("expected goal 50, got %d", updated.Goal)
+	}
+
+	_, err = client.UpdateCampaign(ctx, campaign.ID, cosignclient.UpdateCampaignRequest{
+		Name:     "Stale",
+		Revision: &campaign.Revision,
+	})
+	if !errors.Is(err, cosignclient.ErrRevisionConflict) {
+		t.Fatalf("expected revision conflict, got %v", err)
+	}
+
+	if err := client.DeleteCampaign(ctx, campaign.ID); err != nil {
+		t.Fatalf("delete campaign: %v", err)
+	}
+	_, err = client.GetCampaign(ctx, campaign.ID)
+	if !errors.Is(err, cosignclient.ErrCampaignNotFound) {
+		t.Fatalf("expected campaign not found, got %v", err)
+	}
+	apiErr, ok := cosignclient.AsError(err)
+	if !ok || apiErr.Status != http.StatusNotFound {
+		t.Fatalf("expected 404 api error, got %v", err)
+	}
+
+	trash, err := client.ListDeletedCampaigns(ctx, 10, 0)
+	if err != nil || trash.Total != 1 || trash.Campaigns[0].ID != campaign.ID {
+		t.Fatalf("expected the campaign in the trash, got %+v, %v", trash, err)
+	}
+	if _, err := client.RestoreCampaign(ctx, campaign.ID); err != nil {
+		t.Fatalf("restore campaign: %v", err)
+	}
+	if _, err := client.GetCampaign(ctx, campaign.ID); err != nil {
+		t.Fatalf("expected the restored campaign, got %v", err)
+	}
+}
+
+func TestClientSignaturesAndIterator(t *testing.T) {
+	client := setupClient(t)
+	ctx := context.Background()
+
+	campaign, err := client.CreateCampaign(ctx, "Petition")
+	if err != nil {
+		t.Fatalf("create campaign: %v", err)
+	}
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		_, err := client.CreateSignature(ctx, campaign.ID, cosignclient.CreateSignatureRequest{
+			Name: "Signer", Email: email, Location: "Brooklyn",
+		})
+		if err != nil {
+			t.Fatalf("create signature: %v", err)
+		}
+	}
+
+	_, err = client.CreateSignature(ctx, campaign.ID, cosignclient.CreateSignatureRequest{
+		Name: "Signer", Email: "a@example.com", Location: "Brooklyn",
+	})
+	apiErr, ok := cosignclient.AsError(err)
+	if !ok || !errors.Is(err, cosignclient.ErrDuplicateEmail) {
+		t.Fatalf("expected duplicate email, got %v", err)
+	}
+	if _, ok := apiErr.Field("email"); !ok {
+		t.Fatalf("expected email field error, got %+v", apiErr.Fields)
+	}
+
+	seen := 0
+	for signature, err := range client.AllSignatures(ctx, campaign.ID, 2) {
+		if err != nil {
+			t.Fatalf("iterate signatures: %v", err)
+		}
+		if signature.Email == "" {
+			t.Fatalf("expected admin signature with email")
+		}
+		seen++
+	}
+	if seen != 3 {
+		t.Fatalf("expected 3 signatures across pages, got %d", seen)
+	}
+
+	bulk, err := client.BulkSignatures(ctx, campaign.ID, cosignclient.BulkSignaturesRequest{
+		Action: cosignclient.BulkActionHide,
+		Filter: &cosignclient.SignatureFilter{Query: "b@"},
+	})
+	if err != nil || bulk.Affected != 1 {
+		t.Fatalf("expected one hidden signature, got %+v, %v", bulk, err)
+	}
+	hidden, err := client.ListSignaturesMatching(ctx, campaign.ID, cosignclient.SignatureFilter{Visibility: cosignclient.VisibilityHidden}, 10, 0)
+	if err != nil || hidden.Total != 1 || hidden.Signatures[0].Email != "b@example.com" {
+		t.Fatalf("expected the hidden signature, got %+v, %v", hidden, err)
+	}
+	if _, err := client.UndoBulkSignatures(ctx, campaign.ID, bulk.OperationID); err != nil {
+		t.Fatalf("undo bulk: %v", err)
+	}
+	if _, err := client.UndoBulkSignatures(ctx, campaign.ID, bulk.OperationID); !errors.Is(err, cosignclient.ErrBulkOperationNotFound) {
+		t.Fatalf("expected bulk operation not found, got %v", err)
+	}
+
+	deleted := hidden.Signatures[0].ID
+	if err := client.DeleteSignature(ctx, campaign.ID, deleted); err != nil {
+		t.Fatalf("delete signature: %v", err)
+	}
+	trash, err := client.ListDeletedSignatures(ctx, campaign.ID, 10, 0)
+	if err != nil || trash.Total != 1 || trash.Signatures[0].ID != deleted {
+		t.Fatalf("expected the signature in the trash, got %+v, %v", trash, err)
+	}
+	if _, err := client.RestoreSignature(ctx, campaign.ID, deleted); err != nil {
+		t.Fatalf("restore signature: %v", err)
+	}
+	if _, err := client.RestoreSignature(ctx, campaign.ID, deleted); !errors.Is(err, cosignclient.ErrSignatureNotFound) {
+		t.Fatalf("expected signature not found, got %v", err)
+	}
+}
+
+func TestClientRetriesTransientFailures(t *testing.T) {
+	var attempts atomic.Int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"status":"healthy"}}`))
+	}))
+	defer backend.Close()
+
+	client := cosignclient.New(cosignclient.Options{BaseURL: backend.URL, RetryBackoff: time.Millisecond})
+	health, err := client.Health(context.Background())
+	if err != nil || health.Status != "healthy" {
+		t.Fatalf("expected health after retries, got %v %v", health, err)
+	}
+	if attempts.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts.Load())
+	}
+
+	attempts.Store(0)
+	_, err = client.CreateWebhook(context.Background(), cosignclient.CreateWebhookRequest{URL: "https://example.org"})
+	apiErr, ok := cosignclient.AsError(err)
+	if !ok || apiErr.Status != http.StatusServiceUnavailable || attempts.Load() != 1 {
+		t.Fatalf("expected one unretried attempt, got %d attempts and %v", attempts.Load(), err)
+	}
+}
+
+func TestClientDoesNotReplayDeletes(t *testing.T) {
+	var attempts atomic.Int32
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer backend.Close()
+
+	client := cosignclient.New(cosignclient.Options{BaseURL: backend.URL, RetryBackoff: time.Millisecond})
+	err := client.DeleteWebhook(context.Background(), "hook")
+	apiErr, ok := cosignclient.AsError(err)
+	if !ok || apiErr.Status != http.StatusBadGateway || attempts.Load() != 1 {
+		t.Fatalf("expected one unretried delete, got %d attempts and %v", attempts.Load(), err)
+	}
+
+	attempts.Store(0)
+	_ = client.DeleteWebhook(cosignclient.WithRetries(context.Background()), "hook")
+	if attempts.Load() != 3 {
+		t.Fatalf("expected an opted-in delete to be retried twice, got %d attempts", attempts.Load())
+	}
+}
+
+func TestClientHonorsContext(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "busy", http.StatusTooManyRequests)
+	}))
+	defer backend.Close()
+
+	client := cosignclient.New(cosignclient.Options{BaseURL: backend.URL, RetryBackoff: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.Health(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded while waiting to retry, got %v", err)
+	}
+}
+
+func TestClientStreamsEvents(t *testing.T) {
+	client := setupClient(t)
+	ctx := context.Background()
+
+	campaign, err := client.CreateCampaign(ctx, "Petition")
+	if err != nil {
+		t.Fatalf("create campaign: %v", err)
+	}
+
+	for event, err := range client.Events(ctx, campaign.ID, "") {
+		if err != nil {
+			t.Fatalf("stream events: %v", err)
+		}
+		if event.Type != cosignclient.StreamEventCount || string(event.Data) != `{"count":0}` {
+			t.Fatalf("expected initial count event, got %+v", event)
+		}
+		break
+	}
+
+	for _, err := range client.Events(ctx, "missing", "") {
+		if !errors.Is(err, cosignclient.ErrCampaignNotFound) {
+			t.Fatalf("expected campaign not found, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package cosignclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+func locationsPath(campaignID string, segments ...string) string {
+	return "/admin/campaigns/" + pathEscape(append([]string{campaignID, "locations"}, segments...)...)
+}
+
+func locationPath(campaignID string, locationID int64, segments ...string) string {
+	return locationsPath(campaignID, append([]string{strconv.FormatInt(locationID, 10)}, segments...)...)
+}
+
+// GetLocations returns the campaign's location tree with its revision.
+func (c *Client) GetLocations(ctx context.Context, campaignID string) (*CampaignLocationsResponse, error) {
+	response := &CampaignLocationsResponse{}
+	if err := c.do(ctx, newRequest(http.MethodGet, locationsPath(campaignID)), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// SetLocations replaces the campaign's location tree; set
+// locations.Revision to fail with ErrRevisionConflict on a stale tree.
+func (c *Client) SetLocations(ctx context.Context, campaignID string, locations CampaignLocationsRequest) (*CampaignLocationsResponse, error) {
+	req, err := newRequest(http.MethodPut, locationsPath(campaignID)).withJSON(locations)
+	if err != nil {
+		return nil, err
+	}
+	response := &CampaignLocationsResponse{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetPublicLocations returns the location options signers choose from,
+// labelled in lang; an empty lang uses the campaign's default.
+func (c *Client) GetPublicLocations(ctx context.Context, campaignID string, lang string) (*CampaignLocationsResponse, error) {
+	req := newRequest(http.MethodGet, "/campaigns/"+pathEscape(campaignID, "locations")).withQuery(langQuery(lang))
+	response := &CampaignLocationsResponse{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) CreateLocation(ctx context.Context, campaignID string, location LocationOption) (*LocationOption, error) {
+	req, err := newRequest(http.MethodPost, locationsPath(campaignID)).withJSON(location)
+	if err != nil {
+		return nil, err
+	}
+	response := &LocationOption{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) GetLocation(ctx context.Context, campaignID string, locationID int64) (*LocationOption, error) {
+	response := &LocationOption{}
+	if err := c.do(ctx, newRequest(http.MethodGet, locationPath(campaignID, locationID)), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) UpdateLocation(ctx context.Context, campaignID string, locationID int64, update UpdateLocationRequest) (*LocationOption, error) {
+	req, err := newRequest(http.MethodPatch, locationPath(campaignID, locationID)).withJSON(update)
+	if err != nil {
+		return nil, err
+	}
+	response := &LocationOption{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) DeleteLocation(ctx context.Context, campaignID string, locationID int64) error {
+	return c.do(ctx, newRequest(http.MethodDelete, locationPath(campaignID, locationID)), nil)
+}
+
+func (c *Client) MoveLocation(ctx context.Context, campaignID string, locationID int64, move MoveLocationRequest) (*LocationOption, error) {
+	req, err := newRequest(http.MethodPost, locationPath(campaignID, locationID, "move")).withJSON(move)
+	if err != nil {
+		return nil, err
+	}
+	response := &LocationOption{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// MergeLocation folds locationID into intoID, rewriting its signatures.
+func (c *Client) MergeLocation(ctx context.Context, campaignID string, locationID int64, intoID int64) (*MergeLocationResponse, error) {
+	req, err := newRequest(http.MethodPost, locationPath(campaignID, locationID, "merge")).withJSON(MergeLocationRequest{IntoID: intoID})
+	if err != nil {
+		return nil, err
+	}
+	response := &MergeLocationResponse{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) LocationStats(ctx context.Context, campaignID string) (*CampaignLocationStats, error) {
+	response := &CampaignLocationStats{}
+	if err := c.do(ctx, newRequest(http.MethodGet, locationsPath(campaignID, "stats")), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) LocationSuggestions(ctx context.Context, campaignID string) (*LocationSuggestionsResponse, error) {
+	response := &LocationSuggestionsResponse{}
+	if err := c.do(ctx, newRequest(http.MethodGet, locationsPath(campaignID, "suggestions")), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// NormalizeLocations rewrites free-text signature locations to presets and
+// returns how many signatures changed.
+func (c *Client) NormalizeLocations(ctx context.Context, campaignID string, mappings []LocationMapping) (int, error) {
+	req, err := newRequest(http.MethodPost, locationsPath(campaignID, "normalize")).withJSON(NormalizeLocationsRequest{Mappings: mappings})
+	if err != nil {
+		return 0, err
+	}
+	response := &NormalizeLocationsResponse{}
+	if err := c.do(ctx, req, response); err != nil {
+		return 0, err
+	}
+	return response.Rewritten, nil
+}
+
+func (c *Client) ImportCoordinates(ctx context.Context, campaignID string, coordinates []LocationCoordinate) (*ImportCoordinatesResponse, error) {
+	req, err := newRequest(http.MethodPost, locationsPath(campaignID, "coordinates")).withJSON(ImportCoordinatesRequest{Coordinates: coordinates})
+	if err != nil {
+		return nil, err
+	}
+	response := &ImportCoordinatesResponse{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ImportGazetteer sets coordinates from a gazetteer CSV whose header row
+// names value, latitude, and longitude columns.
+func (c *Client) ImportGazetteer(ctx context.Context, campaignID string, csv []byte) (*ImportCoordinatesResponse, error) {
+	req := newRequest(http.MethodPost, locationsPath(campaignID, "coordinates"))
+	req.body = csv
+	req.contentType = "text/csv"
+	response := &ImportCoordinatesResponse{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// SignatureMap returns signature counts per mapped location as GeoJSON,
+// labelled in lang.
+func (c *Client) SignatureMap(ctx context.Context, campaignID string, lang string) (*GeoJSONFeatureCollection, error) {
+	req := newRequest(http.MethodGet, "/campaigns/"+pathEscape(campaignID, "locations", "geojson")).withQuery(langQuery(lang))
+	response := &GeoJSONFeatureCollection{}
+	if err := c.doRaw(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,50 @@
+package cosignclient
+
+import (
+	"context"
+	"net/http"
+)
+
+// ListAPIKeys lists issued keys by id; their secrets are never returned.
+func (c *Client) ListAPIKeys(ctx context.Context) (*APIKeys, error) {
+	response := &APIKeys{}
+	if err := c.do(ctx, newRequest(http.MethodGet, "/settings/keys"), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// CreateAPIKey issues a new API key and returns its token, which the API
+// never returns again.
+func (c *Client) CreateAPIKey(ctx context.Context) (string, error) {
+	var token string
+	if err := c.do(ctx, newRequest(http.MethodPost, "/settings/keys"), &token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// DeleteAPIKey revokes the key with the given id, the part of its token
+// before the dot.
+func (c *Client) DeleteAPIKey(ctx context.Context, id string) error {
+	return c.do(ctx, newRequest(http.MethodDelete, "/settings/keys/"+pathEscape(id)), nil)
+}
+
+// CORSOrigins lists the origins allowed to call the public routes from a
+// browser.
+func (c *Client) CORSOrigins(ctx context.Context) ([]AllowedOrigin, error) {
+	var origins []AllowedOrigin
+	if err := c.do(ctx, newRequest(http.MethodGet, "/settings/cors"), &origins); err != nil {
+		return nil, err
+	}
+	return origins, nil
+}
+
+// SetCORSOrigins replaces the allowed origins.
+func (c *Client) SetCORSOrigins(ctx context.Context, origins []AllowedOrigin) error {
+	req, err := newRequest(http.MethodPut, "/settings/cors").withJSON(origins)
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, req, nil)
+}
@@ -0,0 +1,224 @@
+package cosignclient
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+func (c *Client) ListSignatures(ctx context.Context, campaignID string, limit, offset int) (*Signatures, error) {
+	return c.ListSignaturesMatching(ctx, campaignID, SignatureFilter{}, limit, offset)
+}
+
+// ListSignaturesMatching lists the campaign's signatures that match
+// filter, newest first.
+func (c *Client) ListSignaturesMatching(ctx context.Context, campaignID string, filter SignatureFilter, limit, offset int) (*Signatures, error) {
+	query := pageQuery(limit, offset)
+	for key, value := range map[string]string{"q": filter.Query, "location": filter.Location, "visibility": filter.Visibility} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if filter.IDs != nil {
+		ids := make([]string, len(filter.IDs))
+		for idx, id := range filter.IDs {
+			ids[idx] = strconv.FormatInt(id, 10)
+		}
+		query.Set("ids", strings.Join(ids, ","))
+	}
+
+	req := newRequest(http.MethodGet, "/admin/campaigns/"+pathEscape(campaignID, "signatures")).withQuery(query)
+	response := &Signatures{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// AllSignatures pages through every signature of the campaign, pageSize at
+// a time.
+func (c *Client) AllSignatures(ctx context.Context, campaignID string, pageSize int) iter.Seq2[*Signature, error] {
+	return paginate(func(offset int) ([]*Signature, int, error) {
+		page, err := c.ListSignatures(ctx, campaignID, pageSize, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		return page.Signatures, page.Total, nil
+	})
+}
+
+// DeleteSignature moves the signature to the trash.
+func (c *Client) DeleteSignature(ctx context.Context, campaignID string, signatureID int64) error {
+	path := "/admin/campaigns/" + pathEscape(campaignID, "signatures", strconv.FormatInt(signatureID, 10))
+	return c.do(ctx, newRequest(http.MethodDelete, path), nil)
+}
+
+// ListDeletedSignatures returns one page of the campaign's signatures in the
+// trash, most recently deleted first.
+func (c *Client) ListDeletedSignatures(ctx context.Context, campaignID string, limit, offset int) (*Signatures, error) {
+	req := newRequest(http.MethodGet, "/admin/campaigns/"+pathEscape(campaignID, "signatures", "trash")).withQuery(pageQuery(limit, offset))
+	response := &Signatures{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) RestoreSignature(ctx context.Context, campaignID string, signatureID int64) (*Signature, error) {
+	path := "/admin/campaigns/" + pathEscape(campaignID, "signatures", strconv.FormatInt(signatureID, 10), "restore")
+	response := &Signature{}
+	if err := c.do(ctx, newRequest(http.MethodPost, path), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// CreateSignature signs the campaign under a generated Idempotency-Key, so
+// a retry is answered with the first response instead of ErrDuplicateEmail.
+func (c *Client) CreateSignature(ctx context.Context, campaignID string, signature CreateSignatureRequest) (*Signature, error) {
+	req, err := newRequest(http.MethodPost, "/campaigns/"+pathEscape(campaignID, "signatures")).withJSON(signature)
+	if err != nil {
+		return nil, err
+	}
+	response := &Signature{}
+	if err := c.do(ctx, req.withIdempotencyKey(), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ImportSignatures adds up to MaxImportBatch signatures, checking each on
+// its own and reporting every row; a dry run only checks them.
+func (c *Client) ImportSignatures(ctx context.Context, campaignID string, batch ImportSignaturesRequest) (*ImportSignaturesResponse, error) {
+	req, err := newRequest(http.MethodPost, "/admin/campaigns/"+pathEscape(campaignID, "signatures", "import")).withJSON(batch)
+	if err != nil {
+		return nil, err
+	}
+	response := &ImportSignaturesResponse{}
+	if err := c.do(ctx, req.withIdempotencyKey(), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// BulkSignatures deletes, hides or unhides the listed or matching
+// signatures in one go; the response names the operation to undo.
+func (c *Client) BulkSignatures(ctx context.Context, campaignID string, bulk BulkSignaturesRequest) (*BulkSignaturesResponse, error) {
+	req, err := newRequest(http.MethodPost, "/admin/campaigns/"+pathEscape(campaignID, "signatures", "bulk")).withJSON(bulk)
+	if err != nil {
+		return nil, err
+	}
+	response := &BulkSignaturesResponse{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetSignatureBulkOperation returns a bulk action that has not been undone.
+func (c *Client) GetSignatureBulkOperation(ctx context.Context, campaignID, operationID string) (*SignatureBulkOperation, error) {
+	req := newRequest(http.MethodGet, "/admin/campaigns/"+pathEscape(campaignID, "signatures", "bulk", operationID))
+	response := &SignatureBulkOperation{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// UndoBulkSignatures reverts a bulk action while its undo window is open.
+func (c *Client) UndoBulkSignatures(ctx context.Context, campaignID, operationID string) (*BulkUndoResponse, error) {
+	req := newRequest(http.MethodPost, "/admin/campaigns/"+pathEscape(campaignID, "signatures", "bulk", operationID, "undo"))
+	response := &BulkUndoResponse{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// SignatureStats returns the campaign's signature totals and its daily
+// counts for the last days days; zero uses the API's default.
+func (c *Client) SignatureStats(ctx context.Context, campaignID string, days int) (*SignatureStats, error) {
+	req := newRequest(http.MethodGet, "/admin/campaigns/"+pathEscape(campaignID, "signatures", "stats"))
+	if days > 0 {
+		req = req.withQuery(url.Values{"days": {strconv.Itoa(days)}})
+	}
+	response := &SignatureStats{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Event is one server-sent event from a campaign's stream. Data holds a
+// StreamCount for StreamEventCount and a PublicSignature for
+// StreamEventSignature.
+type Event struct {
+	ID   string
+	Type string
+	Data json.RawMessage
+}
+
+// Events streams the campaign's events, resuming after lastEventID when it
+// is set. The stream ends when ctx is cancelled or the server closes it;
+// reconnect with the last ID seen to continue.
+func (c *Client) Events(ctx context.Context, campaignID string, lastEventID string) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		req := newRequest(http.MethodGet, "/campaigns/"+pathEscape(campaignID, "events"))
+		req.header.Set("Accept", "text/event-stream")
+		if lastEventID != "" {
+			req.header.Set("Last-Event-ID", lastEventID)
+		}
+
+		streamClient := *c.httpClient
+		streamClient.Timeout = 0
+		res, err := c.open(ctx, &streamClient, req)
+		if err != nil {
+			yield(Event{}, err)
+			return
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode >= http.StatusBadRequest {
+			data, _ := io.ReadAll(res.Body)
+			yield(Event{}, responseError(res, data))
+			return
+		}
+
+		var event Event
+		var data []string
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if line == "" {
+				if event.Type != "" || len(data) > 0 {
+					event.Data = json.RawMessage(strings.Join(data, "\n"))
+					if !yield(event, nil) {
+						return
+					}
+				}
+				event, data = Event{}, nil
+				continue
+			}
+
+			field, value, _ := strings.Cut(line, ":")
+			value = strings.TrimPrefix(value, " ")
+			switch field {
+			case "id":
+				event.ID = value
+			case "event":
+				event.Type = value
+			case "data":
+				data = append(data, value)
+			}
+		}
+		if err := scanner.Err(); err != nil && ctx.Err() == nil {
+			yield(Event{}, err)
+		}
+	}
+}
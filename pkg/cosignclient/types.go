@@ -0,0 +1,141 @@
+package cosignclient
+
+import (
+	"cosign/pkg/cosignapi"
+
+	"git.sr.ht/~jakintosh/command-go/pkg/cors"
+)
+
+// The request and response types are the API's own from cosignapi, which
+// the service shares, so the client cannot drift from the API.
+type (
+	APIError   = cosignapi.APIError
+	FieldError = cosignapi.FieldError
+
+	HealthResponse = cosignapi.HealthResponse
+
+	Campaign              = cosignapi.Campaign
+	Campaigns             = cosignapi.Campaigns
+	CampaignTheme         = cosignapi.CampaignTheme
+	CampaignTranslation   = cosignapi.CampaignTranslation
+	CreateCampaignRequest = cosignapi.CreateCampaignRequest
+	UpdateCampaignRequest = cosignapi.UpdateCampaignRequest
+
+	LocationOption              = cosignapi.LocationOption
+	CampaignLocationsRequest    = cosignapi.CampaignLocationsRequest
+	CampaignLocationsResponse   = cosignapi.CampaignLocationsResponse
+	UpdateLocationRequest       = cosignapi.UpdateLocationRequest
+	MoveLocationRequest         = cosignapi.MoveLocationRequest
+	MergeLocationRequest        = cosignapi.MergeLocationRequest
+	MergeLocationResponse       = cosignapi.MergeLocationResponse
+	LocationStat                = cosignapi.LocationStat
+	CampaignLocationStats       = cosignapi.CampaignLocationStats
+	LocationSuggestion          = cosignapi.LocationSuggestion
+	LocationSuggestionsResponse = cosignapi.LocationSuggestionsResponse
+	LocationMapping             = cosignapi.LocationMapping
+	NormalizeLocationsRequest   = cosignapi.NormalizeLocationsRequest
+	NormalizeLocationsResponse  = cosignapi.NormalizeLocationsResponse
+	LocationCoordinate          = cosignapi.LocationCoordinate
+	ImportCoordinatesRequest    = cosignapi.ImportCoordinatesRequest
+	ImportCoordinatesResponse   = cosignapi.ImportCoordinatesResponse
+	GeoJSONFeatureCollection    = cosignapi.GeoJSONFeatureCollection
+	GeoJSONFeature              = cosignapi.GeoJSONFeature
+	GeoJSONPoint                = cosignapi.GeoJSONPoint
+	SignatureMapLocation        = cosignapi.SignatureMapLocation
+	UnmappedLocations           = cosignapi.UnmappedLocations
+	UnmappedLocation            = cosignapi.UnmappedLocation
+
+	Signature                = cosignapi.Signature
+	Signatures               = cosignapi.Signatures
+	SignatureFilter          = cosignapi.SignatureFilter
+	BulkSignaturesRequest    = cosignapi.BulkSignaturesRequest
+	BulkSignaturesResponse   = cosignapi.BulkSignaturesResponse
+	BulkUndoResponse         = cosignapi.BulkUndoResponse
+	SignatureBulkOperation   = cosignapi.SignatureBulkOperation
+	CreateSignatureRequest   = cosignapi.CreateSignatureRequest
+	ImportSignaturesRequest  = cosignapi.ImportSignaturesRequest
+	ImportSignaturesResponse = cosignapi.ImportSignaturesResponse
+	ImportSignatureResult    = cosignapi.ImportSignatureResult
+	SignatureStats           = cosignapi.SignatureStats
+	DailySignatureCount      = cosignapi.DailySignatureCount
+	LocationCount            = cosignapi.LocationCount
+	StreamCount              = cosignapi.StreamCount
+	PublicSignature          = cosignapi.PublicSignature
+
+	Webhook              = cosignapi.Webhook
+	Webhooks             = cosignapi.Webhooks
+	CreateWebhookRequest = cosignapi.CreateWebhookRequest
+	WebhookDelivery      = cosignapi.WebhookDelivery
+	WebhookDeliveries    = cosignapi.WebhookDeliveries
+	WebhookEvent         = cosignapi.WebhookEvent
+
+	APIKey        = cosignapi.APIKey
+	APIKeys       = cosignapi.APIKeys
+	AllowedOrigin = cors.AllowedOrigin
+)
+
+const (
+	MaxImportBatch = cosignapi.MaxImportBatch
+	MaxBulkIDs     = cosignapi.MaxBulkIDs
+)
+
+const (
+	StreamEventCount     = cosignapi.StreamEventCount
+	StreamEventSignature = cosignapi.StreamEventSignature
+
+	DeliveryStatusPending   = cosignapi.DeliveryStatusPending
+	DeliveryStatusSucceeded = cosignapi.DeliveryStatusSucceeded
+	DeliveryStatusDead      = cosignapi.DeliveryStatusDead
+
+	VisibilityVisible = cosignapi.VisibilityVisible
+	VisibilityHidden  = cosignapi.VisibilityHidden
+
+	BulkActionDelete = cosignapi.BulkActionDelete
+	BulkActionHide   = cosignapi.BulkActionHide
+	BulkActionUnhide = cosignapi.BulkActionUnhide
+)
+
+// Errors an *Error unwraps to, for errors.Is checks.
+var (
+	ErrCampaignNotFound        = cosignapi.ErrCampaignNotFound
+	ErrSignatureNotFound       = cosignapi.ErrSignatureNotFound
+	ErrInvalidEmail            = cosignapi.ErrInvalidEmail
+	ErrDuplicateEmail          = cosignapi.ErrDuplicateEmail
+	ErrLocationNotInOptions    = cosignapi.ErrLocationNotInOptions
+	ErrEmptyName               = cosignapi.ErrEmptyName
+	ErrEmptyEmail              = cosignapi.ErrEmptyEmail
+	ErrEmptyLocation           = cosignapi.ErrEmptyLocation
+	ErrEmptyCampaignName       = cosignapi.ErrEmptyCampaignName
+	ErrInvalidThemeColor       = cosignapi.ErrInvalidThemeColor
+	ErrInvalidThemeLogoURL     = cosignapi.ErrInvalidThemeLogoURL
+	ErrInvalidRedirectURL      = cosignapi.ErrInvalidRedirectURL
+	ErrRedirectNotAllowed      = cosignapi.ErrRedirectNotAllowed
+	ErrInvalidGoal             = cosignapi.ErrInvalidGoal
+	ErrInvalidLanguage         = cosignapi.ErrInvalidLanguage
+	ErrLocationNotFound        = cosignapi.ErrLocationNotFound
+	ErrDuplicateLocation       = cosignapi.ErrDuplicateLocation
+	ErrDuplicateLocationAlias  = cosignapi.ErrDuplicateLocationAlias
+	ErrUnknownLocationParent   = cosignapi.ErrUnknownLocationParent
+	ErrLocationCycle           = cosignapi.ErrLocationCycle
+	ErrInvalidCountryCode      = cosignapi.ErrInvalidCountryCode
+	ErrInvalidSubdivisionCode  = cosignapi.ErrInvalidSubdivisionCode
+	ErrInvalidLocationLevel    = cosignapi.ErrInvalidLocationLevel
+	ErrInvalidCoordinates      = cosignapi.ErrInvalidCoordinates
+	ErrInvalidGazetteer        = cosignapi.ErrInvalidGazetteer
+	ErrMergeIntoSelf           = cosignapi.ErrMergeIntoSelf
+	ErrRevisionConflict        = cosignapi.ErrRevisionConflict
+	ErrWebhookNotFound         = cosignapi.ErrWebhookNotFound
+	ErrWebhookDeliveryNotFound = cosignapi.ErrWebhookDeliveryNotFound
+	ErrInvalidWebhookURL       = cosignapi.ErrInvalidWebhookURL
+	ErrInvalidWebhookEvent     = cosignapi.ErrInvalidWebhookEvent
+	ErrEmptyWebhookEvents      = cosignapi.ErrEmptyWebhookEvents
+	ErrImportBatchTooLarge     = cosignapi.ErrImportBatchTooLarge
+	ErrInvalidStatsDays        = cosignapi.ErrInvalidStatsDays
+	ErrInvalidVisibility       = cosignapi.ErrInvalidVisibility
+	ErrInvalidBulkAction       = cosignapi.ErrInvalidBulkAction
+	ErrBulkTargetRequired      = cosignapi.ErrBulkTargetRequired
+	ErrTooManyBulkIDs          = cosignapi.ErrTooManyBulkIDs
+	ErrBulkOperationNotFound   = cosignapi.ErrBulkOperationNotFound
+	ErrBulkUndoExpired         = cosignapi.ErrBulkUndoExpired
+	ErrTooManyStreams          = cosignapi.ErrTooManyStreams
+)
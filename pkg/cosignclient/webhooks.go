@@ -0,0 +1,86 @@
+package cosignclient
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ListWebhooks returns all webhooks, or only those scoped to campaignID
+// when it is set.
+func (c *Client) ListWebhooks(ctx context.Context, campaignID string) (*Webhooks, error) {
+	query := url.Values{}
+	if campaignID != "" {
+		query.Set("campaign_id", campaignID)
+	}
+	response := &Webhooks{}
+	if err := c.do(ctx, newRequest(http.MethodGet, "/admin/webhooks").withQuery(query), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// CreateWebhook registers a webhook. The response carries the signing
+// secret, which the API never returns again.
+func (c *Client) CreateWebhook(ctx context.Context, webhook CreateWebhookRequest) (*Webhook, error) {
+	req, err := newRequest(http.MethodPost, "/admin/webhooks").withJSON(webhook)
+	if err != nil {
+		return nil, err
+	}
+	response := &Webhook{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) GetWebhook(ctx context.Context, webhookID string) (*Webhook, error) {
+	response := &Webhook{}
+	if err := c.do(ctx, newRequest(http.MethodGet, "/admin/webhooks/"+pathEscape(webhookID)), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, webhookID string) error {
+	return c.do(ctx, newRequest(http.MethodDelete, "/admin/webhooks/"+pathEscape(webhookID)), nil)
+}
+
+// ListWebhookDeliveries returns one page of the webhook's deliveries,
+// filtered to status when it is set.
+func (c *Client) ListWebhookDeliveries(ctx context.Context, webhookID string, status string, limit, offset int) (*WebhookDeliveries, error) {
+	query := pageQuery(limit, offset)
+	if status != "" {
+		query.Set("status", status)
+	}
+	req := newRequest(http.MethodGet, "/admin/webhooks/"+pathEscape(webhookID, "deliveries")).withQuery(query)
+	response := &WebhookDeliveries{}
+	if err := c.do(ctx, req, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// AllWebhookDeliveries pages through the webhook's deliveries, pageSize at
+// a time.
+func (c *Client) AllWebhookDeliveries(ctx context.Context, webhookID string, status string, pageSize int) iter.Seq2[*WebhookDelivery, error] {
+	return paginate(func(offset int) ([]*WebhookDelivery, int, error) {
+		page, err := c.ListWebhookDeliveries(ctx, webhookID, status, pageSize, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		return page.Deliveries, page.Total, nil
+	})
+}
+
+// ReplayWebhookDelivery queues a delivery to be sent again.
+func (c *Client) ReplayWebhookDelivery(ctx context.Context, webhookID string, deliveryID int64) (*WebhookDelivery, error) {
+	path := "/admin/webhooks/" + pathEscape(webhookID, "deliveries", strconv.FormatInt(deliveryID, 10), "replay")
+	response := &WebhookDelivery{}
+	if err := c.do(ctx, newRequest(http.MethodPost, path), response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}